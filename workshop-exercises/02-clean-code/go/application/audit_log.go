@@ -0,0 +1,305 @@
+package application
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// AUDIT LOG
+// Append-only record of who did what to which order, hash-chained so that
+// editing, removing or reordering an entry breaks the chain
+// =============================================================================
+
+var ErrAuditLogCorrupted = errors.New("audit log corrupted")
+
+type AuditAction string
+
+const (
+	AuditActionOrderPlace   AuditAction = "order.place"
+	AuditActionOrderCapture AuditAction = "order.capture"
+	AuditActionOrderRefund  AuditAction = "order.refund"
+	AuditActionOrderCancel  AuditAction = "order.cancel"
+	AuditActionOrderFulfill AuditAction = "order.fulfill"
+	AuditActionPaymentEvent AuditAction = "order.payment_event"
+
+	AuditActionPaymentCharge    AuditAction = "payment.charge"
+	AuditActionPaymentAuthorize AuditAction = "payment.authorize"
+	AuditActionPaymentCapture   AuditAction = "payment.capture"
+	AuditActionPaymentRelease   AuditAction = "payment.release"
+	AuditActionPaymentRefund    AuditAction = "payment.refund"
+	AuditActionPaymentVoid      AuditAction = "payment.void"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSucceeded AuditOutcome = "succeeded"
+	AuditOutcomeFailed    AuditOutcome = "failed"
+)
+
+// auditGenesisHash is the PrevHash of the first entry.
+var auditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditEntry is one recorded action. Fee is the processor fee charged, or
+// for a refund the fee given back; Detail says what else the action was
+// about, such as the provider event applied. Sequence, PrevHash and Hash
+// are set by the log when the entry is appended.
+type AuditEntry struct {
+	Sequence      uint64        `json:"seq"`
+	At            time.Time     `json:"at"`
+	Actor         string        `json:"actor"`
+	Action        AuditAction   `json:"action"`
+	OrderID       string        `json:"orderId,omitempty"`
+	TransactionID string        `json:"transactionId,omitempty"`
+	Processor     ProcessorType `json:"processor,omitempty"`
+	Amount        Money         `json:"amount"`
+	Fee           Money         `json:"fee"`
+	Detail        string        `json:"detail,omitempty"`
+	Outcome       AuditOutcome  `json:"outcome"`
+	Error         string        `json:"error,omitempty"`
+	PrevHash      string        `json:"prevHash"`
+	Hash          string        `json:"hash"`
+}
+
+// computeHash covers every field but Hash itself, PrevHash included, which
+// is what links the entry to the one before it.
+func (e AuditEntry) computeHash() (string, error) {
+	e.Hash = ""
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("encode audit entry %d: %w", e.Sequence, err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditQuery selects entries by order and by time. An empty OrderID matches
+// every order; a zero From or To leaves that end of the range open. From is
+// inclusive, To exclusive.
+type AuditQuery struct {
+	OrderID string
+	From    time.Time
+	To      time.Time
+}
+
+func (q AuditQuery) matches(entry AuditEntry) bool {
+	if q.OrderID != "" && entry.OrderID != q.OrderID {
+		return false
+	}
+	if !q.From.IsZero() && entry.At.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.At.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// AuditLogInterface appends entries to the chain and returns them as stored.
+// Query returns matching entries oldest first.
+type AuditLogInterface interface {
+	Append(entry AuditEntry) (AuditEntry, error)
+	Query(query AuditQuery) ([]AuditEntry, error)
+}
+
+// auditChain is the chain state shared by the log implementations.
+type auditChain struct {
+	entries []AuditEntry
+}
+
+func (c *auditChain) head() (uint64, string) {
+	if len(c.entries) == 0 {
+		return 0, auditGenesisHash
+	}
+	last := c.entries[len(c.entries)-1]
+	return last.Sequence, last.Hash
+}
+
+// link makes entry the next one in the chain without adding it yet.
+func (c *auditChain) link(entry AuditEntry) (AuditEntry, error) {
+	sequence, prevHash := c.head()
+	entry.Sequence = sequence + 1
+	entry.At = entry.At.UTC()
+	entry.PrevHash = prevHash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	entry.Hash = hash
+	return entry, nil
+}
+
+func (c *auditChain) query(query AuditQuery) []AuditEntry {
+	var matching []AuditEntry
+	for _, entry := range c.entries {
+		if query.matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+type InMemoryAuditLog struct {
+	mu    sync.Mutex
+	chain auditChain
+}
+
+func NewInMemoryAuditLog() AuditLogInterface {
+	return &InMemoryAuditLog{}
+}
+
+func (l *InMemoryAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	linked, err := l.chain.link(entry)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	l.chain.entries = append(l.chain.entries, linked)
+	return linked, nil
+}
+
+func (l *InMemoryAuditLog) Query(query AuditQuery) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.chain.query(query), nil
+}
+
+// =============================================================================
+// AUDIT VERIFICATION
+// =============================================================================
+
+// AuditViolation is one place where the chain does not hold. Line is the
+// 1-based line in the log file, or the position in the slice verified.
+type AuditViolation struct {
+	Line     int
+	Sequence uint64
+	Problem  string
+}
+
+func (v AuditViolation) String() string {
+	return fmt.Sprintf("line %d (entry %d): %s", v.Line, v.Sequence, v.Problem)
+}
+
+// AuditReport is the result of verifying a log. Head is the hash of the last
+// entry: entries cut off the end leave a valid chain, so only a head
+// recorded elsewhere beforehand shows that they are gone (see Contains).
+type AuditReport struct {
+	Entries    int
+	Head       string
+	Violations []AuditViolation
+	hashes     map[string]bool
+}
+
+func (r AuditReport) Valid() bool {
+	return len(r.Violations) == 0
+}
+
+// Contains reports whether an entry with the given hash was verified.
+func (r AuditReport) Contains(hash string) bool {
+	return r.hashes[hash]
+}
+
+// auditVerifier checks entries one at a time. After a violation it carries
+// on from the entry as found, so one edit is reported once and not again
+// for every entry after it.
+type auditVerifier struct {
+	report   AuditReport
+	sequence uint64
+	prevHash string
+}
+
+func newAuditVerifier() *auditVerifier {
+	return &auditVerifier{
+		report:   AuditReport{Head: auditGenesisHash, hashes: make(map[string]bool)},
+		prevHash: auditGenesisHash,
+	}
+}
+
+func (v *auditVerifier) check(line int, entry AuditEntry) {
+	v.report.Entries++
+	if entry.Sequence != v.sequence+1 {
+		v.violation(line, entry.Sequence, fmt.Sprintf("expected entry %d: entries missing or out of order", v.sequence+1))
+	}
+	if entry.PrevHash != v.prevHash {
+		v.violation(line, entry.Sequence, "previous hash does not match the entry before it")
+	}
+	if hash, err := entry.computeHash(); err != nil || hash != entry.Hash {
+		v.violation(line, entry.Sequence, "hash does not match the entry's contents")
+	}
+	v.sequence = entry.Sequence
+	v.prevHash = entry.Hash
+	v.report.Head = entry.Hash
+	v.report.hashes[entry.Hash] = true
+}
+
+func (v *auditVerifier) malformed(line int, err error) {
+	v.violation(line, 0, fmt.Sprintf("unreadable entry: %v", err))
+}
+
+func (v *auditVerifier) violation(line int, sequence uint64, problem string) {
+	v.report.Violations = append(v.report.Violations, AuditViolation{Line: line, Sequence: sequence, Problem: problem})
+}
+
+// VerifyAuditEntries checks that entries form one unbroken chain from the
+// first entry on.
+func VerifyAuditEntries(entries []AuditEntry) AuditReport {
+	verifier := newAuditVerifier()
+	for i, entry := range entries {
+		verifier.check(i+1, entry)
+	}
+	return verifier.report
+}
+
+// VerifyAuditLog checks a log written by FileAuditLog. Lines that cannot be
+// read are reported as violations; only a failure to read the input is an
+// error.
+func VerifyAuditLog(r io.Reader) (AuditReport, error) {
+	verifier := newAuditVerifier()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxAuditLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			verifier.malformed(line, err)
+			continue
+		}
+		verifier.check(line, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return AuditReport{}, fmt.Errorf("read audit log: %w", err)
+	}
+	return verifier.report, nil
+}
+
+// =============================================================================
+// AUDIT ACTOR
+// =============================================================================
+
+// DefaultAuditActor is recorded when nobody was named on the context.
+const DefaultAuditActor = "system"
+
+type auditActorKey struct{}
+
+// WithAuditActor names who is acting for the operations run with ctx, e.g.
+// the signed-in user or "webhook".
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func AuditActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(auditActorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return DefaultAuditActor
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// AUDIT LOG TESTS
+// Testing: audit_log.go
+// =============================================================================
+
+var auditTestStart = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func appendTestAuditEntries(t *testing.T, log AuditLogInterface, orderIDs ...string) []AuditEntry {
+	t.Helper()
+	var entries []AuditEntry
+	for i, orderID := range orderIDs {
+		entry, err := log.Append(AuditEntry{
+			At:      auditTestStart.Add(time.Duration(i) * time.Minute),
+			Actor:   "alice",
+			Action:  AuditActionOrderPlace,
+			OrderID: orderID,
+			Amount:  MustParseMoney("10.00", USD),
+			Outcome: AuditOutcomeSucceeded,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error appending, got %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestInMemoryAuditLog_Append_ChainsEntries(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+
+	// Act
+	entries := appendTestAuditEntries(t, log, "order_1", "order_2")
+
+	// Assert
+	if entries[0].Sequence != 1 || entries[0].PrevHash != auditGenesisHash {
+		t.Errorf("Expected the first entry to start the chain, got %+v", entries[0])
+	}
+	if entries[1].Sequence != 2 || entries[1].PrevHash != entries[0].Hash {
+		t.Errorf("Expected the second entry to link to the first, got %+v", entries[1])
+	}
+	if report := VerifyAuditEntries(entries); !report.Valid() || report.Head != entries[1].Hash {
+		t.Errorf("Expected a valid chain ending at the last entry, got %+v", report)
+	}
+}
+
+func TestInMemoryAuditLog_Query_FiltersByOrderAndTimeRange(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	appendTestAuditEntries(t, log, "order_1", "order_2", "order_1", "order_1")
+
+	tests := []struct {
+		name      string
+		query     AuditQuery
+		sequences []uint64
+	}{
+		{"everything", AuditQuery{}, []uint64{1, 2, 3, 4}},
+		{"by order", AuditQuery{OrderID: "order_1"}, []uint64{1, 3, 4}},
+		{"from inclusive", AuditQuery{From: auditTestStart.Add(2 * time.Minute)}, []uint64{3, 4}},
+		{"to exclusive", AuditQuery{To: auditTestStart.Add(2 * time.Minute)}, []uint64{1, 2}},
+		{"order within range", AuditQuery{OrderID: "order_1", From: auditTestStart.Add(time.Minute), To: auditTestStart.Add(3 * time.Minute)}, []uint64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			entries, err := log.Query(tt.query)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(entries) != len(tt.sequences) {
+				t.Fatalf("Expected entries %v, got %d", tt.sequences, len(entries))
+			}
+			for i, entry := range entries {
+				if entry.Sequence != tt.sequences[i] {
+					t.Errorf("Expected entry %d at %d, got %d", tt.sequences[i], i, entry.Sequence)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyAuditEntries_DetectsTampering(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(entries []AuditEntry) []AuditEntry
+		problem string
+	}{
+		{"edited amount", func(entries []AuditEntry) []AuditEntry {
+			entries[1].Amount = MustParseMoney("1.00", USD)
+			return entries
+		}, "hash does not match"},
+		{"edited and rehashed", func(entries []AuditEntry) []AuditEntry {
+			entries[1].Actor = "mallory"
+			entries[1].Hash, _ = entries[1].computeHash()
+			return entries
+		}, "previous hash does not match"},
+		{"removed entry", func(entries []AuditEntry) []AuditEntry {
+			return append(entries[:1:1], entries[2:]...)
+		}, "entries missing or out of order"},
+		{"swapped entries", func(entries []AuditEntry) []AuditEntry {
+			entries[1], entries[2] = entries[2], entries[1]
+			return entries
+		}, "entries missing or out of order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			entries := appendTestAuditEntries(t, NewInMemoryAuditLog(), "order_1", "order_2", "order_3")
+
+			// Act
+			report := VerifyAuditEntries(tt.tamper(entries))
+
+			// Assert
+			if report.Valid() {
+				t.Fatal("Expected the tampering to be found")
+			}
+			if !strings.Contains(report.Violations[0].Problem, tt.problem) {
+				t.Errorf("Expected %q, got %v", tt.problem, report.Violations)
+			}
+		})
+	}
+}
+
+func TestVerifyAuditLog_ReportsUnreadableLineAndCarriesOn(t *testing.T) {
+	// Arrange
+	entries := appendTestAuditEntries(t, NewInMemoryAuditLog(), "order_1", "order_2")
+	var log strings.Builder
+	for _, entry := range entries {
+		line, _ := json.Marshal(entry)
+		log.Write(line)
+		log.WriteString("\n")
+		if entry.Sequence == 1 {
+			log.WriteString("not json\n")
+		}
+	}
+
+	// Act
+	report, err := VerifyAuditLog(strings.NewReader(log.String()))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Line != 2 {
+		t.Errorf("Expected only line 2 reported, got %v", report.Violations)
+	}
+	if report.Entries != 2 || !report.Contains(entries[0].Hash) || report.Head != entries[1].Hash {
+		t.Errorf("Expected both entries verified, got %+v", report)
+	}
+}
+
+func TestAuditActorFromContext_DefaultsToSystem(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Act & Assert
+	if actor := AuditActorFromContext(ctx); actor != DefaultAuditActor {
+		t.Errorf("Expected %s, got %s", DefaultAuditActor, actor)
+	}
+	if actor := AuditActorFromContext(WithAuditActor(ctx, "alice")); actor != "alice" {
+		t.Errorf("Expected alice, got %s", actor)
+	}
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+)
+
+// =============================================================================
+// AUDITOR
+// Fills in who, when and how it went, and appends to the audit log
+// =============================================================================
+
+// AuditorInterface records an action after it happened. err is the action's
+// own error, not an audit failure: recording never fails the action, which
+// by then has already charged or refunded.
+type AuditorInterface interface {
+	Record(ctx context.Context, entry AuditEntry, err error)
+}
+
+// AuditorConfig leaves Clock at the system clock when nil. OnFailure is
+// called with entries the log could not store; without it they are lost.
+type AuditorConfig struct {
+	Clock     Clock
+	OnFailure func(entry AuditEntry, err error)
+}
+
+type Auditor struct {
+	log       AuditLogInterface
+	clock     Clock
+	onFailure func(entry AuditEntry, err error)
+}
+
+func NewAuditor(log AuditLogInterface, config AuditorConfig) AuditorInterface {
+	auditor := &Auditor{log: log, clock: config.Clock, onFailure: config.OnFailure}
+	if auditor.clock == nil {
+		auditor.clock = NewSystemClock()
+	}
+	return auditor
+}
+
+func (a *Auditor) Record(ctx context.Context, entry AuditEntry, err error) {
+	entry.At = a.clock.Now()
+	entry.Actor = AuditActorFromContext(ctx)
+	entry.Outcome = AuditOutcomeSucceeded
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailed
+		entry.Error = err.Error()
+	}
+	if _, appendErr := a.log.Append(entry); appendErr != nil && a.onFailure != nil {
+		a.onFailure(entry, appendErr)
+	}
+}
+
+// =============================================================================
+// AUDITING PROCESSOR
+// Decorator that records every call made to the processor it wraps
+// =============================================================================
+
+type AuditingProcessor struct {
+	processor PaymentProcessorInterface
+	auditor   AuditorInterface
+}
+
+func NewAuditingProcessor(processor PaymentProcessorInterface, auditor AuditorInterface) PaymentProcessorInterface {
+	return &AuditingProcessor{processor: processor, auditor: auditor}
+}
+
+func (p *AuditingProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	result, err := p.processor.ProcessPayment(ctx, request)
+	p.auditor.Record(ctx, paymentAuditEntry(AuditActionPaymentCharge, request.OrderID, "", request.Amount, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	result, err := p.processor.Authorize(ctx, request)
+	p.auditor.Record(ctx, paymentAuditEntry(AuditActionPaymentAuthorize, request.OrderID, "", request.Amount, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	result, err := p.processor.Capture(ctx, authorizationID, amount)
+	p.auditor.Record(ctx, paymentAuditEntry(AuditActionPaymentCapture, "", authorizationID, amount, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	result, err := p.processor.ReleaseAuthorization(ctx, authorizationID)
+	p.auditor.Record(ctx, paymentAuditEntry(AuditActionPaymentRelease, "", authorizationID, Money{}, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	result, err := p.processor.Refund(ctx, request)
+	p.auditor.Record(ctx, refundAuditEntry(AuditActionPaymentRefund, request.TransactionID, request.Amount, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	result, err := p.processor.Void(ctx, transactionID)
+	p.auditor.Record(ctx, refundAuditEntry(AuditActionPaymentVoid, transactionID, Money{}, result), err)
+	return result, err
+}
+
+func (p *AuditingProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return QuoteFee(ctx, p.processor, request)
+}
+
+// paymentAuditEntry takes the amounts from result when the processor
+// returned one, and otherwise records what was asked for.
+func paymentAuditEntry(action AuditAction, orderID string, transactionID string, requested Money, result PaymentResult) AuditEntry {
+	entry := AuditEntry{
+		Action:        action,
+		OrderID:       orderID,
+		TransactionID: transactionID,
+		Processor:     result.ProcessorType,
+		Amount:        requested,
+		Fee:           result.Fee,
+	}
+	if result.TransactionID != "" {
+		entry.TransactionID = result.TransactionID
+	}
+	if !result.GrossAmount.IsZero() {
+		entry.Amount = result.GrossAmount
+	}
+	return entry
+}
+
+func refundAuditEntry(action AuditAction, transactionID string, requested Money, result RefundResult) AuditEntry {
+	entry := AuditEntry{
+		Action:        action,
+		TransactionID: transactionID,
+		Processor:     result.ProcessorType,
+		Amount:        requested,
+		Fee:           result.FeeReversed,
+	}
+	if !result.Amount.IsZero() {
+		entry.Amount = result.Amount
+	}
+	return entry
+}
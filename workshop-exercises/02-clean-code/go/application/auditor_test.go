@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// =============================================================================
+// AUDITOR TESTS
+// Testing: auditor.go
+// =============================================================================
+
+type failingAuditLog struct{}
+
+func (failingAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	return AuditEntry{}, errors.New("disk full")
+}
+
+func (failingAuditLog) Query(query AuditQuery) ([]AuditEntry, error) {
+	return nil, nil
+}
+
+func TestAuditor_Record_FillsActorTimeAndOutcome(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	auditor := NewAuditor(log, AuditorConfig{Clock: NewManualClock(auditTestStart)})
+	ctx := WithAuditActor(context.Background(), "alice")
+
+	// Act
+	auditor.Record(ctx, AuditEntry{Action: AuditActionOrderRefund, OrderID: "order_1"}, errors.New("processor down"))
+
+	// Assert
+	entries, _ := log.Query(AuditQuery{})
+	if len(entries) != 1 {
+		t.Fatalf("Expected one entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Actor != "alice" || !entry.At.Equal(auditTestStart) || entry.Outcome != AuditOutcomeFailed || entry.Error != "processor down" {
+		t.Errorf("Expected alice's failed refund at the clock's time, got %+v", entry)
+	}
+}
+
+func TestAuditor_Record_AppendFails_ReportsEntry(t *testing.T) {
+	// Arrange
+	var lost []AuditEntry
+	auditor := NewAuditor(failingAuditLog{}, AuditorConfig{OnFailure: func(entry AuditEntry, err error) {
+		lost = append(lost, entry)
+	}})
+
+	// Act
+	auditor.Record(context.Background(), AuditEntry{Action: AuditActionOrderPlace}, nil)
+
+	// Assert
+	if len(lost) != 1 || lost[0].Action != AuditActionOrderPlace {
+		t.Errorf("Expected the entry reported, got %+v", lost)
+	}
+}
+
+func TestAuditingProcessor_RecordsChargeAndRefund(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	processor := NewAuditingProcessor(NewCreditCardProcessor(), NewAuditor(log, AuditorConfig{}))
+	ctx := context.Background()
+
+	// Act
+	payment, _ := processor.ProcessPayment(ctx, PaymentRequest{OrderID: "order_1", Amount: MustParseMoney("100.00", USD)})
+	processor.Refund(ctx, NewPartialRefundRequest(payment.TransactionID, MustParseMoney("40.00", USD)))
+	processor.Refund(ctx, NewPartialRefundRequest("missing", MustParseMoney("5.00", USD)))
+
+	// Assert
+	entries, _ := log.Query(AuditQuery{})
+	if len(entries) != 3 {
+		t.Fatalf("Expected three entries, got %d", len(entries))
+	}
+	charge := entries[0]
+	if charge.Action != AuditActionPaymentCharge || charge.OrderID != "order_1" || charge.Amount != payment.GrossAmount || charge.Fee != payment.Fee || charge.TransactionID != payment.TransactionID {
+		t.Errorf("Expected the charge with its amount and fee, got %+v", charge)
+	}
+	if refund := entries[1]; refund.Action != AuditActionPaymentRefund || refund.Amount != MustParseMoney("40.00", USD) || refund.Outcome != AuditOutcomeSucceeded {
+		t.Errorf("Expected the refund recorded, got %+v", refund)
+	}
+	if failed := entries[2]; failed.Outcome != AuditOutcomeFailed || failed.Amount != MustParseMoney("5.00", USD) || failed.TransactionID != "missing" {
+		t.Errorf("Expected the failed refund recorded with what was asked for, got %+v", failed)
+	}
+}
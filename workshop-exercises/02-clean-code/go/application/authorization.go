@@ -0,0 +1,69 @@
+package application
+
+import (
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// AUTHORIZATION
+// Two-phase payments: reserve funds now, capture or release them later
+// =============================================================================
+
+var (
+	ErrAuthorizationNotFound    = errors.New("authorization not found")
+	ErrAuthorizationExpired     = errors.New("authorization expired")
+	ErrAuthorizationNotPending  = errors.New("authorization is no longer pending")
+	ErrCaptureExceedsAuthorized = errors.New("capture exceeds authorized amount")
+	ErrInvalidCaptureAmount     = errors.New("capture amount must be positive")
+)
+
+// ProcessorConfig leaves Fees nil to use the processor's standard pricing.
+// A zero AuthorizationTTL or nil Clock takes the processor's default.
+type ProcessorConfig struct {
+	AuthorizationTTL time.Duration
+	Clock            Clock
+	Fees             *FeeSchedule
+}
+
+func (c ProcessorConfig) withDefaults(defaults ProcessorConfig) ProcessorConfig {
+	if c.AuthorizationTTL <= 0 {
+		c.AuthorizationTTL = defaults.AuthorizationTTL
+	}
+	if c.Clock == nil {
+		c.Clock = defaults.Clock
+	}
+	return c
+}
+
+func DefaultCreditCardConfig() ProcessorConfig {
+	return ProcessorConfig{
+		AuthorizationTTL: 7 * 24 * time.Hour,
+		Clock:            NewSystemClock(),
+	}
+}
+
+func DefaultPayPalConfig() ProcessorConfig {
+	return ProcessorConfig{
+		AuthorizationTTL: 3 * 24 * time.Hour, // PayPal's honor period
+		Clock:            NewSystemClock(),
+	}
+}
+
+type authorizationState string
+
+const (
+	authorizationPending  authorizationState = "pending"
+	authorizationCaptured authorizationState = "captured"
+	authorizationReleased authorizationState = "released"
+	authorizationExpired  authorizationState = "expired"
+)
+
+type authorizationEntry struct {
+	authorization PaymentResult
+	state         authorizationState
+}
+
+func (e authorizationEntry) hasExpired(now time.Time) bool {
+	return !now.Before(e.authorization.ExpiresAt)
+}
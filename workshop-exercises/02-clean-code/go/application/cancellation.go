@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// CANCELLATION
+// Typed error returned when a context stops a payment before it completes
+// =============================================================================
+
+type PaymentCancelledError struct {
+	Processor string
+	Cause     error
+}
+
+func NewPaymentCancelledError(processor string, cause error) *PaymentCancelledError {
+	return &PaymentCancelledError{
+		Processor: processor,
+		Cause:     cause,
+	}
+}
+
+func (e *PaymentCancelledError) Error() string {
+	return fmt.Sprintf("%s payment cancelled: %v", e.Processor, e.Cause)
+}
+
+func (e *PaymentCancelledError) Unwrap() error {
+	return e.Cause
+}
+
+func waitOrCancel(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,145 @@
+package application
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// =============================================================================
+// CARD
+// Card payment method; the full number never leaves the payment request
+// =============================================================================
+
+type CardBrand string
+
+const (
+	CardBrandVisa       CardBrand = "visa"
+	CardBrandMastercard CardBrand = "mastercard"
+	CardBrandAmex       CardBrand = "amex"
+	CardBrandDiscover   CardBrand = "discover"
+	CardBrandUnknown    CardBrand = "unknown"
+)
+
+// Card is what the checkout form collects. Number may contain spaces or
+// dashes; ExpiryYear may be given with two digits.
+type Card struct {
+	Number      string
+	ExpiryMonth int
+	ExpiryYear  int
+	CVC         string
+	HolderName  string
+}
+
+// Digits is the card number without separators.
+func (c Card) Digits() string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(c.Number)
+}
+
+func (c Card) Brand() CardBrand {
+	return DetectCardBrand(c.Digits())
+}
+
+func (c Card) Last4() string {
+	digits := c.Digits()
+	if len(digits) < 4 {
+		return digits
+	}
+	return digits[len(digits)-4:]
+}
+
+// String masks the number so a card can be logged safely.
+func (c Card) String() string {
+	return string(c.Brand()) + " ending in " + c.Last4()
+}
+
+// MarshalJSON leaves out the full number and the CVC, so cards can sit in
+// orders that are logged, fingerprinted or stored.
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Brand       CardBrand `json:"brand"`
+		Last4       string    `json:"last4"`
+		ExpiryMonth int       `json:"expiryMonth"`
+		ExpiryYear  int       `json:"expiryYear"`
+	}{c.Brand(), c.Last4(), c.ExpiryMonth, c.ExpiryYear})
+}
+
+type cardBrandRule struct {
+	brand      CardBrand
+	prefixes   [][2]int
+	lengths    []int
+	cvcLength  int
+	prefixSize int
+}
+
+// IIN ranges by brand; a range is the first digits of the number, inclusive.
+var cardBrandRules = []cardBrandRule{
+	{brand: CardBrandAmex, prefixSize: 2, prefixes: [][2]int{{34, 34}, {37, 37}}, lengths: []int{15}, cvcLength: 4},
+	{brand: CardBrandVisa, prefixSize: 1, prefixes: [][2]int{{4, 4}}, lengths: []int{13, 16, 19}, cvcLength: 3},
+	{brand: CardBrandMastercard, prefixSize: 2, prefixes: [][2]int{{51, 55}}, lengths: []int{16}, cvcLength: 3},
+	{brand: CardBrandMastercard, prefixSize: 4, prefixes: [][2]int{{2221, 2720}}, lengths: []int{16}, cvcLength: 3},
+	{brand: CardBrandDiscover, prefixSize: 4, prefixes: [][2]int{{6011, 6011}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{brand: CardBrandDiscover, prefixSize: 3, prefixes: [][2]int{{644, 649}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+	{brand: CardBrandDiscover, prefixSize: 2, prefixes: [][2]int{{65, 65}}, lengths: []int{16, 17, 18, 19}, cvcLength: 3},
+}
+
+func DetectCardBrand(digits string) CardBrand {
+	if rule, ok := findCardBrandRule(digits); ok {
+		return rule.brand
+	}
+	return CardBrandUnknown
+}
+
+func findCardBrandRule(digits string) (cardBrandRule, bool) {
+	for _, rule := range cardBrandRules {
+		if rule.matchesPrefix(digits) {
+			return rule, true
+		}
+	}
+	return cardBrandRule{}, false
+}
+
+func (r cardBrandRule) matchesPrefix(digits string) bool {
+	if len(digits) < r.prefixSize || !isDigits(digits[:r.prefixSize]) {
+		return false
+	}
+	prefix := 0
+	for _, digit := range digits[:r.prefixSize] {
+		prefix = prefix*10 + int(digit-'0')
+	}
+	for _, span := range r.prefixes {
+		if prefix >= span[0] && prefix <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r cardBrandRule) allowsLength(length int) bool {
+	for _, allowed := range r.lengths {
+		if allowed == length {
+			return true
+		}
+	}
+	return false
+}
+
+// LuhnValid reports whether digits pass the Luhn checksum.
+func LuhnValid(digits string) bool {
+	if len(digits) < 2 || !isDigits(digits) {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
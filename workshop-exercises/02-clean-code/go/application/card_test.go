@@ -0,0 +1,91 @@
+package application
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// CARD TESTS
+// Testing: card.go
+// =============================================================================
+
+func TestDetectCardBrand_IINRanges(t *testing.T) {
+	testCases := []struct {
+		number   string
+		expected CardBrand
+	}{
+		{"4111111111111111", CardBrandVisa},
+		{"5555555555554444", CardBrandMastercard},
+		{"2223003122003222", CardBrandMastercard},
+		{"2721000000000000", CardBrandUnknown},
+		{"378282246310005", CardBrandAmex},
+		{"341111111111111", CardBrandAmex},
+		{"6011111111111117", CardBrandDiscover},
+		{"6445644564456445", CardBrandDiscover},
+		{"6500000000000002", CardBrandDiscover},
+		{"3530111333300000", CardBrandUnknown},
+		{"", CardBrandUnknown},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.number, func(t *testing.T) {
+			// Act & Assert
+			if brand := DetectCardBrand(tc.number); brand != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, brand)
+			}
+		})
+	}
+}
+
+func TestLuhnValid_KnownNumbers(t *testing.T) {
+	testCases := []struct {
+		digits string
+		valid  bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"79927398713", true},
+		{"0", false},
+		{"4111a11111111111", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.digits, func(t *testing.T) {
+			// Act & Assert
+			if LuhnValid(tc.digits) != tc.valid {
+				t.Errorf("Expected valid=%v", tc.valid)
+			}
+		})
+	}
+}
+
+func TestCard_Digits_StripsSeparators(t *testing.T) {
+	// Arrange
+	card := Card{Number: "4111 1111-1111 1111"}
+
+	// Act & Assert
+	if card.Digits() != "4111111111111111" || card.Last4() != "1111" || card.Brand() != CardBrandVisa {
+		t.Errorf("Expected a visa ending in 1111, got %s", card)
+	}
+}
+
+func TestCard_MarshalJSON_LeavesOutNumberAndCVC(t *testing.T) {
+	// Arrange
+	card := Card{Number: "4111111111111111", ExpiryMonth: 12, ExpiryYear: 2030, CVC: "123", HolderName: "Jo Doe"}
+
+	// Act
+	data, err := json.Marshal(card)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(string(data), "4111111111111111") || strings.Contains(string(data), "123") {
+		t.Errorf("Expected no number or CVC in %s", data)
+	}
+	if string(data) != `{"brand":"visa","last4":"1111","expiryMonth":12,"expiryYear":2030}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+}
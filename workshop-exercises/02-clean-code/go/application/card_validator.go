@@ -0,0 +1,155 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// CARD VALIDATOR
+// Checks card details before anything is charged and reports problems per
+// form field
+// =============================================================================
+
+var ErrInvalidCard = errors.New("invalid card details")
+
+type CardField string
+
+const (
+	CardFieldNumber     CardField = "number"
+	CardFieldExpiry     CardField = "expiry"
+	CardFieldCVC        CardField = "cvc"
+	CardFieldHolderName CardField = "holderName"
+)
+
+// CardFieldError is one problem with one input. Code is stable for forms to
+// translate; Message is a default English text.
+type CardFieldError struct {
+	Field   CardField
+	Code    string
+	Message string
+}
+
+// CardValidationError holds every field error found, in form order. It
+// matches ErrInvalidCard with errors.Is.
+type CardValidationError struct {
+	Fields []CardFieldError
+}
+
+func (e *CardValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidCard, strings.Join(parts, "; "))
+}
+
+func (e *CardValidationError) Is(target error) bool {
+	return target == ErrInvalidCard
+}
+
+// FieldErrors groups the errors by field for display next to each input.
+func (e *CardValidationError) FieldErrors() map[CardField][]CardFieldError {
+	grouped := make(map[CardField][]CardFieldError, len(e.Fields))
+	for _, field := range e.Fields {
+		grouped[field.Field] = append(grouped[field.Field], field)
+	}
+	return grouped
+}
+
+const maxCardHolderNameLength = 64
+
+type CardValidatorInterface interface {
+	Validate(card Card) error
+}
+
+type CardValidator struct {
+	clock Clock
+}
+
+func NewCardValidator(clock Clock) CardValidatorInterface {
+	return &CardValidator{clock: clock}
+}
+
+func (v *CardValidator) Validate(card Card) error {
+	var fields []CardFieldError
+	rule, numberErrors := v.validateNumber(card.Digits())
+	fields = append(fields, numberErrors...)
+	fields = append(fields, v.validateExpiry(card.ExpiryMonth, card.ExpiryYear)...)
+	fields = append(fields, v.validateCVC(card.CVC, rule)...)
+	fields = append(fields, v.validateHolderName(card.HolderName)...)
+	if len(fields) > 0 {
+		return &CardValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func (v *CardValidator) validateNumber(digits string) (*cardBrandRule, []CardFieldError) {
+	switch {
+	case digits == "":
+		return nil, cardFieldErrors(CardFieldNumber, "required", "Enter the card number")
+	case !isDigits(digits):
+		return nil, cardFieldErrors(CardFieldNumber, "invalid_characters", "The card number may only contain digits")
+	}
+	rule, ok := findCardBrandRule(digits)
+	if !ok {
+		return nil, cardFieldErrors(CardFieldNumber, "unsupported_brand", "This card type is not accepted")
+	}
+	if !rule.allowsLength(len(digits)) {
+		return &rule, cardFieldErrors(CardFieldNumber, "invalid_length", fmt.Sprintf("A %s card number cannot have %d digits", rule.brand, len(digits)))
+	}
+	if !LuhnValid(digits) {
+		return &rule, cardFieldErrors(CardFieldNumber, "failed_checksum", "The card number is not valid")
+	}
+	return &rule, nil
+}
+
+// A card stays valid until the end of its expiry month.
+func (v *CardValidator) validateExpiry(month int, year int) []CardFieldError {
+	if month < 1 || month > 12 {
+		return cardFieldErrors(CardFieldExpiry, "invalid_month", "Enter an expiry month from 1 to 12")
+	}
+	if year < 100 {
+		year += 2000
+	}
+	expiresAt := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	if !v.clock.Now().Before(expiresAt) {
+		return cardFieldErrors(CardFieldExpiry, "expired", "The card has expired")
+	}
+	return nil
+}
+
+// The expected CVC length depends on the brand; without a known brand only
+// the general 3 or 4 digits are checked.
+func (v *CardValidator) validateCVC(cvc string, rule *cardBrandRule) []CardFieldError {
+	if cvc == "" {
+		return cardFieldErrors(CardFieldCVC, "required", "Enter the security code")
+	}
+	if !isDigits(cvc) {
+		return cardFieldErrors(CardFieldCVC, "invalid_characters", "The security code may only contain digits")
+	}
+	if rule != nil && len(cvc) != rule.cvcLength {
+		return cardFieldErrors(CardFieldCVC, "invalid_length", fmt.Sprintf("The security code has %d digits for %s cards", rule.cvcLength, rule.brand))
+	}
+	if rule == nil && (len(cvc) < 3 || len(cvc) > 4) {
+		return cardFieldErrors(CardFieldCVC, "invalid_length", "The security code has 3 or 4 digits")
+	}
+	return nil
+}
+
+func (v *CardValidator) validateHolderName(name string) []CardFieldError {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return cardFieldErrors(CardFieldHolderName, "required", "Enter the name on the card")
+	}
+	if len([]rune(name)) > maxCardHolderNameLength {
+		return cardFieldErrors(CardFieldHolderName, "too_long", fmt.Sprintf("The name can have at most %d characters", maxCardHolderNameLength))
+	}
+	return nil
+}
+
+func cardFieldErrors(field CardField, code string, message string) []CardFieldError {
+	return []CardFieldError{{Field: field, Code: code, Message: message}}
+}
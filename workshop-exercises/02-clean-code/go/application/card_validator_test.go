@@ -0,0 +1,111 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// CARD VALIDATOR TESTS
+// Testing: card_validator.go
+// =============================================================================
+
+var cardValidationTime = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func newTestCard() Card {
+	return Card{Number: "4111 1111 1111 1111", ExpiryMonth: 12, ExpiryYear: 2026, CVC: "123", HolderName: "Jo Doe"}
+}
+
+func validateTestCard(card Card) *CardValidationError {
+	err := NewCardValidator(NewManualClock(cardValidationTime)).Validate(card)
+	var validationErr *CardValidationError
+	errors.As(err, &validationErr)
+	return validationErr
+}
+
+func TestCardValidator_Validate_ValidCard_ReturnsNil(t *testing.T) {
+	// Act
+	err := NewCardValidator(NewManualClock(cardValidationTime)).Validate(newTestCard())
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected a valid card, got %v", err)
+	}
+}
+
+func TestCardValidator_Validate_InvalidField_ReportsFieldAndCode(t *testing.T) {
+	testCases := []struct {
+		name   string
+		change func(card *Card)
+		field  CardField
+		code   string
+	}{
+		{"missing number", func(c *Card) { c.Number = "" }, CardFieldNumber, "required"},
+		{"letters in number", func(c *Card) { c.Number = "4111-abcd-1111-1111" }, CardFieldNumber, "invalid_characters"},
+		{"unsupported brand", func(c *Card) { c.Number = "3530111333300000" }, CardFieldNumber, "unsupported_brand"},
+		{"wrong length", func(c *Card) { c.Number = "41111111111111" }, CardFieldNumber, "invalid_length"},
+		{"bad checksum", func(c *Card) { c.Number = "4111111111111112" }, CardFieldNumber, "failed_checksum"},
+		{"month 13", func(c *Card) { c.ExpiryMonth = 13 }, CardFieldExpiry, "invalid_month"},
+		{"expired last month", func(c *Card) { c.ExpiryMonth, c.ExpiryYear = 5, 2024 }, CardFieldExpiry, "expired"},
+		{"four digit CVC on visa", func(c *Card) { c.CVC = "1234" }, CardFieldCVC, "invalid_length"},
+		{"three digit CVC on amex", func(c *Card) { c.Number = "378282246310005"; c.CVC = "123" }, CardFieldCVC, "invalid_length"},
+		{"missing CVC", func(c *Card) { c.CVC = "" }, CardFieldCVC, "required"},
+		{"blank holder", func(c *Card) { c.HolderName = "   " }, CardFieldHolderName, "required"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			card := newTestCard()
+			tc.change(&card)
+
+			// Act
+			validationErr := validateTestCard(card)
+
+			// Assert
+			if validationErr == nil || len(validationErr.Fields) != 1 {
+				t.Fatalf("Expected exactly one field error, got %v", validationErr)
+			}
+			if validationErr.Fields[0].Field != tc.field || validationErr.Fields[0].Code != tc.code {
+				t.Errorf("Expected %s/%s, got %+v", tc.field, tc.code, validationErr.Fields[0])
+			}
+		})
+	}
+}
+
+func TestCardValidator_Validate_ExpiryMonthStillValid(t *testing.T) {
+	// Arrange: June 2024 cards work until the end of June
+	card := newTestCard()
+	card.ExpiryMonth, card.ExpiryYear = 6, 24
+
+	// Act
+	validationErr := validateTestCard(card)
+
+	// Assert
+	if validationErr != nil {
+		t.Errorf("Expected a card expiring this month to be valid, got %v", validationErr)
+	}
+}
+
+func TestCardValidator_Validate_SeveralProblems_ReportsEveryField(t *testing.T) {
+	// Arrange
+	card := Card{Number: "4111111111111112", ExpiryMonth: 1, ExpiryYear: 2020, CVC: "12"}
+
+	// Act
+	validationErr := validateTestCard(card)
+
+	// Assert
+	if validationErr == nil {
+		t.Fatal("Expected validation errors")
+	}
+	grouped := validationErr.FieldErrors()
+	for _, field := range []CardField{CardFieldNumber, CardFieldExpiry, CardFieldCVC, CardFieldHolderName} {
+		if len(grouped[field]) != 1 {
+			t.Errorf("Expected one error for %s, got %v", field, grouped[field])
+		}
+	}
+	if !errors.Is(validationErr, ErrInvalidCard) {
+		t.Error("Expected the error to match ErrInvalidCard")
+	}
+}
@@ -0,0 +1,302 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// CIRCUIT BREAKER
+// Decorator that stops calling a failing processor for a while instead of
+// making every order wait for it to time out
+// =============================================================================
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreakerConfig opens the circuit once at least MinimumRequests of the
+// last WindowSize calls were made and FailureRate (0 to 1) of them failed.
+// After OpenDuration it lets HalfOpenCalls trial calls through: one failure
+// opens it again, all of them succeeding closes it.
+type CircuitBreakerConfig struct {
+	Name            string
+	FailureRate     float64
+	MinimumRequests int
+	WindowSize      int
+	OpenDuration    time.Duration
+	HalfOpenCalls   int
+	Clock           Clock
+	OnStateChange   func(change CircuitStateChange)
+}
+
+func DefaultCircuitBreakerConfig(name string) CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Name:            name,
+		FailureRate:     0.5,
+		MinimumRequests: 10,
+		WindowSize:      20,
+		OpenDuration:    30 * time.Second,
+		HalfOpenCalls:   3,
+		Clock:           NewSystemClock(),
+	}
+}
+
+func (c CircuitBreakerConfig) validate() error {
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		return fmt.Errorf("circuit breaker %s: failure rate must be above 0 and at most 1", c.Name)
+	}
+	if c.MinimumRequests < 1 || c.WindowSize < c.MinimumRequests {
+		return fmt.Errorf("circuit breaker %s: window must hold at least the minimum number of requests", c.Name)
+	}
+	if c.OpenDuration <= 0 || c.HalfOpenCalls < 1 {
+		return fmt.Errorf("circuit breaker %s: open duration and half-open calls must be positive", c.Name)
+	}
+	return nil
+}
+
+type CircuitStateChange struct {
+	Name        string
+	From        CircuitState
+	To          CircuitState
+	FailureRate float64
+	At          time.Time
+}
+
+// CircuitBreakerInterface is a processor whose state can be read, e.g. by a
+// router choosing between processors or by a health check.
+type CircuitBreakerInterface interface {
+	PaymentProcessorInterface
+	State() CircuitState
+}
+
+type CircuitBreaker struct {
+	processor PaymentProcessorInterface
+	config    CircuitBreakerConfig
+
+	mu               sync.Mutex
+	state            CircuitState
+	generation       int
+	outcomes         []bool
+	next             int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenPassed   int
+}
+
+func NewCircuitBreaker(processor PaymentProcessorInterface, config CircuitBreakerConfig) (CircuitBreakerInterface, error) {
+	if config.Clock == nil {
+		config.Clock = NewSystemClock()
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &CircuitBreaker{
+		processor: processor,
+		config:    config,
+		state:     CircuitClosed,
+		outcomes:  make([]bool, 0, config.WindowSize),
+	}, nil
+}
+
+// State reports the current state; an open circuit whose wait is over
+// reads as half-open.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	change := b.refreshState()
+	state := b.state
+	b.mu.Unlock()
+	b.emit(change)
+	return state
+}
+
+func (b *CircuitBreaker) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return guardPayment(ctx, b, func() (PaymentResult, error) {
+		return b.processor.ProcessPayment(ctx, request)
+	})
+}
+
+func (b *CircuitBreaker) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return guardPayment(ctx, b, func() (PaymentResult, error) {
+		return b.processor.Authorize(ctx, request)
+	})
+}
+
+func (b *CircuitBreaker) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	return guardPayment(ctx, b, func() (PaymentResult, error) {
+		return b.processor.Capture(ctx, authorizationID, amount)
+	})
+}
+
+func (b *CircuitBreaker) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return guardPayment(ctx, b, func() (PaymentResult, error) {
+		return b.processor.ReleaseAuthorization(ctx, authorizationID)
+	})
+}
+
+func (b *CircuitBreaker) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	return guardPayment(ctx, b, func() (RefundResult, error) {
+		return b.processor.Refund(ctx, request)
+	})
+}
+
+func (b *CircuitBreaker) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return guardPayment(ctx, b, func() (RefundResult, error) {
+		return b.processor.Void(ctx, transactionID)
+	})
+}
+
+// QuoteFee does not call out to the processor, so it works while open.
+func (b *CircuitBreaker) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return QuoteFee(ctx, b.processor, request)
+}
+
+func guardPayment[T any](ctx context.Context, b *CircuitBreaker, operation func() (T, error)) (T, error) {
+	generation, err := b.acquire()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := operation()
+	b.release(generation, b.isFailure(err))
+	return result, err
+}
+
+// A rejected call is a transient failure, so a failover chain moves on to
+// the next processor.
+func (b *CircuitBreaker) acquire() (int, error) {
+	b.mu.Lock()
+	change := b.refreshState()
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = b.rejection()
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight+b.halfOpenPassed >= b.config.HalfOpenCalls {
+			err = b.rejection()
+		} else {
+			b.halfOpenInFlight++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.emit(change)
+	return generation, err
+}
+
+func (b *CircuitBreaker) rejection() error {
+	return NewTransientPaymentError(b.config.Name, ErrCircuitOpen)
+}
+
+// release records the outcome of a call. Calls that started before the last
+// state change do not count towards the new state.
+func (b *CircuitBreaker) release(generation int, failed bool) {
+	b.mu.Lock()
+	var change *CircuitStateChange
+	switch {
+	case generation != b.generation:
+	case b.state == CircuitHalfOpen:
+		change = b.recordTrial(failed)
+	default:
+		change = b.recordOutcome(failed)
+	}
+	b.mu.Unlock()
+	b.emit(change)
+}
+
+// Only outages count against the processor. A decline or a bad request is a
+// working processor giving an answer, and a caller cancelling is not its
+// fault; running out of time or not answering at all is.
+func (b *CircuitBreaker) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrPaymentOutcomeUnknown) {
+		return true
+	}
+	return IsRetryablePaymentError(err)
+}
+
+func (b *CircuitBreaker) recordTrial(failed bool) *CircuitStateChange {
+	b.halfOpenInFlight--
+	if failed {
+		return b.transition(CircuitOpen)
+	}
+	b.halfOpenPassed++
+	if b.halfOpenPassed >= b.config.HalfOpenCalls {
+		return b.transition(CircuitClosed)
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) recordOutcome(failed bool) *CircuitStateChange {
+	if len(b.outcomes) < b.config.WindowSize {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		b.outcomes[b.next] = failed
+	}
+	b.next = (b.next + 1) % b.config.WindowSize
+	if len(b.outcomes) >= b.config.MinimumRequests && b.failureRate() >= b.config.FailureRate {
+		return b.transition(CircuitOpen)
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) failureRate() float64 {
+	if len(b.outcomes) == 0 {
+		return 0
+	}
+	failures := 0
+	for _, failed := range b.outcomes {
+		if failed {
+			failures++
+		}
+	}
+	return float64(failures) / float64(len(b.outcomes))
+}
+
+func (b *CircuitBreaker) refreshState() *CircuitStateChange {
+	if b.state == CircuitOpen && !b.config.Clock.Now().Before(b.openedAt.Add(b.config.OpenDuration)) {
+		return b.transition(CircuitHalfOpen)
+	}
+	return nil
+}
+
+// transition resets the counters for the new state and returns the event to
+// emit once the lock is released.
+func (b *CircuitBreaker) transition(to CircuitState) *CircuitStateChange {
+	change := &CircuitStateChange{
+		Name:        b.config.Name,
+		From:        b.state,
+		To:          to,
+		FailureRate: b.failureRate(),
+		At:          b.config.Clock.Now().UTC(),
+	}
+	b.state = to
+	b.generation++
+	b.halfOpenInFlight = 0
+	b.halfOpenPassed = 0
+	switch to {
+	case CircuitOpen:
+		b.openedAt = change.At
+	case CircuitClosed:
+		b.outcomes = b.outcomes[:0]
+		b.next = 0
+	}
+	return change
+}
+
+func (b *CircuitBreaker) emit(change *CircuitStateChange) {
+	if change != nil && b.config.OnStateChange != nil {
+		b.config.OnStateChange(*change)
+	}
+}
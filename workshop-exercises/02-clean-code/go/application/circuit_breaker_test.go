@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// CIRCUIT BREAKER TESTS
+// Testing: circuit_breaker.go
+// =============================================================================
+
+type circuitFixture struct {
+	breaker   CircuitBreakerInterface
+	processor *MockPaymentProcessor
+	clock     *ManualClock
+	changes   []CircuitStateChange
+}
+
+func newCircuitFixture(t *testing.T) *circuitFixture {
+	t.Helper()
+	fixture := &circuitFixture{
+		processor: NewMockPaymentProcessor(false),
+		clock:     NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)),
+	}
+	breaker, err := NewCircuitBreaker(fixture.processor, CircuitBreakerConfig{
+		Name:            "PayPal",
+		FailureRate:     0.5,
+		MinimumRequests: 4,
+		WindowSize:      4,
+		OpenDuration:    time.Minute,
+		HalfOpenCalls:   2,
+		Clock:           fixture.clock,
+		OnStateChange: func(change CircuitStateChange) {
+			fixture.changes = append(fixture.changes, change)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	fixture.breaker = breaker
+	return fixture
+}
+
+func (f *circuitFixture) pay(outcome error) error {
+	f.processor.shouldFail = outcome != nil
+	f.processor.failure = outcome
+	_, err := f.breaker.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+	return err
+}
+
+func (f *circuitFixture) trip() {
+	for i := 0; i < 4; i++ {
+		_ = f.pay(ErrProcessorUnavailable)
+	}
+}
+
+func TestCircuitBreaker_FailureRateReached_Opens(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+	_ = fixture.pay(nil)
+	_ = fixture.pay(ErrProcessorUnavailable)
+	_ = fixture.pay(nil)
+
+	// Act
+	_ = fixture.pay(NewTransientPaymentError("PayPal", nil))
+
+	// Assert
+	if fixture.breaker.State() != CircuitOpen {
+		t.Fatalf("Expected open at a 50%% failure rate, got %s", fixture.breaker.State())
+	}
+	if len(fixture.changes) != 1 || fixture.changes[0].From != CircuitClosed || fixture.changes[0].To != CircuitOpen || fixture.changes[0].FailureRate != 0.5 {
+		t.Errorf("Expected one closed -> open event at 50%%, got %+v", fixture.changes)
+	}
+}
+
+func TestCircuitBreaker_BelowMinimumRequests_StaysClosed(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_ = fixture.pay(ErrProcessorUnavailable)
+	}
+
+	// Assert
+	if fixture.breaker.State() != CircuitClosed {
+		t.Errorf("Expected closed below the minimum number of requests, got %s", fixture.breaker.State())
+	}
+}
+
+func TestCircuitBreaker_DeclinesAreNotFailures(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+
+	// Act
+	for i := 0; i < 4; i++ {
+		_ = fixture.pay(NewDeclinedError("PayPal", "05", "do not honor"))
+	}
+
+	// Assert
+	if fixture.breaker.State() != CircuitClosed {
+		t.Errorf("Expected declines to leave the circuit closed, got %s", fixture.breaker.State())
+	}
+}
+
+func TestCircuitBreaker_Open_RejectsWithoutCallingProcessor(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+	fixture.trip()
+	calls := fixture.processor.callCount()
+
+	// Act
+	err := fixture.pay(nil)
+
+	// Assert
+	if !errors.Is(err, ErrCircuitOpen) || !IsRetryablePaymentError(err) {
+		t.Errorf("Expected a retryable ErrCircuitOpen, got %v", err)
+	}
+	if fixture.processor.callCount() != calls {
+		t.Error("Expected the processor not to be called while open")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenTrialsSucceed_Closes(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+	fixture.trip()
+	fixture.clock.Advance(time.Minute)
+
+	// Act
+	state := fixture.breaker.State()
+	first := fixture.pay(nil)
+	second := fixture.pay(nil)
+
+	// Assert
+	if state != CircuitHalfOpen {
+		t.Errorf("Expected half-open after the open duration, got %s", state)
+	}
+	if first != nil || second != nil {
+		t.Fatalf("Expected the trial calls to go through, got %v / %v", first, second)
+	}
+	if fixture.breaker.State() != CircuitClosed {
+		t.Errorf("Expected closed after successful trials, got %s", fixture.breaker.State())
+	}
+	if len(fixture.changes) != 3 || fixture.changes[1].To != CircuitHalfOpen || fixture.changes[2].To != CircuitClosed {
+		t.Errorf("Expected open -> half-open -> closed events, got %+v", fixture.changes)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenTrialFails_Reopens(t *testing.T) {
+	// Arrange
+	fixture := newCircuitFixture(t)
+	fixture.trip()
+	fixture.clock.Advance(time.Minute)
+
+	// Act
+	_ = fixture.pay(fmt.Errorf("gateway: %w", ErrProcessorUnavailable))
+
+	// Assert
+	if fixture.breaker.State() != CircuitOpen {
+		t.Errorf("Expected the failed trial to reopen the circuit, got %s", fixture.breaker.State())
+	}
+}
+
+func TestCircuitBreaker_TimeoutsAndUnknownOutcomes_CountAsFailures(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{"deadline exceeded", NewPaymentCancelledError("PayPal", context.DeadlineExceeded)},
+		{"outcome unknown", NewOutcomeUnknownError("PayPal", errors.New("read timeout"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			fixture := newCircuitFixture(t)
+
+			// Act
+			for i := 0; i < 4; i++ {
+				_ = fixture.pay(tc.err)
+			}
+
+			// Assert
+			if fixture.breaker.State() != CircuitOpen {
+				t.Errorf("Expected %s to open the circuit, got %s", tc.name, fixture.breaker.State())
+			}
+		})
+	}
+}
+
+func TestNewCircuitBreaker_WindowSmallerThanMinimum_ReturnsError(t *testing.T) {
+	// Arrange
+	config := DefaultCircuitBreakerConfig("PayPal")
+	config.WindowSize = config.MinimumRequests - 1
+
+	// Act
+	_, err := NewCircuitBreaker(NewMockPaymentProcessor(false), config)
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an invalid window to be rejected")
+	}
+}
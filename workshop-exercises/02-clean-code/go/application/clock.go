@@ -0,0 +1,41 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// =============================================================================
+// CLOCK
+// Injectable time source so expiry logic can be tested without sleeping
+// =============================================================================
+
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func NewSystemClock() Clock {
+	return &SystemClock{}
+}
+
+func (c *SystemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Sleeper waits between retries. Tests swap in one that only advances a
+// manual clock.
+type Sleeper interface {
+	Sleep(ctx context.Context, duration time.Duration) error
+}
+
+type SystemSleeper struct{}
+
+func NewSystemSleeper() Sleeper {
+	return &SystemSleeper{}
+}
+
+func (s *SystemSleeper) Sleep(ctx context.Context, duration time.Duration) error {
+	return waitOrCancel(ctx, duration)
+}
@@ -0,0 +1,52 @@
+package application
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// TEST CLOCK
+// Manually advanced clock shared by tests that depend on time
+// =============================================================================
+
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) Advance(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(duration)
+}
+
+// =============================================================================
+// CLOCK TESTS
+// Testing: clock.go
+// =============================================================================
+
+func TestSystemClock_Now_ReturnsCurrentUTCTime(t *testing.T) {
+	// Arrange
+	clock := NewSystemClock()
+	before := time.Now()
+
+	// Act
+	now := clock.Now()
+
+	// Assert
+	if now.Before(before.Add(-time.Second)) || now.Location() != time.UTC {
+		t.Errorf("Expected current UTC time, got %v", now)
+	}
+}
@@ -0,0 +1,211 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// COUPONS
+// Promo codes with usage limits, expiry, minimum order amount and a stacking
+// policy that decides how they combine with other discounts
+// =============================================================================
+
+var (
+	ErrInvalidCoupon          = errors.New("invalid coupon")
+	ErrCouponNotFound         = errors.New("coupon not found")
+	ErrCouponExpired          = errors.New("coupon expired")
+	ErrCouponMinimumNotMet    = errors.New("order below coupon minimum")
+	ErrCouponUsageExhausted   = errors.New("coupon usage limit reached")
+	ErrCouponCustomerLimitHit = errors.New("coupon per-customer limit reached")
+	ErrCouponNotCombinable    = errors.New("coupon cannot be combined")
+	ErrCouponNoMatchingLines  = errors.New("coupon does not apply to any order line")
+)
+
+// CouponStacking decides how a coupon combines with other discounts:
+//   - exclusive: the coupon is the only discount; other coupons are rejected
+//     and the customer's rule discount is not applied
+//   - best_of: competes with the rule discount and other best_of coupons;
+//     only the largest of them is applied
+//   - stackable: applied on top of everything else, on the remaining amount
+//
+// A coupon with product IDs works the same way on each matching order line,
+// competing with and stacking on that line's discounts only.
+type CouponStacking string
+
+const (
+	CouponStackingExclusive CouponStacking = "exclusive"
+	CouponStackingBestOf    CouponStacking = "best_of"
+	CouponStackingStackable CouponStacking = "stackable"
+)
+
+// Coupon limits of zero mean unlimited.
+type Coupon struct {
+	Code             string         `json:"code"`
+	Description      string         `json:"description,omitempty"`
+	Effect           DiscountEffect `json:"effect"`
+	Stacking         CouponStacking `json:"stacking"`
+	ProductIDs       []string       `json:"productIds,omitempty"`
+	MinOrderAmount   *Money         `json:"minOrderAmount,omitempty"`
+	ExpiresAt        *time.Time     `json:"expiresAt,omitempty"`
+	UsageLimit       int            `json:"usageLimit,omitempty"`
+	PerCustomerLimit int            `json:"perCustomerLimit,omitempty"`
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func ValidateCoupons(coupons []Coupon) error {
+	seen := make(map[string]bool, len(coupons))
+	for _, coupon := range coupons {
+		if err := coupon.validate(); err != nil {
+			return err
+		}
+		code := normalizeCouponCode(coupon.Code)
+		if seen[code] {
+			return fmt.Errorf("%w: duplicate code %q", ErrInvalidCoupon, coupon.Code)
+		}
+		seen[code] = true
+	}
+	return nil
+}
+
+func (c Coupon) validate() error {
+	if normalizeCouponCode(c.Code) == "" {
+		return fmt.Errorf("%w: coupon without code", ErrInvalidCoupon)
+	}
+	switch c.Stacking {
+	case CouponStackingExclusive, CouponStackingBestOf, CouponStackingStackable:
+	default:
+		return fmt.Errorf("%w: coupon %q: unknown stacking %q", ErrInvalidCoupon, c.Code, c.Stacking)
+	}
+	if err := c.Effect.validate(); err != nil {
+		return fmt.Errorf("%w: coupon %q: %v", ErrInvalidCoupon, c.Code, err)
+	}
+	if c.MinOrderAmount != nil && !c.MinOrderAmount.Currency().IsKnown() {
+		return fmt.Errorf("%w: coupon %q: minimum without a known currency", ErrInvalidCoupon, c.Code)
+	}
+	if c.UsageLimit < 0 || c.PerCustomerLimit < 0 {
+		return fmt.Errorf("%w: coupon %q: limits cannot be negative", ErrInvalidCoupon, c.Code)
+	}
+	return nil
+}
+
+// checkApplicable covers everything about a coupon except its usage counts,
+// which are checked against the couponUsage tracker.
+func (c Coupon) checkApplicable(amount Money, at time.Time) error {
+	if c.ExpiresAt != nil && !at.Before(*c.ExpiresAt) {
+		return fmt.Errorf("%w: %s expired at %s", ErrCouponExpired, c.Code, c.ExpiresAt.Format(time.RFC3339))
+	}
+	if c.MinOrderAmount != nil {
+		comparison, err := amount.Compare(*c.MinOrderAmount)
+		if err != nil || comparison < 0 {
+			return fmt.Errorf("%w: %s needs at least %s", ErrCouponMinimumNotMet, c.Code, c.MinOrderAmount)
+		}
+	}
+	if !c.Effect.appliesTo(amount) {
+		return fmt.Errorf("coupon %s: %w: %s order", c.Code, ErrCurrencyMismatch, amount.Currency())
+	}
+	return nil
+}
+
+func (c Coupon) targetsLines() bool {
+	return len(c.ProductIDs) > 0
+}
+
+func (c Coupon) checkMatchesLines(lines []LineItem) error {
+	if !c.targetsLines() {
+		return nil
+	}
+	for _, line := range lines {
+		if containsString(c.ProductIDs, line.ProductID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrCouponNoMatchingLines, c.Code)
+}
+
+func (c Coupon) apply(amount Money) AppliedDiscount {
+	discount := c.Effect.discountFor(amount)
+	return AppliedDiscount{
+		Source:      DiscountSourceCoupon,
+		Code:        c.Code,
+		Description: c.Description,
+		Amount:      discount,
+		Explanation: fmt.Sprintf("coupon %q (%s): %s off %s = %s", c.Code, c.Stacking, c.Effect.describe(), amount, discount),
+	}
+}
+
+// =============================================================================
+// COUPON USAGE
+// Redemption counts, overall and per customer
+// =============================================================================
+
+type couponUsage struct {
+	mu          sync.Mutex
+	redemptions map[string]int
+	byCustomer  map[string]map[string]int
+}
+
+func newCouponUsage() *couponUsage {
+	return &couponUsage{
+		redemptions: make(map[string]int),
+		byCustomer:  make(map[string]map[string]int),
+	}
+}
+
+func (u *couponUsage) check(coupon Coupon, customer string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.checkLocked(coupon, customer)
+}
+
+func (u *couponUsage) checkLocked(coupon Coupon, customer string) error {
+	code := normalizeCouponCode(coupon.Code)
+	if coupon.UsageLimit > 0 && u.redemptions[code] >= coupon.UsageLimit {
+		return fmt.Errorf("%w: %s", ErrCouponUsageExhausted, coupon.Code)
+	}
+	if coupon.PerCustomerLimit > 0 && u.byCustomer[code][customer] >= coupon.PerCustomerLimit {
+		return fmt.Errorf("%w: %s for %s", ErrCouponCustomerLimitHit, coupon.Code, customer)
+	}
+	return nil
+}
+
+// redeem counts all coupons or none of them, so concurrent orders cannot
+// push a coupon past its limits.
+func (u *couponUsage) redeem(coupons []Coupon, customer string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, coupon := range coupons {
+		if err := u.checkLocked(coupon, customer); err != nil {
+			return err
+		}
+	}
+	for _, coupon := range coupons {
+		code := normalizeCouponCode(coupon.Code)
+		u.redemptions[code]++
+		if u.byCustomer[code] == nil {
+			u.byCustomer[code] = make(map[string]int)
+		}
+		u.byCustomer[code][customer]++
+	}
+	return nil
+}
+
+func (u *couponUsage) release(coupons []Coupon, customer string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, coupon := range coupons {
+		code := normalizeCouponCode(coupon.Code)
+		if u.redemptions[code] > 0 {
+			u.redemptions[code]--
+		}
+		if u.byCustomer[code][customer] > 0 {
+			u.byCustomer[code][customer]--
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package application
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// COUPON TESTS
+// Testing: coupon.go
+// =============================================================================
+
+func newPercentCoupon(code string, percent float64, stacking CouponStacking) Coupon {
+	return Coupon{
+		Code:     code,
+		Effect:   DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(percent)},
+		Stacking: stacking,
+	}
+}
+
+func TestValidateCoupons_InvalidDefinitions_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		coupons []Coupon
+	}{
+		{"missing code", []Coupon{newPercentCoupon(" ", 5, CouponStackingStackable)}},
+		{"unknown stacking", []Coupon{newPercentCoupon("A", 5, "sometimes")}},
+		{"invalid effect", []Coupon{newPercentCoupon("A", 0, CouponStackingStackable)}},
+		{"negative limit", []Coupon{{Code: "A", Stacking: CouponStackingStackable, UsageLimit: -1,
+			Effect: DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(5)}}}},
+		{"duplicate code ignoring case", []Coupon{newPercentCoupon("promo", 5, CouponStackingStackable), newPercentCoupon("PROMO", 5, CouponStackingStackable)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := ValidateCoupons(tc.coupons)
+
+			// Assert
+			if !errors.Is(err, ErrInvalidCoupon) {
+				t.Errorf("Expected ErrInvalidCoupon, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCoupon_CheckApplicable_ExpiryAndMinimum(t *testing.T) {
+	// Arrange
+	expiry := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	minimum := MustParseMoney("50.00", USD)
+	coupon := newPercentCoupon("SUMMER", 10, CouponStackingStackable)
+	coupon.ExpiresAt = &expiry
+	coupon.MinOrderAmount = &minimum
+
+	testCases := []struct {
+		name     string
+		amount   Money
+		at       time.Time
+		expected error
+	}{
+		{"valid", MustParseMoney("50.00", USD), expiry.Add(-time.Second), nil},
+		{"expired at expiry instant", MustParseMoney("50.00", USD), expiry, ErrCouponExpired},
+		{"below minimum", MustParseMoney("49.99", USD), expiry.Add(-time.Hour), ErrCouponMinimumNotMet},
+		{"minimum in another currency", MustParseMoney("500.00", EUR), expiry.Add(-time.Hour), ErrCouponMinimumNotMet},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := coupon.checkApplicable(tc.amount, tc.at)
+
+			// Assert
+			if !errors.Is(err, tc.expected) || (tc.expected == nil && err != nil) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCouponUsage_Redeem_EnforcesOverallAndPerCustomerLimits(t *testing.T) {
+	// Arrange
+	usage := newCouponUsage()
+	coupon := newPercentCoupon("PARTNER", 5, CouponStackingStackable)
+	coupon.UsageLimit = 2
+	coupon.PerCustomerLimit = 1
+
+	// Act
+	first := usage.redeem([]Coupon{coupon}, "alice")
+	again := usage.redeem([]Coupon{coupon}, "alice")
+	second := usage.redeem([]Coupon{coupon}, "bob")
+	third := usage.redeem([]Coupon{coupon}, "carol")
+
+	// Assert
+	if first != nil || second != nil {
+		t.Fatalf("Expected first redemptions to succeed, got %v / %v", first, second)
+	}
+	if !errors.Is(again, ErrCouponCustomerLimitHit) {
+		t.Errorf("Expected ErrCouponCustomerLimitHit, got %v", again)
+	}
+	if !errors.Is(third, ErrCouponUsageExhausted) {
+		t.Errorf("Expected ErrCouponUsageExhausted, got %v", third)
+	}
+}
+
+func TestCouponUsage_Redeem_FailingCouponCountsNothing(t *testing.T) {
+	// Arrange
+	usage := newCouponUsage()
+	open := newPercentCoupon("OPEN", 5, CouponStackingStackable)
+	once := newPercentCoupon("ONCE", 5, CouponStackingStackable)
+	once.UsageLimit = 1
+	_ = usage.redeem([]Coupon{once}, "alice")
+
+	// Act
+	err := usage.redeem([]Coupon{open, once}, "bob")
+
+	// Assert
+	if !errors.Is(err, ErrCouponUsageExhausted) {
+		t.Errorf("Expected ErrCouponUsageExhausted, got %v", err)
+	}
+	if usage.redemptions["OPEN"] != 0 {
+		t.Errorf("Expected OPEN not to be counted, got %d", usage.redemptions["OPEN"])
+	}
+}
+
+func TestCouponUsage_Release_MakesCouponAvailableAgain(t *testing.T) {
+	// Arrange
+	usage := newCouponUsage()
+	coupon := newPercentCoupon("ONCE", 5, CouponStackingStackable)
+	coupon.UsageLimit = 1
+	_ = usage.redeem([]Coupon{coupon}, "alice")
+
+	// Act
+	usage.release([]Coupon{coupon}, "alice")
+	err := usage.redeem([]Coupon{coupon}, "bob")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected released coupon to be redeemable, got %v", err)
+	}
+}
+
+func TestCouponUsage_ConcurrentRedemptions_NeverExceedLimit(t *testing.T) {
+	// Arrange
+	usage := newCouponUsage()
+	coupon := newPercentCoupon("LIMITED", 5, CouponStackingStackable)
+	coupon.UsageLimit = 10
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	succeeded := 0
+
+	// Act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(customer int) {
+			defer wg.Done()
+			if usage.redeem([]Coupon{coupon}, string(rune('a'+customer))) == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	if succeeded != 10 {
+		t.Errorf("Expected exactly 10 redemptions, got %d", succeeded)
+	}
+}
@@ -1,7 +1,7 @@
 package application
 
 import (
-	"fmt"
+	"context"
 	"time"
 )
 
@@ -10,50 +10,233 @@ import (
 // =============================================================================
 
 type CreditCardProcessor struct {
-	feePercent float64
+	fees             FeeSchedule
+	authorizationTTL time.Duration
+	clock            Clock
+	cardValidator    CardValidatorInterface
+	ledger           *paymentLedger
 }
 
 func NewCreditCardProcessor() PaymentProcessorInterface {
+	processor, _ := NewCreditCardProcessorWithConfig(DefaultCreditCardConfig())
+	return processor
+}
+
+func NewCreditCardProcessorWithConfig(config ProcessorConfig) (PaymentProcessorInterface, error) {
+	config = config.withDefaults(DefaultCreditCardConfig())
+	fees, err := configuredFees(config.Fees, DefaultCreditCardFeeSchedule())
+	if err != nil {
+		return nil, err
+	}
 	return &CreditCardProcessor{
-		feePercent: 2.9,
+		fees:             fees,
+		authorizationTTL: config.AuthorizationTTL,
+		clock:            config.Clock,
+		cardValidator:    NewCardValidator(config.Clock),
+		ledger:           newPaymentLedger(),
+	}, nil
+}
+
+func (c *CreditCardProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return c.executePaymentProcessing(ctx, request)
+}
+
+func (c *CreditCardProcessor) executePaymentProcessing(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	if err := c.validateCard(request); err != nil {
+		return PaymentResult{}, err
+	}
+	quote := c.calculateProcessingFee(request)
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, c.createCancellationError(err)
 	}
+	return c.recordPayment(c.buildPaymentResult(quote)), nil
 }
 
-func (c *CreditCardProcessor) ProcessPayment(amount float64) (string, error) {
-	return c.executePaymentProcessing(amount)
+// validateCard rejects bad card details before anything is charged. The
+// field errors stay reachable with errors.As(err, **CardValidationError).
+func (c *CreditCardProcessor) validateCard(request PaymentRequest) error {
+	if request.Card == nil {
+		return nil
+	}
+	if err := c.cardValidator.Validate(*request.Card); err != nil {
+		return c.createInvalidCardError(err)
+	}
+	return nil
+}
+
+func (c *CreditCardProcessor) createInvalidCardError(cause error) error {
+	err := NewPaymentError(PaymentErrorInvalidRequest, ProcessorTypeCreditCard.DisplayName(), "card details rejected")
+	err.Cause = cause
+	return err
 }
 
-func (c *CreditCardProcessor) executePaymentProcessing(amount float64) (string, error) {
-	fee := c.calculateProcessingFee(amount)
-	total := c.calculateTotalAmount(amount, fee)
-	c.simulateProcessingDelay()
-	return c.formatPaymentResult(total, fee), nil
+func (c *CreditCardProcessor) recordPayment(result PaymentResult) PaymentResult {
+	c.ledger.record(result)
+	return result
 }
 
-func (c *CreditCardProcessor) calculateProcessingFee(amount float64) float64 {
-	return c.computeFeeFromAmount(amount)
+func (c *CreditCardProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return c.calculateProcessingFee(request), nil
 }
 
-func (c *CreditCardProcessor) computeFeeFromAmount(amount float64) float64 {
-	return amount * c.feePercent / 100
+func (c *CreditCardProcessor) calculateProcessingFee(request PaymentRequest) FeeQuote {
+	return c.fees.Quote(request)
 }
 
-func (c *CreditCardProcessor) calculateTotalAmount(amount float64, fee float64) float64 {
-	return c.addFeeToAmount(amount, fee)
+func (c *CreditCardProcessor) simulateProcessingDelay(ctx context.Context) error {
+	return c.waitForProcessing(ctx, 100*time.Millisecond)
 }
 
-func (c *CreditCardProcessor) addFeeToAmount(amount float64, fee float64) float64 {
-	return amount + fee
+func (c *CreditCardProcessor) waitForProcessing(ctx context.Context, duration time.Duration) error {
+	return waitOrCancel(ctx, duration)
 }
 
-func (c *CreditCardProcessor) simulateProcessingDelay() {
-	c.waitForProcessing(100 * time.Millisecond)
+func (c *CreditCardProcessor) createCancellationError(cause error) error {
+	return NewPaymentCancelledError(ProcessorTypeCreditCard.DisplayName(), cause)
 }
 
-func (c *CreditCardProcessor) waitForProcessing(duration time.Duration) {
-	time.Sleep(duration)
+func (c *CreditCardProcessor) buildPaymentResult(quote FeeQuote) PaymentResult {
+	return PaymentResult{
+		ProcessorType: ProcessorTypeCreditCard,
+		GrossAmount:   quote.GrossAmount,
+		Fee:           quote.Fee,
+		NetAmount:     quote.Amount,
+		TransactionID: c.generateTransactionID(),
+		Timestamp:     c.getCurrentTime(),
+		Status:        PaymentStatusSucceeded,
+		FeeBreakdown:  quote,
+	}
+}
+
+func (c *CreditCardProcessor) generateTransactionID() string {
+	return newTransactionID("cc")
 }
 
-func (c *CreditCardProcessor) formatPaymentResult(total float64, fee float64) string {
-	return fmt.Sprintf("Credit Card: $%.2f (fee: $%.2f)", total, fee)
-}
\ No newline at end of file
+func (c *CreditCardProcessor) getCurrentTime() time.Time {
+	return c.clock.Now().UTC()
+}
+
+func (c *CreditCardProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	return c.executeRefund(ctx, request)
+}
+
+func (c *CreditCardProcessor) executeRefund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return RefundResult{}, c.createCancellationError(err)
+	}
+	reversal, err := c.ledger.refund(request, c.calculateFeeReversal)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	return c.buildRefundResult(reversal), nil
+}
+
+// Card networks hand back the processing fee in proportion to the refunded
+// share of the charge; the last refund closes out whatever fee is left.
+func (c *CreditCardProcessor) calculateFeeReversal(entry ledgerEntry, amount Money) Money {
+	if amount == entry.remaining() {
+		return entry.remainingFee()
+	}
+	// A refund never exceeds the gross amount, so the share stays within the fee.
+	reversal, _ := entry.payment.Fee.ScaleByRatio(amount.MinorUnits(), entry.payment.GrossAmount.MinorUnits())
+	return reversal
+}
+
+func (c *CreditCardProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return c.executeVoid(ctx, transactionID)
+}
+
+func (c *CreditCardProcessor) executeVoid(ctx context.Context, transactionID string) (RefundResult, error) {
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return RefundResult{}, c.createCancellationError(err)
+	}
+	reversal, err := c.ledger.void(transactionID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	return c.buildRefundResult(reversal), nil
+}
+
+func (c *CreditCardProcessor) buildRefundResult(reversal ledgerReversal) RefundResult {
+	return RefundResult{
+		RefundID:            c.generateRefundID(),
+		TransactionID:       reversal.payment.TransactionID,
+		ProcessorType:       ProcessorTypeCreditCard,
+		Type:                reversal.refundType(),
+		Amount:              reversal.amount,
+		FeeReversed:         reversal.feeReversed,
+		RemainingRefundable: reversal.remaining,
+		Timestamp:           c.getCurrentTime(),
+		Status:              reversal.paymentStatus(),
+	}
+}
+
+func (c *CreditCardProcessor) generateRefundID() string {
+	return newTransactionID("cc_re")
+}
+
+func (c *CreditCardProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return c.executeAuthorization(ctx, request)
+}
+
+func (c *CreditCardProcessor) executeAuthorization(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	if err := c.validateCard(request); err != nil {
+		return PaymentResult{}, err
+	}
+	quote := c.calculateProcessingFee(request)
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, c.createCancellationError(err)
+	}
+	return c.recordAuthorization(c.buildAuthorizationResult(quote)), nil
+}
+
+func (c *CreditCardProcessor) buildAuthorizationResult(quote FeeQuote) PaymentResult {
+	result := c.buildPaymentResult(quote)
+	result.Status = PaymentStatusAuthorized
+	result.ExpiresAt = result.Timestamp.Add(c.authorizationTTL)
+	return result
+}
+
+func (c *CreditCardProcessor) recordAuthorization(result PaymentResult) PaymentResult {
+	c.ledger.authorize(result)
+	return result
+}
+
+func (c *CreditCardProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	return c.executeCapture(ctx, authorizationID, amount)
+}
+
+func (c *CreditCardProcessor) executeCapture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, c.createCancellationError(err)
+	}
+	return c.ledger.capture(authorizationID, amount, c.getCurrentTime(), c.buildCaptureResult)
+}
+
+// A capture is priced like its authorization, on the captured amount.
+func (c *CreditCardProcessor) buildCaptureResult(authorization PaymentResult, amount Money) PaymentResult {
+	result := c.buildPaymentResult(c.fees.quoteLike(amount, authorization.FeeBreakdown))
+	result.TransactionID = authorization.TransactionID
+	return result
+}
+
+func (c *CreditCardProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return c.executeRelease(ctx, authorizationID)
+}
+
+func (c *CreditCardProcessor) executeRelease(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	if err := c.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, c.createCancellationError(err)
+	}
+	authorization, err := c.ledger.release(authorizationID, c.getCurrentTime())
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	return c.buildReleaseResult(authorization), nil
+}
+
+func (c *CreditCardProcessor) buildReleaseResult(authorization PaymentResult) PaymentResult {
+	authorization.Status = PaymentStatusReleased
+	authorization.Timestamp = c.getCurrentTime()
+	return authorization
+}
@@ -1,8 +1,11 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -10,100 +13,100 @@ import (
 // Testing: credit_card_processor.go
 // =============================================================================
 
-func TestCreditCardProcessor_ProcessPayment_ValidAmount_ReturnsSuccessMessage(t *testing.T) {
+func TestCreditCardProcessor_ProcessPayment_ValidAmount_ReturnsPaymentResult(t *testing.T) {
 	// Arrange: Test the over-abstracted CreditCardProcessor
 	processor := NewCreditCardProcessor()
-	amount := 100.0
+	amount := MustParseMoney("100.00", USD)
 
 	// Act: Call the method that goes through multiple abstraction layers
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify payment processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if result == "" {
-		t.Error("Expected non-empty result")
+	if result.TransactionID == "" {
+		t.Error("Expected a transaction ID")
 	}
-	if !strings.Contains(result, "Credit Card") {
-		t.Errorf("Expected result to contain 'Credit Card', got '%s'", result)
+	if result.ProcessorType != ProcessorTypeCreditCard {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypeCreditCard, result.ProcessorType)
 	}
-	if !strings.Contains(result, "102.90") { // Amount + 2.9% fee
-		t.Errorf("Expected result to contain '102.90', got '%s'", result)
+	if result.GrossAmount.Decimal() != "102.90" { // Amount + 2.9% fee
+		t.Errorf("Expected result gross amount 102.90, got %s", result.GrossAmount)
 	}
-	if !strings.Contains(result, "fee:") {
-		t.Errorf("Expected result to contain 'fee:', got '%s'", result)
+	if result.Fee.Decimal() != "2.90" {
+		t.Errorf("Expected fee 2.90, got %s", result.Fee)
 	}
 }
 
 func TestCreditCardProcessor_ProcessPayment_SmallAmount_CalculatesCorrectFee(t *testing.T) {
 	// Arrange: Test small amount processing
 	processor := NewCreditCardProcessor()
-	amount := 10.0
+	amount := MustParseMoney("10.00", USD)
 
 	// Act: Process through the abstraction layers
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify fee calculation (10 + 2.9% = 10.29)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "10.29") {
-		t.Errorf("Expected result to contain '10.29', got '%s'", result)
+	if result.GrossAmount.Decimal() != "10.29" {
+		t.Errorf("Expected result gross amount 10.29, got %s", result.GrossAmount)
 	}
 }
 
 func TestCreditCardProcessor_ProcessPayment_LargeAmount_CalculatesCorrectFee(t *testing.T) {
 	// Arrange: Test large amount processing
 	processor := NewCreditCardProcessor()
-	amount := 1000.0
+	amount := MustParseMoney("1000.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify fee calculation (1000 + 2.9% = 1029.00)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "1029.00") {
-		t.Errorf("Expected result to contain '1029.00', got '%s'", result)
+	if result.GrossAmount.Decimal() != "1029.00" {
+		t.Errorf("Expected result gross amount 1029.00, got %s", result.GrossAmount)
 	}
 }
 
 func TestCreditCardProcessor_ProcessPayment_ZeroAmount_ProcessesWithoutFee(t *testing.T) {
 	// Arrange: Test zero amount
 	processor := NewCreditCardProcessor()
-	amount := 0.0
+	amount := MustParseMoney("0.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify zero amount processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "0.00") {
-		t.Errorf("Expected result to contain '0.00', got '%s'", result)
+	if result.GrossAmount.Decimal() != "0.00" {
+		t.Errorf("Expected result gross amount 0.00, got %s", result.GrossAmount)
 	}
 }
 
 func TestCreditCardProcessor_ProcessPayment_NegativeAmount_ProcessesNegativeFee(t *testing.T) {
 	// Arrange: Test negative amount (edge case)
 	processor := NewCreditCardProcessor()
-	amount := -50.0
+	amount := MustParseMoney("-50.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify negative processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if result == "" {
-		t.Error("Expected non-empty result")
+	if result.TransactionID == "" {
+		t.Error("Expected a transaction ID")
 	}
-	if !strings.Contains(result, "Credit Card") {
-		t.Errorf("Expected result to contain 'Credit Card', got '%s'", result)
+	if result.ProcessorType != ProcessorTypeCreditCard {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypeCreditCard, result.ProcessorType)
 	}
 }
 
@@ -111,26 +114,26 @@ func TestCreditCardProcessor_ProcessPayment_VariousAmounts_CalculatesCorrectTota
 	processor := NewCreditCardProcessor()
 
 	testCases := []struct {
-		amount        float64
+		amount        Money
 		expectedTotal string
 	}{
-		{100.0, "102.90"},
-		{50.0, "51.45"},
-		{200.0, "205.80"},
-		{1.0, "1.03"},
+		{MustParseMoney("100.00", USD), "102.90"},
+		{MustParseMoney("50.00", USD), "51.45"},
+		{MustParseMoney("200.00", USD), "205.80"},
+		{MustParseMoney("1.00", USD), "1.03"},
 	}
 
 	for _, tc := range testCases {
 		t.Run("Amount_"+strings.ReplaceAll(tc.expectedTotal, ".", "_"), func(t *testing.T) {
 			// Act: Process through all the abstraction layers
-			result, err := processor.ProcessPayment(tc.amount)
+			result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(tc.amount))
 
 			// Assert: Verify correct total calculation
 			if err != nil {
 				t.Errorf("Expected no error, got %v", err)
 			}
-			if !strings.Contains(result, tc.expectedTotal) {
-				t.Errorf("Expected result to contain '%s', got '%s'", tc.expectedTotal, result)
+			if result.GrossAmount.Decimal() != tc.expectedTotal {
+				t.Errorf("Expected gross amount %s, got %s", tc.expectedTotal, result.GrossAmount)
 			}
 		})
 	}
@@ -141,44 +144,457 @@ func TestCreditCardProcessor_ProcessPayment_MultipleCalls_AllSucceed(t *testing.
 	processor := NewCreditCardProcessor()
 
 	// Act: Process multiple payments
-	result1, err1 := processor.ProcessPayment(10.0)
-	result2, err2 := processor.ProcessPayment(20.0)
-	result3, err3 := processor.ProcessPayment(30.0)
+	result1, err1 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+	result2, err2 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("20.00", USD)))
+	result3, err3 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("30.00", USD)))
 
 	// Assert: Verify all calls succeed
 	if err1 != nil || err2 != nil || err3 != nil {
 		t.Error("Expected no errors for multiple calls")
 	}
-	if !strings.Contains(result1, "10.29") {
-		t.Errorf("Expected result1 to contain '10.29', got '%s'", result1)
+	if result1.GrossAmount.Decimal() != "10.29" {
+		t.Errorf("Expected result1 gross amount 10.29, got %s", result1.GrossAmount)
 	}
-	if !strings.Contains(result2, "20.58") {
-		t.Errorf("Expected result2 to contain '20.58', got '%s'", result2)
+	if result2.GrossAmount.Decimal() != "20.58" {
+		t.Errorf("Expected result2 gross amount 20.58, got %s", result2.GrossAmount)
 	}
-	if !strings.Contains(result3, "30.87") {
-		t.Errorf("Expected result3 to contain '30.87', got '%s'", result3)
+	if result3.GrossAmount.Decimal() != "30.87" {
+		t.Errorf("Expected result3 gross amount 30.87, got %s", result3.GrossAmount)
 	}
 }
 
 func TestCreditCardProcessor_ProcessPayment_ContainsExpectedElements(t *testing.T) {
 	// Arrange: Test result format
 	processor := NewCreditCardProcessor()
-	amount := 75.0
+	amount := MustParseMoney("75.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify result contains all expected elements
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "Credit Card") {
-		t.Errorf("Expected result to contain 'Credit Card', got '%s'", result)
+	if result.ProcessorType != ProcessorTypeCreditCard {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypeCreditCard, result.ProcessorType)
 	}
-	if !strings.Contains(result, "fee:") {
-		t.Errorf("Expected result to contain 'fee:', got '%s'", result)
+	if result.Fee.Decimal() != "2.18" {
+		t.Errorf("Expected fee 2.18, got %s", result.Fee)
 	}
-	if !strings.Contains(result, "77.17") { // 75 + 2.9% = 77.175 ≈ 77.17
-		t.Errorf("Expected result to contain '77.17', got '%s'", result)
+	if result.GrossAmount.Decimal() != "77.18" { // 75 + 2.9% fee of 2.175 rounded half-up to 2.18
+		t.Errorf("Expected result gross amount 77.18, got %s", result.GrossAmount)
 	}
-}
\ No newline at end of file
+}
+
+func TestCreditCardProcessor_ProcessPayment_CancelledContext_ReturnsCancellationError(t *testing.T) {
+	// Arrange: Context is cancelled before processing starts
+	processor := NewCreditCardProcessor()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	result, err := processor.ProcessPayment(ctx, NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert: Typed error that still matches the context error
+	var cancelled *PaymentCancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("Expected PaymentCancelledError, got %v", err)
+	}
+	if cancelled.Processor != "Credit Card" {
+		t.Errorf("Expected processor 'Credit Card', got '%s'", cancelled.Processor)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error to match context.Canceled, got %v", err)
+	}
+	if result.TransactionID != "" {
+		t.Error("Expected empty result on cancellation")
+	}
+}
+
+func TestCreditCardProcessor_ProcessPayment_ValidAmount_ReturnsStructuredBreakdown(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	amount := MustParseMoney("100.00", USD)
+
+	// Act
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
+
+	// Assert: Gross minus fee equals the net amount
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.NetAmount != amount {
+		t.Errorf("Expected net amount %s, got %s", amount, result.NetAmount)
+	}
+	if result.Status != PaymentStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", PaymentStatusSucceeded, result.Status)
+	}
+	if result.Timestamp.IsZero() {
+		t.Error("Expected a timestamp")
+	}
+}
+
+func TestCreditCardProcessor_ProcessPayment_MultipleCalls_UseDistinctTransactionIDs(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+
+	// Act
+	first, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+	second, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if first.TransactionID == second.TransactionID {
+		t.Errorf("Expected distinct transaction IDs, got '%s' twice", first.TransactionID)
+	}
+}
+
+func TestCreditCardProcessor_Refund_PartialAmount_ReversesProportionalFee(t *testing.T) {
+	// Arrange: $100 charge captures $102.90 with a $2.90 fee
+	processor := NewCreditCardProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act: Refund half of the captured amount
+	refund, err := processor.Refund(context.Background(), NewPartialRefundRequest(payment.TransactionID, MustParseMoney("51.45", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.Type != RefundTypePartial {
+		t.Errorf("Expected refund type %s, got %s", RefundTypePartial, refund.Type)
+	}
+	if refund.FeeReversed.Decimal() != "1.45" {
+		t.Errorf("Expected fee reversed 1.45, got %s", refund.FeeReversed)
+	}
+	if refund.RemainingRefundable.Decimal() != "51.45" {
+		t.Errorf("Expected remaining 51.45, got %s", refund.RemainingRefundable)
+	}
+	if refund.Status != PaymentStatusPartiallyRefunded {
+		t.Errorf("Expected status %s, got %s", PaymentStatusPartiallyRefunded, refund.Status)
+	}
+}
+
+func TestCreditCardProcessor_Refund_FullAfterPartial_ClosesOutRemainingFee(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+	first, _ := processor.Refund(context.Background(), NewPartialRefundRequest(payment.TransactionID, MustParseMoney("51.45", USD)))
+
+	// Act
+	second, err := processor.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID))
+
+	// Assert: Both refunds together return the whole charge and fee
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if second.Type != RefundTypeFull {
+		t.Errorf("Expected refund type %s, got %s", RefundTypeFull, second.Type)
+	}
+	if second.Amount.Decimal() != "51.45" {
+		t.Errorf("Expected second refund 51.45, got %s", second.Amount)
+	}
+	totalFee, _ := first.FeeReversed.Add(second.FeeReversed)
+	if totalFee != payment.Fee {
+		t.Errorf("Expected total fee reversed %s, got %s", payment.Fee, totalFee)
+	}
+}
+
+func TestCreditCardProcessor_Refund_ExceedsCaptured_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Act
+	_, err := processor.Refund(context.Background(), NewPartialRefundRequest(payment.TransactionID, MustParseMoney("10.30", USD)))
+
+	// Assert: Captured amount is 10.29
+	if !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("Expected ErrRefundExceedsCaptured, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Refund_UnknownTransaction_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+
+	// Act
+	_, err := processor.Refund(context.Background(), NewFullRefundRequest("cc_missing"))
+
+	// Assert
+	if !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Expected ErrTransactionNotFound, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Void_UnrefundedPayment_ReversesEverything(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	void, err := processor.Void(context.Background(), payment.TransactionID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if void.Type != RefundTypeVoid || void.Status != PaymentStatusVoided {
+		t.Errorf("Expected a voided reversal, got %s/%s", void.Type, void.Status)
+	}
+	if void.Amount != payment.GrossAmount || void.FeeReversed != payment.Fee {
+		t.Errorf("Expected %s and fee %s reversed, got %s and %s", payment.GrossAmount, payment.Fee, void.Amount, void.FeeReversed)
+	}
+}
+
+func TestCreditCardProcessor_Void_AfterRefund_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+	_, _ = processor.Refund(context.Background(), NewPartialRefundRequest(payment.TransactionID, MustParseMoney("1.00", USD)))
+
+	// Act
+	_, err := processor.Void(context.Background(), payment.TransactionID)
+
+	// Assert
+	if !errors.Is(err, ErrVoidNotAllowed) {
+		t.Errorf("Expected ErrVoidNotAllowed, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Authorize_ValidAmount_ReservesFundsUntilExpiry(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	processor, _ := NewCreditCardProcessorWithConfig(ProcessorConfig{AuthorizationTTL: 48 * time.Hour, Clock: clock})
+
+	// Act
+	authorization, err := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authorization.Status != PaymentStatusAuthorized {
+		t.Errorf("Expected status %s, got %s", PaymentStatusAuthorized, authorization.Status)
+	}
+	expectedExpiry := time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)
+	if !authorization.ExpiresAt.Equal(expectedExpiry) {
+		t.Errorf("Expected expiry %v, got %v", expectedExpiry, authorization.ExpiresAt)
+	}
+}
+
+func TestCreditCardProcessor_Capture_FullAmount_SettlesAuthorization(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act: A zero amount captures everything that was authorized
+	capture, err := processor.Capture(context.Background(), authorization.TransactionID, Money{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if capture.Status != PaymentStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", PaymentStatusSucceeded, capture.Status)
+	}
+	if capture.GrossAmount.Decimal() != "102.90" {
+		t.Errorf("Expected captured gross 102.90, got %s", capture.GrossAmount)
+	}
+	if capture.TransactionID != authorization.TransactionID {
+		t.Errorf("Expected capture to keep transaction %s, got %s", authorization.TransactionID, capture.TransactionID)
+	}
+}
+
+func TestCreditCardProcessor_Capture_PartialAmount_RecalculatesFeeAndIsRefundable(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	capture, err := processor.Capture(context.Background(), authorization.TransactionID, MustParseMoney("60.00", USD))
+	_, refundErr := processor.Refund(context.Background(), NewFullRefundRequest(capture.TransactionID))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if capture.Fee.Decimal() != "1.74" {
+		t.Errorf("Expected fee 1.74, got %s", capture.Fee)
+	}
+	if refundErr != nil {
+		t.Errorf("Expected captured payment to be refundable, got %v", refundErr)
+	}
+}
+
+func TestCreditCardProcessor_Capture_MoreThanAuthorized_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	_, err := processor.Capture(context.Background(), authorization.TransactionID, MustParseMoney("100.01", USD))
+
+	// Assert
+	if !errors.Is(err, ErrCaptureExceedsAuthorized) {
+		t.Errorf("Expected ErrCaptureExceedsAuthorized, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Capture_Twice_ReturnsNotPendingError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+	_, _ = processor.Capture(context.Background(), authorization.TransactionID, MustParseMoney("40.00", USD))
+
+	// Act: The uncaptured remainder was released by the first capture
+	_, err := processor.Capture(context.Background(), authorization.TransactionID, MustParseMoney("40.00", USD))
+
+	// Assert
+	if !errors.Is(err, ErrAuthorizationNotPending) {
+		t.Errorf("Expected ErrAuthorizationNotPending, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Capture_AfterExpiry_ReturnsExpiredError(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	processor, _ := NewCreditCardProcessorWithConfig(ProcessorConfig{AuthorizationTTL: time.Hour, Clock: clock})
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+	clock.Advance(time.Hour)
+
+	// Act
+	_, err := processor.Capture(context.Background(), authorization.TransactionID, Money{})
+
+	// Assert
+	if !errors.Is(err, ErrAuthorizationExpired) {
+		t.Errorf("Expected ErrAuthorizationExpired, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_Capture_UnknownAuthorization_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewCreditCardProcessor()
+
+	// Act
+	_, err := processor.Capture(context.Background(), "cc_missing", Money{})
+
+	// Assert
+	if !errors.Is(err, ErrAuthorizationNotFound) {
+		t.Errorf("Expected ErrAuthorizationNotFound, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_WithFeeSchedule_ChargesScheduledFee(t *testing.T) {
+	// Arrange
+	config := DefaultCreditCardConfig()
+	schedule := FeeSchedule{Percent: NewPercentageFromFloat(2.9), FixedFees: []Money{MustParseMoney("0.30", USD)}}
+	config.Fees = &schedule
+	processor, _ := NewCreditCardProcessorWithConfig(config)
+
+	// Act
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fee.Decimal() != "3.20" || result.GrossAmount.Decimal() != "103.20" {
+		t.Errorf("Expected fee 3.20 on 103.20, got %s on %s", result.Fee, result.GrossAmount)
+	}
+	if result.FeeBreakdown.FixedFee.Decimal() != "0.30" {
+		t.Errorf("Expected the fixed fee in the breakdown, got %s", result.FeeBreakdown.FixedFee)
+	}
+}
+
+func TestCreditCardProcessor_WithInvalidFeeSchedule_ReturnsError(t *testing.T) {
+	// Arrange
+	config := DefaultCreditCardConfig()
+	config.Fees = &FeeSchedule{CardBrandSurcharges: map[string]Percentage{"diners": NewPercentageFromFloat(1)}}
+
+	// Act
+	_, err := NewCreditCardProcessorWithConfig(config)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidFeeSchedule) {
+		t.Errorf("Expected ErrInvalidFeeSchedule, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_ZeroConfig_UsesDefaultClockAndTTL(t *testing.T) {
+	// Arrange
+	schedule := DefaultCreditCardFeeSchedule()
+	processor, _ := NewCreditCardProcessorWithConfig(ProcessorConfig{Fees: &schedule})
+
+	// Act
+	authorization, err := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ttl := authorization.ExpiresAt.Sub(authorization.Timestamp); ttl != DefaultCreditCardConfig().AuthorizationTTL {
+		t.Errorf("Expected the default authorization TTL, got %s", ttl)
+	}
+}
+
+func TestCreditCardProcessor_Capture_KeepsAuthorizationSurcharges(t *testing.T) {
+	// Arrange
+	config := DefaultCreditCardConfig()
+	schedule := FeeSchedule{Percent: NewPercentageFromFloat(2.9), CardBrandSurcharges: map[string]Percentage{"amex": NewPercentageFromFloat(1)}}
+	config.Fees = &schedule
+	processor, _ := NewCreditCardProcessorWithConfig(config)
+	authorization, _ := processor.Authorize(context.Background(), PaymentRequest{Amount: MustParseMoney("100.00", USD), CardBrand: "amex"})
+
+	// Act
+	capture, err := processor.Capture(context.Background(), authorization.TransactionID, MustParseMoney("50.00", USD))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if capture.Fee.Decimal() != "1.95" {
+		t.Errorf("Expected 2.9%% plus the 1%% amex surcharge on 50.00, got %s", capture.Fee)
+	}
+}
+
+func TestCreditCardProcessor_ProcessPayment_InvalidCard_RejectsBeforeCharging(t *testing.T) {
+	// Arrange
+	config := DefaultCreditCardConfig()
+	config.Clock = NewManualClock(cardValidationTime)
+	processor, _ := NewCreditCardProcessorWithConfig(config)
+	card := newTestCard()
+	card.CVC = "1"
+
+	// Act
+	_, err := processor.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("10.00", USD), Card: &card})
+
+	// Assert
+	var validationErr *CardValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != CardFieldCVC {
+		t.Errorf("Expected a CVC field error, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidPaymentRequest) || IsRetryablePaymentError(err) {
+		t.Errorf("Expected a non-retryable invalid request, got %v", err)
+	}
+}
+
+func TestCreditCardProcessor_ProcessPayment_AmexCard_AddsBrandSurcharge(t *testing.T) {
+	// Arrange
+	config := DefaultCreditCardConfig()
+	config.Clock = NewManualClock(cardValidationTime)
+	schedule := FeeSchedule{Percent: NewPercentageFromFloat(2.9), CardBrandSurcharges: map[string]Percentage{"Amex": NewPercentageFromFloat(0.6)}}
+	config.Fees = &schedule
+	processor, _ := NewCreditCardProcessorWithConfig(config)
+	card := Card{Number: "3782 822463 10005", ExpiryMonth: 1, ExpiryYear: 2027, CVC: "1234", HolderName: "Jo Doe"}
+
+	// Act
+	result, err := processor.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("100.00", USD), Card: &card})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Fee.Decimal() != "3.50" || result.FeeBreakdown.CardBrand != "amex" {
+		t.Errorf("Expected a 3.50 fee with the amex surcharge, got %s (%+v)", result.Fee, result.FeeBreakdown)
+	}
+}
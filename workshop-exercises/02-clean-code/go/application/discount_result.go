@@ -0,0 +1,60 @@
+package application
+
+// =============================================================================
+// DISCOUNT RESULT
+// What DiscountService is asked to price and the breakdown it hands back
+// =============================================================================
+
+// DiscountRequest carries either a bare Amount or line items; with line
+// items the amount is their sum and Amount, if set, must agree with it.
+type DiscountRequest struct {
+	Amount       Money
+	LineItems    []LineItem
+	Customer     string
+	CustomerType string
+	CouponCodes  []string
+}
+
+type DiscountSource string
+
+const (
+	DiscountSourceRule   DiscountSource = "rule"
+	DiscountSourceCoupon DiscountSource = "coupon"
+)
+
+// AppliedDiscount is one entry of the breakdown. Code is the rule ID for rule
+// discounts and the coupon code for coupons; ProductID is set when the
+// discount was aimed at a single order line.
+type AppliedDiscount struct {
+	Source      DiscountSource
+	Code        string
+	ProductID   string
+	Description string
+	Amount      Money
+	Explanation string
+}
+
+// DiscountResult lists every discount, line discounts first, in Discounts.
+// Lines is empty for orders placed without line items.
+type DiscountResult struct {
+	OriginalAmount   Money
+	Lines            []LineBreakdown
+	Discounts        []AppliedDiscount
+	TotalDiscount    Money
+	DiscountedAmount Money
+}
+
+// CouponCodes lists each coupon used once, even when it discounted several
+// lines, so one order counts as one redemption.
+func (r DiscountResult) CouponCodes() []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, discount := range r.Discounts {
+		code := normalizeCouponCode(discount.Code)
+		if discount.Source == DiscountSourceCoupon && !seen[code] {
+			seen[code] = true
+			codes = append(codes, discount.Code)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,386 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// DISCOUNT RULES
+// Data-driven discount rules, loaded from JSON so rates can change without a
+// release. The matching rule with the highest priority wins.
+// =============================================================================
+
+var ErrInvalidDiscountRule = errors.New("invalid discount rule")
+
+type DiscountEffectType string
+
+const (
+	DiscountEffectPercentOff DiscountEffectType = "percent_off"
+	DiscountEffectFixedOff   DiscountEffectType = "fixed_off"
+)
+
+type DiscountRule struct {
+	ID          string             `json:"id"`
+	Description string             `json:"description,omitempty"`
+	Priority    int                `json:"priority"`
+	Conditions  DiscountConditions `json:"conditions"`
+	Effect      DiscountEffect     `json:"effect"`
+}
+
+// DiscountConditions are all optional; an empty condition matches everything.
+// Amount and date ranges include their lower bound and exclude the upper one.
+// A rule with product IDs targets order lines instead of the whole order: it
+// is checked against each matching line, with the amount range applied to
+// the line subtotal, and a fixed amount off comes off each matching line.
+type DiscountConditions struct {
+	ProductIDs    []string   `json:"productIds,omitempty"`
+	CustomerTypes []string   `json:"customerTypes,omitempty"`
+	MinAmount     *Money     `json:"minAmount,omitempty"`
+	MaxAmount     *Money     `json:"maxAmount,omitempty"`
+	ValidFrom     *time.Time `json:"validFrom,omitempty"`
+	ValidUntil    *time.Time `json:"validUntil,omitempty"`
+}
+
+type DiscountEffect struct {
+	Type    DiscountEffectType `json:"type"`
+	Percent Percentage         `json:"percent,omitempty"`
+	Amount  *Money             `json:"amount,omitempty"`
+}
+
+// DiscountConfig is the layout of the discount file: {"rules": [...],
+// "coupons": [...]}. Coupons are described in coupon.go.
+type DiscountConfig struct {
+	Rules   []DiscountRule `json:"rules"`
+	Coupons []Coupon       `json:"coupons,omitempty"`
+}
+
+// DefaultDiscountRules are the rates DiscountService has always applied:
+// 15% for premium customers, 5% for regular ones and nothing for anyone else.
+func DefaultDiscountRules() []DiscountRule {
+	return []DiscountRule{
+		{
+			ID:          "premium",
+			Description: "Premium customer discount",
+			Priority:    10,
+			Conditions:  DiscountConditions{CustomerTypes: []string{"premium"}},
+			Effect:      DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(15)},
+		},
+		{
+			ID:          "regular",
+			Description: "Regular customer discount",
+			Priority:    10,
+			Conditions:  DiscountConditions{CustomerTypes: []string{"regular"}},
+			Effect:      DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(5)},
+		},
+	}
+}
+
+func LoadDiscountConfig(path string) (DiscountConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DiscountConfig{}, fmt.Errorf("read discount rules: %w", err)
+	}
+	config, err := ParseDiscountConfig(data)
+	if err != nil {
+		return DiscountConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+func LoadDiscountRules(path string) ([]DiscountRule, error) {
+	config, err := LoadDiscountConfig(path)
+	return config.Rules, err
+}
+
+// ParseDiscountConfig reads a discount file and validates its rules and
+// coupons. Unknown fields are rejected so a typo cannot widen a rule.
+func ParseDiscountConfig(data []byte) (DiscountConfig, error) {
+	var config DiscountConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return DiscountConfig{}, fmt.Errorf("decode discount rules: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return DiscountConfig{}, err
+	}
+	return config, nil
+}
+
+func ParseDiscountRules(data []byte) ([]DiscountRule, error) {
+	config, err := ParseDiscountConfig(data)
+	return config.Rules, err
+}
+
+func (c DiscountConfig) Validate() error {
+	if err := ValidateDiscountRules(c.Rules); err != nil {
+		return err
+	}
+	return ValidateCoupons(c.Coupons)
+}
+
+func ValidateDiscountRules(rules []DiscountRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("%w: duplicate rule id %q", ErrInvalidDiscountRule, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	return nil
+}
+
+func (r DiscountRule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: rule without id", ErrInvalidDiscountRule)
+	}
+	if err := r.Conditions.validate(); err != nil {
+		return fmt.Errorf("%w: rule %q: %v", ErrInvalidDiscountRule, r.ID, err)
+	}
+	if err := r.Effect.validate(); err != nil {
+		return fmt.Errorf("%w: rule %q: %v", ErrInvalidDiscountRule, r.ID, err)
+	}
+	return nil
+}
+
+func (c DiscountConditions) validate() error {
+	for _, bound := range []*Money{c.MinAmount, c.MaxAmount} {
+		if bound != nil && !bound.Currency().IsKnown() {
+			return fmt.Errorf("amount bound without a known currency")
+		}
+	}
+	if c.MinAmount != nil && c.MaxAmount != nil {
+		comparison, err := c.MinAmount.Compare(*c.MaxAmount)
+		if err != nil {
+			return err
+		}
+		if comparison >= 0 {
+			return fmt.Errorf("minAmount %s must be below maxAmount %s", c.MinAmount, c.MaxAmount)
+		}
+	}
+	if c.ValidFrom != nil && c.ValidUntil != nil && !c.ValidFrom.Before(*c.ValidUntil) {
+		return fmt.Errorf("validFrom must be before validUntil")
+	}
+	return nil
+}
+
+func (e DiscountEffect) validate() error {
+	switch e.Type {
+	case DiscountEffectPercentOff:
+		if e.Percent <= 0 || e.Percent > NewPercentageFromFloat(100) {
+			return fmt.Errorf("percent must be above 0 and at most 100, got %s", e.Percent)
+		}
+	case DiscountEffectFixedOff:
+		if e.Amount == nil || !e.Amount.Currency().IsKnown() || !e.Amount.IsPositive() {
+			return fmt.Errorf("fixed_off needs a positive amount with a currency")
+		}
+	default:
+		return fmt.Errorf("unknown effect type %q", e.Type)
+	}
+	return nil
+}
+
+// =============================================================================
+// DISCOUNT RULE ENGINE
+// =============================================================================
+
+type DiscountDecision struct {
+	Matched          bool
+	Rule             DiscountRule
+	OriginalAmount   Money
+	Discount         Money
+	DiscountedAmount Money
+	Explanation      string
+}
+
+type DiscountRuleEngine struct {
+	rules []DiscountRule
+}
+
+// NewDiscountRuleEngine orders rules by descending priority; rules with equal
+// priority keep the order they were given in.
+func NewDiscountRuleEngine(rules []DiscountRule) *DiscountRuleEngine {
+	sorted := append([]DiscountRule(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return &DiscountRuleEngine{rules: sorted}
+}
+
+// Evaluate picks the order-wide rule for the order amount; rules that target
+// products are left to EvaluateLine.
+func (e *DiscountRuleEngine) Evaluate(amount Money, customerType string, at time.Time) DiscountDecision {
+	return e.evaluate(amount, customerType, at, func(rule DiscountRule) bool {
+		return !rule.Conditions.targetsLines()
+	})
+}
+
+// EvaluateLine picks the highest-priority rule targeting the line's product.
+// The line must have passed LineItemsTotal, which rejects subtotals that
+// overflow.
+func (e *DiscountRuleEngine) EvaluateLine(line LineItem, customerType string, at time.Time) DiscountDecision {
+	subtotal, _ := line.Subtotal()
+	return e.evaluate(subtotal, customerType, at, func(rule DiscountRule) bool {
+		return rule.Conditions.matchesProduct(line.ProductID)
+	})
+}
+
+func (e *DiscountRuleEngine) evaluate(amount Money, customerType string, at time.Time, inScope func(DiscountRule) bool) DiscountDecision {
+	for _, rule := range e.rules {
+		if inScope(rule) && rule.matches(amount, customerType, at) {
+			return e.applyRule(rule, amount)
+		}
+	}
+	return DiscountDecision{
+		OriginalAmount:   amount,
+		Discount:         ZeroMoney(amount.Currency()),
+		DiscountedAmount: amount,
+		Explanation:      "no discount rule matched",
+	}
+}
+
+func (d DiscountDecision) appliedDiscount() AppliedDiscount {
+	return AppliedDiscount{
+		Source:      DiscountSourceRule,
+		Code:        d.Rule.ID,
+		Description: d.Rule.Description,
+		Amount:      d.Discount,
+		Explanation: d.Explanation,
+	}
+}
+
+func (e *DiscountRuleEngine) applyRule(rule DiscountRule, amount Money) DiscountDecision {
+	discount := rule.Effect.discountFor(amount)
+	discounted, _ := amount.Subtract(discount)
+	return DiscountDecision{
+		Matched:          true,
+		Rule:             rule,
+		OriginalAmount:   amount,
+		Discount:         discount,
+		DiscountedAmount: discounted,
+		Explanation:      rule.explain(amount, discount),
+	}
+}
+
+func (r DiscountRule) matches(amount Money, customerType string, at time.Time) bool {
+	return r.Conditions.matchesCustomerType(customerType) &&
+		r.Conditions.matchesAmount(amount) &&
+		r.Conditions.matchesDate(at) &&
+		r.Effect.appliesTo(amount)
+}
+
+func (c DiscountConditions) targetsLines() bool {
+	return len(c.ProductIDs) > 0
+}
+
+func (c DiscountConditions) matchesProduct(productID string) bool {
+	return containsString(c.ProductIDs, productID)
+}
+
+func (c DiscountConditions) matchesCustomerType(customerType string) bool {
+	return len(c.CustomerTypes) == 0 || containsString(c.CustomerTypes, customerType)
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// An amount bound in another currency never matches; rules are not converted.
+func (c DiscountConditions) matchesAmount(amount Money) bool {
+	if c.MinAmount != nil {
+		comparison, err := amount.Compare(*c.MinAmount)
+		if err != nil || comparison < 0 {
+			return false
+		}
+	}
+	if c.MaxAmount != nil {
+		comparison, err := amount.Compare(*c.MaxAmount)
+		if err != nil || comparison >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c DiscountConditions) matchesDate(at time.Time) bool {
+	if c.ValidFrom != nil && at.Before(*c.ValidFrom) {
+		return false
+	}
+	if c.ValidUntil != nil && !at.Before(*c.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+func (e DiscountEffect) appliesTo(amount Money) bool {
+	return e.Type != DiscountEffectFixedOff || e.Amount.Currency() == amount.Currency()
+}
+
+// A fixed discount never takes the amount below zero.
+func (e DiscountEffect) discountFor(amount Money) Money {
+	if e.Type == DiscountEffectPercentOff {
+		// validate caps the percent at 100, so the discount fits the amount.
+		discount, _ := amount.ApplyPercentage(e.Percent)
+		return discount
+	}
+	if comparison, _ := e.Amount.Compare(amount); comparison > 0 {
+		if amount.IsNegative() {
+			return ZeroMoney(amount.Currency())
+		}
+		return amount
+	}
+	return *e.Amount
+}
+
+func (r DiscountRule) explain(amount Money, discount Money) string {
+	return fmt.Sprintf("rule %q (priority %d) matched %s: %s off %s = %s",
+		r.ID, r.Priority, r.Conditions.describe(), r.Effect.describe(), amount, discount)
+}
+
+func (c DiscountConditions) describe() string {
+	var parts []string
+	if len(c.ProductIDs) > 0 {
+		parts = append(parts, "product "+strings.Join(c.ProductIDs, "/"))
+	}
+	if len(c.CustomerTypes) > 0 {
+		parts = append(parts, "customer type "+strings.Join(c.CustomerTypes, "/"))
+	}
+	if c.MinAmount != nil {
+		parts = append(parts, "amount >= "+c.MinAmount.String())
+	}
+	if c.MaxAmount != nil {
+		parts = append(parts, "amount < "+c.MaxAmount.String())
+	}
+	if c.ValidFrom != nil {
+		parts = append(parts, "from "+c.ValidFrom.Format(time.RFC3339))
+	}
+	if c.ValidUntil != nil {
+		parts = append(parts, "until "+c.ValidUntil.Format(time.RFC3339))
+	}
+	if len(parts) == 0 {
+		return "unconditionally"
+	}
+	return "on " + strings.Join(parts, ", ")
+}
+
+func (e DiscountEffect) describe() string {
+	if e.Type == DiscountEffectPercentOff {
+		return e.Percent.String()
+	}
+	return e.Amount.String()
+}
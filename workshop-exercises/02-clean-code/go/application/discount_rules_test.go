@@ -0,0 +1,251 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// DISCOUNT RULES TESTS
+// Testing: discount_rules.go
+// =============================================================================
+
+const testDiscountRulesJSON = `{
+  "rules": [
+    {
+      "id": "spring-sale",
+      "priority": 100,
+      "conditions": {
+        "validFrom": "2024-03-01T00:00:00Z",
+        "validUntil": "2024-04-01T00:00:00Z"
+      },
+      "effect": { "type": "percent_off", "percent": "20" }
+    },
+    {
+      "id": "premium-large",
+      "priority": 50,
+      "conditions": {
+        "customerTypes": ["premium"],
+        "minAmount": { "amount": "500.00", "currency": "USD" }
+      },
+      "effect": { "type": "percent_off", "percent": 18 }
+    },
+    {
+      "id": "premium",
+      "priority": 10,
+      "conditions": { "customerTypes": ["premium"] },
+      "effect": { "type": "percent_off", "percent": "15" }
+    },
+    {
+      "id": "welcome",
+      "priority": 5,
+      "conditions": {
+        "customerTypes": ["new"],
+        "maxAmount": { "amount": "100.00", "currency": "USD" }
+      },
+      "effect": { "type": "fixed_off", "amount": { "amount": "10.00", "currency": "USD" } }
+    }
+  ]
+}`
+
+var outsideSale = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestRuleEngine(t *testing.T) *DiscountRuleEngine {
+	t.Helper()
+	rules, err := ParseDiscountRules([]byte(testDiscountRulesJSON))
+	if err != nil {
+		t.Fatalf("Expected rules to parse, got %v", err)
+	}
+	return NewDiscountRuleEngine(rules)
+}
+
+func TestDiscountRuleEngine_Evaluate_MatchesByConditions(t *testing.T) {
+	engine := newTestRuleEngine(t)
+
+	testCases := []struct {
+		name         string
+		amount       Money
+		customerType string
+		at           time.Time
+		expectedRule string
+		expected     Money
+	}{
+		{"premium below large-order bound", MustParseMoney("499.99", USD), "premium", outsideSale, "premium", MustParseMoney("424.99", USD)},
+		{"premium at large-order bound", MustParseMoney("500.00", USD), "premium", outsideSale, "premium-large", MustParseMoney("410.00", USD)},
+		{"sale outranks customer rules", MustParseMoney("500.00", USD), "premium", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "spring-sale", MustParseMoney("400.00", USD)},
+		{"sale window end is exclusive", MustParseMoney("100.00", USD), "premium", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), "premium", MustParseMoney("85.00", USD)},
+		{"fixed off below max amount", MustParseMoney("60.00", USD), "new", outsideSale, "welcome", MustParseMoney("50.00", USD)},
+		{"fixed off never goes negative", MustParseMoney("4.00", USD), "new", outsideSale, "welcome", MustParseMoney("0.00", USD)},
+		{"max amount is exclusive", MustParseMoney("100.00", USD), "new", outsideSale, "", MustParseMoney("100.00", USD)},
+		{"bounds in another currency never match", MustParseMoney("600.00", EUR), "premium", outsideSale, "premium", MustParseMoney("510.00", EUR)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			decision := engine.Evaluate(tc.amount, tc.customerType, tc.at)
+
+			// Assert
+			if decision.Rule.ID != tc.expectedRule || decision.Matched != (tc.expectedRule != "") {
+				t.Errorf("Expected rule %q, got %q (matched=%v)", tc.expectedRule, decision.Rule.ID, decision.Matched)
+			}
+			if decision.DiscountedAmount != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, decision.DiscountedAmount)
+			}
+		})
+	}
+}
+
+func TestDiscountRuleEngine_Evaluate_EqualPriority_FirstRuleWins(t *testing.T) {
+	// Arrange
+	engine := NewDiscountRuleEngine([]DiscountRule{
+		{ID: "first", Priority: 1, Effect: DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(5)}},
+		{ID: "second", Priority: 1, Effect: DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(50)}},
+	})
+
+	// Act
+	decision := engine.Evaluate(MustParseMoney("10.00", USD), "regular", outsideSale)
+
+	// Assert
+	if decision.Rule.ID != "first" {
+		t.Errorf("Expected the first rule to win a tie, got %q", decision.Rule.ID)
+	}
+}
+
+func TestDiscountRuleEngine_Evaluate_ExplainsMatchedRule(t *testing.T) {
+	// Arrange
+	engine := newTestRuleEngine(t)
+
+	// Act
+	decision := engine.Evaluate(MustParseMoney("600.00", USD), "premium", outsideSale)
+
+	// Assert
+	expected := `rule "premium-large" (priority 50) matched on customer type premium, amount >= $500.00: 18% off $600.00 = $108.00`
+	if decision.Explanation != expected {
+		t.Errorf("Expected %q, got %q", expected, decision.Explanation)
+	}
+}
+
+func TestDiscountRuleEngine_Evaluate_NoMatch_ExplainsAndKeepsAmount(t *testing.T) {
+	// Arrange
+	engine := newTestRuleEngine(t)
+
+	// Act
+	decision := engine.Evaluate(MustParseMoney("80.00", USD), "guest", outsideSale)
+
+	// Assert
+	if decision.Matched || decision.Discount != ZeroMoney(USD) {
+		t.Errorf("Expected no discount, got %+v", decision)
+	}
+	if decision.Explanation != "no discount rule matched" {
+		t.Errorf("Expected no-match explanation, got %q", decision.Explanation)
+	}
+}
+
+func TestParseDiscountRules_InvalidRules_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+	}{
+		{"missing id", `{"rules":[{"effect":{"type":"percent_off","percent":"5"}}]}`},
+		{"duplicate id", `{"rules":[{"id":"a","effect":{"type":"percent_off","percent":"5"}},{"id":"a","effect":{"type":"percent_off","percent":"5"}}]}`},
+		{"unknown effect", `{"rules":[{"id":"a","effect":{"type":"free_lunch"}}]}`},
+		{"percent above 100", `{"rules":[{"id":"a","effect":{"type":"percent_off","percent":"120"}}]}`},
+		{"fixed off without amount", `{"rules":[{"id":"a","effect":{"type":"fixed_off"}}]}`},
+		{"inverted amount range", `{"rules":[{"id":"a","conditions":{"minAmount":{"amount":"50.00","currency":"USD"},"maxAmount":{"amount":"10.00","currency":"USD"}},"effect":{"type":"percent_off","percent":"5"}}]}`},
+		{"inverted date window", `{"rules":[{"id":"a","conditions":{"validFrom":"2024-02-01T00:00:00Z","validUntil":"2024-01-01T00:00:00Z"},"effect":{"type":"percent_off","percent":"5"}}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := ParseDiscountRules([]byte(tc.rules))
+
+			// Assert
+			if !errors.Is(err, ErrInvalidDiscountRule) {
+				t.Errorf("Expected ErrInvalidDiscountRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseDiscountRules_UnknownField_ReturnsError(t *testing.T) {
+	// Act: A typo in a condition must not silently widen the rule
+	_, err := ParseDiscountRules([]byte(`{"rules":[{"id":"a","conditions":{"customerType":["premium"]},"effect":{"type":"percent_off","percent":"5"}}]}`))
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "customerType") {
+		t.Errorf("Expected unknown field error, got %v", err)
+	}
+}
+
+func TestLoadDiscountRules_ExampleFile_IsValid(t *testing.T) {
+	// Act
+	rules, err := LoadDiscountRules(filepath.Join("..", "discount_rules.example.json"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected example rules to load, got %v", err)
+	}
+	if len(rules) == 0 {
+		t.Error("Expected example rules")
+	}
+}
+
+func TestLoadDiscountRules_MissingFile_ReturnsError(t *testing.T) {
+	// Act
+	_, err := LoadDiscountRules(filepath.Join(t.TempDir(), "missing.json"))
+
+	// Assert
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
+
+func TestDiscountRule_MarshalJSON_RoundTrips(t *testing.T) {
+	// Arrange
+	original := DefaultDiscountRules()
+
+	// Act
+	data, err := json.Marshal(DiscountConfig{Rules: original})
+	decoded, decodeErr := ParseDiscountRules(data)
+
+	// Assert
+	if err != nil || decodeErr != nil {
+		t.Fatalf("Expected no errors, got %v / %v", err, decodeErr)
+	}
+	if decoded[0].Effect.Percent != NewPercentageFromFloat(15) {
+		t.Errorf("Expected 15%% to round-trip, got %s", decoded[0].Effect.Percent)
+	}
+}
+
+func TestDiscountRuleEngine_ProductRules_OnlyApplyToMatchingLines(t *testing.T) {
+	// Arrange
+	engine := NewDiscountRuleEngine([]DiscountRule{
+		{ID: "mug-deal", Priority: 1, Conditions: DiscountConditions{ProductIDs: []string{"SKU-MUG"}},
+			Effect: DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(50)}},
+	})
+	mug := LineItem{ProductID: "SKU-MUG", Quantity: 2, UnitPrice: MustParseMoney("8.00", USD)}
+	coffee := LineItem{ProductID: "SKU-COFFEE", Quantity: 1, UnitPrice: MustParseMoney("8.00", USD)}
+
+	// Act
+	mugDecision := engine.EvaluateLine(mug, "regular", outsideSale)
+	coffeeDecision := engine.EvaluateLine(coffee, "regular", outsideSale)
+	orderDecision := engine.Evaluate(MustParseMoney("24.00", USD), "regular", outsideSale)
+
+	// Assert
+	if !mugDecision.Matched || mugDecision.Discount != MustParseMoney("8.00", USD) {
+		t.Errorf("Expected half off the mug line, got %+v", mugDecision)
+	}
+	if coffeeDecision.Matched {
+		t.Errorf("Expected no discount on coffee, got %+v", coffeeDecision)
+	}
+	if orderDecision.Matched {
+		t.Error("Expected product rules not to apply to the whole order")
+	}
+}
@@ -1,64 +1,309 @@
 package application
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // =============================================================================
 // DISCOUNT SERVICE
+// Applies the discount rule engine and coupons; see discount_rules.go and
+// coupon.go for the file format
 // =============================================================================
 
-type DiscountService struct{}
+type DiscountService struct {
+	engine  *DiscountRuleEngine
+	coupons map[string]Coupon
+	usage   *couponUsage
+	clock   Clock
+}
 
 func NewDiscountService() DiscountServiceInterface {
-	return &DiscountService{}
+	service, _ := NewDiscountServiceWithRules(DefaultDiscountRules(), NewSystemClock())
+	return service
 }
 
-func (d *DiscountService) CalculateDiscount(amount float64, customerType string) (float64, error) {
-	return d.performDiscountCalculation(amount, customerType)
+func NewDiscountServiceWithRules(rules []DiscountRule, clock Clock) (DiscountServiceInterface, error) {
+	return NewDiscountServiceWithConfig(DiscountConfig{Rules: rules}, clock)
 }
 
-func (d *DiscountService) performDiscountCalculation(amount float64, customerType string) (float64, error) {
-	discountPercent := d.getDiscountPercentage(customerType)
-	return d.applyDiscountPercentage(amount, discountPercent), nil
+func NewDiscountServiceWithConfig(config DiscountConfig, clock Clock) (DiscountServiceInterface, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	coupons := make(map[string]Coupon, len(config.Coupons))
+	for _, coupon := range config.Coupons {
+		coupons[normalizeCouponCode(coupon.Code)] = coupon
+	}
+	return &DiscountService{
+		engine:  NewDiscountRuleEngine(config.Rules),
+		coupons: coupons,
+		usage:   newCouponUsage(),
+		clock:   clock,
+	}, nil
 }
 
-func (d *DiscountService) getDiscountPercentage(customerType string) float64 {
-	return d.determineDiscountRate(customerType)
+func NewDiscountServiceFromFile(path string, clock Clock) (DiscountServiceInterface, error) {
+	config, err := LoadDiscountConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDiscountServiceWithConfig(config, clock)
 }
 
-func (d *DiscountService) determineDiscountRate(customerType string) float64 {
-	switch customerType {
-	case "premium":
-		return d.getPremiumDiscount()
-	case "regular":
-		return d.getRegularDiscount()
-	default:
-		return d.getDefaultDiscount()
+func (d *DiscountService) CalculateDiscount(ctx context.Context, request DiscountRequest) (DiscountResult, error) {
+	if err := ctx.Err(); err != nil {
+		return DiscountResult{}, err
+	}
+	amount, err := resolveOrderAmount(request.Amount, request.LineItems)
+	if err != nil {
+		return DiscountResult{}, err
 	}
+	now := d.getCurrentTime()
+	coupons, err := d.resolveCoupons(request, amount, now)
+	if err != nil {
+		return DiscountResult{}, err
+	}
+
+	rulesApply := !d.hasExclusiveCoupon(coupons)
+	lines := d.priceLines(request, coupons, rulesApply, now)
+	remaining := d.subtractDiscounts(amount, d.lineDiscounts(lines))
+	orderDecision := d.evaluateOrderRules(remaining, request.CustomerType, rulesApply, now)
+	orderDiscounts := d.combineDiscounts(remaining, orderDecision, d.orderCoupons(coupons))
+	d.allocateOrderDiscounts(lines, orderDiscounts)
+	return d.buildDiscountResult(amount, lines, orderDiscounts), nil
 }
 
-func (d *DiscountService) getPremiumDiscount() float64 {
-	return 0.15 // 15%
+func (d *DiscountService) resolveCoupons(request DiscountRequest, amount Money, now time.Time) ([]Coupon, error) {
+	coupons := make([]Coupon, 0, len(request.CouponCodes))
+	seen := make(map[string]bool, len(request.CouponCodes))
+	for _, code := range request.CouponCodes {
+		normalized := normalizeCouponCode(code)
+		coupon, ok := d.coupons[normalized]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCouponNotFound, code)
+		}
+		if seen[normalized] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrCouponNotCombinable, coupon.Code)
+		}
+		seen[normalized] = true
+		if err := coupon.checkApplicable(amount, now); err != nil {
+			return nil, err
+		}
+		if err := coupon.checkMatchesLines(request.LineItems); err != nil {
+			return nil, err
+		}
+		if err := d.usage.check(coupon, request.Customer); err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, coupon)
+	}
+	return coupons, d.checkStacking(coupons)
 }
 
-func (d *DiscountService) getRegularDiscount() float64 {
-	return 0.05 // 5%
+func (d *DiscountService) checkStacking(coupons []Coupon) error {
+	if len(coupons) < 2 {
+		return nil
+	}
+	for _, coupon := range coupons {
+		if coupon.Stacking == CouponStackingExclusive {
+			return fmt.Errorf("%w: %s is exclusive", ErrCouponNotCombinable, coupon.Code)
+		}
+	}
+	return nil
 }
 
-func (d *DiscountService) getDefaultDiscount() float64 {
-	return 0.0 // 0%
+func (d *DiscountService) hasExclusiveCoupon(coupons []Coupon) bool {
+	return len(coupons) == 1 && coupons[0].Stacking == CouponStackingExclusive
 }
 
-func (d *DiscountService) applyDiscountPercentage(amount float64, percentage float64) float64 {
-	return d.calculateDiscountedAmount(amount, percentage)
+// The lines were validated by resolveOrderAmount, so every subtotal fits.
+func (d *DiscountService) priceLines(request DiscountRequest, coupons []Coupon, rulesApply bool, now time.Time) []LineBreakdown {
+	lines := make([]LineBreakdown, 0, len(request.LineItems))
+	for _, item := range request.LineItems {
+		var decision DiscountDecision
+		if rulesApply {
+			decision = d.engine.EvaluateLine(item, request.CustomerType, now)
+		}
+		subtotal, _ := item.Subtotal()
+		discounts := d.combineDiscounts(subtotal, decision, d.lineCoupons(coupons, item.ProductID))
+		lines = append(lines, d.buildLineBreakdown(item, discounts))
+	}
+	return lines
 }
 
-func (d *DiscountService) calculateDiscountedAmount(amount float64, percentage float64) float64 {
-	discount := d.computeDiscountValue(amount, percentage)
-	return d.subtractDiscount(amount, discount)
+func (d *DiscountService) buildLineBreakdown(item LineItem, discounts []AppliedDiscount) LineBreakdown {
+	subtotal, _ := item.Subtotal()
+	for i := range discounts {
+		discounts[i].ProductID = item.ProductID
+	}
+	total := d.subtractDiscounts(subtotal, discounts)
+	lineDiscount, _ := subtotal.Subtract(total)
+	return LineBreakdown{
+		ProductID:     item.ProductID,
+		Quantity:      item.Quantity,
+		UnitPrice:     item.UnitPrice,
+		Subtotal:      subtotal,
+		LineDiscounts: discounts,
+		LineDiscount:  lineDiscount,
+		OrderDiscount: ZeroMoney(subtotal.Currency()),
+		Total:         total,
+	}
 }
 
-func (d *DiscountService) computeDiscountValue(amount float64, percentage float64) float64 {
-	return amount * percentage
+func (d *DiscountService) lineCoupons(coupons []Coupon, productID string) []Coupon {
+	var matching []Coupon
+	for _, coupon := range coupons {
+		if containsString(coupon.ProductIDs, productID) {
+			matching = append(matching, coupon)
+		}
+	}
+	return matching
 }
 
-func (d *DiscountService) subtractDiscount(amount float64, discount float64) float64 {
-	return amount - discount
-}
\ No newline at end of file
+func (d *DiscountService) orderCoupons(coupons []Coupon) []Coupon {
+	var orderWide []Coupon
+	for _, coupon := range coupons {
+		if !coupon.targetsLines() {
+			orderWide = append(orderWide, coupon)
+		}
+	}
+	return orderWide
+}
+
+// Order-wide rules see the amount left after line discounts.
+func (d *DiscountService) evaluateOrderRules(amount Money, customerType string, rulesApply bool, now time.Time) DiscountDecision {
+	if !rulesApply {
+		return DiscountDecision{}
+	}
+	return d.engine.Evaluate(amount, customerType, now)
+}
+
+// combineDiscounts applies the stacking policy within one scope, the whole
+// order or a single line: an exclusive coupon stands alone; otherwise the
+// largest of the rule discount and any best_of coupons is taken first and
+// stackable coupons follow on what is left.
+func (d *DiscountService) combineDiscounts(amount Money, decision DiscountDecision, coupons []Coupon) []AppliedDiscount {
+	if d.hasExclusiveCoupon(coupons) {
+		return []AppliedDiscount{coupons[0].apply(amount)}
+	}
+
+	var discounts []AppliedDiscount
+	if base, ok := d.selectBestOf(amount, decision, coupons); ok {
+		discounts = append(discounts, base)
+	}
+	remaining := d.subtractDiscounts(amount, discounts)
+	for _, coupon := range coupons {
+		if coupon.Stacking != CouponStackingStackable {
+			continue
+		}
+		discount := coupon.apply(remaining)
+		discounts = append(discounts, discount)
+		remaining, _ = remaining.Subtract(discount.Amount)
+	}
+	return discounts
+}
+
+func (d *DiscountService) selectBestOf(amount Money, decision DiscountDecision, coupons []Coupon) (AppliedDiscount, bool) {
+	var best AppliedDiscount
+	found := false
+	if decision.Matched {
+		best, found = decision.appliedDiscount(), true
+	}
+	for _, coupon := range coupons {
+		if coupon.Stacking != CouponStackingBestOf {
+			continue
+		}
+		candidate := coupon.apply(amount)
+		if comparison, _ := candidate.Amount.Compare(best.Amount); !found || comparison > 0 {
+			best, found = candidate, true
+		}
+	}
+	return best, found
+}
+
+// allocateOrderDiscounts spreads order-wide discounts over the lines in
+// proportion to what each line still costs, so every line shows what is
+// actually paid for it.
+func (d *DiscountService) allocateOrderDiscounts(lines []LineBreakdown, discounts []AppliedDiscount) {
+	if len(lines) == 0 || len(discounts) == 0 {
+		return
+	}
+	weights := make([]int64, len(lines))
+	for i, line := range lines {
+		weights[i] = line.Total.MinorUnits()
+	}
+	total := d.subtractDiscounts(ZeroMoney(lines[0].Total.Currency()), discounts).Negate()
+	for i, share := range total.Allocate(weights) {
+		lines[i].OrderDiscount = share
+		lines[i].Total, _ = lines[i].Total.Subtract(share)
+	}
+}
+
+func (d *DiscountService) lineDiscounts(lines []LineBreakdown) []AppliedDiscount {
+	var discounts []AppliedDiscount
+	for _, line := range lines {
+		discounts = append(discounts, line.LineDiscounts...)
+	}
+	return discounts
+}
+
+func (d *DiscountService) subtractDiscounts(amount Money, discounts []AppliedDiscount) Money {
+	for _, discount := range discounts {
+		amount, _ = amount.Subtract(discount.Amount)
+	}
+	return amount
+}
+
+func (d *DiscountService) buildDiscountResult(amount Money, lines []LineBreakdown, orderDiscounts []AppliedDiscount) DiscountResult {
+	discounts := append(d.lineDiscounts(lines), orderDiscounts...)
+	discounted := d.subtractDiscounts(amount, discounts)
+	total, _ := amount.Subtract(discounted)
+	return DiscountResult{
+		OriginalAmount:   amount,
+		Lines:            lines,
+		Discounts:        discounts,
+		TotalDiscount:    total,
+		DiscountedAmount: discounted,
+	}
+}
+
+// RedeemDiscount counts the coupons in result against their limits. It fails
+// without counting anything if any coupon has run out in the meantime.
+func (d *DiscountService) RedeemDiscount(ctx context.Context, customer string, result DiscountResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return d.usage.redeem(d.couponsIn(result), customer)
+}
+
+// ReleaseDiscount gives back a redemption when the order it was made for did
+// not go through.
+func (d *DiscountService) ReleaseDiscount(ctx context.Context, customer string, result DiscountResult) error {
+	d.usage.release(d.couponsIn(result), customer)
+	return nil
+}
+
+func (d *DiscountService) couponsIn(result DiscountResult) []Coupon {
+	var coupons []Coupon
+	for _, code := range result.CouponCodes() {
+		if coupon, ok := d.coupons[normalizeCouponCode(code)]; ok {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons
+}
+
+// ExplainDiscount returns the rule engine's decision, including which rule
+// matched and why, without taking coupons into account.
+func (d *DiscountService) ExplainDiscount(ctx context.Context, amount Money, customerType string) (DiscountDecision, error) {
+	if err := ctx.Err(); err != nil {
+		return DiscountDecision{}, err
+	}
+	return d.engine.Evaluate(amount, customerType, d.getCurrentTime()), nil
+}
+
+func (d *DiscountService) getCurrentTime() time.Time {
+	return d.clock.Now()
+}
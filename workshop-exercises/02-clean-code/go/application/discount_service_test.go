@@ -1,7 +1,12 @@
 package application
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -12,118 +17,118 @@ import (
 func TestDiscountService_CalculateDiscount_PremiumCustomer_Returns15PercentDiscount(t *testing.T) {
 	// Arrange
 	discountService := NewDiscountService()
-	amount := 100.0
+	amount := MustParseMoney("100.00", USD)
 	customerType := "premium"
 
 	// Act: Call the method that goes through multiple abstraction layers
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify the discount calculation
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 	// Expected: 100 * (1 - 0.15) = 85.0
-	expected := 85.0
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("85.00", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
 func TestDiscountService_CalculateDiscount_RegularCustomer_Returns5PercentDiscount(t *testing.T) {
 	// Arrange: Test regular customer discount
 	discountService := NewDiscountService()
-	amount := 200.0
+	amount := MustParseMoney("200.00", USD)
 	customerType := "regular"
 
 	// Act
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify the discount calculation
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 	// Expected: 200 * (1 - 0.05) = 190.0
-	expected := 190.0
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("190.00", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
 func TestDiscountService_CalculateDiscount_UnknownCustomer_ReturnsNoDiscount(t *testing.T) {
 	// Arrange: Test unknown customer type
 	discountService := NewDiscountService()
-	amount := 150.0
+	amount := MustParseMoney("150.00", USD)
 	customerType := "unknown"
 
 	// Act: Call the method
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify no discount applied
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 	// Expected: 150 * (1 - 0.0) = 150.0
-	expected := 150.0
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("150.00", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
 func TestDiscountService_CalculateDiscount_EmptyCustomerType_ReturnsNoDiscount(t *testing.T) {
 	// Arrange: Test empty customer type
 	discountService := NewDiscountService()
-	amount := 75.0
+	amount := MustParseMoney("75.00", USD)
 	customerType := ""
 
 	// Act: Call the method
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify no discount applied
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	expected := 75.0
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("75.00", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
 func TestDiscountService_CalculateDiscount_ZeroAmount_ReturnsZero(t *testing.T) {
 	// Arrange: Test zero amount
 	discountService := NewDiscountService()
-	amount := 0.0
+	amount := MustParseMoney("0.00", USD)
 	customerType := "premium"
 
 	// Act: Call the method
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify zero result
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	expected := 0.0
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("0.00", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
 func TestDiscountService_CalculateDiscount_NegativeAmount_ReturnsNegativeResult(t *testing.T) {
 	// Arrange: Test negative amount (edge case)
 	discountService := NewDiscountService()
-	amount := -50.0
+	amount := MustParseMoney("-50.00", USD)
 	customerType := "premium"
 
 	// Act: Call the method
-	result, err := discountService.CalculateDiscount(amount, customerType)
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: amount, CustomerType: customerType})
 
 	// Assert: Verify calculation still works
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 	// Expected: -50 * (1 - 0.15) = -42.5
-	expected := -42.5
-	if result != expected {
-		t.Errorf("Expected %.2f, got %.2f", expected, result)
+	expected := MustParseMoney("-42.50", USD)
+	if result.DiscountedAmount != expected {
+		t.Errorf("Expected %s, got %s", expected, result.DiscountedAmount)
 	}
 }
 
@@ -133,29 +138,351 @@ func TestDiscountService_VariousInputs(t *testing.T) {
 
 	testCases := []struct {
 		name         string
-		amount       float64
+		amount       Money
 		customerType string
-		expected     float64
+		expected     Money
 	}{
-		{"Premium 100", 100.0, "premium", 85.0},
-		{"Regular 100", 100.0, "regular", 95.0},
-		{"Unknown 100", 100.0, "unknown", 100.0},
-		{"Premium 50", 50.0, "premium", 42.5},
-		{"Regular 200", 200.0, "regular", 190.0},
+		{"Premium 100", MustParseMoney("100.00", USD), "premium", MustParseMoney("85.00", USD)},
+		{"Regular 100", MustParseMoney("100.00", USD), "regular", MustParseMoney("95.00", USD)},
+		{"Unknown 100", MustParseMoney("100.00", USD), "unknown", MustParseMoney("100.00", USD)},
+		{"Premium 50", MustParseMoney("50.00", USD), "premium", MustParseMoney("42.50", USD)},
+		{"Regular 200", MustParseMoney("200.00", USD), "regular", MustParseMoney("190.00", USD)},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Act
-			result, err := discountService.CalculateDiscount(tc.amount, tc.customerType)
+			result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: tc.amount, CustomerType: tc.customerType})
 
 			// Assert: Verify all scenarios work correctly
 			if err != nil {
 				t.Errorf("Expected no error, got %v", err)
 			}
-			if result != tc.expected {
-				t.Errorf("Expected %.2f, got %.2f", tc.expected, result)
+			if result.DiscountedAmount != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, result.DiscountedAmount)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestDiscountService_CalculateDiscount_CancelledContext_ReturnsContextError(t *testing.T) {
+	// Arrange
+	discountService := NewDiscountService()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err := discountService.CalculateDiscount(ctx, DiscountRequest{Amount: MustParseMoney("100.00", USD), CustomerType: "premium"})
+
+	// Assert
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDiscountService_NewDiscountServiceFromFile_AppliesRulesAtClockTime(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "rules.json")
+	_ = os.WriteFile(path, []byte(testDiscountRulesJSON), 0o600)
+	clock := NewManualClock(time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC))
+	discountService, err := NewDiscountServiceFromFile(path, clock)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Act
+	inSale, _ := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: MustParseMoney("100.00", USD), CustomerType: "regular"})
+	clock.Advance(30 * 24 * time.Hour)
+	afterSale, _ := discountService.CalculateDiscount(context.Background(), DiscountRequest{Amount: MustParseMoney("100.00", USD), CustomerType: "regular"})
+
+	// Assert
+	if inSale.DiscountedAmount != MustParseMoney("80.00", USD) {
+		t.Errorf("Expected sale price 80.00, got %s", inSale.DiscountedAmount)
+	}
+	if afterSale.DiscountedAmount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected no discount after the sale, got %s", afterSale.DiscountedAmount)
+	}
+}
+
+func TestDiscountService_ExplainDiscount_ReportsMatchedRule(t *testing.T) {
+	// Arrange
+	discountService := NewDiscountService().(DiscountExplainerInterface)
+
+	// Act
+	decision, err := discountService.ExplainDiscount(context.Background(), MustParseMoney("100.00", USD), "premium")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if decision.Rule.ID != "premium" || decision.Discount != MustParseMoney("15.00", USD) {
+		t.Errorf("Expected premium rule with $15.00 off, got %+v", decision)
+	}
+}
+
+func TestDiscountService_NewDiscountServiceWithRules_InvalidRules_ReturnsError(t *testing.T) {
+	// Act
+	_, err := NewDiscountServiceWithRules([]DiscountRule{{ID: "broken"}}, NewSystemClock())
+
+	// Assert
+	if !errors.Is(err, ErrInvalidDiscountRule) {
+		t.Errorf("Expected ErrInvalidDiscountRule, got %v", err)
+	}
+}
+
+func newCouponDiscountService(t *testing.T, coupons ...Coupon) DiscountServiceInterface {
+	t.Helper()
+	service, err := NewDiscountServiceWithConfig(DiscountConfig{Rules: DefaultDiscountRules(), Coupons: coupons}, NewSystemClock())
+	if err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	return service
+}
+
+func fixedOffCoupon(code string, amount string, stacking CouponStacking) Coupon {
+	off := MustParseMoney(amount, USD)
+	return Coupon{Code: code, Effect: DiscountEffect{Type: DiscountEffectFixedOff, Amount: &off}, Stacking: stacking}
+}
+
+func TestDiscountService_CalculateDiscount_CouponStacking_BuildsBreakdown(t *testing.T) {
+	discountService := newCouponDiscountService(t,
+		fixedOffCoupon("PARTNER", "10.00", CouponStackingStackable),
+		newPercentCoupon("DIST2024", 20, CouponStackingBestOf),
+		newPercentCoupon("SMALLBEST", 2, CouponStackingBestOf),
+		newPercentCoupon("WHOLESALE", 8, CouponStackingExclusive),
+	)
+
+	testCases := []struct {
+		name          string
+		customerType  string
+		codes         []string
+		expectedCodes []string
+		expected      Money
+	}{
+		{"rule only", "premium", nil, []string{"premium"}, MustParseMoney("85.00", USD)},
+		{"stackable applies after rule", "premium", []string{"PARTNER"}, []string{"premium", "PARTNER"}, MustParseMoney("75.00", USD)},
+		{"best-of beats smaller rule", "premium", []string{"DIST2024"}, []string{"DIST2024"}, MustParseMoney("80.00", USD)},
+		{"rule beats smaller best-of", "premium", []string{"smallbest"}, []string{"premium"}, MustParseMoney("85.00", USD)},
+		{"best-of then stackable", "regular", []string{"PARTNER", "DIST2024"}, []string{"DIST2024", "PARTNER"}, MustParseMoney("70.00", USD)},
+		{"exclusive replaces rule", "premium", []string{"WHOLESALE"}, []string{"WHOLESALE"}, MustParseMoney("92.00", USD)},
+		{"best-of without rule", "guest", []string{"SMALLBEST"}, []string{"SMALLBEST"}, MustParseMoney("98.00", USD)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{
+				Amount:       MustParseMoney("100.00", USD),
+				Customer:     "alice@example.com",
+				CustomerType: tc.customerType,
+				CouponCodes:  tc.codes,
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.DiscountedAmount != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, result.DiscountedAmount)
+			}
+			if len(result.Discounts) != len(tc.expectedCodes) {
+				t.Fatalf("Expected discounts %v, got %+v", tc.expectedCodes, result.Discounts)
+			}
+			for i, code := range tc.expectedCodes {
+				if result.Discounts[i].Code != code {
+					t.Errorf("Expected discount %d to be %s, got %s", i, code, result.Discounts[i].Code)
+				}
+			}
+			total, _ := result.OriginalAmount.Subtract(result.DiscountedAmount)
+			if result.TotalDiscount != total {
+				t.Errorf("Expected total discount %s, got %s", total, result.TotalDiscount)
+			}
+		})
+	}
+}
+
+func TestDiscountService_CalculateDiscount_RejectedCoupons_ReturnTypedErrors(t *testing.T) {
+	// Arrange
+	expired := newPercentCoupon("OLD", 10, CouponStackingStackable)
+	past := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired.ExpiresAt = &past
+	minimum := MustParseMoney("1000.00", USD)
+	large := newPercentCoupon("LARGE", 10, CouponStackingStackable)
+	large.MinOrderAmount = &minimum
+	discountService := newCouponDiscountService(t, expired, large,
+		newPercentCoupon("SOLO", 10, CouponStackingExclusive),
+		newPercentCoupon("EXTRA", 5, CouponStackingStackable))
+
+	testCases := []struct {
+		name     string
+		codes    []string
+		expected error
+	}{
+		{"unknown code", []string{"NOPE"}, ErrCouponNotFound},
+		{"expired", []string{"OLD"}, ErrCouponExpired},
+		{"below minimum", []string{"LARGE"}, ErrCouponMinimumNotMet},
+		{"exclusive with another coupon", []string{"SOLO", "EXTRA"}, ErrCouponNotCombinable},
+		{"same code twice", []string{"EXTRA", "extra"}, ErrCouponNotCombinable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{
+				Amount:       MustParseMoney("100.00", USD),
+				Customer:     "alice@example.com",
+				CustomerType: "regular",
+				CouponCodes:  tc.codes,
+			})
+
+			// Assert
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestDiscountService_RedeemDiscount_PerCustomerLimit_RejectsSecondUse(t *testing.T) {
+	// Arrange
+	coupon := fixedOffCoupon("PARTNER", "25.00", CouponStackingStackable)
+	coupon.PerCustomerLimit = 1
+	discountService := newCouponDiscountService(t, coupon)
+	request := DiscountRequest{Amount: MustParseMoney("100.00", USD), Customer: "alice@example.com", CouponCodes: []string{"partner"}}
+	result, _ := discountService.CalculateDiscount(context.Background(), request)
+
+	// Act
+	redeemErr := discountService.RedeemDiscount(context.Background(), "alice@example.com", result)
+	_, secondQuote := discountService.CalculateDiscount(context.Background(), request)
+	request.Customer = "bob@example.com"
+	_, otherCustomer := discountService.CalculateDiscount(context.Background(), request)
+
+	// Assert
+	if redeemErr != nil {
+		t.Fatalf("Expected redemption to succeed, got %v", redeemErr)
+	}
+	if !errors.Is(secondQuote, ErrCouponCustomerLimitHit) {
+		t.Errorf("Expected ErrCouponCustomerLimitHit, got %v", secondQuote)
+	}
+	if otherCustomer != nil {
+		t.Errorf("Expected another customer to still use the coupon, got %v", otherCustomer)
+	}
+}
+
+func TestDiscountService_RedeemDiscount_MultiLineCoupon_CountsOncePerOrder(t *testing.T) {
+	// Arrange
+	coupon := fixedOffCoupon("TWO", "1.00", CouponStackingStackable)
+	coupon.ProductIDs = []string{"SKU-MUG", "SKU-TEA"}
+	coupon.UsageLimit = 3
+	discountService := newCouponDiscountService(t, coupon)
+	request := DiscountRequest{
+		LineItems: []LineItem{
+			{ProductID: "SKU-MUG", Quantity: 1, UnitPrice: MustParseMoney("12.00", USD)},
+			{ProductID: "SKU-TEA", Quantity: 1, UnitPrice: MustParseMoney("8.00", USD)},
+		},
+		Customer:    "alice@example.com",
+		CouponCodes: []string{"TWO"},
+	}
+
+	// Act
+	var errs []error
+	for order := 0; order < 4; order++ {
+		result, err := discountService.CalculateDiscount(context.Background(), request)
+		if err == nil {
+			err = discountService.RedeemDiscount(context.Background(), request.Customer, result)
+		}
+		errs = append(errs, err)
+	}
+
+	// Assert
+	for order, err := range errs[:3] {
+		if err != nil {
+			t.Errorf("Expected order %d within the limit to redeem, got %v", order+1, err)
+		}
+	}
+	if !errors.Is(errs[3], ErrCouponUsageExhausted) {
+		t.Errorf("Expected the fourth order to hit the limit, got %v", errs[3])
+	}
+}
+
+func TestLoadDiscountConfig_ExampleFile_ContainsFrontendCoupons(t *testing.T) {
+	// Act
+	config, err := LoadDiscountConfig(filepath.Join("..", "discount_rules.example.json"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected example config to load, got %v", err)
+	}
+	codes := make(map[string]bool)
+	for _, coupon := range config.Coupons {
+		codes[coupon.Code] = true
+	}
+	for _, code := range []string{"DIST2024", "WHOLESALE", "PARTNER"} {
+		if !codes[code] {
+			t.Errorf("Expected example coupon %s", code)
+		}
+	}
+}
+
+func TestDiscountService_CalculateDiscount_LineItems_BreaksDownPerLine(t *testing.T) {
+	// Arrange: 50% off mugs on the line, then the regular 5% on the rest of the order
+	rules := append(DefaultDiscountRules(), DiscountRule{
+		ID: "mug-deal", Priority: 1, Conditions: DiscountConditions{ProductIDs: []string{"SKU-MUG"}},
+		Effect: DiscountEffect{Type: DiscountEffectPercentOff, Percent: NewPercentageFromFloat(50)},
+	})
+	discountService, _ := NewDiscountServiceWithRules(rules, NewSystemClock())
+	request := DiscountRequest{
+		LineItems: []LineItem{
+			{ProductID: "SKU-MUG", Quantity: 2, UnitPrice: MustParseMoney("10.00", USD)},
+			{ProductID: "SKU-COFFEE", Quantity: 3, UnitPrice: MustParseMoney("10.00", USD)},
+		},
+		CustomerType: "regular",
+	}
+
+	// Act
+	result, err := discountService.CalculateDiscount(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.OriginalAmount != MustParseMoney("50.00", USD) || result.DiscountedAmount != MustParseMoney("38.00", USD) {
+		t.Errorf("Expected 50.00 -> 38.00, got %s -> %s", result.OriginalAmount, result.DiscountedAmount)
+	}
+	mug, coffee := result.Lines[0], result.Lines[1]
+	if mug.LineDiscount != MustParseMoney("10.00", USD) || mug.OrderDiscount != MustParseMoney("0.50", USD) || mug.Total != MustParseMoney("9.50", USD) {
+		t.Errorf("Unexpected mug line %+v", mug)
+	}
+	if coffee.LineDiscount != ZeroMoney(USD) || coffee.OrderDiscount != MustParseMoney("1.50", USD) || coffee.Total != MustParseMoney("28.50", USD) {
+		t.Errorf("Unexpected coffee line %+v", coffee)
+	}
+	if len(result.Discounts) != 2 || result.Discounts[0].ProductID != "SKU-MUG" || result.Discounts[1].ProductID != "" {
+		t.Errorf("Expected line discount then order discount, got %+v", result.Discounts)
+	}
+}
+
+func TestDiscountService_CalculateDiscount_ProductCoupon_TargetsItsLines(t *testing.T) {
+	// Arrange
+	coupon := fixedOffCoupon("MUGS5", "5.00", CouponStackingStackable)
+	coupon.ProductIDs = []string{"SKU-MUG"}
+	discountService := newCouponDiscountService(t, coupon)
+	lines := []LineItem{
+		{ProductID: "SKU-MUG", Quantity: 1, UnitPrice: MustParseMoney("12.00", USD)},
+		{ProductID: "SKU-TEA", Quantity: 1, UnitPrice: MustParseMoney("8.00", USD)},
+	}
+
+	// Act
+	result, err := discountService.CalculateDiscount(context.Background(), DiscountRequest{LineItems: lines, CouponCodes: []string{"MUGS5"}})
+	_, noMatch := discountService.CalculateDiscount(context.Background(), DiscountRequest{LineItems: lines[1:], CouponCodes: []string{"MUGS5"}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Lines[0].Total != MustParseMoney("7.00", USD) || result.Lines[1].Total != MustParseMoney("8.00", USD) {
+		t.Errorf("Expected only the mug line to be discounted, got %+v", result.Lines)
+	}
+	if !errors.Is(noMatch, ErrCouponNoMatchingLines) {
+		t.Errorf("Expected ErrCouponNoMatchingLines, got %v", noMatch)
+	}
+}
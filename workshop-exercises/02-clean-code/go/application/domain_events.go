@@ -0,0 +1,96 @@
+package application
+
+import "time"
+
+// =============================================================================
+// DOMAIN EVENTS
+// What happened while an order was processed, for anyone who wants to react
+// =============================================================================
+
+// DomainEvent is published on the EventBus. EventName identifies the event
+// type and is what subscribers are registered under.
+type DomainEvent interface {
+	EventName() string
+	EventOrderID() string
+	EventTime() time.Time
+}
+
+const (
+	EventOrderValidated   = "order.validated"
+	EventDiscountApplied  = "order.discount_applied"
+	EventPaymentSucceeded = "order.payment_succeeded"
+	EventPaymentFailed    = "order.payment_failed"
+	EventOrderCompleted   = "order.completed"
+)
+
+// OrderEvent holds what every order event carries.
+type OrderEvent struct {
+	OrderID    string
+	Customer   string
+	OccurredAt time.Time
+}
+
+func (e OrderEvent) EventOrderID() string {
+	return e.OrderID
+}
+
+func (e OrderEvent) EventTime() time.Time {
+	return e.OccurredAt
+}
+
+// OrderValidated is published once the order passed validation and has an ID.
+type OrderValidated struct {
+	OrderEvent
+	CustomerType string
+	Amount       Money
+}
+
+func (OrderValidated) EventName() string {
+	return EventOrderValidated
+}
+
+// DiscountApplied is published after the discount is calculated, also when
+// it came to nothing, so every processed order has one.
+type DiscountApplied struct {
+	OrderEvent
+	OriginalAmount   Money
+	TotalDiscount    Money
+	DiscountedAmount Money
+	Discounts        []AppliedDiscount
+}
+
+func (DiscountApplied) EventName() string {
+	return EventDiscountApplied
+}
+
+// PaymentSucceeded is published when the processor charged or, for deferred
+// capture, authorized the order.
+type PaymentSucceeded struct {
+	OrderEvent
+	Payment PaymentResult
+}
+
+func (PaymentSucceeded) EventName() string {
+	return EventPaymentSucceeded
+}
+
+type PaymentFailed struct {
+	OrderEvent
+	Amount Money
+	Reason string
+}
+
+func (PaymentFailed) EventName() string {
+	return EventPaymentFailed
+}
+
+// OrderCompleted is published once the paid or authorized order is stored.
+type OrderCompleted struct {
+	OrderEvent
+	Status      OrderStatus
+	FinalAmount Money
+}
+
+func (OrderCompleted) EventName() string {
+	return EventOrderCompleted
+}
@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// =============================================================================
+// EVENT BUS
+// In-process publish/subscribe for domain events
+// =============================================================================
+
+var (
+	ErrSubscriberFailed = errors.New("event subscriber failed")
+	ErrEventBusDrained  = errors.New("event bus drained")
+)
+
+type DeliveryMode int
+
+const (
+	// DeliverSync runs the subscriber inside Publish, before it returns.
+	DeliverSync DeliveryMode = iota
+	// DeliverAsync runs the subscriber on its own goroutine; Publish does not
+	// wait for it and the publisher's cancellation does not reach it.
+	DeliverAsync
+)
+
+type EventHandler func(ctx context.Context, event DomainEvent) error
+
+// Subscriber is one registered reaction to an event. Name only shows up in
+// failure reports.
+type Subscriber struct {
+	Name    string
+	Handler EventHandler
+	Mode    DeliveryMode
+}
+
+// SubscriberFailure is reported when a subscriber returned an error or
+// panicked.
+type SubscriberFailure struct {
+	Subscriber string
+	Event      DomainEvent
+	Err        error
+}
+
+func (f *SubscriberFailure) Error() string {
+	return fmt.Sprintf("%v: %s on %s for order %s: %v", ErrSubscriberFailed, f.Subscriber, f.Event.EventName(), f.Event.EventOrderID(), f.Err)
+}
+
+func (f *SubscriberFailure) Unwrap() []error {
+	return []error{ErrSubscriberFailed, f.Err}
+}
+
+// EventBusInterface delivers each published event to the subscribers of its
+// name. A failing subscriber never affects the publisher or the other
+// subscribers; its failure goes to the bus's failure handler instead.
+type EventBusInterface interface {
+	Publish(ctx context.Context, event DomainEvent)
+	Subscribe(eventName string, subscriber Subscriber) (unsubscribe func())
+	// Drain stops the bus taking events and waits for asynchronous
+	// deliveries still running. An event published from then on reaches no
+	// subscriber; each is reported as failed with ErrEventBusDrained.
+	Drain(ctx context.Context) error
+}
+
+// EventBusConfig.OnFailure may be called from several goroutines at once.
+// Without one, failures are dropped.
+type EventBusConfig struct {
+	OnFailure func(failure *SubscriberFailure)
+}
+
+// draining is guarded by mu, and inFlight only grows while holding it, so no
+// asynchronous delivery starts once Drain is waiting.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]*Subscriber
+	draining    bool
+	inFlight    sync.WaitGroup
+	onFailure   func(failure *SubscriberFailure)
+}
+
+func NewEventBus() EventBusInterface {
+	return NewEventBusWithConfig(EventBusConfig{})
+}
+
+func NewEventBusWithConfig(config EventBusConfig) EventBusInterface {
+	return &EventBus{
+		subscribers: make(map[string][]*Subscriber),
+		onFailure:   config.OnFailure,
+	}
+}
+
+// Subscribe registers a typed handler for events of type E, e.g.
+// Subscribe(bus, "welcome-email", DeliverAsync, func(ctx context.Context, e OrderCompleted) error {...}).
+func Subscribe[E DomainEvent](bus EventBusInterface, name string, mode DeliveryMode, handler func(ctx context.Context, event E) error) (unsubscribe func()) {
+	var zero E
+	return bus.Subscribe(zero.EventName(), Subscriber{
+		Name: name,
+		Mode: mode,
+		Handler: func(ctx context.Context, event DomainEvent) error {
+			typed, ok := event.(E)
+			if !ok {
+				return fmt.Errorf("expected %T, got %T", zero, event)
+			}
+			return handler(ctx, typed)
+		},
+	})
+}
+
+func (b *EventBus) Subscribe(eventName string, subscriber Subscriber) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry := &subscriber
+	b.subscribers[eventName] = append(b.subscribers[eventName], entry)
+	return func() { b.unsubscribe(eventName, entry) }
+}
+
+func (b *EventBus) unsubscribe(eventName string, entry *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := b.subscribers[eventName]
+	for i, candidate := range entries {
+		if candidate == entry {
+			b.subscribers[eventName] = append(entries[:i:i], entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish delivers to the subscribers registered when it is called, in
+// registration order. Asynchronous subscribers get no ordering guarantee
+// between events.
+func (b *EventBus) Publish(ctx context.Context, event DomainEvent) {
+	entries, draining := b.subscribersFor(event.EventName())
+	for _, entry := range entries {
+		if draining {
+			b.reportDrained(entry, event)
+			continue
+		}
+		if entry.Mode == DeliverAsync {
+			b.deliverAsync(ctx, entry, event)
+			continue
+		}
+		b.deliver(ctx, entry, event)
+	}
+}
+
+func (b *EventBus) subscribersFor(eventName string) ([]*Subscriber, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]*Subscriber(nil), b.subscribers[eventName]...), b.draining
+}
+
+func (b *EventBus) deliverAsync(ctx context.Context, entry *Subscriber, event DomainEvent) {
+	if !b.startDelivery() {
+		b.reportDrained(entry, event)
+		return
+	}
+	detached := context.WithoutCancel(ctx)
+	go func() {
+		defer b.inFlight.Done()
+		b.deliver(detached, entry, event)
+	}()
+}
+
+// startDelivery counts an asynchronous delivery in, unless Drain has begun.
+func (b *EventBus) startDelivery() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.draining {
+		return false
+	}
+	b.inFlight.Add(1)
+	return true
+}
+
+func (b *EventBus) deliver(ctx context.Context, entry *Subscriber, event DomainEvent) {
+	if err := b.invoke(ctx, entry, event); err != nil {
+		b.reportFailure(&SubscriberFailure{Subscriber: entry.Name, Event: event, Err: err})
+	}
+}
+
+func (b *EventBus) invoke(ctx context.Context, entry *Subscriber, event DomainEvent) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return entry.Handler(ctx, event)
+}
+
+func (b *EventBus) reportDrained(entry *Subscriber, event DomainEvent) {
+	b.reportFailure(&SubscriberFailure{Subscriber: entry.Name, Event: event, Err: ErrEventBusDrained})
+}
+
+func (b *EventBus) reportFailure(failure *SubscriberFailure) {
+	if b.onFailure != nil {
+		b.onFailure(failure)
+	}
+}
+
+func (b *EventBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	b.draining = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.inFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
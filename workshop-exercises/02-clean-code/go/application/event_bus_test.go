@@ -0,0 +1,260 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// EVENT BUS TESTS
+// Testing: event_bus.go
+// =============================================================================
+
+type failureRecorder struct {
+	mu       sync.Mutex
+	failures []*SubscriberFailure
+}
+
+func (r *failureRecorder) record(failure *SubscriberFailure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, failure)
+}
+
+func (r *failureRecorder) all() []*SubscriberFailure {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*SubscriberFailure(nil), r.failures...)
+}
+
+func newTestOrderCompleted(orderID string) OrderCompleted {
+	return OrderCompleted{
+		OrderEvent:  OrderEvent{OrderID: orderID, Customer: "test@example.com", OccurredAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		Status:      OrderStatusPaid,
+		FinalAmount: MustParseMoney("10.00", USD),
+	}
+}
+
+func TestEventBus_Publish_DeliversTypedEventToMatchingSubscribersOnly(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	var completed []OrderCompleted
+	var failed int
+	Subscribe(bus, "loyalty", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		completed = append(completed, event)
+		return nil
+	})
+	Subscribe(bus, "alerts", DeliverSync, func(ctx context.Context, event PaymentFailed) error {
+		failed++
+		return nil
+	})
+
+	// Act
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+
+	// Assert
+	if len(completed) != 1 || completed[0].OrderID != "order_1" || completed[0].FinalAmount != MustParseMoney("10.00", USD) {
+		t.Errorf("Expected the OrderCompleted event delivered once, got %+v", completed)
+	}
+	if failed != 0 {
+		t.Errorf("Expected the PaymentFailed subscriber not to run, ran %d times", failed)
+	}
+}
+
+func TestEventBus_Publish_FailingSubscribersDoNotStopOthers(t *testing.T) {
+	// Arrange
+	recorder := &failureRecorder{}
+	bus := NewEventBusWithConfig(EventBusConfig{OnFailure: recorder.record})
+	var order []string
+	Subscribe(bus, "email", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		order = append(order, "email")
+		return errors.New("smtp down")
+	})
+	Subscribe(bus, "analytics", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		order = append(order, "analytics")
+		panic("nil map")
+	})
+	Subscribe(bus, "loyalty", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		order = append(order, "loyalty")
+		return nil
+	})
+
+	// Act
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+
+	// Assert
+	if len(order) != 3 || order[0] != "email" || order[1] != "analytics" || order[2] != "loyalty" {
+		t.Errorf("Expected all subscribers in registration order, got %v", order)
+	}
+	failures := recorder.all()
+	if len(failures) != 2 {
+		t.Fatalf("Expected 2 failures reported, got %d", len(failures))
+	}
+	if failures[0].Subscriber != "email" || !errors.Is(failures[0], ErrSubscriberFailed) {
+		t.Errorf("Expected the email failure first, got %v", failures[0])
+	}
+	if failures[1].Subscriber != "analytics" || failures[1].Event.EventOrderID() != "order_1" {
+		t.Errorf("Expected the analytics panic reported for order_1, got %v", failures[1])
+	}
+}
+
+func TestEventBus_Publish_AsyncSubscriberDoesNotBlockPublisher(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	release := make(chan struct{})
+	delivered := make(chan OrderCompleted, 1)
+	Subscribe(bus, "slow", DeliverAsync, func(ctx context.Context, event OrderCompleted) error {
+		<-release
+		delivered <- event
+		return nil
+	})
+
+	// Act
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := bus.Drain(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected drain to finish, got %v", err)
+	}
+	if event := <-delivered; event.OrderID != "order_1" {
+		t.Errorf("Expected order_1 delivered, got %s", event.OrderID)
+	}
+}
+
+func TestEventBus_Publish_AsyncSubscriberOutlivesPublisherCancellation(t *testing.T) {
+	// Arrange
+	recorder := &failureRecorder{}
+	bus := NewEventBusWithConfig(EventBusConfig{OnFailure: recorder.record})
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	Subscribe(bus, "analytics", DeliverAsync, func(ctx context.Context, event OrderCompleted) error {
+		<-release
+		return ctx.Err()
+	})
+
+	// Act
+	bus.Publish(ctx, newTestOrderCompleted("order_1"))
+	cancel()
+	close(release)
+	bus.Drain(context.Background())
+
+	// Assert
+	if failures := recorder.all(); len(failures) != 0 {
+		t.Errorf("Expected the subscriber's context to stay live, got %v", failures)
+	}
+}
+
+func TestEventBus_Drain_ContextExpires_ReturnsContextError(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	release := make(chan struct{})
+	defer close(release)
+	Subscribe(bus, "stuck", DeliverAsync, func(ctx context.Context, event OrderCompleted) error {
+		<-release
+		return nil
+	})
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := bus.Drain(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestEventBus_Publish_AfterDrain_ReportsEventNotDelivered(t *testing.T) {
+	// Arrange
+	recorder := &failureRecorder{}
+	bus := NewEventBusWithConfig(EventBusConfig{OnFailure: recorder.record})
+	calls := 0
+	Subscribe(bus, "email", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		calls++
+		return nil
+	})
+	Subscribe(bus, "analytics", DeliverAsync, func(ctx context.Context, event OrderCompleted) error {
+		calls++
+		return nil
+	})
+	_ = bus.Drain(context.Background())
+
+	// Act
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+
+	// Assert
+	if calls != 0 {
+		t.Errorf("Expected no delivery after drain, got %d", calls)
+	}
+	failures := recorder.all()
+	if len(failures) != 2 || !errors.Is(failures[0], ErrEventBusDrained) || failures[1].Subscriber != "analytics" {
+		t.Errorf("Expected both subscribers reported as drained, got %v", failures)
+	}
+}
+
+func TestEventBus_Drain_ConcurrentPublishes_WaitsForStartedDeliveries(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	var mu sync.Mutex
+	finished := 0
+	Subscribe(bus, "analytics", DeliverAsync, func(ctx context.Context, event OrderCompleted) error {
+		mu.Lock()
+		defer mu.Unlock()
+		finished++
+		return nil
+	})
+	var publishers sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+		}()
+	}
+
+	// Act
+	err := bus.Drain(context.Background())
+	mu.Lock()
+	atDrain := finished
+	mu.Unlock()
+	publishers.Wait()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected drain to finish, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if finished != atDrain {
+		t.Errorf("Expected no delivery to start after drain returned, got %d then %d", atDrain, finished)
+	}
+}
+
+func TestEventBus_Unsubscribe_StopsDelivery(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	calls := 0
+	unsubscribe := Subscribe(bus, "email", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		calls++
+		return nil
+	})
+	bus.Publish(context.Background(), newTestOrderCompleted("order_1"))
+
+	// Act
+	unsubscribe()
+	bus.Publish(context.Background(), newTestOrderCompleted("order_2"))
+
+	// Assert
+	if calls != 1 {
+		t.Errorf("Expected 1 delivery before unsubscribing, got %d", calls)
+	}
+}
@@ -0,0 +1,184 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// FAILOVER PROCESSOR
+// Tries registered processors in order until one takes the payment. Only
+// retryable failures move on to the next processor; a decline ends the chain.
+// =============================================================================
+
+var ErrNoFailoverProcessors = errors.New("failover chain has no processors")
+
+type FailoverConfig struct {
+	Processors []string
+	AttemptLog FailoverAttemptLogInterface
+	Clock      Clock
+}
+
+// FailoverAttempt is one processor's go at a payment. Err is nil for the
+// attempt that succeeded.
+type FailoverAttempt struct {
+	OrderID   string
+	Operation PaymentOperation
+	Processor string
+	Amount    Money
+	Err       error
+	Timestamp time.Time
+}
+
+func (a FailoverAttempt) Succeeded() bool {
+	return a.Err == nil
+}
+
+// FailoverError is returned when the chain stopped without a payment. It
+// unwraps to the last processor's error, so a decline is still a decline.
+type FailoverError struct {
+	Attempts []FailoverAttempt
+}
+
+func (e *FailoverError) Error() string {
+	parts := make([]string, 0, len(e.Attempts))
+	for _, attempt := range e.Attempts {
+		parts = append(parts, fmt.Sprintf("%s: %v", attempt.Processor, attempt.Err))
+	}
+	return fmt.Sprintf("payment failed after %d attempt(s): %s", len(e.Attempts), strings.Join(parts, "; "))
+}
+
+func (e *FailoverError) Unwrap() error {
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+type FailoverProcessor struct {
+	*transactionRouter
+	registry   ProcessorRegistryInterface
+	processors []string
+	attempts   FailoverAttemptLogInterface
+	clock      Clock
+}
+
+func NewFailoverProcessor(registry ProcessorRegistryInterface, config FailoverConfig) (PaymentProcessorInterface, error) {
+	if len(config.Processors) == 0 {
+		return nil, ErrNoFailoverProcessors
+	}
+	for _, name := range config.Processors {
+		if _, err := registry.Lookup(name); err != nil {
+			return nil, fmt.Errorf("failover chain: %w", err)
+		}
+	}
+	failover := &FailoverProcessor{
+		transactionRouter: newTransactionRouter(registry),
+		registry:          registry,
+		processors:        append([]string(nil), config.Processors...),
+		attempts:          config.AttemptLog,
+		clock:             config.Clock,
+	}
+	if failover.attempts == nil {
+		failover.attempts = NewInMemoryFailoverAttemptLog()
+	}
+	if failover.clock == nil {
+		failover.clock = NewSystemClock()
+	}
+	return failover, nil
+}
+
+func (f *FailoverProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return f.runChain(ctx, request, PaymentOperationCharge, func(processor PaymentProcessorInterface) (PaymentResult, error) {
+		return processor.ProcessPayment(ctx, request)
+	})
+}
+
+func (f *FailoverProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return f.runChain(ctx, request, PaymentOperationAuthorization, func(processor PaymentProcessorInterface) (PaymentResult, error) {
+		return processor.Authorize(ctx, request)
+	})
+}
+
+// QuoteFee quotes from the first processor in the chain, which takes the
+// payment unless it is unavailable.
+func (f *FailoverProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	processor, err := f.registry.Lookup(f.processors[0])
+	if err != nil {
+		return FeeQuote{}, err
+	}
+	return QuoteFee(ctx, processor, request)
+}
+
+func (f *FailoverProcessor) runChain(ctx context.Context, request PaymentRequest, operation PaymentOperation, attempt func(PaymentProcessorInterface) (PaymentResult, error)) (PaymentResult, error) {
+	var attempts []FailoverAttempt
+	for _, name := range f.processors {
+		processor, err := f.registry.Lookup(name)
+		if err != nil {
+			return PaymentResult{}, err
+		}
+		result, err := attempt(processor)
+		attempts = append(attempts, f.recordAttempt(request, operation, name, err))
+		if err == nil {
+			f.remember(result.TransactionID, name)
+			result.Processor = name
+			return result, nil
+		}
+		if !f.shouldFailOver(ctx, err) {
+			break
+		}
+	}
+	return PaymentResult{}, &FailoverError{Attempts: attempts}
+}
+
+// shouldFailOver moves on only when the processor marked the failure
+// transient, i.e. it did not take the payment. An outcome it cannot tell
+// stops the chain: the next processor could charge the customer a second
+// time.
+func (f *FailoverProcessor) shouldFailOver(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && IsRetryablePaymentError(err)
+}
+
+func (f *FailoverProcessor) recordAttempt(request PaymentRequest, operation PaymentOperation, name string, err error) FailoverAttempt {
+	attempt := FailoverAttempt{
+		OrderID:   request.OrderID,
+		Operation: operation,
+		Processor: name,
+		Amount:    request.Amount,
+		Err:       err,
+		Timestamp: f.clock.Now().UTC(),
+	}
+	f.attempts.Record(attempt)
+	return attempt
+}
+
+// =============================================================================
+// FAILOVER ATTEMPT LOG
+// =============================================================================
+
+type FailoverAttemptLogInterface interface {
+	Record(attempt FailoverAttempt)
+	Attempts() []FailoverAttempt
+}
+
+type InMemoryFailoverAttemptLog struct {
+	mu       sync.Mutex
+	attempts []FailoverAttempt
+}
+
+func NewInMemoryFailoverAttemptLog() FailoverAttemptLogInterface {
+	return &InMemoryFailoverAttemptLog{}
+}
+
+func (l *InMemoryFailoverAttemptLog) Record(attempt FailoverAttempt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, attempt)
+}
+
+func (l *InMemoryFailoverAttemptLog) Attempts() []FailoverAttempt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]FailoverAttempt(nil), l.attempts...)
+}
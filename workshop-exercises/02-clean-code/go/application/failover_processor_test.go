@@ -0,0 +1,168 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// =============================================================================
+// FAILOVER PROCESSOR TESTS
+// Testing: failover_processor.go
+// =============================================================================
+
+type failoverFixture struct {
+	failover PaymentProcessorInterface
+	primary  *MockPaymentProcessor
+	backup   *MockPaymentProcessor
+	attempts FailoverAttemptLogInterface
+}
+
+func newFailoverFixture(t *testing.T, primary *MockPaymentProcessor) failoverFixture {
+	t.Helper()
+	fixture := failoverFixture{
+		primary:  primary,
+		backup:   NewMockPaymentProcessor(false),
+		attempts: NewInMemoryFailoverAttemptLog(),
+	}
+	registry := NewProcessorRegistry()
+	_ = registry.Register("primary", fixture.primary)
+	_ = registry.Register("backup", fixture.backup)
+	failover, err := NewFailoverProcessor(registry, FailoverConfig{
+		Processors: []string{"primary", "backup"},
+		AttemptLog: fixture.attempts,
+	})
+	if err != nil {
+		t.Fatalf("Expected valid failover chain, got %v", err)
+	}
+	fixture.failover = failover
+	return fixture
+}
+
+func TestFailoverProcessor_ProcessPayment_PrimaryUnavailable_UsesBackup(t *testing.T) {
+	// Arrange
+	fixture := newFailoverFixture(t, NewFailingMockPaymentProcessor(fmt.Errorf("timeout: %w", ErrProcessorUnavailable)))
+
+	// Act
+	result, err := fixture.failover.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("50.00", USD), OrderID: "order_000001"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected the backup to take the payment, got %v", err)
+	}
+	if result.TransactionID != "mock_txn" || fixture.backup.callCount() != 1 {
+		t.Errorf("Expected the backup result, got %+v", result)
+	}
+	if result.Processor != "backup" {
+		t.Errorf("Expected the result to name the backup, got %q", result.Processor)
+	}
+	attempts := fixture.attempts.Attempts()
+	if len(attempts) != 2 || attempts[0].Succeeded() || !attempts[1].Succeeded() {
+		t.Fatalf("Expected a failed then a successful attempt, got %+v", attempts)
+	}
+	if attempts[1].Processor != "backup" || attempts[1].OrderID != "order_000001" {
+		t.Errorf("Expected the backup to be reported as the processor that succeeded, got %+v", attempts[1])
+	}
+}
+
+func TestFailoverProcessor_ProcessPayment_Declined_DoesNotFailOver(t *testing.T) {
+	// Arrange
+	fixture := newFailoverFixture(t, NewFailingMockPaymentProcessor(fmt.Errorf("card: %w", ErrPaymentDeclined)))
+
+	// Act
+	_, err := fixture.failover.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("50.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Errorf("Expected ErrPaymentDeclined, got %v", err)
+	}
+	if fixture.backup.callCount() != 0 {
+		t.Error("Expected a decline not to be tried on the backup")
+	}
+	var failoverErr *FailoverError
+	if !errors.As(err, &failoverErr) || len(failoverErr.Attempts) != 1 {
+		t.Errorf("Expected one recorded attempt, got %v", err)
+	}
+}
+
+func TestFailoverProcessor_ProcessPayment_UnclassifiedError_DoesNotFailOver(t *testing.T) {
+	// Arrange
+	fixture := newFailoverFixture(t, NewMockPaymentProcessor(true))
+
+	// Act
+	_, err := fixture.failover.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("50.00", USD)))
+
+	// Assert
+	if err == nil || fixture.backup.callCount() != 0 {
+		t.Errorf("Expected the unclassified error to end the chain, got %v", err)
+	}
+}
+
+func TestFailoverProcessor_ProcessPayment_OutcomeUnknown_DoesNotFailOver(t *testing.T) {
+	// Arrange
+	fixture := newFailoverFixture(t, NewFailingMockPaymentProcessor(NewOutcomeUnknownError("primary", errors.New("read timeout"))))
+
+	// Act
+	_, err := fixture.failover.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("50.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrPaymentOutcomeUnknown) {
+		t.Errorf("Expected ErrPaymentOutcomeUnknown, got %v", err)
+	}
+	if fixture.backup.callCount() != 0 {
+		t.Error("Expected a payment the primary may have taken not to be tried on the backup")
+	}
+}
+
+func TestFailoverProcessor_ProcessPayment_AllUnavailable_ReportsEveryAttempt(t *testing.T) {
+	// Arrange
+	fixture := newFailoverFixture(t, NewFailingMockPaymentProcessor(ErrProcessorUnavailable))
+	fixture.backup.shouldFail = true
+	fixture.backup.failure = ErrProcessorUnavailable
+
+	// Act
+	_, err := fixture.failover.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("50.00", USD)))
+
+	// Assert
+	var failoverErr *FailoverError
+	if !errors.As(err, &failoverErr) || len(failoverErr.Attempts) != 2 {
+		t.Fatalf("Expected both attempts in the error, got %v", err)
+	}
+	if failoverErr.Attempts[1].Operation != PaymentOperationAuthorization {
+		t.Errorf("Expected authorization attempts, got %s", failoverErr.Attempts[1].Operation)
+	}
+	if !errors.Is(err, ErrProcessorUnavailable) {
+		t.Errorf("Expected ErrProcessorUnavailable, got %v", err)
+	}
+}
+
+func TestFailoverProcessor_Refund_GoesToProcessorThatSucceeded(t *testing.T) {
+	// Arrange
+	registry := NewProcessorRegistry()
+	_ = registry.Register("down", NewFailingMockPaymentProcessor(ErrProcessorUnavailable))
+	_ = registry.Register(string(ProcessorTypePayPal), NewPayPalProcessor())
+	failover, _ := NewFailoverProcessor(registry, FailoverConfig{Processors: []string{"down", "paypal"}})
+	payment, _ := failover.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	refund, err := failover.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.ProcessorType != ProcessorTypePayPal {
+		t.Errorf("Expected the refund to go to PayPal, got %s", refund.ProcessorType)
+	}
+}
+
+func TestNewFailoverProcessor_EmptyChain_ReturnsError(t *testing.T) {
+	// Act
+	_, err := NewFailoverProcessor(NewProcessorRegistry(), FailoverConfig{})
+
+	// Assert
+	if !errors.Is(err, ErrNoFailoverProcessors) {
+		t.Errorf("Expected ErrNoFailoverProcessors, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// =============================================================================
+// FEE SCHEDULE
+// What a processor charges per payment, loaded from a JSON file keyed by
+// processor name (see fee_schedules.example.json)
+// =============================================================================
+
+var (
+	ErrInvalidFeeSchedule  = errors.New("invalid fee schedule")
+	ErrFeeQuoteUnsupported = errors.New("processor does not quote fees")
+)
+
+// FeeSchedule charges Percent of the amount plus a fixed fee in the payment's
+// currency. Card brands (see CardBrand) can carry a surcharge, and so can
+// payments from outside HomeCountry. The minimum fee for the currency is
+// charged when the sum comes out lower. Fixed and minimum fees only apply to
+// positive amounts.
+type FeeSchedule struct {
+	Percent                Percentage            `json:"percent"`
+	FixedFees              []Money               `json:"fixedFees,omitempty"`
+	CardBrandSurcharges    map[string]Percentage `json:"cardBrandSurcharges,omitempty"`
+	HomeCountry            string                `json:"homeCountry,omitempty"`
+	InternationalSurcharge Percentage            `json:"internationalSurcharge,omitempty"`
+	MinimumFees            []Money               `json:"minimumFees,omitempty"`
+}
+
+func DefaultCreditCardFeeSchedule() FeeSchedule {
+	return FeeSchedule{Percent: NewPercentageFromFloat(2.9)}
+}
+
+func DefaultPayPalFeeSchedule() FeeSchedule {
+	return FeeSchedule{Percent: NewPercentageFromFloat(3.49)}
+}
+
+// FeeQuote is the fee for one payment, part by part. Fee is what the
+// processor keeps and GrossAmount what the customer is charged.
+type FeeQuote struct {
+	Amount                 Money
+	CardBrand              string
+	International          bool
+	PercentageFee          Money
+	CardBrandSurcharge     Money
+	InternationalSurcharge Money
+	FixedFee               Money
+	MinimumFeeTopUp        Money
+	Fee                    Money
+	GrossAmount            Money
+}
+
+// FeeQuoterInterface is implemented by processors that can say what a
+// payment would cost without charging it, e.g. for checkout to show fees.
+type FeeQuoterInterface interface {
+	QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error)
+}
+
+// QuoteFee asks processor for a quote if it can give one.
+func QuoteFee(ctx context.Context, processor PaymentProcessorInterface, request PaymentRequest) (FeeQuote, error) {
+	quoter, ok := processor.(FeeQuoterInterface)
+	if !ok {
+		return FeeQuote{}, ErrFeeQuoteUnsupported
+	}
+	return quoter.QuoteFee(ctx, request)
+}
+
+func LoadFeeSchedules(path string) (map[string]FeeSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fee schedules: %w", err)
+	}
+	schedules, err := ParseFeeSchedules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schedules, nil
+}
+
+func ParseFeeSchedules(data []byte) (map[string]FeeSchedule, error) {
+	var schedules map[string]FeeSchedule
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&schedules); err != nil {
+		return nil, fmt.Errorf("decode fee schedules: %w", err)
+	}
+	for name, schedule := range schedules {
+		if err := schedule.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		schedules[name] = schedule.normalized()
+	}
+	return schedules, nil
+}
+
+func (s FeeSchedule) Validate() error {
+	percentages := []Percentage{s.Percent, s.InternationalSurcharge}
+	for brand, surcharge := range s.CardBrandSurcharges {
+		if !isSurchargeableCardBrand(brand) {
+			return fmt.Errorf("%w: unknown card brand %q", ErrInvalidFeeSchedule, brand)
+		}
+		percentages = append(percentages, surcharge)
+	}
+	for _, percentage := range percentages {
+		if percentage < 0 || percentage > NewPercentageFromFloat(100) {
+			return fmt.Errorf("%w: percentage %s out of range", ErrInvalidFeeSchedule, percentage)
+		}
+	}
+	if s.HomeCountry != "" && !isCountryCode(s.HomeCountry) {
+		return fmt.Errorf("%w: home country %q is not a two-letter code", ErrInvalidFeeSchedule, s.HomeCountry)
+	}
+	for _, fees := range [][]Money{s.FixedFees, s.MinimumFees} {
+		if err := validateFeesPerCurrency(fees); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSurchargeableCardBrand(brand string) bool {
+	switch CardBrand(normalizeCardBrand(brand)) {
+	case CardBrandVisa, CardBrandMastercard, CardBrandAmex, CardBrandDiscover:
+		return true
+	default:
+		return false
+	}
+}
+
+func normalizeCardBrand(brand string) string {
+	return strings.ToLower(strings.TrimSpace(brand))
+}
+
+// configuredFees is the schedule a processor was configured with, checked
+// like one loaded from a file, or defaults without one.
+func configuredFees(fees *FeeSchedule, defaults FeeSchedule) (FeeSchedule, error) {
+	if fees == nil {
+		return defaults, nil
+	}
+	if err := fees.Validate(); err != nil {
+		return FeeSchedule{}, err
+	}
+	return fees.normalized(), nil
+}
+
+// normalized keys the card-brand surcharges the way Quote looks them up, so
+// "Visa" in a schedule matches a visa card.
+func (s FeeSchedule) normalized() FeeSchedule {
+	if len(s.CardBrandSurcharges) == 0 {
+		return s
+	}
+	surcharges := make(map[string]Percentage, len(s.CardBrandSurcharges))
+	for brand, surcharge := range s.CardBrandSurcharges {
+		surcharges[normalizeCardBrand(brand)] = surcharge
+	}
+	s.CardBrandSurcharges = surcharges
+	return s
+}
+
+func validateFeesPerCurrency(fees []Money) error {
+	seen := make(map[Currency]bool, len(fees))
+	for _, fee := range fees {
+		if !fee.Currency().IsKnown() || fee.IsNegative() {
+			return fmt.Errorf("%w: fee %s must be a non-negative amount in a known currency", ErrInvalidFeeSchedule, fee)
+		}
+		if seen[fee.Currency()] {
+			return fmt.Errorf("%w: more than one fee in %s", ErrInvalidFeeSchedule, fee.Currency())
+		}
+		seen[fee.Currency()] = true
+	}
+	return nil
+}
+
+func (s FeeSchedule) Quote(request PaymentRequest) FeeQuote {
+	return s.quote(request.Amount, normalizeCardBrand(request.cardBrand()), s.isInternational(request.Country))
+}
+
+// quoteLike prices a capture the way its authorization was priced.
+func (s FeeSchedule) quoteLike(amount Money, authorization FeeQuote) FeeQuote {
+	return s.quote(amount, authorization.CardBrand, authorization.International)
+}
+
+// Every percentage in a validated schedule is at most 100%, so none of the
+// shares of amount can overflow.
+func (s FeeSchedule) quote(amount Money, cardBrand string, international bool) FeeQuote {
+	currency := amount.Currency()
+	quote := FeeQuote{
+		Amount:                 amount,
+		CardBrand:              cardBrand,
+		International:          international,
+		InternationalSurcharge: ZeroMoney(currency),
+		FixedFee:               ZeroMoney(currency),
+		MinimumFeeTopUp:        ZeroMoney(currency),
+	}
+	quote.PercentageFee, _ = amount.ApplyPercentage(s.Percent)
+	quote.CardBrandSurcharge, _ = amount.ApplyPercentage(s.CardBrandSurcharges[cardBrand])
+	if international {
+		quote.InternationalSurcharge, _ = amount.ApplyPercentage(s.InternationalSurcharge)
+	}
+	if amount.IsPositive() {
+		quote.FixedFee = feeIn(s.FixedFees, currency)
+	}
+	quote.Fee = sumMoney(currency, quote.PercentageFee, quote.CardBrandSurcharge, quote.InternationalSurcharge, quote.FixedFee)
+	if minimum := feeIn(s.MinimumFees, currency); amount.IsPositive() {
+		if comparison, _ := quote.Fee.Compare(minimum); comparison < 0 {
+			quote.MinimumFeeTopUp, _ = minimum.Subtract(quote.Fee)
+			quote.Fee = minimum
+		}
+	}
+	quote.GrossAmount, _ = amount.Add(quote.Fee)
+	return quote
+}
+
+// A payment counts as international when both countries are known and differ.
+func (s FeeSchedule) isInternational(country string) bool {
+	return s.HomeCountry != "" && country != "" && !strings.EqualFold(country, s.HomeCountry)
+}
+
+func feeIn(fees []Money, currency Currency) Money {
+	for _, fee := range fees {
+		if fee.Currency() == currency {
+			return fee
+		}
+	}
+	return ZeroMoney(currency)
+}
+
+func sumMoney(currency Currency, amounts ...Money) Money {
+	total := ZeroMoney(currency)
+	for _, amount := range amounts {
+		total, _ = total.Add(amount)
+	}
+	return total
+}
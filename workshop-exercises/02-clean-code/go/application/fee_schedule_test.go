@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// =============================================================================
+// FEE SCHEDULE TESTS
+// Testing: fee_schedule.go
+// =============================================================================
+
+func newTestFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		Percent:                NewPercentageFromFloat(2.9),
+		FixedFees:              []Money{MustParseMoney("0.30", USD)},
+		CardBrandSurcharges:    map[string]Percentage{"amex": NewPercentageFromFloat(0.6)},
+		HomeCountry:            "US",
+		InternationalSurcharge: NewPercentageFromFloat(1.5),
+		MinimumFees:            []Money{MustParseMoney("0.50", USD)},
+	}
+}
+
+func TestFeeSchedule_Quote_BreaksDownEveryPart(t *testing.T) {
+	// Arrange
+	schedule := newTestFeeSchedule()
+	request := PaymentRequest{Amount: MustParseMoney("100.00", USD), CardBrand: "AMEX", Country: "DE"}
+
+	// Act
+	quote := schedule.Quote(request)
+
+	// Assert
+	parts := []struct {
+		name     string
+		actual   Money
+		expected string
+	}{
+		{"percentage", quote.PercentageFee, "2.90"},
+		{"card brand", quote.CardBrandSurcharge, "0.60"},
+		{"international", quote.InternationalSurcharge, "1.50"},
+		{"fixed", quote.FixedFee, "0.30"},
+	}
+	for _, part := range parts {
+		if part.actual.Decimal() != part.expected {
+			t.Errorf("Expected %s fee %s, got %s", part.name, part.expected, part.actual)
+		}
+	}
+	if quote.Fee != MustParseMoney("5.30", USD) || quote.GrossAmount != MustParseMoney("105.30", USD) {
+		t.Errorf("Expected 5.30 fee on 105.30, got %s on %s", quote.Fee, quote.GrossAmount)
+	}
+	if !quote.International || quote.CardBrand != "amex" {
+		t.Errorf("Expected an international amex quote, got %+v", quote)
+	}
+}
+
+func TestFeeSchedule_Quote_DomesticOrUnknownCountry_NoInternationalSurcharge(t *testing.T) {
+	for _, country := range []string{"US", "us", ""} {
+		t.Run("country_"+country, func(t *testing.T) {
+			// Act
+			quote := newTestFeeSchedule().Quote(PaymentRequest{Amount: MustParseMoney("100.00", USD), Country: country})
+
+			// Assert
+			if quote.International || !quote.InternationalSurcharge.IsZero() {
+				t.Errorf("Expected no international surcharge, got %+v", quote)
+			}
+		})
+	}
+}
+
+func TestFeeSchedule_Quote_SmallAmount_ChargesMinimumFee(t *testing.T) {
+	// Act
+	quote := newTestFeeSchedule().Quote(NewPaymentRequest(MustParseMoney("5.00", USD)))
+
+	// Assert: 0.15 + 0.30 = 0.45, topped up to 0.50
+	if quote.Fee != MustParseMoney("0.50", USD) || quote.MinimumFeeTopUp != MustParseMoney("0.05", USD) {
+		t.Errorf("Expected a 0.50 minimum fee with a 0.05 top-up, got %s and %s", quote.Fee, quote.MinimumFeeTopUp)
+	}
+}
+
+func TestFeeSchedule_Quote_OtherCurrency_SkipsFixedAndMinimumFees(t *testing.T) {
+	// Act
+	quote := newTestFeeSchedule().Quote(NewPaymentRequest(MustParseMoney("10.00", EUR)))
+
+	// Assert
+	if quote.Fee != MustParseMoney("0.29", EUR) {
+		t.Errorf("Expected only the percentage fee, got %s", quote.Fee)
+	}
+}
+
+func TestFeeSchedule_Quote_ZeroAmount_ChargesNothing(t *testing.T) {
+	// Act
+	quote := newTestFeeSchedule().Quote(NewPaymentRequest(ZeroMoney(USD)))
+
+	// Assert
+	if !quote.Fee.IsZero() {
+		t.Errorf("Expected no fee on a zero amount, got %s", quote.Fee)
+	}
+}
+
+func TestFeeSchedule_Validate_InvalidSchedules_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule FeeSchedule
+	}{
+		{"negative percent", FeeSchedule{Percent: NewPercentageFromFloat(-1)}},
+		{"surcharge above 100%", FeeSchedule{CardBrandSurcharges: map[string]Percentage{"amex": NewPercentageFromFloat(101)}}},
+		{"unknown card brand", FeeSchedule{CardBrandSurcharges: map[string]Percentage{"diners": NewPercentageFromFloat(1)}}},
+		{"bad home country", FeeSchedule{HomeCountry: "USA"}},
+		{"negative fixed fee", FeeSchedule{FixedFees: []Money{MustParseMoney("-0.30", USD)}}},
+		{"two minimums in one currency", FeeSchedule{MinimumFees: []Money{MustParseMoney("0.50", USD), MustParseMoney("1.00", USD)}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := tc.schedule.Validate()
+
+			// Assert
+			if !errors.Is(err, ErrInvalidFeeSchedule) {
+				t.Errorf("Expected ErrInvalidFeeSchedule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseFeeSchedules_MixedCaseCardBrand_AppliesSurcharge(t *testing.T) {
+	// Arrange
+	data := []byte(`{"credit_card": {"percent": "2.9", "cardBrandSurcharges": {"Amex": "0.6"}}}`)
+
+	// Act
+	schedules, err := ParseFeeSchedules(data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected the schedule to parse, got %v", err)
+	}
+	quote := schedules["credit_card"].Quote(PaymentRequest{Amount: MustParseMoney("100.00", USD), CardBrand: "amex"})
+	if quote.CardBrandSurcharge != MustParseMoney("0.60", USD) {
+		t.Errorf("Expected a 0.60 amex surcharge, got %s", quote.CardBrandSurcharge)
+	}
+}
+
+func TestLoadFeeSchedules_ExampleFile_IsValid(t *testing.T) {
+	// Act
+	schedules, err := LoadFeeSchedules(filepath.Join("..", "fee_schedules.example.json"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected example fee schedules to load, got %v", err)
+	}
+	if _, ok := schedules["credit_card"]; !ok {
+		t.Error("Expected a credit_card schedule")
+	}
+}
+
+func TestQuoteFee_ThroughRouter_QuotesRoutedProcessorWithoutRecording(t *testing.T) {
+	// Arrange
+	decisions := NewInMemoryRoutingDecisionLog()
+	router, _ := NewRoutingProcessor(NewDefaultProcessorRegistry(), RoutingConfig{DefaultProcessor: "paypal", DecisionLog: decisions})
+
+	// Act
+	quote, err := QuoteFee(context.Background(), router, NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quote.Fee != MustParseMoney("3.49", USD) {
+		t.Errorf("Expected the PayPal fee 3.49, got %s", quote.Fee)
+	}
+	if len(decisions.Decisions()) != 0 {
+		t.Error("Expected a quote not to be recorded as a routing decision")
+	}
+}
+
+func TestQuoteFee_ProcessorWithoutQuotes_ReturnsError(t *testing.T) {
+	// Act
+	_, err := QuoteFee(context.Background(), NewMockPaymentProcessor(false), NewPaymentRequest(MustParseMoney("1.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrFeeQuoteUnsupported) {
+		t.Errorf("Expected ErrFeeQuoteUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// =============================================================================
+// FILE AUDIT LOG
+// One JSON entry per line, fsynced before Append returns. The chain is
+// verified when the log is opened: a log that has been tampered with is not
+// written to until someone has looked at it.
+// =============================================================================
+
+const maxAuditLineSize = 1 << 20
+
+type FileAuditLog struct {
+	mu    sync.Mutex
+	path  string
+	size  int64
+	chain auditChain
+}
+
+func NewFileAuditLog(path string) (AuditLogInterface, error) {
+	log := &FileAuditLog{path: path}
+	if err := log.load(); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (l *FileAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	linked, err := l.chain.link(entry)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	if err := l.append(linked); err != nil {
+		return AuditEntry{}, err
+	}
+	l.chain.entries = append(l.chain.entries, linked)
+	return linked, nil
+}
+
+func (l *FileAuditLog) Query(query AuditQuery) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.chain.query(query), nil
+}
+
+// append writes one entry and fsyncs it. A failed write is truncated away so
+// the next entry never lands behind a partial line. Once synced the entry is
+// on disk and in the chain, so a failing close is not reported: the next
+// entry has to link to this one.
+func (l *FileAuditLog) append(entry AuditEntry) error {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+	line := append(payload, '\n')
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if err := l.writeAndSync(file, line); err != nil {
+		file.Close()
+		_ = os.Truncate(l.path, l.size)
+		return err
+	}
+	_ = file.Close()
+	l.size += int64(len(line))
+	return nil
+}
+
+func (l *FileAuditLog) writeAndSync(file *os.File, line []byte) error {
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("append audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync audit log: %w", err)
+	}
+	return nil
+}
+
+// load reads the existing entries. A last line without its newline is a
+// write cut short by a crash and is dropped; anything else that does not
+// verify fails the open.
+func (l *FileAuditLog) load() error {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(l.path, int64(end)); err != nil {
+			return fmt.Errorf("truncate torn audit log entry: %w", err)
+		}
+		data = data[:end]
+	}
+	for number, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("%w: %s line %d: %v", ErrAuditLogCorrupted, l.path, number+1, err)
+		}
+		l.chain.entries = append(l.chain.entries, entry)
+	}
+	if report := VerifyAuditEntries(l.chain.entries); !report.Valid() {
+		return fmt.Errorf("%w: %s %s", ErrAuditLogCorrupted, l.path, report.Violations[0])
+	}
+	l.size = int64(len(data))
+	return nil
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// FILE AUDIT LOG TESTS
+// Testing: file_audit_log.go
+// =============================================================================
+
+func TestFileAuditLog_Reopen_ContinuesChain(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "audit.log")
+	first, _ := NewFileAuditLog(path)
+	appendTestAuditEntries(t, first, "order_1", "order_2")
+
+	// Act
+	reopened, err := NewFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	appendTestAuditEntries(t, reopened, "order_3")
+
+	// Assert
+	entries, _ := reopened.Query(AuditQuery{})
+	if len(entries) != 3 || entries[2].Sequence != 3 || entries[2].PrevHash != entries[1].Hash {
+		t.Fatalf("Expected the chain continued after reopening, got %+v", entries)
+	}
+	file, _ := os.Open(path)
+	defer file.Close()
+	report, err := VerifyAuditLog(file)
+	if err != nil || !report.Valid() || report.Entries != 3 {
+		t.Errorf("Expected the file to verify, got %+v / %v", report, err)
+	}
+}
+
+func TestFileAuditLog_TornLastLine_IsDropped(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, _ := NewFileAuditLog(path)
+	appendTestAuditEntries(t, log, "order_1")
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	file.WriteString(`{"seq":2,"act`)
+	file.Close()
+
+	// Act
+	reopened, err := NewFileAuditLog(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entry := appendTestAuditEntries(t, reopened, "order_2")[0]
+	if entry.Sequence != 2 {
+		t.Errorf("Expected the torn entry replaced, got sequence %d", entry.Sequence)
+	}
+}
+
+func TestFileAuditLog_TamperedFile_RefusesToOpen(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, _ := NewFileAuditLog(path)
+	appendTestAuditEntries(t, log, "order_1", "order_2")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), `"alice"`, `"mallory"`, 1)), 0o600)
+
+	// Act
+	_, err := NewFileAuditLog(path)
+
+	// Assert
+	if !errors.Is(err, ErrAuditLogCorrupted) {
+		t.Errorf("Expected ErrAuditLogCorrupted, got %v", err)
+	}
+}
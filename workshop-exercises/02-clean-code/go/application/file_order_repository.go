@@ -0,0 +1,261 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// FILE ORDER REPOSITORY
+// Append-only log of order changes, replayed into memory on startup.
+// Each line is "<crc32> <json>\n" and is fsynced before the change is applied,
+// so a crash can at worst leave one torn line at the end, which replay drops.
+// =============================================================================
+
+var ErrOrderLogCorrupted = errors.New("order log corrupted")
+
+type orderLogOperation string
+
+const (
+	orderLogSave            orderLogOperation = "save"
+	orderLogOutboxDelivered orderLogOperation = "outbox_delivered"
+	orderLogOutboxFailed    orderLogOperation = "outbox_failed"
+)
+
+// A save entry carries the outbox messages written with the order, so one
+// line, and therefore one fsync, covers both.
+type orderLogEntry struct {
+	Operation orderLogOperation `json:"op"`
+	Order     *OrderResult      `json:"order,omitempty"`
+	Outbox    []OutboxMessage   `json:"outbox,omitempty"`
+	MessageID string            `json:"messageId,omitempty"`
+	Message   *OutboxMessage    `json:"message,omitempty"`
+}
+
+type FileOrderRepository struct {
+	mu     sync.Mutex
+	path   string
+	size   int64
+	state  *orderIndex
+	outbox *outboxIndex
+}
+
+func NewFileOrderRepository(path string) (OrderRepository, error) {
+	return NewFileOutboxRepository(path)
+}
+
+func NewFileOutboxRepository(path string) (OutboxRepository, error) {
+	repository := &FileOrderRepository{
+		path:   path,
+		state:  newOrderIndex(),
+		outbox: newOutboxIndex(),
+	}
+	if err := repository.replay(); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+func (r *FileOrderRepository) Save(order OrderResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.append(orderLogEntry{Operation: orderLogSave, Order: &order}); err != nil {
+		return err
+	}
+	r.state.put(order)
+	return nil
+}
+
+func (r *FileOrderRepository) Get(orderID string) (OrderResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state.get(orderID)
+}
+
+func (r *FileOrderRepository) ListByCustomer(customer string) ([]OrderResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state.listByCustomer(customer), nil
+}
+
+func (r *FileOrderRepository) SaveWithOutbox(order OrderResult, messages []OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.append(orderLogEntry{Operation: orderLogSave, Order: &order, Outbox: messages}); err != nil {
+		return err
+	}
+	r.state.put(order)
+	r.outbox.add(messages)
+	return nil
+}
+
+func (r *FileOrderRepository) PendingOutbox(due time.Time, limit int) ([]OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outbox.pending(due, limit), nil
+}
+
+func (r *FileOrderRepository) ParkedOutbox() ([]OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outbox.parked(), nil
+}
+
+func (r *FileOrderRepository) MarkOutboxDelivered(messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.outbox.check(messageID); err != nil {
+		return err
+	}
+	if err := r.append(orderLogEntry{Operation: orderLogOutboxDelivered, MessageID: messageID}); err != nil {
+		return err
+	}
+	r.outbox.remove(messageID)
+	return nil
+}
+
+func (r *FileOrderRepository) RecordOutboxFailure(message OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.outbox.check(message.ID); err != nil {
+		return err
+	}
+	if err := r.append(orderLogEntry{Operation: orderLogOutboxFailed, Message: &message}); err != nil {
+		return err
+	}
+	r.outbox.update(message)
+	return nil
+}
+
+// append writes one entry and fsyncs it. A failed write is truncated away so
+// the next entry never lands behind a partial line.
+func (r *FileOrderRepository) append(entry orderLogEntry) error {
+	line, err := encodeOrderLogLine(entry)
+	if err != nil {
+		return fmt.Errorf("encode order log entry: %w", err)
+	}
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open order log: %w", err)
+	}
+	if err := r.writeAndSync(file, line); err != nil {
+		file.Close()
+		_ = os.Truncate(r.path, r.size)
+		return err
+	}
+	// The entry is on disk once synced; a failing close cannot take it back,
+	// and the size must count it so a later truncate keeps it.
+	_ = file.Close()
+	r.size += int64(len(line))
+	return nil
+}
+
+func (r *FileOrderRepository) writeAndSync(file *os.File, line []byte) error {
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("append order log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync order log: %w", err)
+	}
+	return nil
+}
+
+func (r *FileOrderRepository) replay() error {
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read order log: %w", err)
+	}
+
+	offset := 0
+	for lineNumber := 1; offset < len(data); lineNumber++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			return r.dropTornTail(offset)
+		}
+		entry, err := decodeOrderLogLine(data[offset : offset+end])
+		if err != nil {
+			if offset+end+1 == len(data) {
+				return r.dropTornTail(offset)
+			}
+			return fmt.Errorf("%w: %s line %d: %v", ErrOrderLogCorrupted, r.path, lineNumber, err)
+		}
+		if err := r.applyEntry(entry); err != nil {
+			return fmt.Errorf("%w: %s line %d: %v", ErrOrderLogCorrupted, r.path, lineNumber, err)
+		}
+		offset += end + 1
+	}
+	r.size = int64(offset)
+	return nil
+}
+
+func (r *FileOrderRepository) dropTornTail(offset int) error {
+	if err := os.Truncate(r.path, int64(offset)); err != nil {
+		return fmt.Errorf("truncate torn order log entry: %w", err)
+	}
+	r.size = int64(offset)
+	return nil
+}
+
+func (r *FileOrderRepository) applyEntry(entry orderLogEntry) error {
+	switch entry.Operation {
+	case orderLogSave:
+		if entry.Order == nil {
+			return errors.New("save entry without order")
+		}
+		r.state.put(*entry.Order)
+		r.outbox.add(entry.Outbox)
+		return nil
+	case orderLogOutboxDelivered:
+		if err := r.outbox.check(entry.MessageID); err != nil {
+			return err
+		}
+		r.outbox.remove(entry.MessageID)
+		return nil
+	case orderLogOutboxFailed:
+		if entry.Message == nil {
+			return errors.New("outbox failure entry without message")
+		}
+		if err := r.outbox.check(entry.Message.ID); err != nil {
+			return err
+		}
+		r.outbox.update(*entry.Message)
+		return nil
+	default:
+		return fmt.Errorf("unknown operation %q", entry.Operation)
+	}
+}
+
+func encodeOrderLogLine(entry orderLogEntry) ([]byte, error) {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	line := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(payload), payload)
+	return []byte(line), nil
+}
+
+func decodeOrderLogLine(line []byte) (orderLogEntry, error) {
+	checksum, payload, found := bytes.Cut(line, []byte(" "))
+	if !found {
+		return orderLogEntry{}, errors.New("missing checksum")
+	}
+	expected, err := strconv.ParseUint(string(checksum), 16, 32)
+	if err != nil || uint32(expected) != crc32.ChecksumIEEE(payload) {
+		return orderLogEntry{}, errors.New("checksum mismatch")
+	}
+	var entry orderLogEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return orderLogEntry{}, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// FILE ORDER REPOSITORY TESTS
+// Testing: file_order_repository.go
+// =============================================================================
+
+func openTestOrderLog(t *testing.T, path string) OrderRepository {
+	t.Helper()
+	repository, err := NewFileOrderRepository(path)
+	if err != nil {
+		t.Fatalf("Expected no error opening %s, got %v", path, err)
+	}
+	return repository
+}
+
+func TestFileOrderRepository_Reopen_ReplaysLatestSaves(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository := openTestOrderLog(t, path)
+	order := newTestOrder("order_1", "alice@example.com")
+	_ = repository.Save(order)
+	_ = repository.Save(newTestOrder("order_2", "alice@example.com"))
+	order.Status = OrderStatusRefunded
+	_ = repository.Save(order)
+
+	// Act
+	reopened := openTestOrderLog(t, path)
+	order, err := reopened.Get("order_1")
+	orders, _ := reopened.ListByCustomer("alice@example.com")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.Status != OrderStatusRefunded || order.FinalAmount != MustParseMoney("95.00", USD) {
+		t.Errorf("Expected replayed refunded order, got %+v", order)
+	}
+	if len(orders) != 2 {
+		t.Errorf("Expected 2 orders for customer, got %d", len(orders))
+	}
+}
+
+func TestFileOrderRepository_TornLastLine_IsDroppedOnReplay(t *testing.T) {
+	// Arrange: Simulate a crash halfway through writing the second entry
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository := openTestOrderLog(t, path)
+	_ = repository.Save(newTestOrder("order_1", "alice@example.com"))
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	_, _ = file.WriteString(`1234abcd {"op":"save","order":{"OrderID":"order_2"`)
+	file.Close()
+
+	// Act
+	reopened := openTestOrderLog(t, path)
+	saveErr := reopened.Save(newTestOrder("order_3", "alice@example.com"))
+	again := openTestOrderLog(t, path)
+	orders, _ := again.ListByCustomer("alice@example.com")
+
+	// Assert
+	if saveErr != nil {
+		t.Fatalf("Expected appends to continue after recovery, got %v", saveErr)
+	}
+	if len(orders) != 2 || orders[0].OrderID != "order_1" || orders[1].OrderID != "order_3" {
+		t.Errorf("Expected [order_1 order_3], got %+v", orders)
+	}
+}
+
+func TestFileOrderRepository_CorruptedMiddleLine_ReturnsError(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository := openTestOrderLog(t, path)
+	_ = repository.Save(newTestOrder("order_1", "alice@example.com"))
+	_ = repository.Save(newTestOrder("order_2", "alice@example.com"))
+	data, _ := os.ReadFile(path)
+	data[12] ^= 0xFF
+	_ = os.WriteFile(path, data, 0o600)
+
+	// Act
+	_, err := NewFileOrderRepository(path)
+
+	// Assert
+	if !errors.Is(err, ErrOrderLogCorrupted) {
+		t.Errorf("Expected ErrOrderLogCorrupted, got %v", err)
+	}
+}
+
+func newTestOutboxMessage(t *testing.T, orderID string) OutboxMessage {
+	t.Helper()
+	message, err := NewOutboxMessage(newTestOrderCompleted(orderID))
+	if err != nil {
+		t.Fatalf("Expected no error encoding event, got %v", err)
+	}
+	return message
+}
+
+func openTestOutboxLog(t *testing.T, path string) OutboxRepository {
+	t.Helper()
+	repository, err := NewFileOutboxRepository(path)
+	if err != nil {
+		t.Fatalf("Expected no error opening %s, got %v", path, err)
+	}
+	return repository
+}
+
+func TestFileOrderRepository_Reopen_ReplaysOutboxState(t *testing.T) {
+	// Arrange: One message delivered, one failed once, one parked, one untouched
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository := openTestOutboxLog(t, path)
+	delivered := newTestOutboxMessage(t, "order_1")
+	failed := newTestOutboxMessage(t, "order_1")
+	parked := newTestOutboxMessage(t, "order_1")
+	untouched := newTestOutboxMessage(t, "order_2")
+	_ = repository.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{delivered, failed, parked})
+	_ = repository.SaveWithOutbox(newTestOrder("order_2", "alice@example.com"), []OutboxMessage{untouched})
+	_ = repository.MarkOutboxDelivered(delivered.ID)
+	failed.Attempts = 1
+	failed.LastError = "broker down"
+	_ = repository.RecordOutboxFailure(failed)
+	parked.Attempts = 10
+	parked.Parked = true
+	_ = repository.RecordOutboxFailure(parked)
+
+	// Act
+	reopened := openTestOutboxLog(t, path)
+	pending, err := reopened.PendingOutbox(time.Now(), 0)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != failed.ID || pending[1].ID != untouched.ID {
+		t.Fatalf("Expected the failed and untouched messages in order, got %+v", pending)
+	}
+	if pending[0].Attempts != 1 || pending[0].LastError != "broker down" {
+		t.Errorf("Expected the failed attempt replayed, got %+v", pending[0])
+	}
+	if stillParked, _ := reopened.ParkedOutbox(); len(stillParked) != 1 || stillParked[0].ID != parked.ID {
+		t.Errorf("Expected the parked message replayed as parked, got %+v", stillParked)
+	}
+	if _, err := reopened.Get("order_2"); err != nil {
+		t.Errorf("Expected the order saved with its messages, got %v", err)
+	}
+}
+
+func TestFileOrderRepository_TornOutboxSave_LosesOrderAndMessagesTogether(t *testing.T) {
+	// Arrange: Crash halfway through the line holding an order and its events
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository := openTestOutboxLog(t, path)
+	_ = repository.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{newTestOutboxMessage(t, "order_1")})
+	before, _ := os.ReadFile(path)
+	_ = repository.SaveWithOutbox(newTestOrder("order_2", "alice@example.com"), []OutboxMessage{newTestOutboxMessage(t, "order_2")})
+	after, _ := os.ReadFile(path)
+	_ = os.WriteFile(path, after[:len(before)+(len(after)-len(before))/2], 0o600)
+
+	// Act
+	reopened := openTestOutboxLog(t, path)
+	_, orderErr := reopened.Get("order_2")
+	pending, _ := reopened.PendingOutbox(time.Now(), 0)
+
+	// Assert
+	if !errors.Is(orderErr, ErrOrderNotFound) {
+		t.Errorf("Expected order_2 to be gone, got %v", orderErr)
+	}
+	if len(pending) != 1 || pending[0].OrderID != "order_1" {
+		t.Errorf("Expected only order_1's message left, got %+v", pending)
+	}
+}
@@ -0,0 +1,89 @@
+package application
+
+import "context"
+
+// =============================================================================
+// FLOAT COMPATIBILITY
+// Adapters for callers that still use the float64 signatures
+// =============================================================================
+
+type FloatPaymentProcessorInterface interface {
+	ProcessPayment(amount float64) (string, error)
+}
+
+type FloatDiscountServiceInterface interface {
+	CalculateDiscount(amount float64, customerType string) (float64, error)
+}
+
+func NewOrderDataFromFloat(amount float64, currency Currency, customer string, customerType string) (OrderData, error) {
+	money, err := MoneyFromFloat(amount, currency)
+	if err != nil {
+		return OrderData{}, err
+	}
+	return OrderData{
+		Amount:       money,
+		Customer:     customer,
+		CustomerType: customerType,
+	}, nil
+}
+
+type floatPaymentProcessor struct {
+	processor PaymentProcessorInterface
+	formatter ResultFormatterInterface
+	currency  Currency
+}
+
+func NewFloatPaymentProcessor(processor PaymentProcessorInterface, currency Currency) FloatPaymentProcessorInterface {
+	return &floatPaymentProcessor{
+		processor: processor,
+		formatter: NewTextResultFormatter(),
+		currency:  currency,
+	}
+}
+
+func (f *floatPaymentProcessor) ProcessPayment(amount float64) (string, error) {
+	money, err := f.toMoney(amount)
+	if err != nil {
+		return "", err
+	}
+	result, err := f.processor.ProcessPayment(context.Background(), NewPaymentRequest(money))
+	if err != nil {
+		return "", err
+	}
+	return f.formatter.FormatPaymentResult(result), nil
+}
+
+func (f *floatPaymentProcessor) toMoney(amount float64) (Money, error) {
+	return MoneyFromFloat(amount, f.currency)
+}
+
+type floatDiscountService struct {
+	discountService DiscountServiceInterface
+	currency        Currency
+}
+
+func NewFloatDiscountService(discountService DiscountServiceInterface, currency Currency) FloatDiscountServiceInterface {
+	return &floatDiscountService{
+		discountService: discountService,
+		currency:        currency,
+	}
+}
+
+func (f *floatDiscountService) CalculateDiscount(amount float64, customerType string) (float64, error) {
+	money, err := f.toMoney(amount)
+	if err != nil {
+		return 0, err
+	}
+	result, err := f.discountService.CalculateDiscount(context.Background(), DiscountRequest{
+		Amount:       money,
+		CustomerType: customerType,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DiscountedAmount.Float64(), nil
+}
+
+func (f *floatDiscountService) toMoney(amount float64) (Money, error) {
+	return MoneyFromFloat(amount, f.currency)
+}
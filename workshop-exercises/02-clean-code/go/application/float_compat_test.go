@@ -0,0 +1,95 @@
+package application
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// FLOAT COMPATIBILITY TESTS
+// Testing: float_compat.go
+// =============================================================================
+
+func TestFloatPaymentProcessor_ProcessPayment_FloatAmount_ChargesExactMoney(t *testing.T) {
+	// Arrange: Old float callers wrap the Money-based processor
+	processor := NewFloatPaymentProcessor(NewCreditCardProcessor(), USD)
+
+	// Act
+	result, err := processor.ProcessPayment(75.0)
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(result, "$77.18") {
+		t.Errorf("Expected result to contain '$77.18', got '%s'", result)
+	}
+}
+
+func TestFloatDiscountService_CalculateDiscount_FloatAmount_ReturnsFloat(t *testing.T) {
+	// Arrange
+	discountService := NewFloatDiscountService(NewDiscountService(), USD)
+
+	// Act
+	result, err := discountService.CalculateDiscount(100.0, "premium")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result != 85.0 {
+		t.Errorf("Expected 85.00, got %.2f", result)
+	}
+}
+
+func TestNewOrderDataFromFloat_DriftingAmount_RoundsToMinorUnits(t *testing.T) {
+	// Act
+	order, err := NewOrderDataFromFloat(103.41449999, USD, "test@example.com", "regular")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.Amount != MustParseMoney("103.41", USD) {
+		t.Errorf("Expected 103.41, got %s", order.Amount)
+	}
+}
+
+func TestFloatPaymentProcessor_ProcessPayment_NaN_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewFloatPaymentProcessor(NewCreditCardProcessor(), USD)
+
+	// Act
+	_, err := processor.ProcessPayment(math.NaN())
+
+	// Assert
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestNewOrderDataFromFloat_InvalidAmount_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount float64
+	}{
+		{"NaN", math.NaN()},
+		{"infinity", math.Inf(1)},
+		{"negative infinity", math.Inf(-1)},
+		{"beyond int64 minor units", 1e20},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := NewOrderDataFromFloat(tc.amount, USD, "test@example.com", "regular")
+
+			// Assert
+			if !errors.Is(err, ErrInvalidAmount) {
+				t.Errorf("Expected ErrInvalidAmount, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// IDEMPOTENCY
+// Replays the stored result when a client resubmits an order with the same key
+// =============================================================================
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different order")
+
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	Result      OrderResult
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (r IdempotencyRecord) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+type IdempotencyStoreInterface interface {
+	Load(key string) (IdempotencyRecord, bool, error)
+	Save(record IdempotencyRecord) error
+	PurgeExpired(now time.Time) (int, error)
+}
+
+func fingerprintOrder(order OrderData) (string, error) {
+	order.IdempotencyKey = ""
+	payload, err := json.Marshal(order)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(payload)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// keyedMutex serialises work per key, e.g. so concurrent retries with the
+// same idempotency key cannot both reach the payment processor.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+type keyedMutexEntry struct {
+	mu      sync.Mutex
+	holders int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{
+		locks: make(map[string]*keyedMutexEntry),
+	}
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	entry, ok := k.locks[key]
+	if !ok {
+		entry = &keyedMutexEntry{}
+		k.locks[key] = entry
+	}
+	entry.holders++
+	k.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+		k.mu.Lock()
+		entry.holders--
+		if entry.holders == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// =============================================================================
+// PAYMENT IDEMPOTENCY KEYS
+// One key per logical payment call, shared by every attempt at it, so a
+// processor that already acted on an attempt can recognise the next one
+// =============================================================================
+
+type paymentIdempotencyKey struct{}
+
+// WithPaymentIdempotencyKey sets the key processors send with the payment
+// calls made with ctx.
+func WithPaymentIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, paymentIdempotencyKey{}, key)
+}
+
+func PaymentIdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(paymentIdempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// ensurePaymentIdempotencyKey keeps the key already on ctx, or adds a new
+// one before the first attempt of a call that may be repeated.
+func ensurePaymentIdempotencyKey(ctx context.Context) context.Context {
+	if _, ok := PaymentIdempotencyKey(ctx); ok {
+		return ctx
+	}
+	return WithPaymentIdempotencyKey(ctx, "pay_"+randomHex(16))
+}
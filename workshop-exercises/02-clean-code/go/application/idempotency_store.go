@@ -0,0 +1,165 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// IN-MEMORY IDEMPOTENCY STORE
+// =============================================================================
+
+type InMemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+func NewInMemoryIdempotencyStore() IdempotencyStoreInterface {
+	return &InMemoryIdempotencyStore{
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+func (s *InMemoryIdempotencyStore) Load(key string) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	return record, ok, nil
+}
+
+func (s *InMemoryIdempotencyStore) Save(record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Key] = record
+	return nil
+}
+
+func (s *InMemoryIdempotencyStore) PurgeExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return purgeExpiredRecords(s.records, now), nil
+}
+
+// =============================================================================
+// FILE IDEMPOTENCY STORE
+// JSON snapshot rewritten atomically (temp file + rename) on every change
+// =============================================================================
+
+type FileIdempotencyStore struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]IdempotencyRecord
+}
+
+func NewFileIdempotencyStore(path string) (IdempotencyStoreInterface, error) {
+	store := &FileIdempotencyStore{
+		path:    path,
+		records: make(map[string]IdempotencyRecord),
+	}
+	if err := store.loadFromDisk(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileIdempotencyStore) Load(key string) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	return record, ok, nil
+}
+
+func (s *FileIdempotencyStore) Save(record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.records[record.Key]
+	s.records[record.Key] = record
+	if err := s.writeToDisk(); err != nil {
+		s.restoreRecord(record.Key, previous, existed)
+		return err
+	}
+	return nil
+}
+
+func (s *FileIdempotencyStore) PurgeExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := purgeExpiredRecords(s.records, now)
+	if purged == 0 {
+		return 0, nil
+	}
+	return purged, s.writeToDisk()
+}
+
+func (s *FileIdempotencyStore) restoreRecord(key string, previous IdempotencyRecord, existed bool) {
+	if existed {
+		s.records[key] = previous
+		return
+	}
+	delete(s.records, key)
+}
+
+func (s *FileIdempotencyStore) loadFromDisk() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read idempotency store: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &s.records); err != nil {
+		return fmt.Errorf("decode idempotency store %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *FileIdempotencyStore) writeToDisk() error {
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode idempotency store: %w", err)
+	}
+	return writeFileAtomically(s.path, data)
+}
+
+func purgeExpiredRecords(records map[string]IdempotencyRecord, now time.Time) int {
+	purged := 0
+	for key, record := range records {
+		if record.IsExpired(now) {
+			delete(records, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+func writeFileAtomically(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("replace %s: %w", path, err)
+	}
+	return nil
+}
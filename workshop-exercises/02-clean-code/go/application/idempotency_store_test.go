@@ -0,0 +1,126 @@
+package application
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// IDEMPOTENCY STORE TESTS
+// Testing: idempotency_store.go
+// =============================================================================
+
+func newTestIdempotencyRecord(key string, expiresAt time.Time) IdempotencyRecord {
+	return IdempotencyRecord{
+		Key:         key,
+		Fingerprint: "fingerprint-" + key,
+		Result: OrderResult{
+			OrderID:     "order_" + key,
+			FinalAmount: MustParseMoney("95.00", USD),
+			Status:      OrderStatusPaid,
+		},
+		CreatedAt: expiresAt.Add(-time.Hour),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestInMemoryIdempotencyStore_SaveAndLoad_ReturnsRecord(t *testing.T) {
+	// Arrange
+	store := NewInMemoryIdempotencyStore()
+	record := newTestIdempotencyRecord("key-1", time.Now().Add(time.Hour))
+
+	// Act
+	saveErr := store.Save(record)
+	loaded, found, err := store.Load("key-1")
+
+	// Assert
+	if saveErr != nil || err != nil {
+		t.Fatalf("Expected no errors, got %v / %v", saveErr, err)
+	}
+	if !found || loaded.Result.OrderID != "order_key-1" {
+		t.Errorf("Expected stored record, got %+v (found=%v)", loaded, found)
+	}
+}
+
+func TestInMemoryIdempotencyStore_PurgeExpired_RemovesOnlyExpiredKeys(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewInMemoryIdempotencyStore()
+	_ = store.Save(newTestIdempotencyRecord("old", now.Add(-time.Minute)))
+	_ = store.Save(newTestIdempotencyRecord("fresh", now.Add(time.Minute)))
+
+	// Act
+	purged, err := store.PurgeExpired(now)
+
+	// Assert
+	if err != nil || purged != 1 {
+		t.Errorf("Expected 1 purged record, got %d (%v)", purged, err)
+	}
+	if _, found, _ := store.Load("old"); found {
+		t.Error("Expected expired key to be removed")
+	}
+	if _, found, _ := store.Load("fresh"); !found {
+		t.Error("Expected fresh key to be kept")
+	}
+}
+
+func TestFileIdempotencyStore_Reopen_RestoresSavedRecords(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, err := NewFileIdempotencyStore(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_ = store.Save(newTestIdempotencyRecord("key-2", time.Now().Add(time.Hour)))
+
+	// Act
+	reopened, err := NewFileIdempotencyStore(path)
+	loaded, found, _ := reopened.Load("key-2")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error reopening, got %v", err)
+	}
+	if !found {
+		t.Fatal("Expected record to survive reopening the store")
+	}
+	if loaded.Result.FinalAmount != MustParseMoney("95.00", USD) {
+		t.Errorf("Expected final amount 95.00, got %s", loaded.Result.FinalAmount)
+	}
+}
+
+func TestFileIdempotencyStore_PurgeExpired_PersistsRemoval(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	store, _ := NewFileIdempotencyStore(path)
+	_ = store.Save(newTestIdempotencyRecord("old", now.Add(-time.Minute)))
+
+	// Act
+	purged, err := store.PurgeExpired(now)
+	reopened, _ := NewFileIdempotencyStore(path)
+
+	// Assert
+	if err != nil || purged != 1 {
+		t.Errorf("Expected 1 purged record, got %d (%v)", purged, err)
+	}
+	if _, found, _ := reopened.Load("old"); found {
+		t.Error("Expected purge to be written to disk")
+	}
+}
+
+func TestFileIdempotencyStore_CorruptFile_ReturnsError(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "idempotency.json")
+	_ = os.WriteFile(path, []byte("{not json"), 0o600)
+
+	// Act
+	_, err := NewFileIdempotencyStore(path)
+
+	// Assert
+	if err == nil {
+		t.Error("Expected error for a corrupt store file")
+	}
+}
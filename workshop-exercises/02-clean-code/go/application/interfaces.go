@@ -1,19 +1,65 @@
 package application
 
+import "context"
+
 type OrderServiceInterface interface {
-	ProcessOrder(order OrderData) (string, error)
+	ProcessOrder(ctx context.Context, order OrderData) (OrderResult, error)
+	RefundOrder(ctx context.Context, orderID string, amount Money) (OrderResult, error)
+	CaptureOrder(ctx context.Context, orderID string, amount Money) (OrderResult, error)
+	CancelOrder(ctx context.Context, orderID string) (OrderResult, error)
+	FulfillOrder(ctx context.Context, orderID string) (OrderResult, error)
+	GetOrder(orderID string) (OrderResult, error)
+	ListCustomerOrders(customer string) ([]OrderResult, error)
+	PaymentEventHandlerInterface
+}
+
+// PaymentEventHandlerInterface records what a provider reports happened to
+// a payment, such as a capture, refund or dispute, on the order it belongs to.
+type PaymentEventHandlerInterface interface {
+	ApplyPaymentEvent(ctx context.Context, event PaymentEvent) (OrderResult, error)
 }
 
 type PaymentProcessorInterface interface {
-	ProcessPayment(amount float64) (string, error)
+	ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error)
+	Refund(ctx context.Context, request RefundRequest) (RefundResult, error)
+	Void(ctx context.Context, transactionID string) (RefundResult, error)
+	Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error)
+	Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error)
+	ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error)
 }
 
+// DiscountServiceInterface prices an order and tracks coupon redemptions.
+// RedeemDiscount is called once the order is going ahead; ReleaseDiscount
+// undoes it if the payment then fails.
 type DiscountServiceInterface interface {
-	CalculateDiscount(amount float64, customerType string) (float64, error)
+	CalculateDiscount(ctx context.Context, request DiscountRequest) (DiscountResult, error)
+	RedeemDiscount(ctx context.Context, customer string, result DiscountResult) error
+	ReleaseDiscount(ctx context.Context, customer string, result DiscountResult) error
+}
+
+type TaxServiceInterface interface {
+	CalculateTax(ctx context.Context, request TaxRequest) (TaxResult, error)
+}
+
+type DiscountExplainerInterface interface {
+	ExplainDiscount(ctx context.Context, amount Money, customerType string) (DiscountDecision, error)
 }
 
+// OrderData describes an order either by a single Amount or by LineItems.
+// With line items the total is their sum; an Amount given as well must match.
 type OrderData struct {
-	Amount       float64
+	Amount       Money
+	LineItems    []LineItem
 	Customer     string
 	CustomerType string
-}
\ No newline at end of file
+	CouponCodes  []string
+	// PreferredProcessor names a registered processor the customer asked
+	// for; only a RoutingProcessor takes it into account.
+	PreferredProcessor string
+	Card               *Card
+	Country            string
+	Region             string
+	VATID              string
+	PricesIncludeTax   bool
+	IdempotencyKey     string
+}
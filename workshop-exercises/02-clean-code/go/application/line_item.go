@@ -0,0 +1,104 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+)
+
+// =============================================================================
+// LINE ITEMS
+// Products on an order; when present the order total is the sum of the lines
+// =============================================================================
+
+var (
+	ErrInvalidLineItem     = errors.New("invalid line item")
+	ErrDuplicateLineItem   = errors.New("duplicate line item")
+	ErrOrderTotalMismatch  = errors.New("order total does not match line items")
+	ErrInvalidLineQuantity = errors.New("line item quantity must be positive")
+)
+
+// TaxCategory selects the rate in the tax table; empty means standard.
+type LineItem struct {
+	ProductID   string
+	Quantity    int64
+	UnitPrice   Money
+	TaxCategory string
+}
+
+func (l LineItem) Subtotal() (Money, error) {
+	return l.UnitPrice.Multiply(l.Quantity)
+}
+
+func (l LineItem) validate(currency Currency) error {
+	if l.ProductID == "" {
+		return fmt.Errorf("%w: product ID cannot be empty", ErrInvalidLineItem)
+	}
+	if l.Quantity <= 0 {
+		return fmt.Errorf("%w: %s has quantity %d", ErrInvalidLineQuantity, l.ProductID, l.Quantity)
+	}
+	if l.UnitPrice.IsNegative() {
+		return fmt.Errorf("%w: %s has negative price %s", ErrInvalidLineItem, l.ProductID, l.UnitPrice)
+	}
+	if l.UnitPrice.Currency() != currency {
+		return fmt.Errorf("%w: %s is priced in %q, order is in %q", ErrCurrencyMismatch, l.ProductID, l.UnitPrice.Currency(), currency)
+	}
+	if _, err := l.Subtotal(); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidLineItem, l.ProductID, err)
+	}
+	return nil
+}
+
+// LineItemsTotal validates the lines and returns their sum. A product may
+// appear only once; quantities are combined on a single line instead.
+func LineItemsTotal(lines []LineItem) (Money, error) {
+	if len(lines) == 0 {
+		return Money{}, fmt.Errorf("%w: no line items", ErrInvalidLineItem)
+	}
+	currency := lines[0].UnitPrice.Currency()
+	total := ZeroMoney(currency)
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if err := line.validate(currency); err != nil {
+			return Money{}, err
+		}
+		if seen[line.ProductID] {
+			return Money{}, fmt.Errorf("%w: %s", ErrDuplicateLineItem, line.ProductID)
+		}
+		seen[line.ProductID] = true
+		subtotal, _ := line.Subtotal()
+		var err error
+		if total, err = total.Add(subtotal); err != nil {
+			return Money{}, fmt.Errorf("%w: lines add up to more than can be charged: %w", ErrInvalidLineItem, err)
+		}
+	}
+	return total, nil
+}
+
+// resolveOrderAmount returns the amount to charge before discounts: the sum
+// of the lines when there are any, checked against Amount if that is set too.
+func resolveOrderAmount(amount Money, lines []LineItem) (Money, error) {
+	if len(lines) == 0 {
+		return amount, nil
+	}
+	total, err := LineItemsTotal(lines)
+	if err != nil {
+		return Money{}, err
+	}
+	if amount != (Money{}) && amount != total {
+		return Money{}, fmt.Errorf("%w: amount %s, lines add up to %s", ErrOrderTotalMismatch, amount, total)
+	}
+	return total, nil
+}
+
+// LineBreakdown is the priced view of one line: its subtotal, the discounts
+// aimed at it, its share of order-wide discounts and what is left to pay.
+type LineBreakdown struct {
+	ProductID     string
+	Quantity      int64
+	UnitPrice     Money
+	Subtotal      Money
+	LineDiscounts []AppliedDiscount
+	LineDiscount  Money
+	OrderDiscount Money
+	Total         Money
+}
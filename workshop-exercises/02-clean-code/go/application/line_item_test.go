@@ -0,0 +1,84 @@
+package application
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// LINE ITEM TESTS
+// Testing: line_item.go
+// =============================================================================
+
+func TestLineItemsTotal_ValidLines_SumsSubtotals(t *testing.T) {
+	// Arrange
+	lines := []LineItem{
+		{ProductID: "SKU-1", Quantity: 3, UnitPrice: MustParseMoney("19.99", USD)},
+		{ProductID: "SKU-2", Quantity: 1, UnitPrice: MustParseMoney("0.03", USD)},
+	}
+
+	// Act
+	total, err := LineItemsTotal(lines)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != MustParseMoney("60.00", USD) {
+		t.Errorf("Expected 60.00, got %s", total)
+	}
+}
+
+func TestLineItemsTotal_InvalidLines_ReturnTypedErrors(t *testing.T) {
+	price := MustParseMoney("10.00", USD)
+
+	testCases := []struct {
+		name     string
+		lines    []LineItem
+		expected error
+	}{
+		{"no lines", nil, ErrInvalidLineItem},
+		{"zero quantity", []LineItem{{ProductID: "SKU-1", Quantity: 0, UnitPrice: price}}, ErrInvalidLineQuantity},
+		{"negative quantity", []LineItem{{ProductID: "SKU-1", Quantity: -2, UnitPrice: price}}, ErrInvalidLineQuantity},
+		{"missing product", []LineItem{{Quantity: 1, UnitPrice: price}}, ErrInvalidLineItem},
+		{"negative price", []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: price.Negate()}}, ErrInvalidLineItem},
+		{"duplicate product", []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: price}, {ProductID: "SKU-1", Quantity: 2, UnitPrice: price}}, ErrDuplicateLineItem},
+		{"mixed currencies", []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: price}, {ProductID: "SKU-2", Quantity: 1, UnitPrice: MustParseMoney("1.00", EUR)}}, ErrCurrencyMismatch},
+		{"subtotal overflows", []LineItem{{ProductID: "SKU-1", Quantity: math.MaxInt64 / 100, UnitPrice: price}}, ErrAmountOverflow},
+		{"total overflows", []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: NewMoney(math.MaxInt64/2+1, USD)}, {ProductID: "SKU-2", Quantity: 1, UnitPrice: NewMoney(math.MaxInt64/2+1, USD)}}, ErrAmountOverflow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := LineItemsTotal(tc.lines)
+
+			// Assert
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestResolveOrderAmount_AmountDisagreesWithLines_ReturnsMismatch(t *testing.T) {
+	// Arrange
+	lines := []LineItem{{ProductID: "SKU-1", Quantity: 2, UnitPrice: MustParseMoney("10.00", USD)}}
+
+	// Act
+	_, mismatch := resolveOrderAmount(MustParseMoney("25.00", USD), lines)
+	matching, err := resolveOrderAmount(MustParseMoney("20.00", USD), lines)
+	derived, _ := resolveOrderAmount(Money{}, lines)
+
+	// Assert
+	if !errors.Is(mismatch, ErrOrderTotalMismatch) {
+		t.Errorf("Expected ErrOrderTotalMismatch, got %v", mismatch)
+	}
+	if err != nil || matching != MustParseMoney("20.00", USD) {
+		t.Errorf("Expected matching amount to be accepted, got %s (%v)", matching, err)
+	}
+	if derived != MustParseMoney("20.00", USD) {
+		t.Errorf("Expected amount derived from lines, got %s", derived)
+	}
+}
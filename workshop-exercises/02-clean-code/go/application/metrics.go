@@ -0,0 +1,339 @@
+package application
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// METRICS
+// Counters, gauges and histograms with labels, written out in the Prometheus
+// text exposition format
+// =============================================================================
+
+// DefaultLatencyBuckets are upper bounds in seconds, from 5ms to 10s.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricKind string
+
+const (
+	metricCounter   metricKind = "counter"
+	metricGauge     metricKind = "gauge"
+	metricHistogram metricKind = "histogram"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// MetricsRegistryInterface hands out metrics by name. Asking again for a
+// name returns the metric already registered under it. Like MustParseMoney,
+// a name or label that is not valid Prometheus, or a name registered again
+// as a different kind or with other labels, panics: it is a programming
+// error, not something to handle at run time.
+type MetricsRegistryInterface interface {
+	Counter(name string, help string, labelNames ...string) *CounterVec
+	Gauge(name string, help string, labelNames ...string) *GaugeVec
+	// Histogram uses DefaultLatencyBuckets when buckets is nil.
+	Histogram(name string, help string, buckets []float64, labelNames ...string) *HistogramVec
+	// WriteText writes every metric, sorted by name and labels.
+	WriteText(w io.Writer) error
+}
+
+type MetricsRegistry struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+func NewMetricsRegistry() MetricsRegistryInterface {
+	return &MetricsRegistry{families: make(map[string]*metricFamily)}
+}
+
+func (r *MetricsRegistry) Counter(name string, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{family: r.register(metricCounter, name, help, nil, labelNames)}
+}
+
+func (r *MetricsRegistry) Gauge(name string, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{family: r.register(metricGauge, name, help, nil, labelNames)}
+}
+
+func (r *MetricsRegistry) Histogram(name string, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+	return &HistogramVec{family: r.register(metricHistogram, name, help, buckets, labelNames)}
+}
+
+func (r *MetricsRegistry) register(kind metricKind, name string, help string, buckets []float64, labelNames []string) *metricFamily {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.families[name]; ok {
+		if existing.kind != kind || strings.Join(existing.labelNames, ",") != strings.Join(labelNames, ",") {
+			panic(fmt.Sprintf("metrics: %s already registered as a %s with labels %v", name, existing.kind, existing.labelNames))
+		}
+		return existing
+	}
+	family := newMetricFamily(kind, name, help, buckets, labelNames)
+	r.families[name] = family
+	return family
+}
+
+func (r *MetricsRegistry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]*metricFamily, 0, len(r.families))
+	for _, family := range r.families {
+		families = append(families, family)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	buffered := bufio.NewWriter(w)
+	for _, family := range families {
+		family.writeText(buffered)
+	}
+	return buffered.Flush()
+}
+
+// =============================================================================
+// METRIC TYPES
+// =============================================================================
+
+// CounterVec is a counter per combination of label values.
+type CounterVec struct {
+	family *metricFamily
+}
+
+// With returns the counter for the label values, given in the order the
+// label names were registered.
+func (v *CounterVec) With(labelValues ...string) Counter {
+	return Counter{family: v.family, series: v.family.seriesFor(labelValues)}
+}
+
+// Counter only goes up; it restarts at zero with the process.
+type Counter struct {
+	family *metricFamily
+	series *metricSeries
+}
+
+func (c Counter) Inc() {
+	c.Add(1)
+}
+
+// Add panics on a negative value: a counter that went down would read as a
+// restart.
+func (c Counter) Add(value float64) {
+	if value < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.family.name))
+	}
+	c.family.mu.Lock()
+	defer c.family.mu.Unlock()
+	c.series.value += value
+}
+
+type GaugeVec struct {
+	family *metricFamily
+}
+
+func (v *GaugeVec) With(labelValues ...string) Gauge {
+	return Gauge{family: v.family, series: v.family.seriesFor(labelValues)}
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	family *metricFamily
+	series *metricSeries
+}
+
+func (g Gauge) Set(value float64) {
+	g.family.mu.Lock()
+	defer g.family.mu.Unlock()
+	g.series.value = value
+}
+
+func (g Gauge) Add(value float64) {
+	g.family.mu.Lock()
+	defer g.family.mu.Unlock()
+	g.series.value += value
+}
+
+func (g Gauge) Inc() {
+	g.Add(1)
+}
+
+func (g Gauge) Dec() {
+	g.Add(-1)
+}
+
+type HistogramVec struct {
+	family *metricFamily
+}
+
+func (v *HistogramVec) With(labelValues ...string) Histogram {
+	return Histogram{family: v.family, series: v.family.seriesFor(labelValues)}
+}
+
+// Histogram counts observations per bucket, plus their sum and count.
+type Histogram struct {
+	family *metricFamily
+	series *metricSeries
+}
+
+func (h Histogram) Observe(value float64) {
+	h.family.mu.Lock()
+	defer h.family.mu.Unlock()
+	for i, upperBound := range h.family.buckets {
+		if value <= upperBound {
+			h.series.bucketCounts[i]++
+		}
+	}
+	h.series.count++
+	h.series.sum += value
+}
+
+// =============================================================================
+// METRIC FAMILIES
+// =============================================================================
+
+// metricFamily is one metric name and all its series. Its mutex guards the
+// values of every series in it.
+type metricFamily struct {
+	mu         sync.Mutex
+	kind       metricKind
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+	series     map[string]*metricSeries
+}
+
+// metricSeries holds value for counters and gauges, the rest for histograms.
+// bucketCounts are cumulative, as they are exposed.
+type metricSeries struct {
+	labelValues  []string
+	value        float64
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+func newMetricFamily(kind metricKind, name string, help string, buckets []float64, labelNames []string) *metricFamily {
+	if !metricNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, labelName := range labelNames {
+		if !labelNamePattern.MatchString(labelName) || strings.HasPrefix(labelName, "__") || (kind == metricHistogram && labelName == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q on %s", labelName, name))
+		}
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("metrics: buckets of %s must be increasing", name))
+		}
+	}
+	return &metricFamily{
+		kind:       kind,
+		name:       name,
+		help:       help,
+		labelNames: append([]string(nil), labelNames...),
+		buckets:    append([]float64(nil), buckets...),
+		series:     make(map[string]*metricSeries),
+	}
+}
+
+func (f *metricFamily) seriesFor(labelValues []string) *metricSeries {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s takes labels %v, got %d values", f.name, f.labelNames, len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if series, ok := f.series[key]; ok {
+		return series
+	}
+	series := &metricSeries{
+		labelValues:  append([]string(nil), labelValues...),
+		bucketCounts: make([]uint64, len(f.buckets)),
+	}
+	f.series[key] = series
+	return series
+}
+
+func (f *metricFamily) writeText(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeMetricHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		series := f.series[key]
+		labels := formatMetricLabels(f.labelNames, series.labelValues)
+		if f.kind != metricHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, labels.String(""), formatMetricValue(series.value))
+			continue
+		}
+		for i, upperBound := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, labels.String(formatMetricValue(upperBound)), series.bucketCounts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, labels.String("+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, labels.String(""), formatMetricValue(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, labels.String(""), series.count)
+	}
+}
+
+// metricLabels is a series' label pairs, already escaped.
+type metricLabels []string
+
+func formatMetricLabels(names []string, values []string) metricLabels {
+	labels := make(metricLabels, len(names))
+	for i, name := range names {
+		labels[i] = fmt.Sprintf(`%s="%s"`, name, escapeMetricLabelValue(values[i]))
+	}
+	return labels
+}
+
+// String renders the labels in braces, with le added for a histogram
+// bucket when le is not empty.
+func (l metricLabels) String(le string) string {
+	pairs := l
+	if le != "" {
+		pairs = append(pairs[:len(pairs):len(pairs)], fmt.Sprintf(`le="%s"`, le))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func escapeMetricHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeMetricLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
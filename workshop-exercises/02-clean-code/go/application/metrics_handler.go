@@ -0,0 +1,42 @@
+package application
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// =============================================================================
+// METRICS HANDLER
+// Serves a registry for Prometheus to scrape
+// =============================================================================
+
+// MetricsContentType is the Prometheus text exposition format, version 0.0.4.
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metricsHandler struct {
+	registry MetricsRegistryInterface
+}
+
+func NewMetricsHandler(registry MetricsRegistryInterface) http.Handler {
+	return &metricsHandler{registry: registry}
+}
+
+// ServeHTTP renders into a buffer first, so a failure can still be answered
+// with a 500 instead of a half-written page.
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body bytes.Buffer
+	if err := h.registry.WriteText(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", MetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(body.Bytes())
+	}
+}
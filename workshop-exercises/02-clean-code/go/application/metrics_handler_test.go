@@ -0,0 +1,49 @@
+package application
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// METRICS HANDLER TESTS
+// Testing: metrics_handler.go
+// =============================================================================
+
+func TestMetricsHandler_Get_ServesTextFormat(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	registry.Counter("orders_total", "Orders.").With().Inc()
+	handler := NewMetricsHandler(registry)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != MetricsContentType {
+		t.Errorf("Expected %s, got %s", MetricsContentType, contentType)
+	}
+	if !strings.Contains(recorder.Body.String(), "orders_total 1\n") {
+		t.Errorf("Expected the counter in the body, got:\n%s", recorder.Body.String())
+	}
+}
+
+func TestMetricsHandler_Post_IsNotAllowed(t *testing.T) {
+	// Arrange
+	handler := NewMetricsHandler(NewMetricsRegistry())
+	recorder := httptest.NewRecorder()
+
+	// Act
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+
+	// Assert
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,163 @@
+package application
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// METRICS TESTS
+// Testing: metrics.go
+// =============================================================================
+
+func writeMetricsText(t *testing.T, registry MetricsRegistryInterface) string {
+	t.Helper()
+	var text strings.Builder
+	if err := registry.WriteText(&text); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return text.String()
+}
+
+func expectPanic(t *testing.T, act func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	act()
+}
+
+func TestMetricsRegistry_WriteText_CounterAndGauge(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	requests := registry.Counter("requests_total", "Requests handled.", "method")
+	registry.Gauge("queue_depth", "Jobs waiting.").With().Set(3)
+
+	// Act
+	requests.With("POST").Add(2)
+	requests.With("GET").Inc()
+	text := writeMetricsText(t, registry)
+
+	// Assert
+	want := `# HELP queue_depth Jobs waiting.
+# TYPE queue_depth gauge
+queue_depth 3
+# HELP requests_total Requests handled.
+# TYPE requests_total counter
+requests_total{method="GET"} 1
+requests_total{method="POST"} 2
+`
+	if text != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, text)
+	}
+}
+
+func TestMetricsRegistry_WriteText_HistogramBucketsAreCumulative(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	latency := registry.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+
+	// Act
+	for _, value := range []float64{0.05, 0.1, 0.5, 3} {
+		latency.With("/orders").Observe(value)
+	}
+	text := writeMetricsText(t, registry)
+
+	// Assert
+	for _, line := range []string{
+		`latency_seconds_bucket{route="/orders",le="0.1"} 2`,
+		`latency_seconds_bucket{route="/orders",le="1"} 3`,
+		`latency_seconds_bucket{route="/orders",le="+Inf"} 4`,
+		`latency_seconds_sum{route="/orders"} 3.65`,
+		`latency_seconds_count{route="/orders"} 4`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestMetricsRegistry_WriteText_EscapesHelpAndLabelValues(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	registry.Counter("errors_total", "Errors\nby \\ reason.", "reason").With("said \"no\"\nC:\\").Inc()
+
+	// Act
+	text := writeMetricsText(t, registry)
+
+	// Assert
+	if !strings.Contains(text, `# HELP errors_total Errors\nby \\ reason.`) {
+		t.Errorf("Expected escaped help, got:\n%s", text)
+	}
+	if !strings.Contains(text, `errors_total{reason="said \"no\"\nC:\\"} 1`) {
+		t.Errorf("Expected escaped label value, got:\n%s", text)
+	}
+}
+
+func TestFormatMetricValue_SpecialValues(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+		{0.25, "0.25"},
+		{1e21, "1e+21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			// Act & Assert
+			if got := formatMetricValue(tt.value); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMetricsRegistry_SameNameAndLabels_ReturnsSameMetric(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	registry.Counter("jobs_total", "Jobs.", "queue").With("mail").Inc()
+
+	// Act
+	registry.Counter("jobs_total", "Jobs.", "queue").With("mail").Inc()
+
+	// Assert
+	if text := writeMetricsText(t, registry); !strings.Contains(text, `jobs_total{queue="mail"} 2`) {
+		t.Errorf("Expected both increments on one counter, got:\n%s", text)
+	}
+}
+
+func TestMetricsRegistry_Misuse_Panics(t *testing.T) {
+	tests := []struct {
+		name string
+		act  func(registry MetricsRegistryInterface)
+	}{
+		{"invalid metric name", func(registry MetricsRegistryInterface) { registry.Counter("jobs-total", "") }},
+		{"invalid label name", func(registry MetricsRegistryInterface) { registry.Counter("jobs_total", "", "queue name") }},
+		{"le on histogram", func(registry MetricsRegistryInterface) { registry.Histogram("jobs_seconds", "", nil, "le") }},
+		{"unsorted buckets", func(registry MetricsRegistryInterface) { registry.Histogram("jobs_seconds", "", []float64{1, 0.5}) }},
+		{"kind changed", func(registry MetricsRegistryInterface) {
+			registry.Counter("jobs", "")
+			registry.Gauge("jobs", "")
+		}},
+		{"labels changed", func(registry MetricsRegistryInterface) {
+			registry.Counter("jobs_total", "", "queue")
+			registry.Counter("jobs_total", "", "priority")
+		}},
+		{"wrong label count", func(registry MetricsRegistryInterface) { registry.Counter("jobs_total", "", "queue").With() }},
+		{"counter decreased", func(registry MetricsRegistryInterface) { registry.Counter("jobs_total", "").With().Add(-1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			expectPanic(t, func() { tt.act(NewMetricsRegistry()) })
+		})
+	}
+}
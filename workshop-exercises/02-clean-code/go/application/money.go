@@ -0,0 +1,514 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// MONEY
+// Exact decimal amounts stored as integer minor units plus an ISO-4217 currency
+// =============================================================================
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrAmountOverflow   = errors.New("amount out of range")
+)
+
+type Currency string
+
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	CHF Currency = "CHF"
+	SEK Currency = "SEK"
+	PLN Currency = "PLN"
+	CAD Currency = "CAD"
+	AUD Currency = "AUD"
+	JPY Currency = "JPY"
+	KWD Currency = "KWD"
+)
+
+type currencyDefinition struct {
+	minorUnits int
+	symbol     string
+}
+
+var currencyDefinitions = map[Currency]currencyDefinition{
+	USD: {minorUnits: 2, symbol: "$"},
+	EUR: {minorUnits: 2, symbol: "€"},
+	GBP: {minorUnits: 2, symbol: "£"},
+	CHF: {minorUnits: 2},
+	SEK: {minorUnits: 2},
+	PLN: {minorUnits: 2},
+	CAD: {minorUnits: 2},
+	AUD: {minorUnits: 2},
+	JPY: {minorUnits: 0, symbol: "¥"},
+	KWD: {minorUnits: 3},
+}
+
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !currency.IsKnown() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+func (c Currency) IsKnown() bool {
+	_, ok := currencyDefinitions[c]
+	return ok
+}
+
+func (c Currency) MinorUnits() int {
+	return currencyDefinitions[c].minorUnits
+}
+
+func (c Currency) Symbol() string {
+	return currencyDefinitions[c].symbol
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// =============================================================================
+// PERCENTAGE
+// Rates stored in ten-thousandths of a percent (2.9% == 29000)
+// =============================================================================
+
+const percentageScale = 4
+
+type Percentage int64
+
+func NewPercentageFromBasisPoints(basisPoints int64) Percentage {
+	return Percentage(basisPoints * 100)
+}
+
+func ParsePercentage(value string) (Percentage, error) {
+	scaled, err := parseScaledDecimal(value, percentageScale, false)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q: %w", value, err)
+	}
+	return Percentage(scaled), nil
+}
+
+// PercentageFromFloat reads percent by its shortest decimal representation,
+// rounded half-up to the percentage scale. NaN, infinities and values out of
+// range are rejected.
+func PercentageFromFloat(percent float64) (Percentage, error) {
+	scaled, err := parseScaledDecimal(strconv.FormatFloat(percent, 'f', -1, 64), percentageScale, true)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %v: %w", percent, err)
+	}
+	return Percentage(scaled), nil
+}
+
+// NewPercentageFromFloat is PercentageFromFloat for percentages known to be
+// valid, such as literals; it panics on anything else.
+func NewPercentageFromFloat(percent float64) Percentage {
+	percentage, err := PercentageFromFloat(percent)
+	if err != nil {
+		panic(err)
+	}
+	return percentage
+}
+
+func (p Percentage) Float64() float64 {
+	value, _ := strconv.ParseFloat(p.Decimal(), 64)
+	return value
+}
+
+func (p Percentage) Decimal() string {
+	return strings.TrimSuffix(strings.TrimRight(formatScaledDecimal(int64(p), percentageScale), "0"), ".")
+}
+
+func (p Percentage) String() string {
+	return p.Decimal() + "%"
+}
+
+// Percentages are written to JSON as decimal strings ("3.49"). Plain JSON
+// numbers are accepted when reading so hand-written config stays simple.
+func (p Percentage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Decimal())
+}
+
+func (p *Percentage) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		text = string(data)
+	}
+	parsed, err := ParsePercentage(text)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// =============================================================================
+// MONEY VALUE
+// =============================================================================
+
+type Money struct {
+	minorUnits int64
+	currency   Currency
+}
+
+func NewMoney(minorUnits int64, currency Currency) Money {
+	return Money{minorUnits: minorUnits, currency: currency}
+}
+
+func ZeroMoney(currency Currency) Money {
+	return NewMoney(0, currency)
+}
+
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if !currency.IsKnown() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	minorUnits, err := parseScaledDecimal(amount, currency.MinorUnits(), false)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid %s amount %q: %w", currency, amount, err)
+	}
+	return NewMoney(minorUnits, currency), nil
+}
+
+func MustParseMoney(amount string, currency Currency) Money {
+	money, err := ParseMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+// MoneyFromFloat is the compatibility path for callers that still hold
+// float64 amounts. The float is read by its shortest decimal representation
+// and rounded half-up to the currency's minor unit, so 103.4145 becomes 103.41
+// and 1.005 becomes 1.01. NaN, infinities and amounts too large for the
+// minor units are rejected.
+func MoneyFromFloat(amount float64, currency Currency) (Money, error) {
+	if !currency.IsKnown() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	minorUnits, err := parseScaledDecimal(strconv.FormatFloat(amount, 'f', -1, 64), currency.MinorUnits(), true)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid %s amount %v: %w", currency, amount, err)
+	}
+	return NewMoney(minorUnits, currency), nil
+}
+
+// NewMoneyFromFloat is MoneyFromFloat for amounts known to be valid; it
+// panics on anything else, like MustParseMoney.
+func NewMoneyFromFloat(amount float64, currency Currency) Money {
+	money, err := MoneyFromFloat(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+func (m Money) MinorUnits() int64 {
+	return m.minorUnits
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.minorUnits == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.minorUnits > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minorUnits < 0
+}
+
+func (m Money) Float64() float64 {
+	value, _ := strconv.ParseFloat(m.Decimal(), 64)
+	return value
+}
+
+func (m Money) Decimal() string {
+	return formatScaledDecimal(m.minorUnits, m.currency.MinorUnits())
+}
+
+func (m Money) String() string {
+	return m.formatWithCurrency(m.Decimal())
+}
+
+func (m Money) formatWithCurrency(decimal string) string {
+	symbol := m.currency.Symbol()
+	if symbol == "" {
+		return decimal + " " + string(m.currency)
+	}
+	if strings.HasPrefix(decimal, "-") {
+		return "-" + symbol + decimal[1:]
+	}
+	return symbol + decimal
+}
+
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+// Add, Subtract and Multiply return ErrAmountOverflow rather than a result
+// that wrapped around.
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := m.minorUnits + other.minorUnits
+	if (other.minorUnits > 0 && sum < m.minorUnits) || (other.minorUnits < 0 && sum > m.minorUnits) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrAmountOverflow, m, other)
+	}
+	return NewMoney(sum, m.currency), nil
+}
+
+func (m Money) Subtract(other Money) (Money, error) {
+	if err := m.checkSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	difference := m.minorUnits - other.minorUnits
+	if (other.minorUnits > 0 && difference > m.minorUnits) || (other.minorUnits < 0 && difference < m.minorUnits) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrAmountOverflow, m, other)
+	}
+	return NewMoney(difference, m.currency), nil
+}
+
+func (m Money) Compare(other Money) (int, error) {
+	if err := m.checkSameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minorUnits < other.minorUnits:
+		return -1, nil
+	case m.minorUnits > other.minorUnits:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) Negate() Money {
+	return NewMoney(-m.minorUnits, m.currency)
+}
+
+func (m Money) Multiply(quantity int64) (Money, error) {
+	product := m.minorUnits * quantity
+	if quantity != 0 && (product/quantity != m.minorUnits || (quantity == -1 && m.minorUnits == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrAmountOverflow, m, quantity)
+	}
+	return NewMoney(product, m.currency), nil
+}
+
+// ApplyPercentage returns the given share of the amount, rounded half-up
+// (away from zero) to the currency's minor unit. Only shares above 100% can
+// overflow.
+func (m Money) ApplyPercentage(percentage Percentage) (Money, error) {
+	numerator := new(big.Int).Mul(big.NewInt(m.minorUnits), big.NewInt(int64(percentage)))
+	denominator := big.NewInt(100 * pow10(percentageScale))
+	share := divideRoundHalfUp(numerator, denominator)
+	if !share.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s x %s", ErrAmountOverflow, m, percentage)
+	}
+	return NewMoney(share.Int64(), m.currency), nil
+}
+
+// ScaleByRatio returns amount * numerator / denominator, rounded half-up.
+// Only ratios above one can overflow.
+func (m Money) ScaleByRatio(numerator int64, denominator int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.minorUnits), big.NewInt(numerator))
+	scaled := divideRoundHalfUp(product, big.NewInt(denominator))
+	if !scaled.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s x %d/%d", ErrAmountOverflow, m, numerator, denominator)
+	}
+	return NewMoney(scaled.Int64(), m.currency), nil
+}
+
+// Allocate splits the amount in proportion to weights. Rounding leftovers go
+// one minor unit at a time to the parts with the largest remainders, so the
+// parts always add up to the amount exactly. All-zero weights split evenly.
+func (m Money) Allocate(weights []int64) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+	weights = allocationWeights(weights)
+	totalWeight := new(big.Int)
+	for _, weight := range weights {
+		totalWeight.Add(totalWeight, big.NewInt(weight))
+	}
+
+	magnitude := big.NewInt(m.minorUnits)
+	magnitude.Abs(magnitude)
+	remainders := make([]*big.Int, len(weights))
+	allocated := int64(0)
+	for i, weight := range weights {
+		share, remainder := new(big.Int).QuoRem(new(big.Int).Mul(magnitude, big.NewInt(weight)), totalWeight, new(big.Int))
+		parts[i] = NewMoney(share.Int64(), m.currency)
+		remainders[i] = remainder
+		allocated += share.Int64()
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].Cmp(remainders[order[b]]) > 0
+	})
+	for i := int64(0); i < magnitude.Int64()-allocated; i++ {
+		parts[order[i]].minorUnits++
+	}
+
+	if m.IsNegative() {
+		for i := range parts {
+			parts[i] = parts[i].Negate()
+		}
+	}
+	return parts
+}
+
+func allocationWeights(weights []int64) []int64 {
+	for _, weight := range weights {
+		if weight > 0 {
+			return weights
+		}
+	}
+	even := make([]int64, len(weights))
+	for i := range even {
+		even[i] = 1
+	}
+	return even
+}
+
+type moneyJSON struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var decoded moneyJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Currency == "" {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := ParseMoney(decoded.Amount, decoded.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) checkSameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+// =============================================================================
+// DECIMAL HELPERS
+// =============================================================================
+
+func parseScaledDecimal(value string, scale int, round bool) (int64, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	if len(fraction) > scale && !round && strings.TrimRight(fraction[scale:], "0") != "" {
+		return 0, fmt.Errorf("%w: more than %d decimal places", ErrInvalidAmount, scale)
+	}
+
+	digits := whole + padOrTrim(fraction, scale+1)
+	scaled, ok := new(big.Int).SetString("0"+digits, 10)
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		scaled.Neg(scaled)
+	}
+	result := divideRoundHalfUp(scaled, big.NewInt(10))
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+	return result.Int64(), nil
+}
+
+func formatScaledDecimal(value int64, scale int) string {
+	sign := ""
+	magnitude := new(big.Int).Abs(big.NewInt(value)).String()
+	if value < 0 {
+		sign = "-"
+	}
+	if scale == 0 {
+		return sign + magnitude
+	}
+	if len(magnitude) <= scale {
+		magnitude = strings.Repeat("0", scale-len(magnitude)+1) + magnitude
+	}
+	split := len(magnitude) - scale
+	return sign + magnitude[:split] + "." + magnitude[split:]
+}
+
+func divideRoundHalfUp(numerator *big.Int, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+func padOrTrim(fraction string, length int) string {
+	if len(fraction) >= length {
+		return fraction[:length]
+	}
+	return fraction + strings.Repeat("0", length-len(fraction))
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(exponent int) int64 {
+	result := int64(1)
+	for i := 0; i < exponent; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,322 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+// =============================================================================
+// MONEY TESTS
+// Testing: money.go
+// =============================================================================
+
+func TestMoney_ParseMoney_ValidAmount_StoresMinorUnits(t *testing.T) {
+	// Arrange
+	amount := "103.41"
+
+	// Act
+	money, err := ParseMoney(amount, USD)
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if money.MinorUnits() != 10341 {
+		t.Errorf("Expected 10341 minor units, got %d", money.MinorUnits())
+	}
+	if money.Currency() != USD {
+		t.Errorf("Expected USD, got %s", money.Currency())
+	}
+}
+
+func TestMoney_ParseMoney_TooManyDecimals_ReturnsError(t *testing.T) {
+	// Act
+	_, err := ParseMoney("103.414", USD)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestMoney_ParseMoney_UnknownCurrency_ReturnsError(t *testing.T) {
+	// Act
+	_, err := ParseMoney("10.00", Currency("XXX"))
+
+	// Assert
+	if !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestMoney_ParseMoney_CurrencyMinorUnits_RespectsExponent(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		currency Currency
+		expected int64
+	}{
+		{"1500", JPY, 1500},
+		{"1.250", KWD, 1250},
+		{"0.05", EUR, 5},
+		{"-42.50", USD, -4250},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.amount+"_"+tc.currency.String(), func(t *testing.T) {
+			// Act
+			money, err := ParseMoney(tc.amount, tc.currency)
+
+			// Assert
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if money.MinorUnits() != tc.expected {
+				t.Errorf("Expected %d minor units, got %d", tc.expected, money.MinorUnits())
+			}
+		})
+	}
+}
+
+func TestMoney_NewMoneyFromFloat_DriftingFloat_RoundsHalfUp(t *testing.T) {
+	testCases := []struct {
+		amount   float64
+		expected string
+	}{
+		{103.41449999, "103.41"},
+		{1.005, "1.01"},
+		{77.175, "77.18"},
+		{-2.175, "-2.18"},
+		{0.1 + 0.2, "0.30"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			// Act
+			money := NewMoneyFromFloat(tc.amount, USD)
+
+			// Assert
+			if money.Decimal() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, money.Decimal())
+			}
+		})
+	}
+}
+
+func TestMoney_ApplyPercentage_FeePercent_RoundsHalfUpWithoutDrift(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		percent  string
+		expected string
+	}{
+		{"100.00", "2.9", "2.90"},
+		{"75.00", "2.9", "2.18"},
+		{"50.00", "3.49", "1.75"},
+		{"10.00", "3.49", "0.35"},
+		{"1.00", "3.49", "0.03"},
+		{"-50.00", "15", "-7.50"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.amount+"_"+tc.percent, func(t *testing.T) {
+			// Arrange
+			percentage, err := ParsePercentage(tc.percent)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			// Act
+			result, err := MustParseMoney(tc.amount, USD).ApplyPercentage(percentage)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Decimal() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, result.Decimal())
+			}
+		})
+	}
+}
+
+func TestMoney_ApplyPercentage_ResultBeyondInt64_ReturnsError(t *testing.T) {
+	// Arrange
+	amount := NewMoney(math.MaxInt64/2, USD)
+
+	// Act
+	_, err := amount.ApplyPercentage(NewPercentageFromFloat(300))
+
+	// Assert
+	if !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("Expected ErrAmountOverflow, got %v", err)
+	}
+}
+
+func TestPercentage_PercentageFromFloat_NaN_ReturnsError(t *testing.T) {
+	// Act
+	_, err := PercentageFromFloat(math.NaN())
+
+	// Assert
+	if !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+func TestMoney_ApplyPercentage_RepeatedAddition_DoesNotDrift(t *testing.T) {
+	// Arrange: 0.10 added a thousand times drifts as float64
+	total := ZeroMoney(USD)
+	step := MustParseMoney("0.10", USD)
+
+	// Act
+	for i := 0; i < 1000; i++ {
+		total, _ = total.Add(step)
+	}
+
+	// Assert
+	if total.Decimal() != "100.00" {
+		t.Errorf("Expected 100.00, got %s", total.Decimal())
+	}
+}
+
+func TestMoney_Add_DifferentCurrencies_ReturnsError(t *testing.T) {
+	// Act
+	_, err := MustParseMoney("1.00", USD).Add(MustParseMoney("1.00", EUR))
+
+	// Assert
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestMoney_Arithmetic_Overflow_ReturnsError(t *testing.T) {
+	largest := NewMoney(math.MaxInt64, USD)
+	smallest := NewMoney(math.MinInt64, USD)
+	cent := NewMoney(1, USD)
+
+	testCases := []struct {
+		name      string
+		calculate func() (Money, error)
+	}{
+		{"add", func() (Money, error) { return largest.Add(cent) }},
+		{"add negative", func() (Money, error) { return smallest.Add(cent.Negate()) }},
+		{"subtract", func() (Money, error) { return smallest.Subtract(cent) }},
+		{"subtract negative", func() (Money, error) { return largest.Subtract(cent.Negate()) }},
+		{"multiply", func() (Money, error) { return MustParseMoney("10.00", USD).Multiply(math.MaxInt64 / 100) }},
+		{"multiply negative", func() (Money, error) { return smallest.Multiply(-1) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := tc.calculate()
+
+			// Assert
+			if !errors.Is(err, ErrAmountOverflow) {
+				t.Errorf("Expected ErrAmountOverflow, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMoney_Multiply_InRange_ReturnsProduct(t *testing.T) {
+	// Act
+	product, err := MustParseMoney("19.99", USD).Multiply(3)
+
+	// Assert
+	if err != nil || product != MustParseMoney("59.97", USD) {
+		t.Errorf("Expected 59.97, got %s (%v)", product, err)
+	}
+}
+
+func TestMoney_String_KnownSymbols_FormatsAmount(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{MustParseMoney("102.90", USD), "$102.90"},
+		{MustParseMoney("-7.50", EUR), "-€7.50"},
+		{MustParseMoney("1500", JPY), "¥1500"},
+		{MustParseMoney("12.50", CHF), "12.50 CHF"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			// Act & Assert
+			if tc.money.String() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, tc.money.String())
+			}
+		})
+	}
+}
+
+func TestPercentage_ParsePercentage_ValidValue_RoundTrips(t *testing.T) {
+	// Act
+	percentage, err := ParsePercentage("3.49")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if percentage != NewPercentageFromFloat(3.49) {
+		t.Errorf("Expected parsed and float percentages to match, got %v", percentage)
+	}
+	if percentage.String() != "3.49%" {
+		t.Errorf("Expected '3.49%%', got '%s'", percentage.String())
+	}
+}
+
+func TestMoney_MarshalJSON_RoundTrip_PreservesAmountAndCurrency(t *testing.T) {
+	// Arrange
+	original := MustParseMoney("103.41", EUR)
+
+	// Act
+	data, err := json.Marshal(original)
+	var decoded Money
+	decodeErr := json.Unmarshal(data, &decoded)
+
+	// Assert
+	if err != nil || decodeErr != nil {
+		t.Fatalf("Expected no errors, got %v / %v", err, decodeErr)
+	}
+	if string(data) != `{"amount":"103.41","currency":"EUR"}` {
+		t.Errorf("Expected decimal JSON, got %s", data)
+	}
+	if decoded != original {
+		t.Errorf("Expected %s, got %s", original, decoded)
+	}
+}
+
+func TestMoney_Allocate_SplitsExactlyByWeight(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   Money
+		weights  []int64
+		expected []string
+	}{
+		{"even thirds", MustParseMoney("10.00", USD), []int64{1, 1, 1}, []string{"3.34", "3.33", "3.33"}},
+		{"proportional", MustParseMoney("15.08", USD), []int64{2550, 7500}, []string{"3.83", "11.25"}},
+		{"negative amount", MustParseMoney("-10.00", USD), []int64{1, 2}, []string{"-3.33", "-6.67"}},
+		{"zero weights split evenly", MustParseMoney("1.00", USD), []int64{0, 0}, []string{"0.50", "0.50"}},
+		{"zero weight gets nothing", MustParseMoney("5.00", USD), []int64{0, 3}, []string{"0.00", "5.00"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			parts := tc.amount.Allocate(tc.weights)
+
+			// Assert
+			sum := ZeroMoney(USD)
+			for i, part := range parts {
+				if part.Decimal() != tc.expected[i] {
+					t.Errorf("Expected part %d to be %s, got %s", i, tc.expected[i], part.Decimal())
+				}
+				sum, _ = sum.Add(part)
+			}
+			if sum != tc.amount {
+				t.Errorf("Expected parts to add up to %s, got %s", tc.amount, sum)
+			}
+		})
+	}
+}
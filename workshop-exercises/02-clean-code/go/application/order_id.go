@@ -0,0 +1,127 @@
+package application
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// =============================================================================
+// ORDER ID GENERATION
+// Unique order identifiers, safe to generate from concurrent orders
+// =============================================================================
+
+var ErrOrderIDExhausted = errors.New("order ID space exhausted for this millisecond")
+
+type OrderIDGenerator interface {
+	NewOrderID() (string, error)
+}
+
+const (
+	orderIDPrefix     = "order_"
+	ulidEntropyBytes  = 10
+	ulidEncodedLength = 26
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// ULIDOrderIDGenerator produces ULID-style identifiers: a 48-bit millisecond
+// timestamp followed by 80 random bits, in Crockford base32. IDs sort by
+// creation time. Within one millisecond the random part is incremented rather
+// than redrawn, so IDs from the same generator stay strictly increasing even
+// when the clock stalls or steps back.
+type ULIDOrderIDGenerator struct {
+	mu         sync.Mutex
+	clock      Clock
+	entropy    io.Reader
+	lastMillis uint64
+	lastRandom [ulidEntropyBytes]byte
+}
+
+func NewULIDOrderIDGenerator() OrderIDGenerator {
+	return NewULIDOrderIDGeneratorWithSource(NewSystemClock(), rand.Reader)
+}
+
+func NewULIDOrderIDGeneratorWithSource(clock Clock, entropy io.Reader) OrderIDGenerator {
+	return &ULIDOrderIDGenerator{
+		clock:   clock,
+		entropy: entropy,
+	}
+}
+
+func (g *ULIDOrderIDGenerator) NewOrderID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	millis := g.currentMillis()
+	if millis <= g.lastMillis {
+		if err := g.incrementRandom(); err != nil {
+			return "", err
+		}
+	} else {
+		if err := g.drawRandom(); err != nil {
+			return "", err
+		}
+		g.lastMillis = millis
+	}
+	return orderIDPrefix + g.encode(), nil
+}
+
+func (g *ULIDOrderIDGenerator) currentMillis() uint64 {
+	return uint64(g.clock.Now().UnixMilli())
+}
+
+func (g *ULIDOrderIDGenerator) drawRandom() error {
+	if _, err := io.ReadFull(g.entropy, g.lastRandom[:]); err != nil {
+		return fmt.Errorf("reading order ID entropy: %w", err)
+	}
+	return nil
+}
+
+func (g *ULIDOrderIDGenerator) incrementRandom() error {
+	for i := len(g.lastRandom) - 1; i >= 0; i-- {
+		g.lastRandom[i]++
+		if g.lastRandom[i] != 0 {
+			return nil
+		}
+	}
+	return ErrOrderIDExhausted
+}
+
+func (g *ULIDOrderIDGenerator) encode() string {
+	var raw [16]byte
+	for i := 0; i < 6; i++ {
+		raw[i] = byte(g.lastMillis >> (8 * (5 - i)))
+	}
+	copy(raw[6:], g.lastRandom[:])
+	return encodeCrockford(raw[:], ulidEncodedLength)
+}
+
+func encodeCrockford(data []byte, length int) string {
+	value := new(big.Int).SetBytes(data)
+	base := big.NewInt(32)
+	digit := new(big.Int)
+	encoded := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		value.DivMod(value, base, digit)
+		encoded[i] = crockfordAlphabet[digit.Int64()]
+	}
+	return string(encoded)
+}
+
+// SequentialOrderIDGenerator hands out order_000001, order_000002, ... and is
+// meant for tests that need predictable identifiers.
+type SequentialOrderIDGenerator struct {
+	next atomic.Int64
+}
+
+func NewSequentialOrderIDGenerator() OrderIDGenerator {
+	return &SequentialOrderIDGenerator{}
+}
+
+func (g *SequentialOrderIDGenerator) NewOrderID() (string, error) {
+	return fmt.Sprintf("%s%06d", orderIDPrefix, g.next.Add(1)), nil
+}
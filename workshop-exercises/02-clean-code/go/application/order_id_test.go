@@ -0,0 +1,170 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ORDER ID GENERATOR TESTS
+// Testing: order_id.go
+// =============================================================================
+
+func TestULIDOrderIDGenerator_NewOrderID_ReturnsPrefixedCrockfordID(t *testing.T) {
+	// Arrange
+	generator := NewULIDOrderIDGenerator()
+
+	// Act
+	orderID, err := generator.NewOrderID()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	encoded := strings.TrimPrefix(orderID, "order_")
+	if len(encoded) != 26 {
+		t.Errorf("Expected 26 character ULID, got %q", orderID)
+	}
+	for _, r := range encoded {
+		if !strings.ContainsRune(crockfordAlphabet, r) {
+			t.Errorf("Expected Crockford base32, got %q", orderID)
+		}
+	}
+}
+
+func TestULIDOrderIDGenerator_NewOrderID_KnownTimestamp_EncodesTimeFirst(t *testing.T) {
+	// Arrange: 1469918176385 ms is the reference timestamp from the ULID spec
+	clock := NewManualClock(time.UnixMilli(1469918176385))
+	generator := NewULIDOrderIDGeneratorWithSource(clock, bytes.NewReader(make([]byte, 10)))
+
+	// Act
+	orderID, _ := generator.NewOrderID()
+
+	// Assert
+	if orderID != "order_01ARYZ6S410000000000000000" {
+		t.Errorf("Expected spec-encoded timestamp, got %s", orderID)
+	}
+}
+
+func TestULIDOrderIDGenerator_NewOrderID_SameMillisecond_StaysSorted(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	generator := NewULIDOrderIDGeneratorWithSource(clock, bytes.NewReader(bytes.Repeat([]byte{0xAB}, 30)))
+
+	// Act
+	first, _ := generator.NewOrderID()
+	second, _ := generator.NewOrderID()
+	clock.Advance(time.Millisecond)
+	third, _ := generator.NewOrderID()
+
+	// Assert
+	if !(first < second && second < third) {
+		t.Errorf("Expected strictly increasing IDs, got %s, %s, %s", first, second, third)
+	}
+}
+
+func TestULIDOrderIDGenerator_NewOrderID_ClockStepsBack_StaysSorted(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	generator := NewULIDOrderIDGeneratorWithSource(clock, bytes.NewReader(bytes.Repeat([]byte{0x01}, 30)))
+	first, _ := generator.NewOrderID()
+
+	// Act
+	clock.Advance(-time.Second)
+	second, _ := generator.NewOrderID()
+
+	// Assert
+	if second <= first {
+		t.Errorf("Expected %s to sort after %s", second, first)
+	}
+}
+
+func TestULIDOrderIDGenerator_NewOrderID_RandomPartExhausted_ReturnsError(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	generator := NewULIDOrderIDGeneratorWithSource(clock, bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)))
+	_, _ = generator.NewOrderID()
+
+	// Act
+	_, err := generator.NewOrderID()
+
+	// Assert
+	if !errors.Is(err, ErrOrderIDExhausted) {
+		t.Errorf("Expected ErrOrderIDExhausted, got %v", err)
+	}
+}
+
+func TestULIDOrderIDGenerator_NewOrderID_EntropyFailure_ReturnsError(t *testing.T) {
+	// Arrange
+	generator := NewULIDOrderIDGeneratorWithSource(NewSystemClock(), bytes.NewReader(nil))
+
+	// Act
+	_, err := generator.NewOrderID()
+
+	// Assert
+	if err == nil {
+		t.Error("Expected error when entropy cannot be read")
+	}
+}
+
+func TestSequentialOrderIDGenerator_NewOrderID_CountsUp(t *testing.T) {
+	// Arrange
+	generator := NewSequentialOrderIDGenerator()
+
+	// Act
+	first, _ := generator.NewOrderID()
+	second, _ := generator.NewOrderID()
+
+	// Assert
+	if first != "order_000001" || second != "order_000002" {
+		t.Errorf("Expected order_000001 and order_000002, got %s and %s", first, second)
+	}
+}
+
+func TestOrderIDGenerators_ConcurrentUse_NeverCollide(t *testing.T) {
+	generators := map[string]OrderIDGenerator{
+		"ulid":       NewULIDOrderIDGenerator(),
+		"sequential": NewSequentialOrderIDGenerator(),
+	}
+
+	for name, generator := range generators {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			const workers, perWorker = 16, 500
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			seen := make(map[string]bool, workers*perWorker)
+
+			// Act
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWorker; i++ {
+						orderID, err := generator.NewOrderID()
+						if err != nil {
+							t.Errorf("Expected no error, got %v", err)
+							return
+						}
+						mu.Lock()
+						if seen[orderID] {
+							t.Errorf("Duplicate order ID %s", orderID)
+						}
+						seen[orderID] = true
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			// Assert
+			if len(seen) != workers*perWorker {
+				t.Errorf("Expected %d unique IDs, got %d", workers*perWorker, len(seen))
+			}
+		})
+	}
+}
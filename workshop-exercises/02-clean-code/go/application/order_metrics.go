@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// ORDER METRICS
+// What the order pipeline reports: order and payment latency, orders in
+// flight, fees and discount rates
+// =============================================================================
+
+// Order outcomes besides the status of a successful order.
+const (
+	OrderOutcomePaymentFailed = "payment_failed"
+	OrderOutcomeCancelled     = "cancelled"
+	OrderOutcomeError         = "error"
+)
+
+// MetricsCustomerTypes are the customer types discount rates are reported
+// under; any other type, which the client is free to send, is reported as
+// CustomerTypeOther so it cannot add series without limit.
+var MetricsCustomerTypes = []string{"regular", "premium", "new", "distributor"}
+
+const CustomerTypeOther = "other"
+
+// DiscountRateBuckets are upper bounds for the share of an order taken off.
+var DiscountRateBuckets = []float64{0, 0.05, 0.1, 0.15, 0.2, 0.25, 0.3, 0.4, 0.5, 0.75, 1}
+
+type OrderMetricsInterface interface {
+	// OrderStarted counts an order in flight until the returned func is
+	// called.
+	OrderStarted() func()
+	ObserveOrder(outcome string, duration time.Duration)
+	ObserveDiscount(customerType string, discount DiscountResult)
+	ObservePayment(processor string, err error, duration time.Duration)
+	// AddFees adds the fee a processor charged, or with reversed set the fee
+	// it gave back on a refund or void.
+	AddFees(processor string, fee Money, reversed bool)
+}
+
+type OrderMetrics struct {
+	ordersInFlight  Gauge
+	orderDuration   *HistogramVec
+	discountRate    *HistogramVec
+	paymentDuration *HistogramVec
+	fees            *CounterVec
+	feesReversed    *CounterVec
+}
+
+func NewOrderMetrics(registry MetricsRegistryInterface) OrderMetricsInterface {
+	return &OrderMetrics{
+		ordersInFlight:  registry.Gauge("orders_in_flight", "Orders being processed right now.").With(),
+		orderDuration:   registry.Histogram("order_process_duration_seconds", "Time taken by ProcessOrder, by outcome.", nil, "outcome"),
+		discountRate:    registry.Histogram("order_discount_rate", "Share of the order amount taken off by discounts.", DiscountRateBuckets, "customer_type"),
+		paymentDuration: registry.Histogram("payment_process_duration_seconds", "Time taken by ProcessPayment, by processor and outcome.", nil, "processor", "outcome"),
+		fees:            registry.Counter("payment_fees_total", "Processor fees charged, in major currency units.", "processor", "currency"),
+		feesReversed:    registry.Counter("payment_fees_reversed_total", "Processor fees given back on refunds and voids, in major currency units.", "processor", "currency"),
+	}
+}
+
+func (m *OrderMetrics) OrderStarted() func() {
+	m.ordersInFlight.Inc()
+	return m.ordersInFlight.Dec
+}
+
+func (m *OrderMetrics) ObserveOrder(outcome string, duration time.Duration) {
+	m.orderDuration.With(outcome).Observe(duration.Seconds())
+}
+
+// An order with nothing to pay has no rate and is not observed.
+func (m *OrderMetrics) ObserveDiscount(customerType string, discount DiscountResult) {
+	if discount.OriginalAmount.MinorUnits() <= 0 {
+		return
+	}
+	rate := float64(discount.TotalDiscount.MinorUnits()) / float64(discount.OriginalAmount.MinorUnits())
+	m.discountRate.With(customerTypeLabel(customerType)).Observe(rate)
+}
+
+func customerTypeLabel(customerType string) string {
+	if containsString(MetricsCustomerTypes, customerType) {
+		return customerType
+	}
+	return CustomerTypeOther
+}
+
+func (m *OrderMetrics) ObservePayment(processor string, err error, duration time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	m.paymentDuration.With(processor, outcome).Observe(duration.Seconds())
+}
+
+func (m *OrderMetrics) AddFees(processor string, fee Money, reversed bool) {
+	if fee.MinorUnits() <= 0 {
+		return
+	}
+	counter := m.fees
+	if reversed {
+		counter = m.feesReversed
+	}
+	counter.With(processor, string(fee.Currency())).Add(fee.Float64())
+}
+
+// orderOutcome labels a ProcessOrder call: the status of the order when it
+// went through, otherwise the kind of failure.
+func orderOutcome(result OrderResult, err error) string {
+	var paymentErr *PaymentError
+	switch {
+	case err == nil:
+		return string(result.Status)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return OrderOutcomeCancelled
+	case errors.As(err, &paymentErr), errors.Is(err, ErrPaymentDeclined), errors.Is(err, ErrProcessorUnavailable):
+		return OrderOutcomePaymentFailed
+	default:
+		return OrderOutcomeError
+	}
+}
+
+// =============================================================================
+// METRICS PROCESSOR
+// Decorator that times payments and adds up fees for one named processor
+// =============================================================================
+
+type MetricsProcessor struct {
+	processor PaymentProcessorInterface
+	name      string
+	metrics   OrderMetricsInterface
+	clock     Clock
+}
+
+// NewMetricsProcessor reports under name, usually the name the processor is
+// registered with.
+func NewMetricsProcessor(processor PaymentProcessorInterface, name string, metrics OrderMetricsInterface) PaymentProcessorInterface {
+	return &MetricsProcessor{processor: processor, name: name, metrics: metrics, clock: NewSystemClock()}
+}
+
+func (p *MetricsProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	started := p.clock.Now()
+	result, err := p.processor.ProcessPayment(ctx, request)
+	p.metrics.ObservePayment(p.name, err, p.clock.Now().Sub(started))
+	if err == nil {
+		p.metrics.AddFees(p.name, result.Fee, false)
+	}
+	return result, err
+}
+
+func (p *MetricsProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return p.processor.Authorize(ctx, request)
+}
+
+func (p *MetricsProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	result, err := p.processor.Capture(ctx, authorizationID, amount)
+	if err == nil {
+		p.metrics.AddFees(p.name, result.Fee, false)
+	}
+	return result, err
+}
+
+func (p *MetricsProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return p.processor.ReleaseAuthorization(ctx, authorizationID)
+}
+
+func (p *MetricsProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	result, err := p.processor.Refund(ctx, request)
+	if err == nil {
+		p.metrics.AddFees(p.name, result.FeeReversed, true)
+	}
+	return result, err
+}
+
+func (p *MetricsProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	result, err := p.processor.Void(ctx, transactionID)
+	if err == nil {
+		p.metrics.AddFees(p.name, result.FeeReversed, true)
+	}
+	return result, err
+}
+
+func (p *MetricsProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return QuoteFee(ctx, p.processor, request)
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ORDER METRICS TESTS
+// Testing: order_metrics.go, order_service_metrics.go
+// =============================================================================
+
+func expectMetricLines(t *testing.T, registry MetricsRegistryInterface, lines ...string) {
+	t.Helper()
+	text := writeMetricsText(t, registry)
+	for _, line := range lines {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestOrderService_WithMetrics_RecordsOutcomeAndDiscountRate(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	metrics := NewOrderMetrics(registry)
+	paid := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("80.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithMetrics(metrics))
+	declined := NewOrderService(NewFailingMockPaymentProcessor(NewDeclinedError("credit_card", "05", "do not honor")), NewMockDiscountService(false, MustParseMoney("100.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithMetrics(metrics))
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "premium"}
+
+	// Act
+	paid.ProcessOrder(context.Background(), order)
+	declined.ProcessOrder(context.Background(), order)
+	paid.ProcessOrder(context.Background(), OrderData{Amount: MustParseMoney("-1.00", USD), Customer: "test@example.com"})
+
+	// Assert
+	expectMetricLines(t, registry,
+		`order_process_duration_seconds_count{outcome="paid"} 1`,
+		`order_process_duration_seconds_count{outcome="payment_failed"} 1`,
+		`order_process_duration_seconds_count{outcome="error"} 1`,
+		`order_discount_rate_bucket{customer_type="premium",le="0.15"} 1`,
+		`order_discount_rate_bucket{customer_type="premium",le="0.2"} 2`,
+		`orders_in_flight 0`,
+	)
+}
+
+func TestOrderOutcome_ClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result OrderResult
+		err    error
+		want   string
+	}{
+		{"paid", OrderResult{Status: OrderStatusPaid}, nil, "paid"},
+		{"authorized", OrderResult{Status: OrderStatusAuthorized}, nil, "authorized"},
+		{"declined", OrderResult{}, fmt.Errorf("payment: %w", NewDeclinedError("paypal", "", "refused")), OrderOutcomePaymentFailed},
+		{"deadline", OrderResult{}, fmt.Errorf("payment: %w", context.DeadlineExceeded), OrderOutcomeCancelled},
+		{"validation", OrderResult{}, ErrInvalidAmount, OrderOutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			if got := orderOutcome(tt.result, tt.err); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMetricsProcessor_RecordsLatencyAndFees(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	metrics := NewOrderMetrics(registry)
+	processor := NewMetricsProcessor(NewCreditCardProcessor(), "card", metrics)
+	unavailable := NewMetricsProcessor(NewFailingMockPaymentProcessor(errors.New("connection reset")), "card", metrics)
+	ctx := context.Background()
+
+	// Act
+	payment, _ := processor.ProcessPayment(ctx, PaymentRequest{OrderID: "order_1", Amount: MustParseMoney("100.00", USD)})
+	refund, _ := processor.Refund(ctx, NewFullRefundRequest(payment.TransactionID))
+	unavailable.ProcessPayment(ctx, PaymentRequest{OrderID: "order_2", Amount: MustParseMoney("5.00", USD)})
+
+	// Assert
+	expectMetricLines(t, registry,
+		`payment_process_duration_seconds_count{processor="card",outcome="success"} 1`,
+		`payment_process_duration_seconds_count{processor="card",outcome="failure"} 1`,
+		fmt.Sprintf(`payment_fees_total{processor="card",currency="USD"} %s`, formatMetricValue(payment.Fee.Float64())),
+		fmt.Sprintf(`payment_fees_reversed_total{processor="card",currency="USD"} %s`, formatMetricValue(refund.FeeReversed.Float64())),
+	)
+}
+
+func TestOrderMetrics_ObserveDiscount_UnknownCustomerType_ReportedAsOther(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	metrics := NewOrderMetrics(registry)
+	discount := DiscountResult{OriginalAmount: MustParseMoney("100.00", USD), TotalDiscount: MustParseMoney("10.00", USD)}
+
+	// Act
+	for i := 0; i < 3; i++ {
+		metrics.ObserveDiscount(fmt.Sprintf("made-up-%d", i), discount)
+	}
+	metrics.ObserveDiscount("regular", discount)
+
+	// Assert
+	expectMetricLines(t, registry,
+		`order_discount_rate_count{customer_type="other"} 3`,
+		`order_discount_rate_count{customer_type="regular"} 1`,
+	)
+	if text := writeMetricsText(t, registry); strings.Contains(text, "made-up") {
+		t.Errorf("Expected no series per made-up customer type, got:\n%s", text)
+	}
+}
+
+func TestOrderMetrics_OrderStarted_TracksInFlight(t *testing.T) {
+	// Arrange
+	registry := NewMetricsRegistry()
+	metrics := NewOrderMetrics(registry)
+
+	// Act
+	first := metrics.OrderStarted()
+	metrics.OrderStarted()
+	first()
+	metrics.ObserveOrder("paid", 30*time.Millisecond)
+
+	// Assert
+	expectMetricLines(t, registry,
+		`orders_in_flight 1`,
+		`order_process_duration_seconds_bucket{outcome="paid",le="0.025"} 0`,
+		`order_process_duration_seconds_bucket{outcome="paid",le="0.05"} 1`,
+	)
+}
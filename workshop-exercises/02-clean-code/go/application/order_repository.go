@@ -0,0 +1,174 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// ORDER REPOSITORY
+// Orders processed by OrderService, kept so they can be looked up, refunded,
+// captured or cancelled later
+// =============================================================================
+
+var (
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotRefundable  = errors.New("order cannot be refunded")
+	ErrOrderNotCapturable  = errors.New("order cannot be captured")
+	ErrOrderNotCancellable = errors.New("order cannot be cancelled")
+)
+
+// OrderRepository stores the latest state of each order. Save inserts or
+// replaces the whole order, so status changes go through the service, which
+// checks them against the OrderStateMachine and records them in the history.
+type OrderRepository interface {
+	Save(order OrderResult) error
+	Get(orderID string) (OrderResult, error)
+	ListByCustomer(customer string) ([]OrderResult, error)
+}
+
+// =============================================================================
+// IN-MEMORY ORDER REPOSITORY
+// =============================================================================
+
+type InMemoryOrderRepository struct {
+	mu     sync.Mutex
+	state  *orderIndex
+	outbox *outboxIndex
+}
+
+func NewInMemoryOrderRepository() OrderRepository {
+	return NewInMemoryOutboxRepository()
+}
+
+func NewInMemoryOutboxRepository() OutboxRepository {
+	return &InMemoryOrderRepository{
+		state:  newOrderIndex(),
+		outbox: newOutboxIndex(),
+	}
+}
+
+func (r *InMemoryOrderRepository) Save(order OrderResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.put(order)
+	return nil
+}
+
+func (r *InMemoryOrderRepository) Get(orderID string) (OrderResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state.get(orderID)
+}
+
+func (r *InMemoryOrderRepository) ListByCustomer(customer string) ([]OrderResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state.listByCustomer(customer), nil
+}
+
+func (r *InMemoryOrderRepository) SaveWithOutbox(order OrderResult, messages []OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.put(order)
+	r.outbox.add(messages)
+	return nil
+}
+
+func (r *InMemoryOrderRepository) PendingOutbox(due time.Time, limit int) ([]OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outbox.pending(due, limit), nil
+}
+
+func (r *InMemoryOrderRepository) ParkedOutbox() ([]OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.outbox.parked(), nil
+}
+
+func (r *InMemoryOrderRepository) MarkOutboxDelivered(messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.outbox.check(messageID); err != nil {
+		return err
+	}
+	r.outbox.remove(messageID)
+	return nil
+}
+
+func (r *InMemoryOrderRepository) RecordOutboxFailure(message OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.outbox.check(message.ID); err != nil {
+		return err
+	}
+	r.outbox.update(message)
+	return nil
+}
+
+// orderIndex is the state shared by the repository implementations: orders by
+// ID plus each customer's order IDs in the order they were first saved.
+type orderIndex struct {
+	orders         map[string]OrderResult
+	customerOrders map[string][]string
+}
+
+func newOrderIndex() *orderIndex {
+	return &orderIndex{
+		orders:         make(map[string]OrderResult),
+		customerOrders: make(map[string][]string),
+	}
+}
+
+func (i *orderIndex) put(order OrderResult) {
+	previous, exists := i.orders[order.OrderID]
+	if exists && previous.Customer != order.Customer {
+		i.removeFromCustomer(previous.Customer, order.OrderID)
+	}
+	if !exists || previous.Customer != order.Customer {
+		i.customerOrders[order.Customer] = append(i.customerOrders[order.Customer], order.OrderID)
+	}
+	i.orders[order.OrderID] = cloneOrder(order)
+}
+
+func (i *orderIndex) removeFromCustomer(customer string, orderID string) {
+	ids := i.customerOrders[customer]
+	for index, id := range ids {
+		if id == orderID {
+			i.customerOrders[customer] = append(ids[:index:index], ids[index+1:]...)
+			return
+		}
+	}
+}
+
+func (i *orderIndex) get(orderID string) (OrderResult, error) {
+	order, ok := i.orders[orderID]
+	if !ok {
+		return OrderResult{}, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	return cloneOrder(order), nil
+}
+
+func (i *orderIndex) listByCustomer(customer string) []OrderResult {
+	ids := i.customerOrders[customer]
+	orders := make([]OrderResult, 0, len(ids))
+	for _, id := range ids {
+		orders = append(orders, cloneOrder(i.orders[id]))
+	}
+	return orders
+}
+
+// cloneOrder copies the refund and history slices so callers cannot change
+// stored orders through the value they were handed.
+func cloneOrder(order OrderResult) OrderResult {
+	if order.Refunds != nil {
+		order.Refunds = append([]RefundResult(nil), order.Refunds...)
+	}
+	if order.StatusHistory != nil {
+		order.StatusHistory = append([]StatusChange(nil), order.StatusHistory...)
+	}
+	return order
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"errors"
+	"testing"
+)
+
+// =============================================================================
+// ORDER REPOSITORY TESTS
+// Testing: order_repository.go
+// =============================================================================
+
+func newTestOrder(orderID string, customer string) OrderResult {
+	return OrderResult{
+		OrderID:        orderID,
+		Customer:       customer,
+		CustomerType:   "regular",
+		Status:         OrderStatusPaid,
+		OriginalAmount: MustParseMoney("100.00", USD),
+		FinalAmount:    MustParseMoney("95.00", USD),
+		RefundedAmount: ZeroMoney(USD),
+	}
+}
+
+func TestInMemoryOrderRepository_SaveAndGet_ReturnsOrder(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOrderRepository()
+
+	// Act
+	saveErr := repository.Save(newTestOrder("order_1", "alice@example.com"))
+	order, err := repository.Get("order_1")
+
+	// Assert
+	if saveErr != nil || err != nil {
+		t.Fatalf("Expected no errors, got %v / %v", saveErr, err)
+	}
+	if order.FinalAmount != MustParseMoney("95.00", USD) {
+		t.Errorf("Expected final amount 95.00, got %s", order.FinalAmount)
+	}
+}
+
+func TestInMemoryOrderRepository_Get_UnknownOrder_ReturnsNotFound(t *testing.T) {
+	// Act
+	_, err := NewInMemoryOrderRepository().Get("order_missing")
+
+	// Assert
+	if !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryOrderRepository_ListByCustomer_ReturnsCustomerOrdersInSaveOrder(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOrderRepository()
+	_ = repository.Save(newTestOrder("order_b", "alice@example.com"))
+	_ = repository.Save(newTestOrder("order_x", "bob@example.com"))
+	_ = repository.Save(newTestOrder("order_a", "alice@example.com"))
+	_ = repository.Save(newTestOrder("order_b", "alice@example.com"))
+
+	// Act
+	orders, err := repository.ListByCustomer("alice@example.com")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(orders) != 2 || orders[0].OrderID != "order_b" || orders[1].OrderID != "order_a" {
+		t.Errorf("Expected [order_b order_a], got %+v", orders)
+	}
+}
+
+func TestInMemoryOrderRepository_Get_ReturnsCopy(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOrderRepository()
+	order := newTestOrder("order_1", "alice@example.com")
+	order.Refunds = []RefundResult{{RefundID: "re_1"}}
+	_ = repository.Save(order)
+
+	// Act
+	loaded, _ := repository.Get("order_1")
+	loaded.Refunds[0].RefundID = "changed"
+	reloaded, _ := repository.Get("order_1")
+
+	// Assert
+	if reloaded.Refunds[0].RefundID != "re_1" {
+		t.Errorf("Expected stored order to be isolated from callers, got %s", reloaded.Refunds[0].RefundID)
+	}
+}
@@ -1,6 +1,8 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -13,36 +15,213 @@ import (
 type OrderService struct {
 	paymentProcessor PaymentProcessorInterface
 	discountService  DiscountServiceInterface
+	taxService       TaxServiceInterface
+	orderIDGenerator OrderIDGenerator
+	orders           OrderRepository
+	orderLocks       *keyedMutex
+	deferredCapture  deferredCapturePolicy
+	idempotencyStore IdempotencyStoreInterface
+	idempotencyTTL   time.Duration
+	idempotencyLocks *keyedMutex
+	clock            Clock
+	events           EventBusInterface
+	outbox           OutboxRepository
+	stateMachine     OrderStateMachineInterface
+	auditor          AuditorInterface
+	metrics          OrderMetricsInterface
 }
 
-func NewOrderService(paymentProcessor PaymentProcessorInterface, discountService DiscountServiceInterface) OrderServiceInterface {
-	return &OrderService{
+func NewOrderService(paymentProcessor PaymentProcessorInterface, discountService DiscountServiceInterface, taxService TaxServiceInterface, orderIDGenerator OrderIDGenerator, options ...OrderServiceOption) OrderServiceInterface {
+	service := &OrderService{
 		paymentProcessor: paymentProcessor,
 		discountService:  discountService,
+		taxService:       taxService,
+		orderIDGenerator: orderIDGenerator,
+		orders:           NewInMemoryOrderRepository(),
+		orderLocks:       newKeyedMutex(),
+		idempotencyStore: NewInMemoryIdempotencyStore(),
+		idempotencyTTL:   DefaultIdempotencyTTL,
+		idempotencyLocks: newKeyedMutex(),
+		clock:            NewSystemClock(),
+		events:           NewEventBus(),
+		stateMachine:     NewOrderStateMachine(),
 	}
+	for _, option := range options {
+		option(service)
+	}
+	return service
 }
 
-func (s *OrderService) ProcessOrder(order OrderData) (string, error) {
-	return s.executeOrderProcessing(order)
+func (s *OrderService) ProcessOrder(ctx context.Context, order OrderData) (OrderResult, error) {
+	finished := s.startOrderTimer()
+	result, err := s.processOrder(ctx, order)
+	finished(result, err)
+	s.auditPlacedOrder(ctx, order, result, err)
+	if err != nil && !paymentWentThrough(result) {
+		return OrderResult{}, err
+	}
+	return result, err
 }
 
-func (s *OrderService) executeOrderProcessing(order OrderData) (string, error) {
+func (s *OrderService) processOrder(ctx context.Context, order OrderData) (OrderResult, error) {
+	if order.IdempotencyKey != "" {
+		return s.executeIdempotentOrderProcessing(ctx, order)
+	}
+	return s.executeOrderProcessing(ctx, order)
+}
+
+func (s *OrderService) executeOrderProcessing(ctx context.Context, order OrderData) (OrderResult, error) {
+	order, err := s.resolveOrderTotal(order)
+	if err != nil {
+		return OrderResult{}, s.handleValidationError(err)
+	}
+
 	if err := s.validateOrder(order); err != nil {
-		return "", s.handleValidationError(err)
+		return OrderResult{}, s.handleValidationError(err)
+	}
+
+	orderID, err := s.generateOrderId()
+	if err != nil {
+		return OrderResult{}, err
 	}
+	validated := s.newOrderValidated(orderID, order)
+	s.publishStep(ctx, validated)
 
-	discountedAmount, err := s.calculateDiscountedAmount(order)
+	discount, err := s.calculateDiscount(ctx, order)
 	if err != nil {
-		return "", s.handleDiscountError(err)
+		return s.unplacedOrder(orderID, order.Amount), s.handleDiscountError(err)
 	}
+	applied := s.newDiscountApplied(orderID, order, discount)
+	s.publishStep(ctx, applied)
+	s.observeDiscount(order, discount)
 
-	paymentResult, err := s.processPayment(discountedAmount)
+	tax, err := s.calculateTax(ctx, order, discount)
 	if err != nil {
-		return "", s.handlePaymentError(err)
+		return s.unplacedOrder(orderID, order.Amount), err
+	}
+
+	if err := s.redeemDiscount(ctx, order, discount); err != nil {
+		return s.unplacedOrder(orderID, tax.GrossAmount), err
+	}
+
+	pending, err := s.storePendingOrder(orderID, order, discount, tax, validated, applied)
+	if err != nil {
+		return s.unplacedOrder(orderID, tax.GrossAmount), s.releaseDiscount(ctx, order, discount, err)
+	}
+
+	paymentResult, err := s.processPayment(ctx, orderID, order, tax.GrossAmount)
+	if err != nil {
+		failed := s.newPaymentFailed(orderID, order, tax.GrossAmount, err)
+		paymentErr := s.recordPaymentFailure(ctx, failed, s.handlePaymentError(err))
+		return s.unplacedOrder(orderID, tax.GrossAmount), s.releaseDiscount(ctx, order, discount, paymentErr)
+	}
+
+	result, err := s.buildSuccessResult(pending, paymentResult)
+	if err != nil {
+		return s.unplacedOrder(orderID, tax.GrossAmount), err
+	}
+	return s.storeOrder(ctx, result)
+}
+
+// unplacedOrder is what is known of an order that failed after it got its
+// ID: the ID and the total so far. It is only for the audit log; the caller
+// of ProcessOrder gets an empty result with the error (see
+// paymentWentThrough).
+func (s *OrderService) unplacedOrder(orderID string, amount Money) OrderResult {
+	return OrderResult{OrderID: orderID, FinalAmount: amount}
+}
+
+// The order is written before the processor is called so that a payment in
+// flight always has an order record, even if the process dies mid-call.
+// Its history starts at created: by now the order has been validated and is
+// about to be paid for. steps are the events published so far, which an
+// outbox stores with the order.
+func (s *OrderService) storePendingOrder(orderID string, order OrderData, discount DiscountResult, tax TaxResult, steps ...DomainEvent) (OrderResult, error) {
+	now := s.clock.Now()
+	pending := OrderResult{
+		OrderID:        orderID,
+		Customer:       order.Customer,
+		CustomerType:   order.CustomerType,
+		Status:         OrderStatusCreated,
+		OriginalAmount: order.Amount,
+		FinalAmount:    tax.GrossAmount,
+		Lines:          discount.Lines,
+		Discounts:      discount.Discounts,
+		Tax:            tax,
+		RefundedAmount: ZeroMoney(tax.GrossAmount.Currency()),
+		StatusHistory:  newOrderHistory(now),
+	}
+	if err := s.transition(&pending, OrderStatusValidated, "order validated"); err != nil {
+		return OrderResult{}, err
+	}
+	if err := s.transition(&pending, OrderStatusPending, "payment started"); err != nil {
+		return OrderResult{}, err
+	}
+	if err := s.savePendingOrder(pending, steps); err != nil {
+		return OrderResult{}, s.wrapStorageError(err)
+	}
+	return pending, nil
+}
+
+func (s *OrderService) recordPaymentFailure(ctx context.Context, failed PaymentFailed, paymentErr error) error {
+	if err := s.saveFailedOrder(ctx, failed); err != nil {
+		return errors.Join(paymentErr, s.wrapStorageError(err))
+	}
+	return paymentErr
+}
+
+// A storage failure after a successful payment still hands back the result so
+// the caller knows the customer was charged.
+func (s *OrderService) storeOrder(ctx context.Context, result OrderResult) (OrderResult, error) {
+	if err := s.saveCompletedOrder(ctx, result); err != nil {
+		return result, s.wrapStorageError(err)
+	}
+	return result, nil
+}
+
+func (s *OrderService) wrapStorageError(err error) error {
+	return fmt.Errorf("order storage failed: %w", err)
+}
+
+func (s *OrderService) GetOrder(orderID string) (OrderResult, error) {
+	return s.orders.Get(orderID)
+}
+
+func (s *OrderService) ListCustomerOrders(customer string) ([]OrderResult, error) {
+	return s.orders.ListByCustomer(customer)
+}
+
+// updateOrder serialises read-modify-write cycles per order so concurrent
+// refunds or captures on the same order cannot overwrite each other. An
+// error from change leaves the stored order as it was.
+func (s *OrderService) updateOrder(orderID string, change func(order *OrderResult) error) (OrderResult, error) {
+	unlock := s.orderLocks.lock(orderID)
+	defer unlock()
+
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		return OrderResult{}, err
+	}
+	if err := change(&order); err != nil {
+		return OrderResult{}, err
 	}
+	if err := s.orders.Save(order); err != nil {
+		return OrderResult{}, s.wrapStorageError(err)
+	}
+	return order, nil
+}
 
-	orderID := s.generateOrderId()
-	return s.formatSuccessResult(orderID, paymentResult, discountedAmount), nil
+func (s *OrderService) transition(order *OrderResult, to OrderStatus, reason string) error {
+	return s.stateMachine.Transition(order, to, reason, s.clock.Now())
+}
+
+func (s *OrderService) resolveOrderTotal(order OrderData) (OrderData, error) {
+	amount, err := resolveOrderAmount(order.Amount, order.LineItems)
+	if err != nil {
+		return OrderData{}, err
+	}
+	order.Amount = amount
+	return order, nil
 }
 
 func (s *OrderService) validateOrder(order OrderData) error {
@@ -50,18 +229,36 @@ func (s *OrderService) validateOrder(order OrderData) error {
 }
 
 func (s *OrderService) performOrderValidation(order OrderData) error {
+	if err := s.validateCurrency(order.Amount.Currency()); err != nil {
+		return err
+	}
 	if err := s.validateAmount(order.Amount); err != nil {
 		return err
 	}
 	return s.validateCustomer(order.Customer)
 }
 
-func (s *OrderService) validateAmount(amount float64) error {
+func (s *OrderService) validateCurrency(currency Currency) error {
+	return s.checkCurrencyIsSupported(currency)
+}
+
+func (s *OrderService) checkCurrencyIsSupported(currency Currency) error {
+	if !currency.IsKnown() {
+		return s.createCurrencyError(currency)
+	}
+	return nil
+}
+
+func (s *OrderService) createCurrencyError(currency Currency) error {
+	return fmt.Errorf("unsupported currency %q", currency)
+}
+
+func (s *OrderService) validateAmount(amount Money) error {
 	return s.checkAmountIsPositive(amount)
 }
 
-func (s *OrderService) checkAmountIsPositive(amount float64) error {
-	if amount <= 0 {
+func (s *OrderService) checkAmountIsPositive(amount Money) error {
+	if !amount.IsPositive() {
 		return s.createAmountError()
 	}
 	return nil
@@ -86,52 +283,146 @@ func (s *OrderService) createCustomerError() error {
 	return fmt.Errorf("customer cannot be empty")
 }
 
-func (s *OrderService) calculateDiscountedAmount(order OrderData) (float64, error) {
-	return s.executeDiscountCalculation(order.Amount, order.CustomerType)
+func (s *OrderService) calculateDiscount(ctx context.Context, order OrderData) (DiscountResult, error) {
+	return s.executeDiscountCalculation(ctx, s.buildDiscountRequest(order))
+}
+
+func (s *OrderService) buildDiscountRequest(order OrderData) DiscountRequest {
+	return DiscountRequest{
+		Amount:       order.Amount,
+		LineItems:    order.LineItems,
+		Customer:     order.Customer,
+		CustomerType: order.CustomerType,
+		CouponCodes:  order.CouponCodes,
+	}
+}
+
+func (s *OrderService) executeDiscountCalculation(ctx context.Context, request DiscountRequest) (DiscountResult, error) {
+	result, err := s.discountService.CalculateDiscount(ctx, request)
+	if err != nil {
+		return DiscountResult{}, s.wrapDiscountError(err)
+	}
+	return result, nil
 }
 
-func (s *OrderService) executeDiscountCalculation(amount float64, customerType string) (float64, error) {
-	discountedAmount, err := s.discountService.CalculateDiscount(amount, customerType)
+// Tax is worked out on the discounted lines, so a discount lowers the tax.
+func (s *OrderService) calculateTax(ctx context.Context, order OrderData, discount DiscountResult) (TaxResult, error) {
+	tax, err := s.taxService.CalculateTax(ctx, s.buildTaxRequest(order, discount))
 	if err != nil {
-		return 0, s.wrapDiscountError(err)
+		return TaxResult{}, s.wrapTaxError(err)
 	}
-	return discountedAmount, nil
+	return tax, nil
+}
+
+func (s *OrderService) buildTaxRequest(order OrderData, discount DiscountResult) TaxRequest {
+	return TaxRequest{
+		Country:          order.Country,
+		Region:           order.Region,
+		VATID:            order.VATID,
+		PricesIncludeTax: order.PricesIncludeTax,
+		Amount:           discount.DiscountedAmount,
+		Lines:            s.buildTaxableLines(order, discount),
+	}
+}
+
+func (s *OrderService) buildTaxableLines(order OrderData, discount DiscountResult) []TaxableLine {
+	categories := make(map[string]string, len(order.LineItems))
+	for _, item := range order.LineItems {
+		categories[item.ProductID] = item.TaxCategory
+	}
+	lines := make([]TaxableLine, 0, len(discount.Lines))
+	for _, line := range discount.Lines {
+		lines = append(lines, TaxableLine{
+			ProductID:   line.ProductID,
+			TaxCategory: categories[line.ProductID],
+			Amount:      line.Total,
+		})
+	}
+	return lines
+}
+
+func (s *OrderService) wrapTaxError(err error) error {
+	return fmt.Errorf("tax calculation failed: %w", err)
+}
+
+func (s *OrderService) redeemDiscount(ctx context.Context, order OrderData, discount DiscountResult) error {
+	if err := s.discountService.RedeemDiscount(ctx, order.Customer, discount); err != nil {
+		return fmt.Errorf("coupon redemption failed: %w", err)
+	}
+	return nil
+}
+
+// releaseDiscount hands coupon redemptions back after the order failed with
+// cause. It runs even when ctx is already cancelled.
+func (s *OrderService) releaseDiscount(ctx context.Context, order OrderData, discount DiscountResult, cause error) error {
+	if err := s.discountService.ReleaseDiscount(context.WithoutCancel(ctx), order.Customer, discount); err != nil {
+		return errors.Join(cause, fmt.Errorf("coupon release failed: %w", err))
+	}
+	return cause
 }
 
 func (s *OrderService) wrapDiscountError(err error) error {
 	return fmt.Errorf("discount calculation failed: %w", err)
 }
 
-func (s *OrderService) processPayment(amount float64) (string, error) {
-	return s.executePaymentProcessing(amount)
+func (s *OrderService) processPayment(ctx context.Context, orderID string, order OrderData, amount Money) (PaymentResult, error) {
+	request := s.buildPaymentRequest(orderID, order, amount)
+	if s.deferredCapture.appliesTo(order) {
+		return s.executePaymentAuthorization(ctx, request)
+	}
+	return s.executePaymentProcessing(ctx, request)
+}
+
+func (s *OrderService) buildPaymentRequest(orderID string, order OrderData, amount Money) PaymentRequest {
+	return PaymentRequest{
+		Amount:             amount,
+		OrderID:            orderID,
+		Customer:           order.Customer,
+		CustomerType:       order.CustomerType,
+		PreferredProcessor: order.PreferredProcessor,
+		Card:               order.Card,
+		Country:            order.Country,
+	}
 }
 
-func (s *OrderService) executePaymentProcessing(amount float64) (string, error) {
-	result, err := s.paymentProcessor.ProcessPayment(amount)
+func (s *OrderService) executePaymentAuthorization(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	result, err := s.paymentProcessor.Authorize(ctx, request)
 	if err != nil {
-		return "", s.wrapPaymentError(err)
+		return PaymentResult{}, s.wrapPaymentError(err)
 	}
 	return result, nil
 }
 
-func (s *OrderService) wrapPaymentError(err error) error {
-	return fmt.Errorf("payment processing failed: %w", err)
+func (s *OrderService) executePaymentProcessing(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	result, err := s.paymentProcessor.ProcessPayment(ctx, request)
+	if err != nil {
+		return PaymentResult{}, s.wrapPaymentError(err)
+	}
+	return result, nil
 }
 
-func (s *OrderService) generateOrderId() string {
-	return s.createOrderIdentifier()
+func (s *OrderService) wrapPaymentError(err error) error {
+	if s.isCancellation(err) {
+		return err
+	}
+	return fmt.Errorf("payment processing failed: %w", err)
 }
 
-func (s *OrderService) createOrderIdentifier() string {
-	return s.formatOrderId(s.getCurrentTimestamp())
+func (s *OrderService) isCancellation(err error) bool {
+	var cancelled *PaymentCancelledError
+	return errors.As(err, &cancelled)
 }
 
-func (s *OrderService) getCurrentTimestamp() int64 {
-	return time.Now().Unix()
+func (s *OrderService) generateOrderId() (string, error) {
+	orderID, err := s.orderIDGenerator.NewOrderID()
+	if err != nil {
+		return "", s.wrapOrderIDError(err)
+	}
+	return orderID, nil
 }
 
-func (s *OrderService) formatOrderId(timestamp int64) string {
-	return fmt.Sprintf("order_%d", timestamp)
+func (s *OrderService) wrapOrderIDError(err error) error {
+	return fmt.Errorf("order ID generation failed: %w", err)
 }
 
 func (s *OrderService) handleValidationError(err error) error {
@@ -158,10 +449,245 @@ func (s *OrderService) wrapPaymentFailureError(err error) error {
 	return err
 }
 
-func (s *OrderService) formatSuccessResult(orderID string, paymentResult string, amount float64) string {
-	return s.createSuccessMessage(orderID, paymentResult, amount)
+func (s *OrderService) buildSuccessResult(pending OrderResult, paymentResult PaymentResult) (OrderResult, error) {
+	return s.createOrderResult(pending, paymentResult)
+}
+
+func (s *OrderService) createOrderResult(pending OrderResult, paymentResult PaymentResult) (OrderResult, error) {
+	result := pending
+	result.Payment = paymentResult
+	result.RefundedAmount = ZeroMoney(paymentResult.GrossAmount.Currency())
+	status := s.determineOrderStatus(paymentResult)
+	if err := s.transition(&result, status, "payment "+string(paymentResult.Status)); err != nil {
+		return OrderResult{}, err
+	}
+	return result, nil
+}
+
+func (s *OrderService) determineOrderStatus(paymentResult PaymentResult) OrderStatus {
+	if paymentResult.Status == PaymentStatusAuthorized {
+		return OrderStatusAuthorized
+	}
+	return OrderStatusPaid
+}
+
+func (s *OrderService) RefundOrder(ctx context.Context, orderID string, amount Money) (OrderResult, error) {
+	result, err := s.executeOrderRefund(ctx, orderID, amount)
+	s.auditRefund(ctx, orderID, amount, result, err)
+	return result, err
+}
+
+func (s *OrderService) executeOrderRefund(ctx context.Context, orderID string, amount Money) (OrderResult, error) {
+	order, err := s.findRefundableOrder(orderID)
+	if err != nil {
+		return OrderResult{}, err
+	}
+	// Refunds the provider reported by webhook are on the order but not in
+	// the processor's ledger, so the order is what bounds the amount, and
+	// what a full refund (a zero amount) gives back.
+	amount, err = s.refundableAmount(order, amount)
+	if err != nil {
+		return OrderResult{}, s.wrapRefundError(err)
+	}
+
+	refund, err := s.refundPayment(ctx, order.Payment.TransactionID, amount)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	return s.recordRefund(orderID, refund)
+}
+
+func (s *OrderService) findRefundableOrder(orderID string) (OrderResult, error) {
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		return OrderResult{}, err
+	}
+	return order, s.checkOrderIsRefundable(order)
+}
+
+func (s *OrderService) checkOrderIsRefundable(order OrderResult) error {
+	if order.Status != OrderStatusPaid && order.Status != OrderStatusFulfilled {
+		return s.createNotRefundableError(order)
+	}
+	return nil
+}
+
+func (s *OrderService) createNotRefundableError(order OrderResult) error {
+	return fmt.Errorf("%w: order %s is %s", ErrOrderNotRefundable, order.OrderID, order.Status)
+}
+
+func (s *OrderService) refundableAmount(order OrderResult, amount Money) (Money, error) {
+	if amount.IsZero() {
+		return order.Payment.GrossAmount.Subtract(order.RefundedAmount)
+	}
+	if _, err := s.remainingAfterRefund(order, amount); err != nil {
+		return Money{}, err
+	}
+	return amount, nil
+}
+
+func (s *OrderService) refundPayment(ctx context.Context, transactionID string, amount Money) (RefundResult, error) {
+	refund, err := s.paymentProcessor.Refund(ctx, NewPartialRefundRequest(transactionID, amount))
+	if err != nil {
+		return RefundResult{}, s.wrapRefundError(err)
+	}
+	return refund, nil
+}
+
+func (s *OrderService) wrapRefundError(err error) error {
+	return s.wrapOperationError("refund", err)
+}
+
+func (s *OrderService) wrapOperationError(operation string, err error) error {
+	if s.isCancellation(err) {
+		return err
+	}
+	return fmt.Errorf("%s failed: %w", operation, err)
+}
+
+func (s *OrderService) recordRefund(orderID string, refund RefundResult) (OrderResult, error) {
+	return s.updateOrder(orderID, func(order *OrderResult) error {
+		return s.applyRefund(order, refund, "refund "+refund.RefundID)
+	})
+}
+
+// applyRefund records refund on the order; the one that leaves nothing to
+// refund moves it to refunded. What is left is counted on the order, which
+// also knows the refunds the processor's ledger missed.
+func (s *OrderService) applyRefund(order *OrderResult, refund RefundResult, reason string) error {
+	if remaining, err := s.remainingAfterRefund(*order, refund.Amount); err == nil {
+		refund.RemainingRefundable = remaining
+	}
+	if refund.RemainingRefundable.IsZero() {
+		if err := s.transition(order, OrderStatusRefunded, reason); err != nil {
+			return err
+		}
+	}
+	s.addRefund(order, refund)
+	return nil
 }
 
-func (s *OrderService) createSuccessMessage(orderID string, paymentResult string, amount float64) string {
-	return fmt.Sprintf("Order %s completed: %s (Final: $%.2f)", orderID, paymentResult, amount)
-}
\ No newline at end of file
+func (s *OrderService) addRefund(order *OrderResult, refund RefundResult) {
+	order.Refunds = append(order.Refunds, refund)
+	order.RefundedAmount, _ = order.RefundedAmount.Add(refund.Amount)
+}
+
+func (s *OrderService) CaptureOrder(ctx context.Context, orderID string, amount Money) (OrderResult, error) {
+	result, err := s.executeOrderCapture(ctx, orderID, amount)
+	s.auditOrder(ctx, AuditActionOrderCapture, orderID, amount, result, err)
+	return result, err
+}
+
+func (s *OrderService) executeOrderCapture(ctx context.Context, orderID string, amount Money) (OrderResult, error) {
+	order, err := s.findOrderInStatus(orderID, OrderStatusAuthorized, ErrOrderNotCapturable)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	capture, err := s.capturePayment(ctx, order.Payment.TransactionID, amount)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	return s.recordCapture(orderID, capture)
+}
+
+func (s *OrderService) findOrderInStatus(orderID string, status OrderStatus, statusError error) (OrderResult, error) {
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		return OrderResult{}, err
+	}
+	if order.Status != status {
+		return OrderResult{}, fmt.Errorf("%w: order %s is %s", statusError, order.OrderID, order.Status)
+	}
+	return order, nil
+}
+
+func (s *OrderService) capturePayment(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	capture, err := s.paymentProcessor.Capture(ctx, authorizationID, amount)
+	if err != nil {
+		return PaymentResult{}, s.wrapOperationError("capture", err)
+	}
+	return capture, nil
+}
+
+func (s *OrderService) recordCapture(orderID string, capture PaymentResult) (OrderResult, error) {
+	return s.updateOrder(orderID, func(order *OrderResult) error {
+		if err := s.transition(order, OrderStatusPaid, "captured "+capture.GrossAmount.String()); err != nil {
+			return err
+		}
+		order.Payment = capture
+		order.FinalAmount = capture.NetAmount
+		return nil
+	})
+}
+
+func (s *OrderService) CancelOrder(ctx context.Context, orderID string) (OrderResult, error) {
+	result, err := s.executeOrderCancellation(ctx, orderID)
+	s.auditOrder(ctx, AuditActionOrderCancel, orderID, Money{}, result, err)
+	return result, err
+}
+
+func (s *OrderService) executeOrderCancellation(ctx context.Context, orderID string) (OrderResult, error) {
+	order, err := s.orders.Get(orderID)
+	if err != nil {
+		return OrderResult{}, err
+	}
+
+	switch {
+	case order.Status == OrderStatusAuthorized:
+		return s.releaseOrderAuthorization(ctx, order)
+	case order.Status == OrderStatusPaid && len(order.Refunds) == 0:
+		return s.voidOrderPayment(ctx, order)
+	default:
+		return OrderResult{}, fmt.Errorf("%w: order %s is %s", ErrOrderNotCancellable, order.OrderID, order.Status)
+	}
+}
+
+// FulfillOrder marks a paid order as shipped. It can still be refunded, but
+// no longer cancelled.
+func (s *OrderService) FulfillOrder(ctx context.Context, orderID string) (OrderResult, error) {
+	result, err := s.executeOrderFulfillment(orderID)
+	s.auditOrder(ctx, AuditActionOrderFulfill, orderID, Money{}, result, err)
+	return result, err
+}
+
+// Only a paid order ships. Disputed -> fulfilled exists for a won dispute
+// on an order that had already shipped, not for shipping under dispute.
+func (s *OrderService) executeOrderFulfillment(orderID string) (OrderResult, error) {
+	return s.updateOrder(orderID, func(order *OrderResult) error {
+		if order.Status == OrderStatusDisputed {
+			return &IllegalTransitionError{OrderID: order.OrderID, From: order.Status, To: OrderStatusFulfilled}
+		}
+		return s.transition(order, OrderStatusFulfilled, "order fulfilled")
+	})
+}
+
+func (s *OrderService) releaseOrderAuthorization(ctx context.Context, order OrderResult) (OrderResult, error) {
+	release, err := s.paymentProcessor.ReleaseAuthorization(ctx, order.Payment.TransactionID)
+	if err != nil {
+		return OrderResult{}, s.wrapOperationError("authorization release", err)
+	}
+	return s.updateOrder(order.OrderID, func(order *OrderResult) error {
+		if err := s.transition(order, OrderStatusCancelled, "authorization released"); err != nil {
+			return err
+		}
+		order.Payment = release
+		return nil
+	})
+}
+
+func (s *OrderService) voidOrderPayment(ctx context.Context, order OrderResult) (OrderResult, error) {
+	void, err := s.paymentProcessor.Void(ctx, order.Payment.TransactionID)
+	if err != nil {
+		return OrderResult{}, s.wrapOperationError("void", err)
+	}
+	return s.updateOrder(order.OrderID, func(order *OrderResult) error {
+		if err := s.transition(order, OrderStatusCancelled, "payment voided"); err != nil {
+			return err
+		}
+		s.addRefund(order, void)
+		return nil
+	})
+}
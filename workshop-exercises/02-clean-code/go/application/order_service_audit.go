@@ -0,0 +1,74 @@
+package application
+
+import "context"
+
+// =============================================================================
+// ORDER SERVICE - AUDIT
+// Records each operation on the auditor, when one was configured
+// =============================================================================
+
+// auditPlacedOrder records the order's total after discounts and tax, not
+// the amount on the request, which is empty for an order of line items. An
+// order that failed is recorded with as much as was known by then.
+func (s *OrderService) auditPlacedOrder(ctx context.Context, order OrderData, result OrderResult, err error) {
+	if s.auditor == nil {
+		return
+	}
+	entry := AuditEntry{
+		Action:        AuditActionOrderPlace,
+		OrderID:       result.OrderID,
+		TransactionID: result.Payment.TransactionID,
+		Processor:     result.Payment.ProcessorType,
+		Amount:        result.FinalAmount,
+		Fee:           result.Payment.Fee,
+	}
+	if result.OrderID == "" {
+		entry.Amount = order.Amount
+		if amount, resolveErr := resolveOrderAmount(order.Amount, order.LineItems); resolveErr == nil {
+			entry.Amount = amount
+		}
+	}
+	s.auditor.Record(ctx, entry, err)
+}
+
+// auditOrder takes the amounts from the payment on result; a failed
+// operation has no result and records the amount that was asked for.
+func (s *OrderService) auditOrder(ctx context.Context, action AuditAction, orderID string, requested Money, result OrderResult, err error) {
+	if s.auditor == nil {
+		return
+	}
+	entry := paymentAuditEntry(action, orderID, "", requested, result.Payment)
+	if result.OrderID != "" {
+		entry.OrderID = result.OrderID
+	}
+	s.auditor.Record(ctx, entry, err)
+}
+
+// auditRefund records the refund the operation added to the order.
+func (s *OrderService) auditRefund(ctx context.Context, orderID string, requested Money, result OrderResult, err error) {
+	if s.auditor == nil {
+		return
+	}
+	var refund RefundResult
+	if err == nil && len(result.Refunds) > 0 {
+		refund = result.Refunds[len(result.Refunds)-1]
+	}
+	entry := refundAuditEntry(AuditActionOrderRefund, result.Payment.TransactionID, requested, refund)
+	entry.OrderID = orderID
+	s.auditor.Record(ctx, entry, err)
+}
+
+func (s *OrderService) auditPaymentEvent(ctx context.Context, event PaymentEvent, result OrderResult, err error) {
+	if s.auditor == nil {
+		return
+	}
+	entry := AuditEntry{
+		Action:        AuditActionPaymentEvent,
+		OrderID:       event.Data.OrderID,
+		TransactionID: result.Payment.TransactionID,
+		Processor:     result.Payment.ProcessorType,
+		Amount:        event.Data.Amount,
+		Detail:        s.describeEvent(event),
+	}
+	s.auditor.Record(ctx, entry, err)
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// =============================================================================
+// ORDER SERVICE AUDIT TESTS
+// Testing: order_service_audit.go
+// =============================================================================
+
+func TestOrderService_WithAuditor_RecordsOperationsByActor(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	orderService := NewOrderService(NewCreditCardProcessor(), NewMockDiscountService(false, MustParseMoney("100.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithAuditor(NewAuditor(log, AuditorConfig{})))
+	ctx := WithAuditActor(context.Background(), "alice")
+
+	// Act
+	placed, _ := orderService.ProcessOrder(ctx, OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+	orderService.RefundOrder(ctx, placed.OrderID, MustParseMoney("30.00", USD))
+	orderService.CancelOrder(ctx, placed.OrderID)
+
+	// Assert
+	entries, _ := log.Query(AuditQuery{OrderID: placed.OrderID})
+	want := []struct {
+		action  AuditAction
+		outcome AuditOutcome
+	}{
+		{AuditActionOrderPlace, AuditOutcomeSucceeded},
+		{AuditActionOrderRefund, AuditOutcomeSucceeded},
+		{AuditActionOrderCancel, AuditOutcomeFailed},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), entries)
+	}
+	for i, entry := range entries {
+		if entry.Action != want[i].action || entry.Outcome != want[i].outcome || entry.Actor != "alice" {
+			t.Errorf("Expected alice's %s to have %s, got %+v", want[i].action, want[i].outcome, entry)
+		}
+	}
+	if entries[0].Amount != placed.FinalAmount || entries[0].Fee != placed.Payment.Fee {
+		t.Errorf("Expected the order total and the fee charged, got %s / %s", entries[0].Amount, entries[0].Fee)
+	}
+	if entries[1].Amount != MustParseMoney("30.00", USD) || entries[1].Fee.IsZero() {
+		t.Errorf("Expected the refunded amount and fee given back, got %s / %s", entries[1].Amount, entries[1].Fee)
+	}
+}
+
+func TestOrderService_WithAuditor_FailedPayment_IsRecorded(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	orderService := NewOrderService(NewFailingMockPaymentProcessor(errors.New("card declined")), NewMockDiscountService(false, MustParseMoney("100.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithAuditor(NewAuditor(log, AuditorConfig{})))
+
+	// Act
+	orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	entries, _ := log.Query(AuditQuery{})
+	if len(entries) != 1 || entries[0].Outcome != AuditOutcomeFailed || entries[0].Actor != DefaultAuditActor || entries[0].Amount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected the failed order recorded by the system, got %+v", entries)
+	}
+}
+
+func TestOrderService_WithAuditor_FailedLineItemOrder_RecordsIDAndTotalAfterDiscount(t *testing.T) {
+	// Arrange
+	log := NewInMemoryAuditLog()
+	orderService := NewOrderService(NewFailingMockPaymentProcessor(errors.New("card declined")), NewMockDiscountService(false, MustParseMoney("90.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithAuditor(NewAuditor(log, AuditorConfig{})))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		LineItems:    []LineItem{{ProductID: "SKU-1", Quantity: 2, UnitPrice: MustParseMoney("50.00", USD)}},
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err == nil || result.OrderID != "" {
+		t.Fatalf("Expected the failure with an empty result, got %+v / %v", result, err)
+	}
+	entries, _ := log.Query(AuditQuery{})
+	if len(entries) != 1 || entries[0].OrderID == "" || entries[0].Amount != MustParseMoney("90.00", USD) {
+		t.Errorf("Expected the failed order recorded under its ID with the 90.00 total, got %+v", entries)
+	}
+	if stored, getErr := orderService.GetOrder(entries[0].OrderID); getErr != nil || stored.Status != OrderStatusFailed {
+		t.Errorf("Expected the audited ID to be the stored failed order, got %+v / %v", stored, getErr)
+	}
+}
@@ -0,0 +1,63 @@
+package application
+
+import "context"
+
+// =============================================================================
+// ORDER SERVICE - DOMAIN EVENTS
+// Announces each processing step on the event bus
+// =============================================================================
+
+// Publishing cannot fail an order: subscriber failures stay on the bus.
+
+// publishStep announces a step before the order is stored. With an outbox it
+// is left for storePendingOrder to write together with the pending order.
+func (s *OrderService) publishStep(ctx context.Context, event DomainEvent) {
+	if s.outbox == nil {
+		s.events.Publish(ctx, event)
+	}
+}
+
+func (s *OrderService) newOrderValidated(orderID string, order OrderData) OrderValidated {
+	return OrderValidated{
+		OrderEvent:   s.newOrderEvent(orderID, order.Customer),
+		CustomerType: order.CustomerType,
+		Amount:       order.Amount,
+	}
+}
+
+func (s *OrderService) newDiscountApplied(orderID string, order OrderData, discount DiscountResult) DiscountApplied {
+	return DiscountApplied{
+		OrderEvent:       s.newOrderEvent(orderID, order.Customer),
+		OriginalAmount:   discount.OriginalAmount,
+		TotalDiscount:    discount.TotalDiscount,
+		DiscountedAmount: discount.DiscountedAmount,
+		Discounts:        discount.Discounts,
+	}
+}
+
+func (s *OrderService) newPaymentSucceeded(result OrderResult) PaymentSucceeded {
+	return PaymentSucceeded{
+		OrderEvent: s.newOrderEvent(result.OrderID, result.Customer),
+		Payment:    result.Payment,
+	}
+}
+
+func (s *OrderService) newPaymentFailed(orderID string, order OrderData, amount Money, err error) PaymentFailed {
+	return PaymentFailed{
+		OrderEvent: s.newOrderEvent(orderID, order.Customer),
+		Amount:     amount,
+		Reason:     err.Error(),
+	}
+}
+
+func (s *OrderService) newOrderCompleted(result OrderResult) OrderCompleted {
+	return OrderCompleted{
+		OrderEvent:  s.newOrderEvent(result.OrderID, result.Customer),
+		Status:      result.Status,
+		FinalAmount: result.FinalAmount,
+	}
+}
+
+func (s *OrderService) newOrderEvent(orderID string, customer string) OrderEvent {
+	return OrderEvent{OrderID: orderID, Customer: customer, OccurredAt: s.clock.Now()}
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// ORDER SERVICE DOMAIN EVENT TESTS
+// Testing: order_service_domain_events.go
+// =============================================================================
+
+func recordEventNames(bus EventBusInterface) *[]string {
+	var names []string
+	record := func(ctx context.Context, event DomainEvent) error {
+		names = append(names, event.EventName())
+		return nil
+	}
+	for _, name := range []string{EventOrderValidated, EventDiscountApplied, EventPaymentSucceeded, EventPaymentFailed, EventOrderCompleted} {
+		bus.Subscribe(name, Subscriber{Name: "recorder", Handler: record})
+	}
+	return &names
+}
+
+func TestOrderService_ProcessOrder_Success_PublishesLifecycleEvents(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	names := recordEventNames(bus)
+	var completed OrderCompleted
+	Subscribe(bus, "loyalty", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		completed = event
+		return nil
+	})
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("90.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithEventBus(bus))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "premium",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{EventOrderValidated, EventDiscountApplied, EventPaymentSucceeded, EventOrderCompleted}
+	if len(*names) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, *names)
+	}
+	for i := range want {
+		if (*names)[i] != want[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, want[i], (*names)[i])
+		}
+	}
+	if completed.OrderID != result.OrderID || completed.Status != OrderStatusPaid || completed.FinalAmount != result.FinalAmount {
+		t.Errorf("Expected OrderCompleted to describe the stored order, got %+v", completed)
+	}
+}
+
+func TestOrderService_ProcessOrder_PaymentFails_PublishesPaymentFailed(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	names := recordEventNames(bus)
+	var failed PaymentFailed
+	Subscribe(bus, "alerts", DeliverSync, func(ctx context.Context, event PaymentFailed) error {
+		failed = event
+		return nil
+	})
+	orderService := NewOrderService(NewFailingMockPaymentProcessor(errors.New("card declined")), NewMockDiscountService(false, MustParseMoney("100.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithEventBus(bus))
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected the payment error")
+	}
+	if last := (*names)[len(*names)-1]; last != EventPaymentFailed {
+		t.Errorf("Expected PaymentFailed to be the last event, got %v", *names)
+	}
+	if !strings.Contains(failed.Reason, "card declined") || failed.Amount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected the failure reason and amount, got %+v", failed)
+	}
+}
+
+func TestOrderService_ProcessOrder_InvalidOrder_PublishesNothing(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	names := recordEventNames(bus)
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, Money{}), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithEventBus(bus))
+
+	// Act
+	orderService.ProcessOrder(context.Background(), OrderData{Amount: MustParseMoney("10.00", USD)})
+
+	// Assert
+	if len(*names) != 0 {
+		t.Errorf("Expected no events for a rejected order, got %v", *names)
+	}
+}
+
+func TestOrderService_ProcessOrder_FailingSubscriber_DoesNotFailOrder(t *testing.T) {
+	// Arrange
+	recorder := &failureRecorder{}
+	bus := NewEventBusWithConfig(EventBusConfig{OnFailure: recorder.record})
+	Subscribe(bus, "email", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		return errors.New("smtp down")
+	})
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithEventBus(bus))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err != nil || result.Status != OrderStatusPaid {
+		t.Errorf("Expected a paid order despite the subscriber, got %s / %v", result.Status, err)
+	}
+	if len(recorder.all()) != 1 {
+		t.Errorf("Expected the subscriber failure reported, got %d", len(recorder.all()))
+	}
+}
@@ -0,0 +1,219 @@
+package application
+
+import (
+	"context"
+	"fmt"
+)
+
+// =============================================================================
+// ORDER SERVICE - PROVIDER EVENTS
+// Records changes a provider made or confirmed; never calls the processor
+// =============================================================================
+
+// ApplyPaymentEvent moves the order to the state the event reports. Events
+// are idempotent: one that finds the order already in that state (or the
+// refund already recorded) changes nothing. An event the order's state does
+// not allow returns ErrPaymentEventNotApplicable.
+func (s *OrderService) ApplyPaymentEvent(ctx context.Context, event PaymentEvent) (OrderResult, error) {
+	result, err := s.executePaymentEvent(event)
+	s.auditPaymentEvent(ctx, event, result, err)
+	return result, err
+}
+
+func (s *OrderService) executePaymentEvent(event PaymentEvent) (OrderResult, error) {
+	if err := s.validatePaymentEvent(event); err != nil {
+		return OrderResult{}, err
+	}
+	return s.updateOrder(event.Data.OrderID, func(order *OrderResult) error {
+		return s.applyPaymentEvent(order, event)
+	})
+}
+
+func (s *OrderService) validatePaymentEvent(event PaymentEvent) error {
+	if !event.Type.IsKnown() {
+		return fmt.Errorf("%w: %q", ErrUnknownPaymentEventType, event.Type)
+	}
+	if event.Data.OrderID == "" {
+		return fmt.Errorf("%w: %s event %s has no order ID", ErrInvalidPaymentEvent, event.Type, event.ID)
+	}
+	return nil
+}
+
+func (s *OrderService) applyPaymentEvent(order *OrderResult, event PaymentEvent) error {
+	switch event.Type {
+	case PaymentEventCaptureCompleted:
+		return s.applyCaptureCompleted(order, event)
+	case PaymentEventCaptureDenied:
+		return s.applyCaptureDenied(order, event)
+	case PaymentEventRefundCompleted:
+		return s.applyRefundCompleted(order, event)
+	case PaymentEventAuthorizationVoided:
+		return s.applyAuthorizationVoided(order, event)
+	case PaymentEventDisputeOpened:
+		return s.applyDisputeOpened(order, event)
+	default:
+		return s.applyDisputeResolved(order, event)
+	}
+}
+
+// A capture made outside CaptureOrder, e.g. from the provider's dashboard,
+// settles an authorized order; for a paid order it only confirms it.
+func (s *OrderService) applyCaptureCompleted(order *OrderResult, event PaymentEvent) error {
+	switch order.Status {
+	case OrderStatusPaid, OrderStatusRefunded, OrderStatusDisputed:
+		return nil
+	case OrderStatusAuthorized:
+		order.Payment.Status = PaymentStatusSucceeded
+		order.Payment.Timestamp = event.CreatedAt
+		return s.transitionForEvent(order, OrderStatusPaid, event)
+	default:
+		return s.createEventNotApplicableError(order, event)
+	}
+}
+
+// A denied capture (e.g. one held for review) means the order was not paid
+// after all.
+func (s *OrderService) applyCaptureDenied(order *OrderResult, event PaymentEvent) error {
+	switch {
+	case order.Status == OrderStatusFailed:
+		return nil
+	case order.Status == OrderStatusPending, order.Status == OrderStatusAuthorized,
+		order.Status == OrderStatusPaid && len(order.Refunds) == 0:
+		return s.transitionForEvent(order, OrderStatusFailed, event)
+	default:
+		return s.createEventNotApplicableError(order, event)
+	}
+}
+
+func (s *OrderService) applyRefundCompleted(order *OrderResult, event PaymentEvent) error {
+	if event.Data.ReferenceID == "" || !event.Data.Amount.IsPositive() {
+		return fmt.Errorf("%w: refund event %s needs a refund ID and a positive amount", ErrInvalidPaymentEvent, event.ID)
+	}
+	if hasRefund(*order, event.Data.ReferenceID) {
+		return nil
+	}
+	if order.Status != OrderStatusPaid && order.Status != OrderStatusFulfilled {
+		return s.createEventNotApplicableError(order, event)
+	}
+	remaining, err := s.remainingAfterRefund(*order, event.Data.Amount)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentEventNotApplicable, err)
+	}
+	return s.applyRefund(order, s.buildProviderRefund(*order, event, event.Data.Amount, remaining), s.describeEvent(event))
+}
+
+// Refunds this service asked for come back as events too; their refund ID
+// is already on the order.
+func hasRefund(order OrderResult, refundID string) bool {
+	for _, refund := range order.Refunds {
+		if refund.RefundID == refundID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *OrderService) remainingAfterRefund(order OrderResult, amount Money) (Money, error) {
+	refundable, err := order.Payment.GrossAmount.Subtract(order.RefundedAmount)
+	if err != nil {
+		return Money{}, err
+	}
+	remaining, err := refundable.Subtract(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	if remaining.IsNegative() {
+		return Money{}, fmt.Errorf("%w: refunded %s, refundable %s", ErrRefundExceedsCaptured, amount, refundable)
+	}
+	return remaining, nil
+}
+
+// The event does not say whether the provider returned its fee, so none is
+// recorded as reversed.
+func (s *OrderService) buildProviderRefund(order OrderResult, event PaymentEvent, amount Money, remaining Money) RefundResult {
+	refund := RefundResult{
+		RefundID:            event.Data.ReferenceID,
+		TransactionID:       order.Payment.TransactionID,
+		ProcessorType:       order.Payment.ProcessorType,
+		Type:                RefundTypePartial,
+		Amount:              amount,
+		FeeReversed:         ZeroMoney(amount.Currency()),
+		RemainingRefundable: remaining,
+		Timestamp:           event.CreatedAt,
+		Status:              PaymentStatusPartiallyRefunded,
+	}
+	if remaining.IsZero() {
+		refund.Type = RefundTypeFull
+		refund.Status = PaymentStatusRefunded
+	}
+	return refund
+}
+
+func (s *OrderService) applyAuthorizationVoided(order *OrderResult, event PaymentEvent) error {
+	switch order.Status {
+	case OrderStatusCancelled:
+		return nil
+	case OrderStatusAuthorized:
+		order.Payment.Status = PaymentStatusReleased
+		order.Payment.Timestamp = event.CreatedAt
+		return s.transitionForEvent(order, OrderStatusCancelled, event)
+	default:
+		return s.createEventNotApplicableError(order, event)
+	}
+}
+
+func (s *OrderService) applyDisputeOpened(order *OrderResult, event PaymentEvent) error {
+	switch order.Status {
+	case OrderStatusDisputed:
+		return nil
+	case OrderStatusPaid, OrderStatusFulfilled:
+		return s.transitionForEvent(order, OrderStatusDisputed, event)
+	default:
+		return s.createEventNotApplicableError(order, event)
+	}
+}
+
+// A won dispute returns the order to the status it had when the dispute
+// opened; a lost one takes back whatever had not been refunded yet as a
+// chargeback.
+func (s *OrderService) applyDisputeResolved(order *OrderResult, event PaymentEvent) error {
+	if event.Data.Outcome != DisputeOutcomeWon && event.Data.Outcome != DisputeOutcomeLost {
+		return fmt.Errorf("%w: dispute outcome %q", ErrInvalidPaymentEvent, event.Data.Outcome)
+	}
+	if order.Status != OrderStatusDisputed {
+		return s.createEventNotApplicableError(order, event)
+	}
+	if event.Data.Outcome == DisputeOutcomeWon {
+		return s.transitionForEvent(order, s.statusBeforeDispute(*order), event)
+	}
+
+	chargeback, _ := order.Payment.GrossAmount.Subtract(order.RefundedAmount)
+	refund := s.buildProviderRefund(*order, event, chargeback, ZeroMoney(chargeback.Currency()))
+	refund.RefundID = event.ID
+	refund.Type = RefundTypeChargeback
+	return s.applyRefund(order, refund, s.describeEvent(event))
+}
+
+// statusBeforeDispute reads the status history: the order was paid or
+// fulfilled when its dispute opened. An order stored without a history is
+// taken to have been paid.
+func (s *OrderService) statusBeforeDispute(order OrderResult) OrderStatus {
+	for i := len(order.StatusHistory) - 1; i >= 0; i-- {
+		if change := order.StatusHistory[i]; change.To == OrderStatusDisputed {
+			return change.From
+		}
+	}
+	return OrderStatusPaid
+}
+
+func (s *OrderService) transitionForEvent(order *OrderResult, to OrderStatus, event PaymentEvent) error {
+	return s.transition(order, to, s.describeEvent(event))
+}
+
+func (s *OrderService) describeEvent(event PaymentEvent) string {
+	return fmt.Sprintf("provider event %s (%s)", event.ID, event.Type)
+}
+
+func (s *OrderService) createEventNotApplicableError(order *OrderResult, event PaymentEvent) error {
+	return fmt.Errorf("%w: %s on order %s, which is %s", ErrPaymentEventNotApplicable, event.Type, order.OrderID, order.Status)
+}
@@ -0,0 +1,340 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ORDER SERVICE EVENT TESTS
+// Testing: order_service_events.go
+// =============================================================================
+
+var paymentEventTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newEventOrderService(t *testing.T, options ...OrderServiceOption) (OrderServiceInterface, *MockPaymentProcessor) {
+	t.Helper()
+	processor := NewMockPaymentProcessor(false)
+	service := NewOrderService(processor, NewMockDiscountService(false, MustParseMoney("100.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), options...)
+	return service, processor
+}
+
+func placeEventOrder(t *testing.T, service OrderServiceInterface, customerType string) OrderResult {
+	t.Helper()
+	placed, err := service.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: customerType,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error placing order, got %v", err)
+	}
+	return placed
+}
+
+func newPaymentEvent(id string, eventType PaymentEventType, orderID string) PaymentEvent {
+	return PaymentEvent{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: paymentEventTime,
+		Data:      PaymentEventData{OrderID: orderID},
+	}
+}
+
+func newRefundEvent(id string, orderID string, refundID string, amount string) PaymentEvent {
+	event := newPaymentEvent(id, PaymentEventRefundCompleted, orderID)
+	event.Data.ReferenceID = refundID
+	event.Data.Amount = MustParseMoney(amount, USD)
+	return event
+}
+
+func TestOrderService_ApplyPaymentEvent_CaptureCompleted_MarksAuthorizedOrderPaid(t *testing.T) {
+	// Arrange
+	service, processor := newEventOrderService(t, WithDeferredCapture())
+	placed := placeEventOrder(t, service, "distributor")
+	callsBefore := processor.callCount()
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), newPaymentEvent("evt_1", PaymentEventCaptureCompleted, placed.OrderID))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid || result.Payment.Status != PaymentStatusSucceeded {
+		t.Errorf("Expected a paid order with a succeeded payment, got %s / %s", result.Status, result.Payment.Status)
+	}
+	if processor.callCount() != callsBefore {
+		t.Error("Expected the event not to call the processor")
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_StateTransitions(t *testing.T) {
+	tests := []struct {
+		name         string
+		customerType string
+		events       []PaymentEventType
+		wantStatus   OrderStatus
+		wantErr      error
+	}{
+		{"capture confirms paid order", "regular", []PaymentEventType{PaymentEventCaptureCompleted}, OrderStatusPaid, nil},
+		{"capture denied fails paid order", "regular", []PaymentEventType{PaymentEventCaptureDenied}, OrderStatusFailed, nil},
+		{"capture denied fails authorized order", "distributor", []PaymentEventType{PaymentEventCaptureDenied}, OrderStatusFailed, nil},
+		{"authorization voided cancels authorized order", "distributor", []PaymentEventType{PaymentEventAuthorizationVoided}, OrderStatusCancelled, nil},
+		{"authorization voided on paid order", "regular", []PaymentEventType{PaymentEventAuthorizationVoided}, OrderStatusPaid, ErrPaymentEventNotApplicable},
+		{"dispute opened on paid order", "regular", []PaymentEventType{PaymentEventDisputeOpened}, OrderStatusDisputed, nil},
+		{"dispute opened twice", "regular", []PaymentEventType{PaymentEventDisputeOpened, PaymentEventDisputeOpened}, OrderStatusDisputed, nil},
+		{"dispute opened on authorized order", "distributor", []PaymentEventType{PaymentEventDisputeOpened}, OrderStatusAuthorized, ErrPaymentEventNotApplicable},
+		{"capture completed during dispute", "regular", []PaymentEventType{PaymentEventDisputeOpened, PaymentEventCaptureCompleted}, OrderStatusDisputed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _ := newEventOrderService(t, WithDeferredCapture("distributor"))
+			placed := placeEventOrder(t, service, tt.customerType)
+
+			// Act
+			var err error
+			for i, eventType := range tt.events {
+				_, err = service.ApplyPaymentEvent(context.Background(), newPaymentEvent(string(rune('a'+i)), eventType, placed.OrderID))
+			}
+
+			// Assert
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			stored, _ := service.GetOrder(placed.OrderID)
+			if stored.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, stored.Status)
+			}
+		})
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_RefundCompleted_RecordsProviderRefund(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), newRefundEvent("evt_1", placed.OrderID, "re_1", "40.00"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid {
+		t.Errorf("Expected status %s, got %s", OrderStatusPaid, result.Status)
+	}
+	if result.RefundedAmount != MustParseMoney("40.00", USD) {
+		t.Errorf("Expected refunded 40.00, got %s", result.RefundedAmount)
+	}
+	if len(result.Refunds) != 1 || result.Refunds[0].RefundID != "re_1" || result.Refunds[0].Type != RefundTypePartial {
+		t.Errorf("Expected one partial refund re_1, got %+v", result.Refunds)
+	}
+}
+
+func TestOrderService_RefundOrder_AfterProviderRefund_CannotRefundItAgain(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	_, _ = service.ApplyPaymentEvent(context.Background(), newRefundEvent("evt_1", placed.OrderID, "re_1", "80.00"))
+
+	// Act
+	_, tooMuch := service.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("40.00", USD))
+	rest, restErr := service.RefundOrder(context.Background(), placed.OrderID, Money{})
+
+	// Assert
+	if !errors.Is(tooMuch, ErrRefundExceedsCaptured) {
+		t.Errorf("Expected ErrRefundExceedsCaptured, got %v", tooMuch)
+	}
+	if restErr != nil || rest.Status != OrderStatusRefunded || rest.Refunds[1].Amount != MustParseMoney("20.00", USD) {
+		t.Errorf("Expected a full refund to give back the remaining 20.00, got %+v (%v)", rest, restErr)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_RefundAlreadyRecorded_ChangesNothing(t *testing.T) {
+	// Arrange: The refund this service made comes back as an event
+	service := NewOrderService(NewCreditCardProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed := placeEventOrder(t, service, "regular")
+	refunded, err := service.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("30.00", USD))
+	if err != nil {
+		t.Fatalf("Expected no error refunding, got %v", err)
+	}
+	refundID := refunded.Refunds[0].RefundID
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), newRefundEvent("evt_1", placed.OrderID, refundID, "30.00"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Refunds) != 1 || result.RefundedAmount != MustParseMoney("30.00", USD) {
+		t.Errorf("Expected the refund recorded once, got %d refunds totalling %s", len(result.Refunds), result.RefundedAmount)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_RefundOfRemainder_MarksOrderRefunded(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), newRefundEvent("evt_1", placed.OrderID, "re_1", "100.00"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusRefunded || result.Refunds[0].Type != RefundTypeFull {
+		t.Errorf("Expected a fully refunded order, got %s / %s", result.Status, result.Refunds[0].Type)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_RefundExceedsCapture_IsNotApplicable(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+
+	// Act
+	_, err := service.ApplyPaymentEvent(context.Background(), newRefundEvent("evt_1", placed.OrderID, "re_1", "150.00"))
+
+	// Assert
+	if !errors.Is(err, ErrPaymentEventNotApplicable) {
+		t.Errorf("Expected ErrPaymentEventNotApplicable, got %v", err)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_RefundWithoutAmount_ReturnsInvalidEventError(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	event := newPaymentEvent("evt_1", PaymentEventRefundCompleted, placed.OrderID)
+	event.Data.ReferenceID = "re_1"
+
+	// Act
+	_, err := service.ApplyPaymentEvent(context.Background(), event)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidPaymentEvent) {
+		t.Errorf("Expected ErrInvalidPaymentEvent, got %v", err)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_DisputeWon_ReturnsOrderToPaid(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	if _, err := service.ApplyPaymentEvent(context.Background(), newPaymentEvent("evt_1", PaymentEventDisputeOpened, placed.OrderID)); err != nil {
+		t.Fatalf("Expected no error opening dispute, got %v", err)
+	}
+	resolved := newPaymentEvent("evt_2", PaymentEventDisputeResolved, placed.OrderID)
+	resolved.Data.Outcome = DisputeOutcomeWon
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), resolved)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid || len(result.Refunds) != 0 {
+		t.Errorf("Expected a paid order without refunds, got %s with %d refunds", result.Status, len(result.Refunds))
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_DisputeWonOnShippedOrder_ReturnsOrderToFulfilled(t *testing.T) {
+	// Arrange
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	if _, err := service.FulfillOrder(context.Background(), placed.OrderID); err != nil {
+		t.Fatalf("Expected no error fulfilling, got %v", err)
+	}
+	if _, err := service.ApplyPaymentEvent(context.Background(), newPaymentEvent("evt_1", PaymentEventDisputeOpened, placed.OrderID)); err != nil {
+		t.Fatalf("Expected a shipped order to be disputable, got %v", err)
+	}
+	_, fulfillErr := service.FulfillOrder(context.Background(), placed.OrderID)
+	resolved := newPaymentEvent("evt_2", PaymentEventDisputeResolved, placed.OrderID)
+	resolved.Data.Outcome = DisputeOutcomeWon
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), resolved)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusFulfilled {
+		t.Errorf("Expected the order back at %s, got %s", OrderStatusFulfilled, result.Status)
+	}
+	var illegal *IllegalTransitionError
+	if !errors.As(fulfillErr, &illegal) {
+		t.Errorf("Expected a disputed order not to be fulfilled again, got %v", fulfillErr)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_DisputeLost_RecordsChargebackOfRemainder(t *testing.T) {
+	// Arrange: Part of the order was refunded before the dispute
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	if _, err := service.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("25.00", USD)); err != nil {
+		t.Fatalf("Expected no error refunding, got %v", err)
+	}
+	if _, err := service.ApplyPaymentEvent(context.Background(), newPaymentEvent("evt_1", PaymentEventDisputeOpened, placed.OrderID)); err != nil {
+		t.Fatalf("Expected no error opening dispute, got %v", err)
+	}
+	resolved := newPaymentEvent("evt_2", PaymentEventDisputeResolved, placed.OrderID)
+	resolved.Data.Outcome = DisputeOutcomeLost
+
+	// Act
+	result, err := service.ApplyPaymentEvent(context.Background(), resolved)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusRefunded {
+		t.Errorf("Expected status %s, got %s", OrderStatusRefunded, result.Status)
+	}
+	chargeback := result.Refunds[len(result.Refunds)-1]
+	if chargeback.Type != RefundTypeChargeback || chargeback.Amount != MustParseMoney("75.00", USD) {
+		t.Errorf("Expected a 75.00 chargeback, got %s %s", chargeback.Type, chargeback.Amount)
+	}
+	if result.RefundedAmount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected refunded 100.00 in total, got %s", result.RefundedAmount)
+	}
+}
+
+func TestOrderService_ApplyPaymentEvent_InvalidEvents_ReturnTypedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   PaymentEvent
+		wantErr error
+	}{
+		{"unknown type", newPaymentEvent("evt_1", "payment.capture.reversed", "order_1"), ErrUnknownPaymentEventType},
+		{"missing order ID", newPaymentEvent("evt_1", PaymentEventCaptureCompleted, ""), ErrInvalidPaymentEvent},
+		{"unknown order", newPaymentEvent("evt_1", PaymentEventCaptureCompleted, "order_missing"), ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _ := newEventOrderService(t)
+
+			// Act
+			_, err := service.ApplyPaymentEvent(context.Background(), tt.event)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
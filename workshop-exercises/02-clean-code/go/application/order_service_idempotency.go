@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// =============================================================================
+// ORDER SERVICE - IDEMPOTENT SUBMISSION
+// =============================================================================
+
+func (s *OrderService) executeIdempotentOrderProcessing(ctx context.Context, order OrderData) (OrderResult, error) {
+	unlock := s.idempotencyLocks.lock(order.IdempotencyKey)
+	defer unlock()
+
+	fingerprint, err := fingerprintOrder(order)
+	if err != nil {
+		return OrderResult{}, s.wrapIdempotencyError(err)
+	}
+
+	if replay, found, err := s.findReplay(order.IdempotencyKey, fingerprint); err != nil || found {
+		return replay, err
+	}
+
+	result, err := s.executeOrderProcessing(ctx, order)
+	if !paymentWentThrough(result) {
+		return result, err
+	}
+	if rememberErr := s.rememberResult(order.IdempotencyKey, fingerprint, result); rememberErr != nil {
+		return result, errors.Join(err, rememberErr)
+	}
+	return result, err
+}
+
+// paymentWentThrough reports whether the customer was charged (or the
+// payment authorized), even if storing the order failed afterwards (see
+// storeOrder). Such a result is remembered too: a retry must replay it, not
+// charge again.
+func paymentWentThrough(result OrderResult) bool {
+	return result.Payment.TransactionID != ""
+}
+
+func (s *OrderService) findReplay(key string, fingerprint string) (OrderResult, bool, error) {
+	record, found, err := s.idempotencyStore.Load(key)
+	if err != nil {
+		return OrderResult{}, false, s.wrapIdempotencyError(err)
+	}
+	if !found || record.IsExpired(s.clock.Now()) {
+		return OrderResult{}, false, nil
+	}
+	if record.Fingerprint != fingerprint {
+		return OrderResult{}, false, s.createKeyReusedError(key)
+	}
+	return s.markReplayed(record.Result), true, nil
+}
+
+func (s *OrderService) markReplayed(result OrderResult) OrderResult {
+	result.Replayed = true
+	return result
+}
+
+// Expired keys are swept whenever a new one is stored so the store only grows
+// with the keys that can still be replayed.
+func (s *OrderService) rememberResult(key string, fingerprint string, result OrderResult) error {
+	now := s.clock.Now()
+	if _, err := s.idempotencyStore.PurgeExpired(now); err != nil {
+		return s.wrapIdempotencyError(err)
+	}
+	err := s.idempotencyStore.Save(IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Result:      result,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.idempotencyTTL),
+	})
+	if err != nil {
+		return s.wrapIdempotencyError(err)
+	}
+	return nil
+}
+
+func (s *OrderService) createKeyReusedError(key string) error {
+	return fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+}
+
+func (s *OrderService) wrapIdempotencyError(err error) error {
+	return fmt.Errorf("idempotency store failed: %w", err)
+}
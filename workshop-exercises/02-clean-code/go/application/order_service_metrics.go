@@ -0,0 +1,27 @@
+package application
+
+// =============================================================================
+// ORDER SERVICE - METRICS
+// Reports to the order metrics, when they were configured
+// =============================================================================
+
+// startOrderTimer counts the order in flight and returns the func that
+// records how it ended.
+func (s *OrderService) startOrderTimer() func(result OrderResult, err error) {
+	if s.metrics == nil {
+		return func(OrderResult, error) {}
+	}
+	started := s.clock.Now()
+	finished := s.metrics.OrderStarted()
+	return func(result OrderResult, err error) {
+		finished()
+		s.metrics.ObserveOrder(orderOutcome(result, err), s.clock.Now().Sub(started))
+	}
+}
+
+func (s *OrderService) observeDiscount(order OrderData, discount DiscountResult) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.ObserveDiscount(order.CustomerType, discount)
+}
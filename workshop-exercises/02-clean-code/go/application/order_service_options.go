@@ -0,0 +1,100 @@
+package application
+
+import "time"
+
+// =============================================================================
+// ORDER SERVICE OPTIONS
+// Optional behaviour configured when the OrderService is built
+// =============================================================================
+
+type OrderServiceOption func(service *OrderService)
+
+// WithDeferredCapture authorizes payment when the order is placed and leaves
+// the capture to CaptureOrder. Without customer types it applies to every order.
+func WithDeferredCapture(customerTypes ...string) OrderServiceOption {
+	return func(service *OrderService) {
+		service.deferredCapture = newDeferredCapturePolicy(customerTypes)
+	}
+}
+
+// WithIdempotencyStore replaces the default in-memory store that remembers
+// results per idempotency key, and how long each key is kept.
+func WithIdempotencyStore(store IdempotencyStoreInterface, ttl time.Duration) OrderServiceOption {
+	return func(service *OrderService) {
+		service.idempotencyStore = store
+		service.idempotencyTTL = ttl
+	}
+}
+
+// WithOrderRepository replaces the default in-memory repository, e.g. with a
+// FileOrderRepository so orders survive a restart.
+func WithOrderRepository(repository OrderRepository) OrderServiceOption {
+	return func(service *OrderService) {
+		service.orders = repository
+	}
+}
+
+// WithEventBus publishes the order's domain events on bus instead of a
+// private bus nobody subscribes to.
+func WithEventBus(bus EventBusInterface) OrderServiceOption {
+	return func(service *OrderService) {
+		service.events = bus
+	}
+}
+
+// WithOutbox stores orders in repository and, instead of publishing the
+// order events straight away, writes them to its outbox together with the
+// order change they report. An OutboxRelay delivers them from there.
+func WithOutbox(repository OutboxRepository) OrderServiceOption {
+	return func(service *OrderService) {
+		service.orders = repository
+		service.outbox = repository
+	}
+}
+
+// WithAuditor records every order operation, who asked for it and how it
+// went. Wrap the processor with NewAuditingProcessor as well to record the
+// calls made to the provider.
+func WithAuditor(auditor AuditorInterface) OrderServiceOption {
+	return func(service *OrderService) {
+		service.auditor = auditor
+	}
+}
+
+// WithMetrics reports order latency, orders in flight and discount rates.
+// Payment latency and fees are reported by wrapping each processor with
+// NewMetricsProcessor.
+func WithMetrics(metrics OrderMetricsInterface) OrderServiceOption {
+	return func(service *OrderService) {
+		service.metrics = metrics
+	}
+}
+
+func WithClock(clock Clock) OrderServiceOption {
+	return func(service *OrderService) {
+		service.clock = clock
+	}
+}
+
+type deferredCapturePolicy struct {
+	enabled       bool
+	customerTypes map[string]bool
+}
+
+func newDeferredCapturePolicy(customerTypes []string) deferredCapturePolicy {
+	policy := deferredCapturePolicy{
+		enabled:       true,
+		customerTypes: make(map[string]bool),
+	}
+	for _, customerType := range customerTypes {
+		policy.customerTypes[customerType] = true
+	}
+	return policy
+}
+
+func (p deferredCapturePolicy) appliesTo(order OrderData) bool {
+	if !p.enabled {
+		return false
+	}
+	return len(p.customerTypes) == 0 || p.customerTypes[order.CustomerType]
+}
@@ -0,0 +1,64 @@
+package application
+
+import "context"
+
+// =============================================================================
+// ORDER SERVICE - OUTBOX
+// Stores the payment outcome and the events announcing it
+// =============================================================================
+
+// savePendingOrder stores the order before it is paid for. With an outbox the
+// steps announced so far go into it in the same append; an order that fails
+// before it is stored leaves no events behind, as nothing describes it yet.
+func (s *OrderService) savePendingOrder(pending OrderResult, steps []DomainEvent) error {
+	if s.outbox != nil {
+		return s.saveWithOutbox(pending, steps...)
+	}
+	return s.orders.Save(pending)
+}
+
+// saveCompletedOrder stores a charged or authorized order. Without an outbox
+// the events are published around the save, and a crash in between loses
+// them; with one they are written in the same append as the order.
+func (s *OrderService) saveCompletedOrder(ctx context.Context, result OrderResult) error {
+	succeeded := s.newPaymentSucceeded(result)
+	completed := s.newOrderCompleted(result)
+	if s.outbox != nil {
+		return s.saveWithOutbox(result, succeeded, completed)
+	}
+
+	s.events.Publish(ctx, succeeded)
+	if err := s.orders.Save(result); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, completed)
+	return nil
+}
+
+func (s *OrderService) saveFailedOrder(ctx context.Context, failed PaymentFailed) error {
+	order, err := s.orders.Get(failed.OrderID)
+	if err != nil {
+		return err
+	}
+	if err := s.transition(&order, OrderStatusFailed, failed.Reason); err != nil {
+		return err
+	}
+	if s.outbox != nil {
+		return s.saveWithOutbox(order, failed)
+	}
+
+	s.events.Publish(ctx, failed)
+	return s.orders.Save(order)
+}
+
+func (s *OrderService) saveWithOutbox(order OrderResult, events ...DomainEvent) error {
+	messages := make([]OutboxMessage, 0, len(events))
+	for _, event := range events {
+		message, err := NewOutboxMessage(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	return s.outbox.SaveWithOutbox(order, messages)
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ORDER SERVICE OUTBOX TESTS
+// Testing: order_service_outbox.go
+// =============================================================================
+
+func TestOrderService_ProcessOrder_WithOutbox_StoresEventsInsteadOfPublishing(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	names := recordEventNames(bus)
+	repository := NewInMemoryOutboxRepository()
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithEventBus(bus), WithOutbox(repository))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	pending, _ := repository.PendingOutbox(time.Now(), 0)
+	expected := []string{EventOrderValidated, EventDiscountApplied, EventPaymentSucceeded, EventOrderCompleted}
+	if len(pending) != len(expected) {
+		t.Fatalf("Expected %v in the outbox, got %+v", expected, pending)
+	}
+	for i, name := range expected {
+		if pending[i].EventName != name || pending[i].OrderID != result.OrderID {
+			t.Errorf("Expected %s for %s at %d, got %+v", name, result.OrderID, i, pending[i])
+		}
+	}
+	if len(*names) != 0 {
+		t.Errorf("Expected every event to wait for the relay, but %v were published directly", *names)
+	}
+}
+
+func TestOrderService_ProcessOrder_WithOutbox_PaymentFailureStoresPaymentFailed(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOutboxRepository()
+	orderService := NewOrderService(NewFailingMockPaymentProcessor(errors.New("card declined")), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithOutbox(repository))
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected the payment error")
+	}
+	pending, _ := repository.PendingOutbox(time.Now(), 0)
+	if len(pending) != 3 || pending[2].EventName != EventPaymentFailed {
+		t.Fatalf("Expected PaymentFailed after the order's steps in the outbox, got %+v", pending)
+	}
+	stored, _ := repository.Get(pending[2].OrderID)
+	if stored.Status != OrderStatusFailed {
+		t.Errorf("Expected the order stored as failed, got %s", stored.Status)
+	}
+}
+
+func TestOrderService_ProcessOrder_WithFileOutbox_RelaysEventsAfterRestart(t *testing.T) {
+	// Arrange: Charge the order, then "crash" before anything is relayed
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository, _ := NewFileOutboxRepository(path)
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithOutbox(repository))
+	placed, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error placing order, got %v", err)
+	}
+
+	reopened, _ := NewFileOutboxRepository(path)
+	bus := NewEventBus()
+	var completed []OrderCompleted
+	Subscribe(bus, "email", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		completed = append(completed, event)
+		return nil
+	})
+	relay := newTestOutboxRelay(t, reopened, NewEventBusOutboxConsumer(bus), NewSystemClock())
+
+	// Act
+	delivered, err := relay.RelayPending(context.Background())
+
+	// Assert
+	if err != nil || delivered != 4 {
+		t.Fatalf("Expected all four events relayed, got %d (%v)", delivered, err)
+	}
+	if len(completed) != 1 || completed[0].OrderID != placed.OrderID {
+		t.Errorf("Expected OrderCompleted for %s, got %+v", placed.OrderID, completed)
+	}
+}
@@ -1,8 +1,13 @@
 package application
 
 import (
+	"context"
 	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -10,47 +15,149 @@ import (
 // =============================================================================
 
 type MockPaymentProcessor struct {
-	shouldFail    bool
-	expectedAmount float64
-	result        string
+	mu             sync.Mutex
+	shouldFail     bool
+	failure        error
+	expectedAmount Money
+	lastRequest    PaymentRequest
+	calls          int
 }
 
-func NewMockPaymentProcessor(shouldFail bool, result string) *MockPaymentProcessor {
+func NewMockPaymentProcessor(shouldFail bool) *MockPaymentProcessor {
 	return &MockPaymentProcessor{
 		shouldFail: shouldFail,
-		result:     result,
 	}
 }
 
-func (m *MockPaymentProcessor) ProcessPayment(amount float64) (string, error) {
+func NewFailingMockPaymentProcessor(failure error) *MockPaymentProcessor {
+	return &MockPaymentProcessor{
+		shouldFail: true,
+		failure:    failure,
+	}
+}
+
+func (m *MockPaymentProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	amount := request.Amount
+	m.calls++
+	m.lastRequest = request
 	m.expectedAmount = amount
+	if m.shouldFail && m.failure != nil {
+		return PaymentResult{}, m.failure
+	}
+	if m.shouldFail {
+		return PaymentResult{}, errors.New("payment failed")
+	}
+	return PaymentResult{
+		ProcessorType: "mock",
+		GrossAmount:   amount,
+		Fee:           ZeroMoney(amount.Currency()),
+		NetAmount:     amount,
+		TransactionID: "mock_txn",
+		Status:        PaymentStatusSucceeded,
+	}, nil
+}
+
+func (m *MockPaymentProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
 	if m.shouldFail {
-		return "", errors.New("payment failed")
+		return RefundResult{}, errors.New("refund failed")
 	}
-	return m.result, nil
+	amount := request.Amount
+	if request.IsFull() {
+		amount = m.expectedAmount
+	}
+	remaining, _ := m.expectedAmount.Subtract(amount)
+	return RefundResult{
+		TransactionID:       request.TransactionID,
+		Amount:              amount,
+		RemainingRefundable: remaining,
+	}, nil
+}
+
+func (m *MockPaymentProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return RefundResult{TransactionID: transactionID, Type: RefundTypeVoid, Amount: m.expectedAmount}, nil
+}
+
+func (m *MockPaymentProcessor) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+func (m *MockPaymentProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	result, err := m.ProcessPayment(ctx, request)
+	result.Status = PaymentStatusAuthorized
+	return result, err
+}
+
+func (m *MockPaymentProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	if amount.IsZero() {
+		amount = m.expectedAmount
+	}
+	return PaymentResult{TransactionID: authorizationID, GrossAmount: amount, NetAmount: amount, Status: PaymentStatusSucceeded}, nil
+}
+
+func (m *MockPaymentProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return PaymentResult{TransactionID: authorizationID, Status: PaymentStatusReleased}, nil
 }
 
 type MockDiscountService struct {
-	shouldFail      bool
-	expectedAmount  float64
-	expectedType    string
-	discountedAmount float64
+	shouldFail       bool
+	expectedAmount   Money
+	expectedType     string
+	discountedAmount Money
 }
 
-func NewMockDiscountService(shouldFail bool, discountedAmount float64) *MockDiscountService {
+func NewMockDiscountService(shouldFail bool, discountedAmount Money) *MockDiscountService {
 	return &MockDiscountService{
 		shouldFail:       shouldFail,
 		discountedAmount: discountedAmount,
 	}
 }
 
-func (m *MockDiscountService) CalculateDiscount(amount float64, customerType string) (float64, error) {
-	m.expectedAmount = amount
-	m.expectedType = customerType
+func (m *MockDiscountService) CalculateDiscount(ctx context.Context, request DiscountRequest) (DiscountResult, error) {
+	m.expectedAmount = request.Amount
+	m.expectedType = request.CustomerType
+	if m.shouldFail {
+		return DiscountResult{}, errors.New("discount calculation failed")
+	}
+	total, _ := request.Amount.Subtract(m.discountedAmount)
+	return DiscountResult{
+		OriginalAmount:   request.Amount,
+		TotalDiscount:    total,
+		DiscountedAmount: m.discountedAmount,
+	}, nil
+}
+
+func (m *MockDiscountService) RedeemDiscount(ctx context.Context, customer string, result DiscountResult) error {
+	return nil
+}
+
+func (m *MockDiscountService) ReleaseDiscount(ctx context.Context, customer string, result DiscountResult) error {
+	return nil
+}
+
+// MockTaxService charges no tax, so the amount charged is the discounted amount
+type MockTaxService struct {
+	shouldFail  bool
+	lastRequest TaxRequest
+}
+
+func NewMockTaxService() *MockTaxService {
+	return &MockTaxService{}
+}
+
+func (m *MockTaxService) CalculateTax(ctx context.Context, request TaxRequest) (TaxResult, error) {
+	m.lastRequest = request
 	if m.shouldFail {
-		return 0, errors.New("discount calculation failed")
+		return TaxResult{}, errors.New("tax calculation failed")
 	}
-	return m.discountedAmount, nil
+	return TaxResult{
+		NetAmount:   request.Amount,
+		TaxAmount:   ZeroMoney(request.Amount.Currency()),
+		GrossAmount: request.Amount,
+	}, nil
 }
 
 // =============================================================================
@@ -62,52 +169,61 @@ func (m *MockDiscountService) CalculateDiscount(amount float64, customerType str
 func TestOrderService_ProcessOrder_ValidOrder_ReturnsSuccess(t *testing.T) {
 	// Arrange: Setup mock services (notice the complexity!)
 	// Need to mock PaymentProcessorInterface (could be CreditCardProcessor or PayPalProcessor)
-	mockProcessor := NewMockPaymentProcessor(false, "Payment successful")
+	mockProcessor := NewMockPaymentProcessor(false)
 
 	// Need to mock DiscountServiceInterface (implemented in discount_service.go)
-	mockDiscount := NewMockDiscountService(false, 85.0) // 15% discount for premium
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("85.00", USD)) // 15% discount for premium
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       100.0,
+		Amount:       MustParseMoney("100.00", USD),
 		Customer:     "test@example.com",
 		CustomerType: "premium",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Verify results
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if result == "" {
-		t.Error("Expected non-empty result")
+	if result.OrderID == "" {
+		t.Error("Expected an order ID")
+	}
+	if mockProcessor.expectedAmount != MustParseMoney("85.00", USD) {
+		t.Errorf("Expected ProcessPayment to be called with 85.00, got %v", mockProcessor.expectedAmount)
 	}
-	if mockProcessor.expectedAmount != 85.0 {
-		t.Errorf("Expected ProcessPayment to be called with 85.0, got %v", mockProcessor.expectedAmount)
+	if mockDiscount.expectedAmount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected CalculateDiscount to be called with 100.00, got %v", mockDiscount.expectedAmount)
 	}
-	if mockDiscount.expectedAmount != 100.0 {
-		t.Errorf("Expected CalculateDiscount to be called with 100.0, got %v", mockDiscount.expectedAmount)
+	if result.FinalAmount != MustParseMoney("85.00", USD) {
+		t.Errorf("Expected final amount 85.00, got %s", result.FinalAmount)
+	}
+	if result.OriginalAmount != MustParseMoney("100.00", USD) {
+		t.Errorf("Expected original amount 100.00, got %s", result.OriginalAmount)
+	}
+	if result.Payment.TransactionID != "mock_txn" {
+		t.Errorf("Expected payment transaction 'mock_txn', got '%s'", result.Payment.TransactionID)
 	}
 }
 
 func TestOrderService_ProcessOrder_InvalidAmount_ReturnsError(t *testing.T) {
 	// Arrange: Setup mock services (won't be called due to validation in order_service.go)
-	mockProcessor := NewMockPaymentProcessor(false, "")
-	mockDiscount := NewMockDiscountService(false, 0)
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("0.00", USD))
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       -10.0, // Invalid amount
+		Amount:       MustParseMoney("-10.00", USD), // Invalid amount
 		Customer:     "test@example.com",
 		CustomerType: "regular",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Should fail validation
 	if err == nil {
@@ -116,26 +232,26 @@ func TestOrderService_ProcessOrder_InvalidAmount_ReturnsError(t *testing.T) {
 	if err.Error() != "amount must be positive" {
 		t.Errorf("Expected 'amount must be positive', got '%v'", err.Error())
 	}
-	if result != "" {
+	if result.OrderID != "" {
 		t.Error("Expected empty result on error")
 	}
 }
 
 func TestOrderService_ProcessOrder_EmptyCustomer_ReturnsError(t *testing.T) {
 	// Arrange: Setup mock services
-	mockProcessor := NewMockPaymentProcessor(false, "")
-	mockDiscount := NewMockDiscountService(false, 0)
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("0.00", USD))
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       100.0,
+		Amount:       MustParseMoney("100.00", USD),
 		Customer:     "", // Empty customer
 		CustomerType: "regular",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Should fail validation
 	if err == nil {
@@ -144,77 +260,77 @@ func TestOrderService_ProcessOrder_EmptyCustomer_ReturnsError(t *testing.T) {
 	if err.Error() != "customer cannot be empty" {
 		t.Errorf("Expected 'customer cannot be empty', got '%v'", err.Error())
 	}
-	if result != "" {
+	if result.OrderID != "" {
 		t.Error("Expected empty result on error")
 	}
 }
 
 func TestOrderService_ProcessOrder_PaymentProcessorFails_ReturnsError(t *testing.T) {
 	// Arrange: Setup mock services - payment processor fails
-	mockProcessor := NewMockPaymentProcessor(true, "")
+	mockProcessor := NewMockPaymentProcessor(true)
 
-	mockDiscount := NewMockDiscountService(false, 95.0)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("95.00", USD))
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       100.0,
+		Amount:       MustParseMoney("100.00", USD),
 		Customer:     "test@example.com",
 		CustomerType: "regular",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Should propagate payment error
 	if err == nil {
 		t.Error("Expected error from payment processor")
 	}
-	if result != "" {
+	if result.OrderID != "" {
 		t.Error("Expected empty result on error")
 	}
 }
 
 func TestOrderService_ProcessOrder_DiscountServiceFails_ReturnsError(t *testing.T) {
 	// Arrange: Setup mock services - discount service fails
-	mockProcessor := NewMockPaymentProcessor(false, "")
-	mockDiscount := NewMockDiscountService(true, 0)
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(true, MustParseMoney("0.00", USD))
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       100.0,
+		Amount:       MustParseMoney("100.00", USD),
 		Customer:     "test@example.com",
 		CustomerType: "regular",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Should propagate discount error
 	if err == nil {
 		t.Error("Expected error from discount service")
 	}
-	if result != "" {
+	if result.OrderID != "" {
 		t.Error("Expected empty result on error")
 	}
 }
 
 func TestOrderService_ProcessOrder_ZeroAmount_ReturnsError(t *testing.T) {
 	// Arrange: Setup mock services
-	mockProcessor := NewMockPaymentProcessor(false, "")
-	mockDiscount := NewMockDiscountService(false, 0)
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("0.00", USD))
 
-	orderService := NewOrderService(mockProcessor, mockDiscount)
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
 
 	order := OrderData{
-		Amount:       0.0, // Zero amount
+		Amount:       MustParseMoney("0.00", USD), // Zero amount
 		Customer:     "test@example.com",
 		CustomerType: "regular",
 	}
 
 	// Act: Process the order
-	result, err := orderService.ProcessOrder(order)
+	result, err := orderService.ProcessOrder(context.Background(), order)
 
 	// Assert: Should fail validation
 	if err == nil {
@@ -223,7 +339,897 @@ func TestOrderService_ProcessOrder_ZeroAmount_ReturnsError(t *testing.T) {
 	if err.Error() != "amount must be positive" {
 		t.Errorf("Expected 'amount must be positive', got '%v'", err.Error())
 	}
-	if result != "" {
+	if result.OrderID != "" {
 		t.Error("Expected empty result on error")
 	}
-}
\ No newline at end of file
+}
+
+func TestOrderService_ProcessOrder_MissingCurrency_ReturnsError(t *testing.T) {
+	// Arrange: Amount without a currency cannot be charged
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, Money{})
+
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
+
+	order := OrderData{
+		Amount:       NewMoney(10000, ""),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	}
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Expected error for missing currency")
+	}
+	if result.OrderID != "" {
+		t.Error("Expected empty result on error")
+	}
+}
+
+func TestOrderService_ProcessOrder_PaymentCancelled_ReturnsCancellationErrorUnchanged(t *testing.T) {
+	// Arrange: Processor reports a typed cancellation
+	cancellation := NewPaymentCancelledError("Credit Card", context.DeadlineExceeded)
+	mockProcessor := NewFailingMockPaymentProcessor(cancellation)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("95.00", USD))
+
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
+
+	order := OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	}
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert: The cancellation error is passed on without wrapping
+	if err != cancellation {
+		t.Errorf("Expected the processor's cancellation error unchanged, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to match context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestOrderService_ProcessOrder_DeadlineDuringPayment_StopsProcessing(t *testing.T) {
+	// Arrange: Real processor with a deadline shorter than its simulated work
+	orderService := NewOrderService(NewPayPalProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	order := OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	}
+
+	// Act
+	start := time.Now()
+	_, err := orderService.ProcessOrder(ctx, order)
+	elapsed := time.Since(start)
+
+	// Assert
+	var cancelled *PaymentCancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("Expected PaymentCancelledError, got %v", err)
+	}
+	if cancelled.Processor != "PayPal" {
+		t.Errorf("Expected processor 'PayPal', got '%s'", cancelled.Processor)
+	}
+	if elapsed >= 150*time.Millisecond {
+		t.Errorf("Expected processing to stop before the simulated delay, took %v", elapsed)
+	}
+}
+
+func TestOrderService_ProcessOrder_CancelledBeforeDiscount_ReturnsContextError(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewCreditCardProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	order := OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	}
+
+	// Act
+	_, err := orderService.ProcessOrder(ctx, order)
+
+	// Assert
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error to match context.Canceled, got %v", err)
+	}
+}
+
+func TestOrderService_RefundOrder_PartialRefund_KeepsOrderPaid(t *testing.T) {
+	// Arrange: Place an order through the mock processor
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("100.00", USD))
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error placing order, got %v", err)
+	}
+
+	// Act
+	result, err := orderService.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("30.00", USD))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid {
+		t.Errorf("Expected status %s, got %s", OrderStatusPaid, result.Status)
+	}
+	if result.RefundedAmount != MustParseMoney("30.00", USD) {
+		t.Errorf("Expected refunded amount 30.00, got %s", result.RefundedAmount)
+	}
+	if len(result.Refunds) != 1 {
+		t.Errorf("Expected 1 refund recorded, got %d", len(result.Refunds))
+	}
+}
+
+func TestOrderService_RefundOrder_FullRefund_MarksOrderRefunded(t *testing.T) {
+	// Arrange: Real processor so the refund is checked against the captured amount
+	orderService := NewOrderService(NewCreditCardProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "unknown",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error placing order, got %v", err)
+	}
+
+	// Act: A zero amount refunds everything that is left
+	result, err := orderService.RefundOrder(context.Background(), placed.OrderID, Money{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusRefunded {
+		t.Errorf("Expected status %s, got %s", OrderStatusRefunded, result.Status)
+	}
+	if result.RefundedAmount != MustParseMoney("102.90", USD) {
+		t.Errorf("Expected refunded amount 102.90, got %s", result.RefundedAmount)
+	}
+}
+
+func TestOrderService_RefundOrder_AlreadyRefunded_ReturnsNotRefundableError(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	mockDiscount := NewMockDiscountService(false, MustParseMoney("50.00", USD))
+	orderService := NewOrderService(mockProcessor, mockDiscount, NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("50.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+	if _, err := orderService.RefundOrder(context.Background(), placed.OrderID, Money{}); err != nil {
+		t.Fatalf("Expected first refund to succeed, got %v", err)
+	}
+
+	// Act
+	_, err := orderService.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("1.00", USD))
+
+	// Assert
+	if !errors.Is(err, ErrOrderNotRefundable) {
+		t.Errorf("Expected ErrOrderNotRefundable, got %v", err)
+	}
+}
+
+func TestOrderService_RefundOrder_UnknownOrder_ReturnsNotFoundError(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, Money{}), NewMockTaxService(), NewSequentialOrderIDGenerator())
+
+	// Act
+	_, err := orderService.RefundOrder(context.Background(), "order_missing", Money{})
+
+	// Assert
+	if !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+func TestOrderService_RefundOrder_ProcessorRejects_ReturnsWrappedError(t *testing.T) {
+	// Arrange: Refund larger than the captured amount
+	orderService := NewOrderService(NewPayPalProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "unknown",
+	})
+
+	// Act
+	_, err := orderService.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("50.00", USD))
+
+	// Assert
+	if !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("Expected ErrRefundExceedsCaptured, got %v", err)
+	}
+}
+
+func TestOrderService_ProcessOrder_DeferredCaptureForDistributor_AuthorizesOnly(t *testing.T) {
+	// Arrange: Distributor orders are reserved now and captured on shipment
+	orderService := NewOrderService(NewCreditCardProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithDeferredCapture("distributor"))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("200.00", USD),
+		Customer:     "warehouse@example.com",
+		CustomerType: "distributor",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusAuthorized {
+		t.Errorf("Expected status %s, got %s", OrderStatusAuthorized, result.Status)
+	}
+	if result.Payment.ExpiresAt.IsZero() {
+		t.Error("Expected the authorization to carry an expiry")
+	}
+}
+
+func TestOrderService_ProcessOrder_DeferredCaptureForOtherType_ChargesImmediately(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithDeferredCapture("distributor"))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("100.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid {
+		t.Errorf("Expected status %s, got %s", OrderStatusPaid, result.Status)
+	}
+}
+
+func TestOrderService_CaptureOrder_PartialCapture_MarksOrderPaid(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewCreditCardProcessor(), NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithDeferredCapture())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("200.00", USD),
+		Customer:     "warehouse@example.com",
+		CustomerType: "distributor",
+	})
+
+	// Act: Only part of the order ships
+	result, err := orderService.CaptureOrder(context.Background(), placed.OrderID, MustParseMoney("150.00", USD))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusPaid {
+		t.Errorf("Expected status %s, got %s", OrderStatusPaid, result.Status)
+	}
+	if result.Payment.GrossAmount.Decimal() != "154.35" {
+		t.Errorf("Expected captured gross 154.35, got %s", result.Payment.GrossAmount)
+	}
+	if result.FinalAmount.Decimal() != "150.00" {
+		t.Errorf("Expected final amount 150.00, got %s", result.FinalAmount)
+	}
+}
+
+func TestOrderService_CaptureOrder_PaidOrder_ReturnsNotCapturableError(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Act
+	_, err := orderService.CaptureOrder(context.Background(), placed.OrderID, Money{})
+
+	// Assert
+	if !errors.Is(err, ErrOrderNotCapturable) {
+		t.Errorf("Expected ErrOrderNotCapturable, got %v", err)
+	}
+}
+
+func TestOrderService_CancelOrder_AuthorizedOrder_ReleasesAuthorization(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithDeferredCapture())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "distributor",
+	})
+
+	// Act
+	result, err := orderService.CancelOrder(context.Background(), placed.OrderID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusCancelled {
+		t.Errorf("Expected status %s, got %s", OrderStatusCancelled, result.Status)
+	}
+	if result.Payment.Status != PaymentStatusReleased {
+		t.Errorf("Expected payment status %s, got %s", PaymentStatusReleased, result.Payment.Status)
+	}
+}
+
+func TestOrderService_CancelOrder_PaidOrder_VoidsPayment(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Act
+	result, err := orderService.CancelOrder(context.Background(), placed.OrderID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusCancelled {
+		t.Errorf("Expected status %s, got %s", OrderStatusCancelled, result.Status)
+	}
+	if len(result.Refunds) != 1 || result.Refunds[0].Type != RefundTypeVoid {
+		t.Errorf("Expected a single void recorded, got %+v", result.Refunds)
+	}
+}
+
+func TestOrderService_ProcessOrder_Success_RecordsStatusHistory(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithClock(clock))
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []OrderStatus{OrderStatusCreated, OrderStatusValidated, OrderStatusPending, OrderStatusPaid}
+	if len(result.StatusHistory) != len(want) {
+		t.Fatalf("Expected %d status changes, got %+v", len(want), result.StatusHistory)
+	}
+	for i, change := range result.StatusHistory {
+		if change.To != want[i] || change.Reason == "" || !change.At.Equal(clock.Now()) {
+			t.Errorf("Expected change %d to %s with reason and time, got %+v", i, want[i], change)
+		}
+	}
+}
+
+func TestOrderService_FulfillOrder_PaidOrder_CanBeRefundedButNotCancelled(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "regular",
+	})
+
+	// Act
+	result, err := orderService.FulfillOrder(context.Background(), placed.OrderID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Status != OrderStatusFulfilled {
+		t.Errorf("Expected status %s, got %s", OrderStatusFulfilled, result.Status)
+	}
+	if _, err := orderService.CancelOrder(context.Background(), placed.OrderID); !errors.Is(err, ErrOrderNotCancellable) {
+		t.Errorf("Expected ErrOrderNotCancellable, got %v", err)
+	}
+	refunded, err := orderService.RefundOrder(context.Background(), placed.OrderID, MustParseMoney("10.00", USD))
+	if err != nil || refunded.Status != OrderStatusRefunded {
+		t.Errorf("Expected a refunded order, got %s / %v", refunded.Status, err)
+	}
+}
+
+func TestOrderService_FulfillOrder_AuthorizedOrder_ReturnsIllegalTransitionError(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("10.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(), WithDeferredCapture())
+	placed, _ := orderService.ProcessOrder(context.Background(), OrderData{
+		Amount:       MustParseMoney("10.00", USD),
+		Customer:     "test@example.com",
+		CustomerType: "distributor",
+	})
+
+	// Act
+	_, err := orderService.FulfillOrder(context.Background(), placed.OrderID)
+
+	// Assert
+	var illegal *IllegalTransitionError
+	if !errors.As(err, &illegal) || illegal.From != OrderStatusAuthorized || illegal.To != OrderStatusFulfilled {
+		t.Errorf("Expected authorized -> fulfilled to be rejected, got %v", err)
+	}
+}
+
+func newIdempotentOrder(key string, amount string) OrderData {
+	return OrderData{
+		Amount:         MustParseMoney(amount, USD),
+		Customer:       "test@example.com",
+		CustomerType:   "regular",
+		IdempotencyKey: key,
+	}
+}
+
+func TestOrderService_ProcessOrder_RetryWithSameKey_ReplaysWithoutCharging(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := newIdempotentOrder("retry-1", "100.00")
+
+	// Act: The client retries after a timeout
+	first, err1 := orderService.ProcessOrder(context.Background(), order)
+	second, err2 := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Expected no errors, got %v / %v", err1, err2)
+	}
+	if mockProcessor.callCount() != 1 {
+		t.Errorf("Expected one charge, got %d", mockProcessor.callCount())
+	}
+	if second.OrderID != first.OrderID {
+		t.Errorf("Expected replayed order %s, got %s", first.OrderID, second.OrderID)
+	}
+	if first.Replayed || !second.Replayed {
+		t.Errorf("Expected only the retry to be marked as replayed, got %v / %v", first.Replayed, second.Replayed)
+	}
+}
+
+func TestOrderService_ProcessOrder_SameKeyDifferentPayload_ReturnsKeyReusedError(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	_, _ = orderService.ProcessOrder(context.Background(), newIdempotentOrder("retry-2", "100.00"))
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), newIdempotentOrder("retry-2", "250.00"))
+
+	// Assert
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	if mockProcessor.callCount() != 1 {
+		t.Errorf("Expected one charge, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_ExpiredKey_ProcessesAgain(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(),
+		WithIdempotencyStore(NewInMemoryIdempotencyStore(), time.Hour), WithClock(clock))
+	order := newIdempotentOrder("retry-3", "100.00")
+	_, _ = orderService.ProcessOrder(context.Background(), order)
+
+	// Act
+	clock.Advance(time.Hour)
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Replayed {
+		t.Error("Expected an expired key to be processed as a new order")
+	}
+	if mockProcessor.callCount() != 2 {
+		t.Errorf("Expected two charges, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_FailedAttempt_IsNotRemembered(t *testing.T) {
+	// Arrange: Processor fails the first time only
+	mockProcessor := NewMockPaymentProcessor(true)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := newIdempotentOrder("retry-4", "100.00")
+	_, firstErr := orderService.ProcessOrder(context.Background(), order)
+	mockProcessor.shouldFail = false
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if firstErr == nil {
+		t.Fatal("Expected the first attempt to fail")
+	}
+	if err != nil || result.Replayed {
+		t.Errorf("Expected a fresh successful attempt, got %+v / %v", result, err)
+	}
+}
+
+// paidOrderFailingRepository stores pending orders but fails to store them
+// once they are paid
+type paidOrderFailingRepository struct {
+	OrderRepository
+}
+
+func (r paidOrderFailingRepository) Save(order OrderResult) error {
+	if order.Status == OrderStatusPaid {
+		return errors.New("disk full")
+	}
+	return r.OrderRepository.Save(order)
+}
+
+func TestOrderService_ProcessOrder_StorageFailsAfterCharge_RetryReplaysWithoutCharging(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator(),
+		WithOrderRepository(paidOrderFailingRepository{OrderRepository: NewInMemoryOrderRepository()}))
+	order := newIdempotentOrder("retry-6", "100.00")
+
+	// Act
+	first, firstErr := orderService.ProcessOrder(context.Background(), order)
+	second, secondErr := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if firstErr == nil || first.OrderID == "" {
+		t.Fatalf("Expected the charged order back with the storage error, got %+v / %v", first, firstErr)
+	}
+	if secondErr != nil || !second.Replayed || second.OrderID != first.OrderID {
+		t.Errorf("Expected the retry to replay order %s, got %+v / %v", first.OrderID, second, secondErr)
+	}
+	if mockProcessor.callCount() != 1 {
+		t.Errorf("Expected one charge, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_ConcurrentRetries_ChargeOnce(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := newIdempotentOrder("retry-5", "100.00")
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = orderService.ProcessOrder(context.Background(), order)
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	if mockProcessor.callCount() != 1 {
+		t.Errorf("Expected one charge, got %d", mockProcessor.callCount())
+	}
+}
+
+type failingOrderIDGenerator struct{}
+
+func (failingOrderIDGenerator) NewOrderID() (string, error) {
+	return "", errors.New("entropy unavailable")
+}
+
+func TestOrderService_ProcessOrder_OrdersInSameSecond_GetDistinctIDs(t *testing.T) {
+	// Arrange: The old timestamp-based IDs collided within one second
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewULIDOrderIDGenerator())
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular"}
+
+	// Act
+	first, _ := orderService.ProcessOrder(context.Background(), order)
+	second, _ := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if first.OrderID == "" || first.OrderID == second.OrderID {
+		t.Errorf("Expected distinct order IDs, got %q and %q", first.OrderID, second.OrderID)
+	}
+}
+
+func TestOrderService_ProcessOrder_OrderIDGenerationFails_DoesNotCharge(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), failingOrderIDGenerator{})
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular"}
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "order ID generation failed") {
+		t.Errorf("Expected order ID generation error, got %v", err)
+	}
+	if mockProcessor.callCount() != 0 {
+		t.Errorf("Expected no charge, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_Success_StoresOrderWithPayment(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular"}
+
+	// Act
+	result, _ := orderService.ProcessOrder(context.Background(), order)
+	stored, err := orderService.GetOrder(result.OrderID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stored.Status != OrderStatusPaid || stored.Payment.TransactionID != "mock_txn" {
+		t.Errorf("Expected paid order with payment, got %+v", stored)
+	}
+}
+
+func TestOrderService_ProcessOrder_PaymentFails_StoresFailedOrder(t *testing.T) {
+	// Arrange
+	orderService := NewOrderService(NewMockPaymentProcessor(true), NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular"}
+
+	// Act
+	_, paymentErr := orderService.ProcessOrder(context.Background(), order)
+	orders, err := orderService.ListCustomerOrders("test@example.com")
+
+	// Assert
+	if paymentErr == nil {
+		t.Fatal("Expected payment error")
+	}
+	if err != nil || len(orders) != 1 || orders[0].Status != OrderStatusFailed {
+		t.Errorf("Expected one failed order, got %+v (%v)", orders, err)
+	}
+}
+
+func TestOrderService_RefundOrder_FileRepository_SurvivesRestart(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "orders.log")
+	repository, _ := NewFileOrderRepository(path)
+	orderService := NewOrderService(NewMockPaymentProcessor(false), NewMockDiscountService(false, MustParseMoney("95.00", USD)),
+		NewMockTaxService(), NewSequentialOrderIDGenerator(), WithOrderRepository(repository))
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular"}
+	result, _ := orderService.ProcessOrder(context.Background(), order)
+	_, _ = orderService.RefundOrder(context.Background(), result.OrderID, MustParseMoney("20.00", USD))
+
+	// Act
+	reopened, _ := NewFileOrderRepository(path)
+	stored, err := reopened.Get(result.OrderID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stored.Refunds) != 1 || stored.RefundedAmount != MustParseMoney("20.00", USD) {
+		t.Errorf("Expected refund to be persisted, got %+v", stored)
+	}
+}
+
+func newCouponOrderService(t *testing.T, processor PaymentProcessorInterface, coupon Coupon) OrderServiceInterface {
+	t.Helper()
+	discountService, err := NewDiscountServiceWithConfig(DiscountConfig{Coupons: []Coupon{coupon}}, NewSystemClock())
+	if err != nil {
+		t.Fatalf("Expected valid coupon, got %v", err)
+	}
+	return NewOrderService(processor, discountService, NewMockTaxService(), NewSequentialOrderIDGenerator())
+}
+
+func TestOrderService_ProcessOrder_WithCoupon_ReturnsDiscountBreakdown(t *testing.T) {
+	// Arrange
+	coupon := newPercentCoupon("DIST2024", 10, CouponStackingStackable)
+	orderService := newCouponOrderService(t, NewMockPaymentProcessor(false), coupon)
+	order := OrderData{Amount: MustParseMoney("200.00", USD), Customer: "dist@example.com", CustomerType: "distributor", CouponCodes: []string{"DIST2024"}}
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.FinalAmount != MustParseMoney("180.00", USD) {
+		t.Errorf("Expected 180.00, got %s", result.FinalAmount)
+	}
+	if len(result.Discounts) != 1 || result.Discounts[0].Code != "DIST2024" {
+		t.Errorf("Expected DIST2024 in breakdown, got %+v", result.Discounts)
+	}
+}
+
+func TestOrderService_ProcessOrder_CouponUsedUp_RejectsBeforePayment(t *testing.T) {
+	// Arrange
+	coupon := newPercentCoupon("ONCE", 10, CouponStackingStackable)
+	coupon.UsageLimit = 1
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := newCouponOrderService(t, mockProcessor, coupon)
+	order := OrderData{Amount: MustParseMoney("50.00", USD), Customer: "a@example.com", CustomerType: "regular", CouponCodes: []string{"ONCE"}}
+	_, _ = orderService.ProcessOrder(context.Background(), order)
+
+	// Act
+	order.Customer = "b@example.com"
+	_, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if !errors.Is(err, ErrCouponUsageExhausted) {
+		t.Errorf("Expected ErrCouponUsageExhausted, got %v", err)
+	}
+	if mockProcessor.callCount() != 1 {
+		t.Errorf("Expected only the first order to be charged, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_PaymentFails_ReleasesCoupon(t *testing.T) {
+	// Arrange
+	coupon := newPercentCoupon("ONCE", 10, CouponStackingStackable)
+	coupon.UsageLimit = 1
+	mockProcessor := NewMockPaymentProcessor(true)
+	orderService := newCouponOrderService(t, mockProcessor, coupon)
+	order := OrderData{Amount: MustParseMoney("50.00", USD), Customer: "a@example.com", CustomerType: "regular", CouponCodes: []string{"ONCE"}}
+	_, firstErr := orderService.ProcessOrder(context.Background(), order)
+	mockProcessor.shouldFail = false
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if firstErr == nil {
+		t.Fatal("Expected the first payment to fail")
+	}
+	if err != nil {
+		t.Errorf("Expected the coupon to be usable after the failed payment, got %v", err)
+	}
+}
+
+func TestOrderService_ProcessOrder_LineItems_ChargesLineTotal(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := OrderData{
+		LineItems: []LineItem{
+			{ProductID: "SKU-1", Quantity: 2, UnitPrice: MustParseMoney("12.75", USD)},
+			{ProductID: "SKU-2", Quantity: 3, UnitPrice: MustParseMoney("25.00", USD)},
+		},
+		Customer:     "test@example.com",
+		CustomerType: "premium",
+	}
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.OriginalAmount != MustParseMoney("100.50", USD) || mockProcessor.expectedAmount != MustParseMoney("85.42", USD) {
+		t.Errorf("Expected 100.50 charged as 85.42, got %s charged as %s", result.OriginalAmount, mockProcessor.expectedAmount)
+	}
+	if len(result.Lines) != 2 || result.Lines[1].Total != MustParseMoney("63.75", USD) {
+		t.Errorf("Expected per-line breakdown, got %+v", result.Lines)
+	}
+}
+
+func TestOrderService_ProcessOrder_InvalidLineItems_RejectsBeforePayment(t *testing.T) {
+	price := MustParseMoney("10.00", USD)
+
+	testCases := []struct {
+		name     string
+		order    OrderData
+		expected error
+	}{
+		{"zero quantity", OrderData{LineItems: []LineItem{{ProductID: "SKU-1", Quantity: 0, UnitPrice: price}}}, ErrInvalidLineQuantity},
+		{"duplicate line", OrderData{LineItems: []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: price}, {ProductID: "SKU-1", Quantity: 1, UnitPrice: price}}}, ErrDuplicateLineItem},
+		{"total mismatch", OrderData{Amount: MustParseMoney("15.00", USD), LineItems: []LineItem{{ProductID: "SKU-1", Quantity: 1, UnitPrice: price}}}, ErrOrderTotalMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mockProcessor := NewMockPaymentProcessor(false)
+			orderService := NewOrderService(mockProcessor, NewDiscountService(), NewMockTaxService(), NewSequentialOrderIDGenerator())
+			tc.order.Customer = "test@example.com"
+
+			// Act
+			_, err := orderService.ProcessOrder(context.Background(), tc.order)
+
+			// Assert
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+			if mockProcessor.callCount() != 0 {
+				t.Errorf("Expected no charge, got %d", mockProcessor.callCount())
+			}
+		})
+	}
+}
+
+func TestOrderService_ProcessOrder_WithTax_ChargesTaxOnDiscountedLines(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewDiscountService(), newTestTaxService(t), NewSequentialOrderIDGenerator())
+	order := OrderData{
+		LineItems: []LineItem{
+			{ProductID: "SKU-BOOK", Quantity: 1, UnitPrice: MustParseMoney("100.00", EUR), TaxCategory: "reduced"},
+			{ProductID: "SKU-MUG", Quantity: 1, UnitPrice: MustParseMoney("100.00", EUR)},
+		},
+		Customer:     "test@example.com",
+		CustomerType: "premium",
+		Country:      "DE",
+	}
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert: 15% premium discount first, then 7% and 19% VAT on 85.00 each
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Tax.TaxAmount != MustParseMoney("22.10", EUR) {
+		t.Errorf("Expected 22.10 VAT, got %s", result.Tax.TaxAmount)
+	}
+	if result.Tax.Lines[0].TaxCategory != "reduced" {
+		t.Errorf("Expected the line tax category to reach the tax service, got %+v", result.Tax.Lines[0])
+	}
+	if result.FinalAmount != MustParseMoney("192.10", EUR) || mockProcessor.expectedAmount != result.FinalAmount {
+		t.Errorf("Expected 192.10 to be charged, got final %s and charged %s", result.FinalAmount, mockProcessor.expectedAmount)
+	}
+}
+
+func TestOrderService_ProcessOrder_TaxFails_DoesNotCharge(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), newTestTaxService(t), NewSequentialOrderIDGenerator())
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular", Country: "JP"}
+
+	// Act
+	_, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if !errors.Is(err, ErrNoTaxJurisdiction) {
+		t.Errorf("Expected ErrNoTaxJurisdiction, got %v", err)
+	}
+	if mockProcessor.callCount() != 0 {
+		t.Errorf("Expected no payment, got %d", mockProcessor.callCount())
+	}
+}
+
+func TestOrderService_ProcessOrder_PassesOrderDetailsToProcessor(t *testing.T) {
+	// Arrange
+	mockProcessor := NewMockPaymentProcessor(false)
+	orderService := NewOrderService(mockProcessor, NewMockDiscountService(false, MustParseMoney("95.00", USD)), NewMockTaxService(), NewSequentialOrderIDGenerator())
+	order := OrderData{Amount: MustParseMoney("100.00", USD), Customer: "test@example.com", CustomerType: "regular", PreferredProcessor: "paypal"}
+
+	// Act
+	result, err := orderService.ProcessOrder(context.Background(), order)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := PaymentRequest{
+		Amount:             MustParseMoney("95.00", USD),
+		OrderID:            result.OrderID,
+		Customer:           "test@example.com",
+		CustomerType:       "regular",
+		PreferredProcessor: "paypal",
+	}
+	if mockProcessor.lastRequest != expected {
+		t.Errorf("Expected %+v, got %+v", expected, mockProcessor.lastRequest)
+	}
+}
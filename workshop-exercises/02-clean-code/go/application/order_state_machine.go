@@ -0,0 +1,127 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// ORDER STATE MACHINE
+// Which status an order may move to from which, and the record of each move
+// =============================================================================
+
+var ErrIllegalOrderTransition = errors.New("illegal order status transition")
+
+// IllegalTransitionError is returned for a move the machine does not allow.
+type IllegalTransitionError struct {
+	OrderID string
+	From    OrderStatus
+	To      OrderStatus
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("%v: order %s cannot go from %s to %s", ErrIllegalOrderTransition, e.OrderID, e.From, e.To)
+}
+
+func (e *IllegalTransitionError) Unwrap() error {
+	return ErrIllegalOrderTransition
+}
+
+// StatusChange is one entry of an order's history. The first entry has no
+// From: it is the order being created.
+type StatusChange struct {
+	From   OrderStatus
+	To     OrderStatus
+	At     time.Time
+	Reason string
+}
+
+type OrderStateMachineInterface interface {
+	CanTransition(from OrderStatus, to OrderStatus) bool
+	// Transition moves order to status and appends the change to its
+	// history, or returns an *IllegalTransitionError and leaves it as is.
+	Transition(order *OrderResult, to OrderStatus, reason string, at time.Time) error
+	// DOT describes the machine in Graphviz DOT.
+	DOT() string
+}
+
+type orderTransition struct {
+	from OrderStatus
+	to   []OrderStatus
+}
+
+// orderTransitions is kept in lifecycle order so DOT output is stable.
+// Pending is the payment in flight, disputed a chargeback under review; a
+// dispute can open before or after shipping and, when won, goes back to
+// where it came from.
+var orderTransitions = []orderTransition{
+	{OrderStatusCreated, []OrderStatus{OrderStatusValidated, OrderStatusCancelled}},
+	{OrderStatusValidated, []OrderStatus{OrderStatusPending, OrderStatusCancelled}},
+	{OrderStatusPending, []OrderStatus{OrderStatusAuthorized, OrderStatusPaid, OrderStatusFailed}},
+	{OrderStatusAuthorized, []OrderStatus{OrderStatusPaid, OrderStatusCancelled, OrderStatusFailed}},
+	{OrderStatusPaid, []OrderStatus{OrderStatusFulfilled, OrderStatusRefunded, OrderStatusCancelled, OrderStatusDisputed, OrderStatusFailed}},
+	{OrderStatusFulfilled, []OrderStatus{OrderStatusRefunded, OrderStatusDisputed}},
+	{OrderStatusDisputed, []OrderStatus{OrderStatusPaid, OrderStatusFulfilled, OrderStatusRefunded}},
+	{OrderStatusCancelled, nil},
+	{OrderStatusRefunded, nil},
+	{OrderStatusFailed, nil},
+}
+
+type OrderStateMachine struct {
+	transitions []orderTransition
+	allowed     map[OrderStatus]map[OrderStatus]bool
+}
+
+func NewOrderStateMachine() OrderStateMachineInterface {
+	machine := &OrderStateMachine{
+		transitions: orderTransitions,
+		allowed:     make(map[OrderStatus]map[OrderStatus]bool),
+	}
+	for _, transition := range orderTransitions {
+		machine.allowed[transition.from] = make(map[OrderStatus]bool)
+		for _, to := range transition.to {
+			machine.allowed[transition.from][to] = true
+		}
+	}
+	return machine
+}
+
+func (m *OrderStateMachine) CanTransition(from OrderStatus, to OrderStatus) bool {
+	return m.allowed[from][to]
+}
+
+func (m *OrderStateMachine) Transition(order *OrderResult, to OrderStatus, reason string, at time.Time) error {
+	if !m.CanTransition(order.Status, to) {
+		return &IllegalTransitionError{OrderID: order.OrderID, From: order.Status, To: to}
+	}
+	order.StatusHistory = append(order.StatusHistory, StatusChange{From: order.Status, To: to, At: at, Reason: reason})
+	order.Status = to
+	return nil
+}
+
+// DOT draws final states with a double circle.
+func (m *OrderStateMachine) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph order_status {\n")
+	dot.WriteString("  rankdir=LR;\n")
+	dot.WriteString("  node [shape=circle];\n")
+	for _, transition := range m.transitions {
+		if len(transition.to) == 0 {
+			fmt.Fprintf(&dot, "  %q [shape=doublecircle];\n", transition.from)
+		}
+	}
+	for _, transition := range m.transitions {
+		for _, to := range transition.to {
+			fmt.Fprintf(&dot, "  %q -> %q;\n", transition.from, to)
+		}
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// newOrderHistory starts the history of an order that was just created.
+func newOrderHistory(at time.Time) []StatusChange {
+	return []StatusChange{{To: OrderStatusCreated, At: at, Reason: "order placed"}}
+}
@@ -0,0 +1,121 @@
+package application
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ORDER STATE MACHINE TESTS
+// Testing: order_state_machine.go
+// =============================================================================
+
+func TestOrderStateMachine_CanTransition(t *testing.T) {
+	machine := NewOrderStateMachine()
+	tests := []struct {
+		from OrderStatus
+		to   OrderStatus
+		want bool
+	}{
+		{OrderStatusCreated, OrderStatusValidated, true},
+		{OrderStatusValidated, OrderStatusPending, true},
+		{OrderStatusPending, OrderStatusAuthorized, true},
+		{OrderStatusPending, OrderStatusPaid, true},
+		{OrderStatusAuthorized, OrderStatusPaid, true},
+		{OrderStatusPaid, OrderStatusFulfilled, true},
+		{OrderStatusFulfilled, OrderStatusRefunded, true},
+		{OrderStatusFulfilled, OrderStatusDisputed, true},
+		{OrderStatusDisputed, OrderStatusPaid, true},
+		{OrderStatusDisputed, OrderStatusFulfilled, true},
+		{OrderStatusCreated, OrderStatusPaid, false},
+		{OrderStatusPaid, OrderStatusAuthorized, false},
+		{OrderStatusPaid, OrderStatusPaid, false},
+		{OrderStatusFulfilled, OrderStatusCancelled, false},
+		{OrderStatusRefunded, OrderStatusPaid, false},
+		{OrderStatusFailed, OrderStatusPending, false},
+		{OrderStatusCancelled, OrderStatusRefunded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			// Act
+			got := machine.CanTransition(tt.from, tt.to)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOrderStateMachine_Transition_RecordsTimestampAndReason(t *testing.T) {
+	// Arrange
+	machine := NewOrderStateMachine()
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	order := OrderResult{OrderID: "order_1", Status: OrderStatusPaid}
+
+	// Act
+	err := machine.Transition(&order, OrderStatusFulfilled, "shipped with DHL", at)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if order.Status != OrderStatusFulfilled {
+		t.Errorf("Expected status %s, got %s", OrderStatusFulfilled, order.Status)
+	}
+	want := StatusChange{From: OrderStatusPaid, To: OrderStatusFulfilled, At: at, Reason: "shipped with DHL"}
+	if len(order.StatusHistory) != 1 || order.StatusHistory[0] != want {
+		t.Errorf("Expected history %+v, got %+v", want, order.StatusHistory)
+	}
+}
+
+func TestOrderStateMachine_Transition_Illegal_ReturnsTypedErrorAndLeavesOrder(t *testing.T) {
+	// Arrange
+	machine := NewOrderStateMachine()
+	order := OrderResult{OrderID: "order_1", Status: OrderStatusRefunded}
+
+	// Act
+	err := machine.Transition(&order, OrderStatusPaid, "retry", time.Now())
+
+	// Assert
+	var illegal *IllegalTransitionError
+	if !errors.As(err, &illegal) || !errors.Is(err, ErrIllegalOrderTransition) {
+		t.Fatalf("Expected an IllegalTransitionError, got %v", err)
+	}
+	if illegal.OrderID != "order_1" || illegal.From != OrderStatusRefunded || illegal.To != OrderStatusPaid {
+		t.Errorf("Expected order_1 refunded -> paid, got %+v", illegal)
+	}
+	if order.Status != OrderStatusRefunded || len(order.StatusHistory) != 0 {
+		t.Errorf("Expected the order unchanged, got %s with %d changes", order.Status, len(order.StatusHistory))
+	}
+}
+
+func TestOrderStateMachine_DOT_ListsEveryTransitionAndFinalState(t *testing.T) {
+	// Arrange
+	machine := NewOrderStateMachine()
+
+	// Act
+	dot := machine.DOT()
+
+	// Assert
+	if !strings.HasPrefix(dot, "digraph order_status {") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("Expected a digraph, got %q", dot)
+	}
+	for _, edge := range []string{`"created" -> "validated";`, `"pending" -> "paid";`, `"paid" -> "fulfilled";`, `"disputed" -> "refunded";`} {
+		if !strings.Contains(dot, edge) {
+			t.Errorf("Expected edge %s in %q", edge, dot)
+		}
+	}
+	for _, final := range []OrderStatus{OrderStatusCancelled, OrderStatusRefunded, OrderStatusFailed} {
+		if !strings.Contains(dot, `"`+string(final)+`" [shape=doublecircle];`) {
+			t.Errorf("Expected %s drawn as final state", final)
+		}
+	}
+	if strings.Count(dot, "->") != 20 {
+		t.Errorf("Expected 20 transitions, got %d", strings.Count(dot, "->"))
+	}
+}
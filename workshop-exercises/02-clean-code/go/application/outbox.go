@@ -0,0 +1,152 @@
+package application
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// OUTBOX
+// Domain events stored together with the order change they describe, kept
+// until a relay has delivered them
+// =============================================================================
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	ErrUnknownDomainEvent    = errors.New("unknown domain event")
+)
+
+// OutboxMessage is one stored event. ID stays the same on every delivery
+// attempt, so consumers deduplicate on it. A Parked message has failed too
+// often to be retried; it stays in the outbox for someone to look at.
+type OutboxMessage struct {
+	ID            string          `json:"id"`
+	OrderID       string          `json:"orderId"`
+	EventName     string          `json:"eventName"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Attempts      int             `json:"attempts,omitempty"`
+	LastError     string          `json:"lastError,omitempty"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	Parked        bool            `json:"parked,omitempty"`
+}
+
+// OutboxRepository is an OrderRepository that can also hold outbox messages.
+// SaveWithOutbox stores the order and its messages in one atomic write: either
+// both are there after a crash or neither is. A delivered message leaves the
+// outbox; a failed attempt is recorded with when to try again, or parked.
+// PendingOutbox leaves parked messages out; ParkedOutbox lists them.
+type OutboxRepository interface {
+	OrderRepository
+	SaveWithOutbox(order OrderResult, messages []OutboxMessage) error
+	PendingOutbox(due time.Time, limit int) ([]OutboxMessage, error)
+	ParkedOutbox() ([]OutboxMessage, error)
+	MarkOutboxDelivered(messageID string) error
+	RecordOutboxFailure(message OutboxMessage) error
+}
+
+func NewOutboxMessage(event DomainEvent) (OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxMessage{}, fmt.Errorf("encode %s event: %w", event.EventName(), err)
+	}
+	return OutboxMessage{
+		ID:        "msg_" + randomHex(12),
+		OrderID:   event.EventOrderID(),
+		EventName: event.EventName(),
+		Payload:   payload,
+		CreatedAt: event.EventTime(),
+	}, nil
+}
+
+// DecodeDomainEvent turns a message back into the event it was made from.
+func DecodeDomainEvent(message OutboxMessage) (DomainEvent, error) {
+	switch message.EventName {
+	case EventOrderValidated:
+		return decodeOutboxPayload[OrderValidated](message)
+	case EventDiscountApplied:
+		return decodeOutboxPayload[DiscountApplied](message)
+	case EventPaymentSucceeded:
+		return decodeOutboxPayload[PaymentSucceeded](message)
+	case EventPaymentFailed:
+		return decodeOutboxPayload[PaymentFailed](message)
+	case EventOrderCompleted:
+		return decodeOutboxPayload[OrderCompleted](message)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDomainEvent, message.EventName)
+	}
+}
+
+func decodeOutboxPayload[E DomainEvent](message OutboxMessage) (DomainEvent, error) {
+	var event E
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return nil, fmt.Errorf("decode %s message %s: %w", message.EventName, message.ID, err)
+	}
+	return event, nil
+}
+
+// outboxIndex is the outbox state shared by the repository implementations:
+// undelivered messages in the order they were added.
+type outboxIndex struct {
+	messages map[string]OutboxMessage
+	sequence []string
+}
+
+func newOutboxIndex() *outboxIndex {
+	return &outboxIndex{messages: make(map[string]OutboxMessage)}
+}
+
+func (i *outboxIndex) add(messages []OutboxMessage) {
+	for _, message := range messages {
+		if _, exists := i.messages[message.ID]; !exists {
+			i.sequence = append(i.sequence, message.ID)
+		}
+		i.messages[message.ID] = message
+	}
+}
+
+func (i *outboxIndex) pending(due time.Time, limit int) []OutboxMessage {
+	var pending []OutboxMessage
+	for _, id := range i.sequence {
+		if limit > 0 && len(pending) == limit {
+			break
+		}
+		if message := i.messages[id]; !message.Parked && !message.NextAttemptAt.After(due) {
+			pending = append(pending, message)
+		}
+	}
+	return pending
+}
+
+func (i *outboxIndex) parked() []OutboxMessage {
+	var parked []OutboxMessage
+	for _, id := range i.sequence {
+		if message := i.messages[id]; message.Parked {
+			parked = append(parked, message)
+		}
+	}
+	return parked
+}
+
+func (i *outboxIndex) check(messageID string) error {
+	if _, ok := i.messages[messageID]; !ok {
+		return fmt.Errorf("%w: %s", ErrOutboxMessageNotFound, messageID)
+	}
+	return nil
+}
+
+func (i *outboxIndex) remove(messageID string) {
+	delete(i.messages, messageID)
+	for index, id := range i.sequence {
+		if id == messageID {
+			i.sequence = append(i.sequence[:index:index], i.sequence[index+1:]...)
+			return
+		}
+	}
+}
+
+func (i *outboxIndex) update(message OutboxMessage) {
+	i.messages[message.ID] = message
+}
@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// OUTBOX RELAY
+// Delivers outbox messages at least once, retrying failures with backoff
+// =============================================================================
+
+var (
+	ErrInvalidOutboxRelayConfig = errors.New("invalid outbox relay configuration")
+	ErrOutboxMessageParked      = errors.New("outbox message parked")
+)
+
+// OutboxConsumerInterface receives relayed messages. A message can arrive
+// more than once, e.g. when the process dies between delivering it and
+// recording the delivery; consumers deduplicate on its ID.
+type OutboxConsumerInterface interface {
+	Deliver(ctx context.Context, message OutboxMessage) error
+}
+
+type OutboxConsumerFunc func(ctx context.Context, message OutboxMessage) error
+
+func (f OutboxConsumerFunc) Deliver(ctx context.Context, message OutboxMessage) error {
+	return f(ctx, message)
+}
+
+type OutboxRelayInterface interface {
+	// RelayPending delivers the messages that are due once and reports how
+	// many were delivered.
+	RelayPending(ctx context.Context) (int, error)
+	// Run relays until ctx is done, pausing PollInterval whenever nothing
+	// was delivered. Failures go to OnFailure; without it Run stops at the
+	// first store failure and returns it.
+	Run(ctx context.Context) error
+}
+
+// OutboxRelayConfig leaves Clock and Sleeper at the system defaults when nil.
+// A failed message waits InitialBackoff, doubling per attempt up to
+// MaxBackoff. After MaxAttempts failures it is parked, e.g. an event this
+// build cannot decode, so it stops taking up every batch. OnFailure, if set,
+// hears about store failures and parked messages.
+type OutboxRelayConfig struct {
+	BatchSize      int
+	PollInterval   time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxAttempts    int
+	Clock          Clock
+	Sleeper        Sleeper
+	OnFailure      func(err error)
+}
+
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		BatchSize:      100,
+		PollInterval:   time.Second,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+		MaxAttempts:    10,
+		Clock:          NewSystemClock(),
+		Sleeper:        NewSystemSleeper(),
+	}
+}
+
+func (c OutboxRelayConfig) validate() error {
+	if c.BatchSize < 1 || c.PollInterval <= 0 {
+		return fmt.Errorf("%w: batch size and poll interval must be positive", ErrInvalidOutboxRelayConfig)
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("%w: backoff must grow from InitialBackoff up to MaxBackoff", ErrInvalidOutboxRelayConfig)
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("%w: max attempts must be positive", ErrInvalidOutboxRelayConfig)
+	}
+	return nil
+}
+
+type OutboxRelay struct {
+	store    OutboxRepository
+	consumer OutboxConsumerInterface
+	config   OutboxRelayConfig
+	backoff  RetryPolicy
+}
+
+func NewOutboxRelay(store OutboxRepository, consumer OutboxConsumerInterface, config OutboxRelayConfig) (OutboxRelayInterface, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if config.Clock == nil {
+		config.Clock = NewSystemClock()
+	}
+	if config.Sleeper == nil {
+		config.Sleeper = NewSystemSleeper()
+	}
+	return &OutboxRelay{
+		store:    store,
+		consumer: consumer,
+		config:   config,
+		backoff: RetryPolicy{
+			InitialBackoff: config.InitialBackoff,
+			MaxBackoff:     config.MaxBackoff,
+			Multiplier:     2,
+		},
+	}, nil
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	for {
+		delivered, err := r.RelayPending(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if r.config.OnFailure == nil {
+				return err
+			}
+			r.config.OnFailure(err)
+		}
+		if err != nil || delivered < r.config.BatchSize {
+			if err := r.config.Sleeper.Sleep(ctx, r.config.PollInterval); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RelayPending only returns an error when the store fails; a consumer
+// failure is recorded on the message and retried later, or parked.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	messages, err := r.store.PendingOutbox(r.config.Clock.Now(), r.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("load outbox: %w", err)
+	}
+	delivered := 0
+	for _, message := range messages {
+		if err := ctx.Err(); err != nil {
+			return delivered, err
+		}
+		ok, err := r.relay(ctx, message)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, message OutboxMessage) (bool, error) {
+	if err := r.consumer.Deliver(ctx, message); err != nil {
+		return false, r.recordFailure(message, err)
+	}
+	if err := r.store.MarkOutboxDelivered(message.ID); err != nil {
+		return false, fmt.Errorf("mark outbox message %s delivered: %w", message.ID, err)
+	}
+	return true, nil
+}
+
+func (r *OutboxRelay) recordFailure(message OutboxMessage, cause error) error {
+	message.Attempts++
+	message.LastError = cause.Error()
+	message.NextAttemptAt = r.config.Clock.Now().Add(r.backoff.Backoff(message.Attempts, 0))
+	message.Parked = message.Attempts >= r.config.MaxAttempts
+	if err := r.store.RecordOutboxFailure(message); err != nil {
+		return fmt.Errorf("record outbox message %s failure: %w", message.ID, err)
+	}
+	if message.Parked && r.config.OnFailure != nil {
+		r.config.OnFailure(fmt.Errorf("%w: %s %s after %d attempts: %s", ErrOutboxMessageParked, message.EventName, message.ID, message.Attempts, message.LastError))
+	}
+	return nil
+}
+
+// =============================================================================
+// OUTBOX CONSUMERS
+// =============================================================================
+
+// DeduplicatingOutboxConsumer passes each message ID to consumer only once.
+// The ID is claimed before delivering and given back if delivery fails, so a
+// retry is not mistaken for a duplicate. A crash mid-delivery cannot give it
+// back, so processed must not outlive the process with a claim held: the
+// in-memory store goes with it, and a durable store has to drop claims that
+// were never confirmed, or the relay's retry of the message is skipped as
+// already delivered.
+type DeduplicatingOutboxConsumer struct {
+	consumer  OutboxConsumerInterface
+	processed ProcessedEventStoreInterface
+	clock     Clock
+}
+
+func NewDeduplicatingOutboxConsumer(consumer OutboxConsumerInterface, processed ProcessedEventStoreInterface, clock Clock) OutboxConsumerInterface {
+	return &DeduplicatingOutboxConsumer{consumer: consumer, processed: processed, clock: clock}
+}
+
+func (c *DeduplicatingOutboxConsumer) Deliver(ctx context.Context, message OutboxMessage) error {
+	first, err := c.processed.Claim(message.ID, c.clock.Now())
+	if err != nil {
+		return err
+	}
+	if !first {
+		return nil
+	}
+	if err := c.consumer.Deliver(ctx, message); err != nil {
+		if releaseErr := c.processed.Release(message.ID); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// NewEventBusOutboxConsumer republishes relayed events on bus. Delivery ends
+// when the bus has the event; subscriber failures stay with the bus.
+func NewEventBusOutboxConsumer(bus EventBusInterface) OutboxConsumerInterface {
+	return OutboxConsumerFunc(func(ctx context.Context, message OutboxMessage) error {
+		event, err := DecodeDomainEvent(message)
+		if err != nil {
+			return err
+		}
+		bus.Publish(ctx, event)
+		return nil
+	})
+}
@@ -0,0 +1,306 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// OUTBOX RELAY TESTS
+// Testing: outbox_relay.go
+// =============================================================================
+
+type recordingOutboxConsumer struct {
+	mu        sync.Mutex
+	failures  int
+	delivered []string
+}
+
+func (c *recordingOutboxConsumer) Deliver(ctx context.Context, message OutboxMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("broker unavailable")
+	}
+	c.delivered = append(c.delivered, message.ID)
+	return nil
+}
+
+func (c *recordingOutboxConsumer) deliveredIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.delivered...)
+}
+
+func newTestOutboxRelay(t *testing.T, store OutboxRepository, consumer OutboxConsumerInterface, clock Clock) OutboxRelayInterface {
+	t.Helper()
+	config := DefaultOutboxRelayConfig()
+	config.Clock = clock
+	relay, err := NewOutboxRelay(store, consumer, config)
+	if err != nil {
+		t.Fatalf("Expected no error creating relay, got %v", err)
+	}
+	return relay
+}
+
+func TestOutboxRelay_RelayPending_DeliversAndRemovesMessages(t *testing.T) {
+	// Arrange
+	store := NewInMemoryOutboxRepository()
+	message := newTestOutboxMessage(t, "order_1")
+	_ = store.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{message})
+	consumer := &recordingOutboxConsumer{}
+	relay := newTestOutboxRelay(t, store, consumer, NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	// Act
+	delivered, err := relay.RelayPending(context.Background())
+	again, _ := relay.RelayPending(context.Background())
+
+	// Assert
+	if err != nil || delivered != 1 {
+		t.Fatalf("Expected 1 delivery, got %d (%v)", delivered, err)
+	}
+	if again != 0 {
+		t.Errorf("Expected nothing left to relay, got %d", again)
+	}
+	if ids := consumer.deliveredIDs(); len(ids) != 1 || ids[0] != message.ID {
+		t.Errorf("Expected %s delivered, got %v", message.ID, ids)
+	}
+}
+
+func TestOutboxRelay_RelayPending_FailureIsRetriedAfterBackoff(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	store := NewInMemoryOutboxRepository()
+	message := newTestOutboxMessage(t, "order_1")
+	_ = store.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{message})
+	consumer := &recordingOutboxConsumer{failures: 2}
+	relay := newTestOutboxRelay(t, store, consumer, clock)
+
+	// Act
+	relay.RelayPending(context.Background())
+	pending, _ := store.PendingOutbox(clock.Now().Add(time.Second), 0)
+	tooEarly, _ := relay.RelayPending(context.Background())
+	clock.Advance(time.Second)
+	relay.RelayPending(context.Background())
+	afterSecondFailure, _ := store.PendingOutbox(clock.Now().Add(2*time.Second), 0)
+	clock.Advance(2 * time.Second)
+	delivered, _ := relay.RelayPending(context.Background())
+
+	// Assert
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "broker unavailable" {
+		t.Fatalf("Expected the first failure tracked on the message, got %+v", pending)
+	}
+	if tooEarly != 0 {
+		t.Errorf("Expected no retry before the backoff elapsed, got %d deliveries", tooEarly)
+	}
+	if len(afterSecondFailure) != 1 || afterSecondFailure[0].Attempts != 2 {
+		t.Errorf("Expected a second attempt with doubled backoff, got %+v", afterSecondFailure)
+	}
+	if delivered != 1 {
+		t.Errorf("Expected the third attempt to deliver, got %d", delivered)
+	}
+}
+
+func TestOutboxRelay_RelayPending_OneFailureDoesNotBlockOthers(t *testing.T) {
+	// Arrange
+	store := NewInMemoryOutboxRepository()
+	first := newTestOutboxMessage(t, "order_1")
+	second := newTestOutboxMessage(t, "order_2")
+	_ = store.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{first})
+	_ = store.SaveWithOutbox(newTestOrder("order_2", "alice@example.com"), []OutboxMessage{second})
+	consumer := &recordingOutboxConsumer{failures: 1}
+	relay := newTestOutboxRelay(t, store, consumer, NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	// Act
+	delivered, err := relay.RelayPending(context.Background())
+
+	// Assert
+	if err != nil || delivered != 1 {
+		t.Fatalf("Expected 1 delivery, got %d (%v)", delivered, err)
+	}
+	if ids := consumer.deliveredIDs(); len(ids) != 1 || ids[0] != second.ID {
+		t.Errorf("Expected the second message delivered, got %v", ids)
+	}
+}
+
+func TestOutboxRelay_RelayPending_UndecodableMessage_IsParkedAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	store := NewInMemoryOutboxRepository()
+	poison := newTestOutboxMessage(t, "order_1")
+	poison.EventName = "order.renamed"
+	_ = store.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{poison})
+	var failures []error
+	config := DefaultOutboxRelayConfig()
+	config.MaxAttempts = 2
+	config.Clock = clock
+	config.OnFailure = func(err error) { failures = append(failures, err) }
+	relay, _ := NewOutboxRelay(store, NewEventBusOutboxConsumer(NewEventBus()), config)
+
+	// Act
+	for attempt := 0; attempt < 3; attempt++ {
+		relay.RelayPending(context.Background())
+		clock.Advance(config.MaxBackoff)
+	}
+
+	// Assert
+	pending, _ := store.PendingOutbox(clock.Now(), 0)
+	parked, _ := store.ParkedOutbox()
+	if len(pending) != 0 {
+		t.Errorf("Expected nothing left to retry, got %+v", pending)
+	}
+	if len(parked) != 1 || parked[0].ID != poison.ID || parked[0].Attempts != 2 {
+		t.Fatalf("Expected the message parked after 2 attempts, got %+v", parked)
+	}
+	if len(failures) != 1 || !errors.Is(failures[0], ErrOutboxMessageParked) {
+		t.Errorf("Expected OnFailure to hear about the parked message, got %v", failures)
+	}
+}
+
+// failingOutboxRepository cannot load the outbox.
+type failingOutboxRepository struct {
+	OutboxRepository
+}
+
+func (r failingOutboxRepository) PendingOutbox(due time.Time, limit int) ([]OutboxMessage, error) {
+	return nil, errors.New("disk unavailable")
+}
+
+func TestOutboxRelay_Run_StoreFailure_ReportsAndKeepsRelaying(t *testing.T) {
+	// Arrange
+	var failures []error
+	config := DefaultOutboxRelayConfig()
+	config.PollInterval = time.Millisecond
+	config.OnFailure = func(err error) { failures = append(failures, err) }
+	relay, _ := NewOutboxRelay(failingOutboxRepository{NewInMemoryOutboxRepository()}, &recordingOutboxConsumer{}, config)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := relay.Run(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Run to keep going until the deadline, got %v", err)
+	}
+	if len(failures) < 2 {
+		t.Errorf("Expected every failed poll reported, got %v", failures)
+	}
+}
+
+func TestOutboxRelay_Run_StoreFailureWithoutOnFailure_ReturnsError(t *testing.T) {
+	// Arrange
+	relay, _ := NewOutboxRelay(failingOutboxRepository{NewInMemoryOutboxRepository()}, &recordingOutboxConsumer{}, DefaultOutboxRelayConfig())
+
+	// Act
+	err := relay.Run(context.Background())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "disk unavailable") {
+		t.Errorf("Expected the store failure, got %v", err)
+	}
+}
+
+func TestOutboxRelay_Run_StopsWhenContextEnds(t *testing.T) {
+	// Arrange
+	store := NewInMemoryOutboxRepository()
+	_ = store.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{newTestOutboxMessage(t, "order_1")})
+	consumer := &recordingOutboxConsumer{}
+	config := DefaultOutboxRelayConfig()
+	config.PollInterval = time.Millisecond
+	relay, _ := NewOutboxRelay(store, consumer, config)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := relay.Run(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if len(consumer.deliveredIDs()) != 1 {
+		t.Errorf("Expected the message delivered while running, got %v", consumer.deliveredIDs())
+	}
+}
+
+func TestNewOutboxRelay_InvalidConfig_ReturnsError(t *testing.T) {
+	// Arrange
+	config := DefaultOutboxRelayConfig()
+	config.MaxBackoff = config.InitialBackoff / 2
+
+	// Act
+	_, err := NewOutboxRelay(NewInMemoryOutboxRepository(), &recordingOutboxConsumer{}, config)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidOutboxRelayConfig) {
+		t.Errorf("Expected ErrInvalidOutboxRelayConfig, got %v", err)
+	}
+}
+
+func TestDeduplicatingOutboxConsumer_Deliver_RedeliveryIsSkipped(t *testing.T) {
+	// Arrange: The relay crashed after delivering but before marking delivered
+	inner := &recordingOutboxConsumer{}
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	consumer := NewDeduplicatingOutboxConsumer(inner, NewInMemoryProcessedEventStore(time.Hour), clock)
+	message := newTestOutboxMessage(t, "order_1")
+
+	// Act
+	first := consumer.Deliver(context.Background(), message)
+	second := consumer.Deliver(context.Background(), message)
+
+	// Assert
+	if first != nil || second != nil {
+		t.Fatalf("Expected both deliveries acknowledged, got %v and %v", first, second)
+	}
+	if len(inner.deliveredIDs()) != 1 {
+		t.Errorf("Expected the consumer to see the message once, got %d", len(inner.deliveredIDs()))
+	}
+}
+
+func TestDeduplicatingOutboxConsumer_Deliver_FailureAllowsRetry(t *testing.T) {
+	// Arrange
+	inner := &recordingOutboxConsumer{failures: 1}
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	consumer := NewDeduplicatingOutboxConsumer(inner, NewInMemoryProcessedEventStore(time.Hour), clock)
+	message := newTestOutboxMessage(t, "order_1")
+
+	// Act
+	first := consumer.Deliver(context.Background(), message)
+	second := consumer.Deliver(context.Background(), message)
+
+	// Assert
+	if first == nil || second != nil {
+		t.Errorf("Expected the first attempt to fail and the retry to succeed, got %v and %v", first, second)
+	}
+	if len(inner.deliveredIDs()) != 1 {
+		t.Errorf("Expected the retry delivered, got %v", inner.deliveredIDs())
+	}
+}
+
+func TestEventBusOutboxConsumer_Deliver_PublishesDecodedEvent(t *testing.T) {
+	// Arrange
+	bus := NewEventBus()
+	var received OrderCompleted
+	Subscribe(bus, "loyalty", DeliverSync, func(ctx context.Context, event OrderCompleted) error {
+		received = event
+		return nil
+	})
+	consumer := NewEventBusOutboxConsumer(bus)
+
+	// Act
+	err := consumer.Deliver(context.Background(), newTestOutboxMessage(t, "order_1"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if received.OrderID != "order_1" || received.FinalAmount != MustParseMoney("10.00", USD) {
+		t.Errorf("Expected the decoded OrderCompleted, got %+v", received)
+	}
+}
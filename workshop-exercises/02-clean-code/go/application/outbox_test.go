@@ -0,0 +1,97 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// OUTBOX TESTS
+// Testing: outbox.go
+// =============================================================================
+
+func TestDecodeDomainEvent_RoundTripsEachEventType(t *testing.T) {
+	base := OrderEvent{OrderID: "order_1", Customer: "test@example.com", OccurredAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name  string
+		event DomainEvent
+	}{
+		{"validated", OrderValidated{OrderEvent: base, CustomerType: "premium", Amount: MustParseMoney("10.00", USD)}},
+		{"discount", DiscountApplied{OrderEvent: base, OriginalAmount: MustParseMoney("10.00", USD), TotalDiscount: MustParseMoney("1.00", USD), DiscountedAmount: MustParseMoney("9.00", USD)}},
+		{"succeeded", PaymentSucceeded{OrderEvent: base, Payment: PaymentResult{TransactionID: "txn_1", GrossAmount: MustParseMoney("9.30", USD), Status: PaymentStatusSucceeded}}},
+		{"failed", PaymentFailed{OrderEvent: base, Amount: MustParseMoney("9.00", USD), Reason: "declined"}},
+		{"completed", newTestOrderCompleted("order_1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			message, err := NewOutboxMessage(tt.event)
+			if err != nil {
+				t.Fatalf("Expected no error encoding, got %v", err)
+			}
+
+			// Act
+			decoded, err := DecodeDomainEvent(message)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error decoding, got %v", err)
+			}
+			if message.OrderID != "order_1" || message.EventName != tt.event.EventName() {
+				t.Errorf("Expected message for order_1 %s, got %s %s", tt.event.EventName(), message.OrderID, message.EventName)
+			}
+			if decoded.EventName() != tt.event.EventName() || decoded.EventOrderID() != "order_1" || !decoded.EventTime().Equal(base.OccurredAt) {
+				t.Errorf("Expected %+v back, got %+v", tt.event, decoded)
+			}
+		})
+	}
+}
+
+func TestDecodeDomainEvent_UnknownName_ReturnsError(t *testing.T) {
+	// Act
+	_, err := DecodeDomainEvent(OutboxMessage{ID: "msg_1", EventName: "order.shipped", Payload: []byte(`{}`)})
+
+	// Assert
+	if !errors.Is(err, ErrUnknownDomainEvent) {
+		t.Errorf("Expected ErrUnknownDomainEvent, got %v", err)
+	}
+}
+
+func TestInMemoryOutboxRepository_PendingOutbox_SkipsMessagesNotYetDue(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOutboxRepository()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := newTestOutboxMessage(t, "order_1")
+	second := newTestOutboxMessage(t, "order_1")
+	_ = repository.SaveWithOutbox(newTestOrder("order_1", "alice@example.com"), []OutboxMessage{first, second})
+	first.Attempts = 1
+	first.NextAttemptAt = now.Add(time.Minute)
+	_ = repository.RecordOutboxFailure(first)
+
+	// Act
+	pending, _ := repository.PendingOutbox(now, 10)
+	later, _ := repository.PendingOutbox(now.Add(time.Minute), 10)
+
+	// Assert
+	if len(pending) != 1 || pending[0].ID != second.ID {
+		t.Errorf("Expected only the second message due now, got %+v", pending)
+	}
+	if len(later) != 2 {
+		t.Errorf("Expected both messages due after the backoff, got %d", len(later))
+	}
+}
+
+func TestInMemoryOutboxRepository_MarkOutboxDelivered_UnknownMessage_ReturnsNotFound(t *testing.T) {
+	// Arrange
+	repository := NewInMemoryOutboxRepository()
+
+	// Act
+	err := repository.MarkOutboxDelivered("msg_missing")
+
+	// Assert
+	if !errors.Is(err, ErrOutboxMessageNotFound) {
+		t.Errorf("Expected ErrOutboxMessageNotFound, got %v", err)
+	}
+}
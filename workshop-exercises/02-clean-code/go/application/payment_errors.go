@@ -0,0 +1,155 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+)
+
+// =============================================================================
+// PAYMENT ERRORS
+// Typed processor failures; failover and retries decide from the kind
+// =============================================================================
+
+var (
+	// ErrPaymentDeclined is a definitive answer from the processor: the
+	// payment was refused and must not be tried again elsewhere. Insufficient
+	// funds and fraud blocks are declines too.
+	ErrPaymentDeclined   = errors.New("payment declined")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrFraudBlocked      = errors.New("payment blocked by fraud screening")
+	// ErrProcessorUnavailable marks a transient failure: the request never
+	// reached the processor, or the processor answered that it did nothing,
+	// so it may be retried. A processor must only report it when it knows.
+	ErrProcessorUnavailable  = errors.New("payment processor unavailable")
+	ErrInvalidPaymentRequest = errors.New("invalid payment request")
+	// ErrPaymentOutcomeUnknown means the request may have been acted on,
+	// e.g. it timed out after it was sent. Trying again could charge or
+	// refund twice, so it is never retried or failed over.
+	ErrPaymentOutcomeUnknown = errors.New("payment outcome unknown")
+)
+
+type PaymentErrorKind string
+
+const (
+	PaymentErrorDeclined          PaymentErrorKind = "declined"
+	PaymentErrorInsufficientFunds PaymentErrorKind = "insufficient_funds"
+	PaymentErrorFraudBlocked      PaymentErrorKind = "fraud_blocked"
+	PaymentErrorTransient         PaymentErrorKind = "transient"
+	PaymentErrorInvalidRequest    PaymentErrorKind = "invalid_request"
+	PaymentErrorOutcomeUnknown    PaymentErrorKind = "outcome_unknown"
+)
+
+// PaymentError is what a processor returns when it refuses or fails a
+// payment. errors.Is matches the sentinel for its kind, and every decline
+// kind also matches ErrPaymentDeclined. Code is the processor's own reason
+// code, if it gave one.
+type PaymentError struct {
+	Kind      PaymentErrorKind
+	Processor string
+	Code      string
+	Message   string
+	Cause     error
+}
+
+func NewPaymentError(kind PaymentErrorKind, processor string, message string) *PaymentError {
+	return &PaymentError{
+		Kind:      kind,
+		Processor: processor,
+		Message:   message,
+	}
+}
+
+func NewDeclinedError(processor string, code string, message string) *PaymentError {
+	err := NewPaymentError(PaymentErrorDeclined, processor, message)
+	err.Code = code
+	return err
+}
+
+func NewTransientPaymentError(processor string, cause error) *PaymentError {
+	err := NewPaymentError(PaymentErrorTransient, processor, "temporarily unavailable")
+	err.Cause = cause
+	return err
+}
+
+func NewOutcomeUnknownError(processor string, cause error) *PaymentError {
+	err := NewPaymentError(PaymentErrorOutcomeUnknown, processor, "the request may have been processed")
+	err.Cause = cause
+	return err
+}
+
+func (e *PaymentError) Error() string {
+	text := fmt.Sprintf("%s payment failed (%s)", e.Processor, e.Kind)
+	if e.Message != "" {
+		text += ": " + e.Message
+	}
+	if e.Code != "" {
+		text += fmt.Sprintf(" [code %s]", e.Code)
+	}
+	if e.Cause != nil {
+		text += fmt.Sprintf(": %v", e.Cause)
+	}
+	return text
+}
+
+func (e *PaymentError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *PaymentError) Is(target error) bool {
+	if target == ErrPaymentDeclined {
+		return e.IsDecline()
+	}
+	return target == e.Kind.sentinel()
+}
+
+// IsDecline reports whether the processor definitively refused the payment.
+func (e *PaymentError) IsDecline() bool {
+	switch e.Kind {
+	case PaymentErrorDeclined, PaymentErrorInsufficientFunds, PaymentErrorFraudBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *PaymentError) Retryable() bool {
+	return e.Kind == PaymentErrorTransient
+}
+
+func (k PaymentErrorKind) sentinel() error {
+	switch k {
+	case PaymentErrorDeclined:
+		return ErrPaymentDeclined
+	case PaymentErrorInsufficientFunds:
+		return ErrInsufficientFunds
+	case PaymentErrorFraudBlocked:
+		return ErrFraudBlocked
+	case PaymentErrorTransient:
+		return ErrProcessorUnavailable
+	case PaymentErrorInvalidRequest:
+		return ErrInvalidPaymentRequest
+	case PaymentErrorOutcomeUnknown:
+		return ErrPaymentOutcomeUnknown
+	default:
+		return nil
+	}
+}
+
+// IsRetryablePaymentError reports whether trying again, on the same or
+// another processor, is safe and could succeed. Only failures the processor
+// marked transient are; declines, invalid requests, cancellations, unknown
+// outcomes and unclassified errors never are.
+func IsRetryablePaymentError(err error) bool {
+	if err == nil || errors.Is(err, ErrPaymentOutcomeUnknown) {
+		return false
+	}
+	var cancelled *PaymentCancelledError
+	if errors.As(err, &cancelled) {
+		return false
+	}
+	var paymentErr *PaymentError
+	if errors.As(err, &paymentErr) {
+		return paymentErr.Retryable()
+	}
+	return errors.Is(err, ErrProcessorUnavailable) && !errors.Is(err, ErrPaymentDeclined)
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// =============================================================================
+// PAYMENT ERROR TESTS
+// Testing: payment_errors.go
+// =============================================================================
+
+func TestIsRetryablePaymentError_Classification(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"unavailable", fmt.Errorf("card: %w", ErrProcessorUnavailable), true},
+		{"declined", fmt.Errorf("card: %w", ErrPaymentDeclined), false},
+		{"cancelled", NewPaymentCancelledError("Card", context.Canceled), false},
+		{"unclassified", errors.New("boom"), false},
+		{"typed transient", NewTransientPaymentError("PayPal", errors.New("503")), true},
+		{"typed decline", NewDeclinedError("PayPal", "05", "do not honor"), false},
+		{"typed invalid request", NewPaymentError(PaymentErrorInvalidRequest, "PayPal", "amount missing"), false},
+		{"typed outcome unknown", NewOutcomeUnknownError("PayPal", errors.New("read timeout")), false},
+		{"outcome unknown around transient", NewOutcomeUnknownError("PayPal", NewTransientPaymentError("PayPal", nil)), false},
+		{"nil", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act & Assert
+			if IsRetryablePaymentError(tc.err) != tc.retryable {
+				t.Errorf("Expected retryable=%v for %v", tc.retryable, tc.err)
+			}
+		})
+	}
+}
+
+func TestPaymentError_Is_MatchesKindAndDeclineFamily(t *testing.T) {
+	testCases := []struct {
+		kind      PaymentErrorKind
+		sentinel  error
+		isDecline bool
+	}{
+		{PaymentErrorDeclined, ErrPaymentDeclined, true},
+		{PaymentErrorInsufficientFunds, ErrInsufficientFunds, true},
+		{PaymentErrorFraudBlocked, ErrFraudBlocked, true},
+		{PaymentErrorTransient, ErrProcessorUnavailable, false},
+		{PaymentErrorInvalidRequest, ErrInvalidPaymentRequest, false},
+		{PaymentErrorOutcomeUnknown, ErrPaymentOutcomeUnknown, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			// Arrange
+			err := fmt.Errorf("payment processing failed: %w", NewPaymentError(tc.kind, "Credit Card", ""))
+
+			// Act & Assert
+			if !errors.Is(err, tc.sentinel) {
+				t.Errorf("Expected %v to match %v", err, tc.sentinel)
+			}
+			if errors.Is(err, ErrPaymentDeclined) != tc.isDecline {
+				t.Errorf("Expected decline=%v for %s", tc.isDecline, tc.kind)
+			}
+		})
+	}
+}
+
+func TestPaymentError_As_ExposesProcessorCode(t *testing.T) {
+	// Arrange
+	err := fmt.Errorf("payment processing failed: %w", NewDeclinedError("Credit Card", "51", "insufficient funds"))
+
+	// Act
+	var paymentErr *PaymentError
+	found := errors.As(err, &paymentErr)
+
+	// Assert
+	if !found || paymentErr.Code != "51" || paymentErr.Kind != PaymentErrorDeclined {
+		t.Errorf("Expected the typed decline, got %v", err)
+	}
+	if paymentErr.Error() != "Credit Card payment failed (declined): insufficient funds [code 51]" {
+		t.Errorf("Unexpected message %q", paymentErr.Error())
+	}
+}
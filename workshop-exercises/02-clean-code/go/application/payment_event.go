@@ -0,0 +1,64 @@
+package application
+
+import (
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// PAYMENT EVENTS
+// What providers report asynchronously about payments they already handled
+// =============================================================================
+
+var (
+	ErrUnknownPaymentEventType   = errors.New("unknown payment event type")
+	ErrInvalidPaymentEvent       = errors.New("invalid payment event")
+	ErrPaymentEventNotApplicable = errors.New("payment event does not apply to the order")
+)
+
+type PaymentEventType string
+
+const (
+	PaymentEventCaptureCompleted    PaymentEventType = "payment.capture.completed"
+	PaymentEventCaptureDenied       PaymentEventType = "payment.capture.denied"
+	PaymentEventRefundCompleted     PaymentEventType = "payment.refund.completed"
+	PaymentEventAuthorizationVoided PaymentEventType = "payment.authorization.voided"
+	PaymentEventDisputeOpened       PaymentEventType = "dispute.opened"
+	PaymentEventDisputeResolved     PaymentEventType = "dispute.resolved"
+)
+
+func (t PaymentEventType) IsKnown() bool {
+	switch t {
+	case PaymentEventCaptureCompleted, PaymentEventCaptureDenied, PaymentEventRefundCompleted,
+		PaymentEventAuthorizationVoided, PaymentEventDisputeOpened, PaymentEventDisputeResolved:
+		return true
+	default:
+		return false
+	}
+}
+
+type DisputeOutcome string
+
+const (
+	DisputeOutcomeWon  DisputeOutcome = "won"
+	DisputeOutcomeLost DisputeOutcome = "lost"
+)
+
+// PaymentEvent is one provider notification. ID is the provider's event ID,
+// the same on every redelivery.
+type PaymentEvent struct {
+	ID        string           `json:"id"`
+	Type      PaymentEventType `json:"type"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Data      PaymentEventData `json:"data"`
+}
+
+// PaymentEventData carries what the event type needs: refunds need Amount
+// and ReferenceID (the provider's refund ID), resolved disputes an Outcome.
+type PaymentEventData struct {
+	OrderID     string         `json:"orderId"`
+	Amount      Money          `json:"amount"`
+	ReferenceID string         `json:"referenceId,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+	Outcome     DisputeOutcome `json:"outcome,omitempty"`
+}
@@ -0,0 +1,298 @@
+package application
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// PAYMENT LEDGER
+// Per-processor record of captured payments and their reversals
+// =============================================================================
+
+type feeReversalRule func(entry ledgerEntry, amount Money) Money
+
+type ledgerEntry struct {
+	payment     PaymentResult
+	refunded    Money
+	feeReversed Money
+	voided      bool
+}
+
+func (e ledgerEntry) remaining() Money {
+	remaining, _ := e.payment.GrossAmount.Subtract(e.refunded)
+	return remaining
+}
+
+func (e ledgerEntry) remainingFee() Money {
+	remaining, _ := e.payment.Fee.Subtract(e.feeReversed)
+	return remaining
+}
+
+type ledgerReversal struct {
+	payment     PaymentResult
+	amount      Money
+	feeReversed Money
+	remaining   Money
+	voided      bool
+}
+
+func (r ledgerReversal) refundType() RefundType {
+	switch {
+	case r.voided:
+		return RefundTypeVoid
+	case r.remaining.IsZero():
+		return RefundTypeFull
+	default:
+		return RefundTypePartial
+	}
+}
+
+func (r ledgerReversal) paymentStatus() PaymentStatus {
+	switch {
+	case r.voided:
+		return PaymentStatusVoided
+	case r.remaining.IsZero():
+		return PaymentStatusRefunded
+	default:
+		return PaymentStatusPartiallyRefunded
+	}
+}
+
+type paymentLedger struct {
+	mu             sync.Mutex
+	entries        map[string]*ledgerEntry
+	authorizations map[string]*authorizationEntry
+}
+
+func newPaymentLedger() *paymentLedger {
+	return &paymentLedger{
+		entries:        make(map[string]*ledgerEntry),
+		authorizations: make(map[string]*authorizationEntry),
+	}
+}
+
+func (l *paymentLedger) record(payment PaymentResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recordLocked(payment)
+}
+
+func (l *paymentLedger) recordLocked(payment PaymentResult) {
+	currency := payment.GrossAmount.Currency()
+	l.entries[payment.TransactionID] = &ledgerEntry{
+		payment:     payment,
+		refunded:    ZeroMoney(currency),
+		feeReversed: ZeroMoney(currency),
+	}
+}
+
+func (l *paymentLedger) refund(request RefundRequest, rule feeReversalRule) (ledgerReversal, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findReversibleEntry(request.TransactionID)
+	if err != nil {
+		return ledgerReversal{}, err
+	}
+	amount, err := l.resolveRefundAmount(*entry, request)
+	if err != nil {
+		return ledgerReversal{}, err
+	}
+
+	fee := rule(*entry, amount)
+	entry.refunded, _ = entry.refunded.Add(amount)
+	entry.feeReversed, _ = entry.feeReversed.Add(fee)
+	return l.createReversal(*entry, amount, fee), nil
+}
+
+func (l *paymentLedger) void(transactionID string) (ledgerReversal, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findVoidableEntry(transactionID)
+	if err != nil {
+		return ledgerReversal{}, err
+	}
+
+	entry.voided = true
+	entry.refunded = entry.payment.GrossAmount
+	entry.feeReversed = entry.payment.Fee
+	return l.createReversal(*entry, entry.payment.GrossAmount, entry.payment.Fee), nil
+}
+
+// refundAmount checks a refund the way refund would without booking it, for
+// processors that have to ask a remote API before they record anything.
+func (l *paymentLedger) refundAmount(request RefundRequest) (Money, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findReversibleEntry(request.TransactionID)
+	if err != nil {
+		return Money{}, err
+	}
+	return l.resolveRefundAmount(*entry, request)
+}
+
+func (l *paymentLedger) checkVoidable(transactionID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.findVoidableEntry(transactionID)
+	return err
+}
+
+func (l *paymentLedger) findVoidableEntry(transactionID string) (*ledgerEntry, error) {
+	entry, err := l.findReversibleEntry(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.refunded.IsZero() {
+		return nil, ErrVoidNotAllowed
+	}
+	return entry, nil
+}
+
+func (l *paymentLedger) findReversibleEntry(transactionID string) (*ledgerEntry, error) {
+	entry, ok := l.entries[transactionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	if entry.voided {
+		return nil, fmt.Errorf("%w: %s", ErrTransactionVoided, transactionID)
+	}
+	return entry, nil
+}
+
+func (l *paymentLedger) resolveRefundAmount(entry ledgerEntry, request RefundRequest) (Money, error) {
+	remaining := entry.remaining()
+	if request.IsFull() {
+		if !remaining.IsPositive() {
+			return Money{}, fmt.Errorf("%w: nothing left to refund on %s", ErrRefundExceedsCaptured, request.TransactionID)
+		}
+		return remaining, nil
+	}
+	if !request.Amount.IsPositive() {
+		return Money{}, ErrInvalidRefundAmount
+	}
+	comparison, err := request.Amount.Compare(remaining)
+	if err != nil {
+		return Money{}, err
+	}
+	if comparison > 0 {
+		return Money{}, fmt.Errorf("%w: requested %s, refundable %s", ErrRefundExceedsCaptured, request.Amount, remaining)
+	}
+	return request.Amount, nil
+}
+
+func (l *paymentLedger) createReversal(entry ledgerEntry, amount Money, fee Money) ledgerReversal {
+	return ledgerReversal{
+		payment:     entry.payment,
+		amount:      amount,
+		feeReversed: fee,
+		remaining:   entry.remaining(),
+		voided:      entry.voided,
+	}
+}
+
+func (l *paymentLedger) authorize(authorization PaymentResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.authorizations[authorization.TransactionID] = &authorizationEntry{
+		authorization: authorization,
+		state:         authorizationPending,
+	}
+}
+
+// capture settles a pending authorization. A zero amount captures the full
+// authorized amount; a smaller amount captures part of it and releases the rest.
+func (l *paymentLedger) capture(authorizationID string, amount Money, now time.Time, settle func(authorization PaymentResult, amount Money) PaymentResult) (PaymentResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findPendingAuthorization(authorizationID, now)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	captureAmount, err := l.resolveCaptureAmount(*entry, amount)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+
+	payment := settle(entry.authorization, captureAmount)
+	entry.state = authorizationCaptured
+	l.recordLocked(payment)
+	return payment, nil
+}
+
+// captureAmount checks a capture the way capture would without settling it.
+func (l *paymentLedger) captureAmount(authorizationID string, amount Money, now time.Time) (PaymentResult, Money, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findPendingAuthorization(authorizationID, now)
+	if err != nil {
+		return PaymentResult{}, Money{}, err
+	}
+	captureAmount, err := l.resolveCaptureAmount(*entry, amount)
+	if err != nil {
+		return PaymentResult{}, Money{}, err
+	}
+	return entry.authorization, captureAmount, nil
+}
+
+func (l *paymentLedger) checkReleasable(authorizationID string, now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.findPendingAuthorization(authorizationID, now)
+	return err
+}
+
+func (l *paymentLedger) release(authorizationID string, now time.Time) (PaymentResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, err := l.findPendingAuthorization(authorizationID, now)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	entry.state = authorizationReleased
+	return entry.authorization, nil
+}
+
+func (l *paymentLedger) findPendingAuthorization(authorizationID string, now time.Time) (*authorizationEntry, error) {
+	entry, ok := l.authorizations[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAuthorizationNotFound, authorizationID)
+	}
+	if entry.state == authorizationPending && entry.hasExpired(now) {
+		entry.state = authorizationExpired
+	}
+	if entry.state == authorizationExpired {
+		return nil, fmt.Errorf("%w: %s expired at %s", ErrAuthorizationExpired, authorizationID, entry.authorization.ExpiresAt.Format(time.RFC3339))
+	}
+	if entry.state != authorizationPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrAuthorizationNotPending, authorizationID, entry.state)
+	}
+	return entry, nil
+}
+
+func (l *paymentLedger) resolveCaptureAmount(entry authorizationEntry, amount Money) (Money, error) {
+	authorized := entry.authorization.NetAmount
+	if amount.IsZero() {
+		return authorized, nil
+	}
+	if !amount.IsPositive() {
+		return Money{}, ErrInvalidCaptureAmount
+	}
+	comparison, err := amount.Compare(authorized)
+	if err != nil {
+		return Money{}, err
+	}
+	if comparison > 0 {
+		return Money{}, fmt.Errorf("%w: requested %s, authorized %s", ErrCaptureExceedsAuthorized, amount, authorized)
+	}
+	return amount, nil
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// =============================================================================
+// PAYMENT RESULT
+// Structured outcome of a payment, formatted for humans by result_formatter.go
+// =============================================================================
+
+type ProcessorType string
+
+const (
+	ProcessorTypeCreditCard ProcessorType = "credit_card"
+	ProcessorTypePayPal     ProcessorType = "paypal"
+)
+
+func (p ProcessorType) DisplayName() string {
+	switch p {
+	case ProcessorTypeCreditCard:
+		return "Credit Card"
+	case ProcessorTypePayPal:
+		return "PayPal"
+	default:
+		return string(p)
+	}
+}
+
+// PaymentRequest asks a processor to charge or authorize Amount. The order
+// details are there for a RoutingProcessor to pick a processor; the
+// processors themselves only charge the amount.
+type PaymentRequest struct {
+	Amount             Money
+	OrderID            string
+	Customer           string
+	CustomerType       string
+	PreferredProcessor string
+	// Card is checked by processors that take cards before they charge;
+	// without one they only charge the amount.
+	Card *Card
+	// CardBrand and Country (the customer's) only affect the fee, through
+	// card-brand and international surcharges in the FeeSchedule. CardBrand
+	// defaults to the brand of Card.
+	CardBrand string
+	Country   string
+}
+
+func NewPaymentRequest(amount Money) PaymentRequest {
+	return PaymentRequest{Amount: amount}
+}
+
+func (r PaymentRequest) cardBrand() string {
+	if r.CardBrand == "" && r.Card != nil {
+		return string(r.Card.Brand())
+	}
+	return r.CardBrand
+}
+
+type PaymentOperation string
+
+const (
+	PaymentOperationCharge        PaymentOperation = "payment"
+	PaymentOperationAuthorization PaymentOperation = "authorization"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusSucceeded         PaymentStatus = "succeeded"
+	PaymentStatusAuthorized        PaymentStatus = "authorized"
+	PaymentStatusReleased          PaymentStatus = "released"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusVoided            PaymentStatus = "voided"
+)
+
+type PaymentResult struct {
+	ProcessorType ProcessorType
+	// Processor is the registry name of the processor that took the payment,
+	// set by the FailoverProcessor and RoutingProcessor that choose it.
+	Processor     string
+	GrossAmount   Money
+	Fee           Money
+	NetAmount     Money
+	TransactionID string
+	Timestamp     time.Time
+	ExpiresAt     time.Time
+	Status        PaymentStatus
+	FeeBreakdown  FeeQuote
+}
+
+type OrderStatus string
+
+// See OrderStateMachine for the moves between them.
+const (
+	OrderStatusCreated    OrderStatus = "created"
+	OrderStatusValidated  OrderStatus = "validated"
+	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusFailed     OrderStatus = "failed"
+	OrderStatusAuthorized OrderStatus = "authorized"
+	OrderStatusPaid       OrderStatus = "paid"
+	OrderStatusFulfilled  OrderStatus = "fulfilled"
+	OrderStatusCancelled  OrderStatus = "cancelled"
+	OrderStatusRefunded   OrderStatus = "refunded"
+	OrderStatusDisputed   OrderStatus = "disputed"
+)
+
+type OrderResult struct {
+	OrderID        string
+	Customer       string
+	CustomerType   string
+	Status         OrderStatus
+	OriginalAmount Money
+	FinalAmount    Money
+	Lines          []LineBreakdown
+	Discounts      []AppliedDiscount
+	Tax            TaxResult
+	Payment        PaymentResult
+	RefundedAmount Money
+	Refunds        []RefundResult
+	StatusHistory  []StatusChange
+	Replayed       bool
+}
+
+func newTransactionID(prefix string) string {
+	return prefix + "_" + randomHex(8)
+}
+
+func randomHex(length int) string {
+	buffer := make([]byte, length)
+	if _, err := rand.Read(buffer); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buffer)
+}
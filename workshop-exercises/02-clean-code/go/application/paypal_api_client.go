@@ -0,0 +1,369 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// PAYPAL API CLIENT
+// OAuth client-credentials tokens and JSON calls against a PayPal-style API
+// =============================================================================
+
+var ErrPayPalAuthentication = errors.New("paypal authentication failed")
+
+// errPayPalRequestNotSent marks a failure before the API could have seen
+// the payment call: no token, or no connection.
+var errPayPalRequestNotSent = errors.New("paypal request not sent")
+
+const maxPayPalResponseBytes = 1 << 20
+
+// payPalAPIError is a non-2xx answer from the API, with the first issue code
+// PayPal gave for it.
+type payPalAPIError struct {
+	StatusCode int
+	Name       string
+	Issue      string
+	Message    string
+}
+
+func (e *payPalAPIError) Error() string {
+	text := fmt.Sprintf("PayPal API returned %d", e.StatusCode)
+	if e.Name != "" {
+		text += " " + e.Name
+	}
+	if e.Issue != "" {
+		text += " (" + e.Issue + ")"
+	}
+	if e.Message != "" {
+		text += ": " + e.Message
+	}
+	return text
+}
+
+// payPalErrorBody covers both the REST error format and the OAuth one.
+type payPalErrorBody struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Details []struct {
+		Issue       string `json:"issue"`
+		Description string `json:"description"`
+	} `json:"details"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func newPayPalAPIError(statusCode int, body []byte) *payPalAPIError {
+	apiErr := &payPalAPIError{StatusCode: statusCode}
+	var decoded payPalErrorBody
+	if json.Unmarshal(body, &decoded) != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+		return apiErr
+	}
+	apiErr.Name, apiErr.Message = decoded.Name, decoded.Message
+	if len(decoded.Details) > 0 {
+		apiErr.Issue = decoded.Details[0].Issue
+	}
+	if decoded.Error != "" {
+		apiErr.Name, apiErr.Message = decoded.Error, decoded.ErrorDescription
+	}
+	return apiErr
+}
+
+type payPalToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// payPalAPIClient caches its access token and renews it once most of its
+// lifetime has passed, or when the API stops accepting it.
+type payPalAPIClient struct {
+	baseURL      string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	clock        Clock
+
+	mu            sync.Mutex
+	token         string
+	tokenRenewsAt time.Time
+}
+
+func newPayPalAPIClient(config PayPalHTTPConfig) *payPalAPIClient {
+	return &payPalAPIClient{
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		httpClient:   config.HTTPClient,
+		clock:        config.Clock,
+	}
+}
+
+// post sends body as JSON and decodes a 2xx answer into response, which may
+// be nil. A rejected token is renewed and the call made once more.
+//
+// Every call carries a PayPal-Request-Id derived from the payment
+// idempotency key on ctx and the path, so PayPal answers a repeat of a call
+// it already acted on with the first result instead of acting again.
+func (c *payPalAPIClient) post(ctx context.Context, path string, body any, response any) error {
+	ctx = ensurePaymentIdempotencyKey(ctx)
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayPalRequestNotSent, err)
+	}
+	err = c.postWithToken(ctx, token, path, body, response)
+	var apiErr *payPalAPIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+
+	c.discardToken(token)
+	if token, err = c.accessToken(ctx); err != nil {
+		return fmt.Errorf("%w: %w", errPayPalRequestNotSent, err)
+	}
+	return c.postWithToken(ctx, token, path, body, response)
+}
+
+func (c *payPalAPIClient) postWithToken(ctx context.Context, token string, path string, body any, response any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayPalRequestNotSent, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPayPalRequestNotSent, err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("PayPal-Request-Id", payPalRequestID(ctx, path))
+	return c.send(request, response)
+}
+
+// payPalRequestID is the same for every attempt at one call. The key alone
+// would not do: a payment is several calls (create the order, then capture
+// it), and PayPal would replay the first answer for all of them.
+func payPalRequestID(ctx context.Context, path string) string {
+	key, _ := PaymentIdempotencyKey(ctx)
+	sum := sha256.Sum256([]byte(key + " " + path))
+	return hex.EncodeToString(sum[:16])
+}
+
+// send only reports errPayPalRequestNotSent when no connection was made.
+// Any later failure, a timeout waiting for the answer included, may come
+// after PayPal acted on the request.
+func (c *payPalAPIClient) send(request *http.Request, response any) error {
+	httpResponse, err := c.httpClient.Do(request)
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return fmt.Errorf("%w: %w", errPayPalRequestNotSent, err)
+	}
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxPayPalResponseBytes))
+	if err != nil {
+		return err
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		return newPayPalAPIError(httpResponse.StatusCode, body)
+	}
+	if response == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, response); err != nil {
+		return fmt.Errorf("decoding PayPal response from %s: %w", request.URL.Path, err)
+	}
+	return nil
+}
+
+func (c *payPalAPIClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && c.clock.Now().Before(c.tokenRenewsAt) {
+		return c.token, nil
+	}
+
+	token, err := c.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	c.token = token.AccessToken
+	c.tokenRenewsAt = c.clock.Now().Add(lifetime * 9 / 10)
+	return c.token, nil
+}
+
+func (c *payPalAPIClient) requestToken(ctx context.Context) (payPalToken, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return payPalToken{}, err
+	}
+	request.SetBasicAuth(c.clientID, c.clientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var token payPalToken
+	err = c.send(request, &token)
+	var apiErr *payPalAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests {
+		return payPalToken{}, fmt.Errorf("%w: %w", ErrPayPalAuthentication, err)
+	}
+	if err != nil {
+		return payPalToken{}, err
+	}
+	if token.AccessToken == "" {
+		return payPalToken{}, fmt.Errorf("%w: no access token in response", ErrPayPalAuthentication)
+	}
+	return token, nil
+}
+
+func (c *payPalAPIClient) discardToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// =============================================================================
+// ORDERS AND PAYMENTS API
+// =============================================================================
+
+const (
+	payPalIntentCapture   = "CAPTURE"
+	payPalIntentAuthorize = "AUTHORIZE"
+	payPalStatusCompleted = "COMPLETED"
+	payPalStatusCreated   = "CREATED"
+)
+
+type payPalAmount struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+func newPayPalAmount(amount Money) payPalAmount {
+	return payPalAmount{CurrencyCode: amount.Currency().String(), Value: amount.Decimal()}
+}
+
+type payPalPurchaseUnit struct {
+	ReferenceID string       `json:"reference_id,omitempty"`
+	Amount      payPalAmount `json:"amount"`
+}
+
+type payPalCreateOrder struct {
+	Intent        string               `json:"intent"`
+	PurchaseUnits []payPalPurchaseUnit `json:"purchase_units"`
+}
+
+type payPalAmountBody struct {
+	Amount *payPalAmount `json:"amount,omitempty"`
+}
+
+type payPalCapture struct {
+	ID     string       `json:"id"`
+	Status string       `json:"status"`
+	Amount payPalAmount `json:"amount"`
+}
+
+type payPalAuthorization struct {
+	ID             string       `json:"id"`
+	Status         string       `json:"status"`
+	Amount         payPalAmount `json:"amount"`
+	ExpirationTime string       `json:"expiration_time"`
+}
+
+type payPalRefund struct {
+	ID     string       `json:"id"`
+	Status string       `json:"status"`
+	Amount payPalAmount `json:"amount"`
+}
+
+type payPalOrder struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	PurchaseUnits []struct {
+		ReferenceID string `json:"reference_id"`
+		Payments    struct {
+			Captures       []payPalCapture       `json:"captures"`
+			Authorizations []payPalAuthorization `json:"authorizations"`
+		} `json:"payments"`
+	} `json:"purchase_units"`
+}
+
+func (o payPalOrder) capture() (payPalCapture, error) {
+	if len(o.PurchaseUnits) == 0 || len(o.PurchaseUnits[0].Payments.Captures) == 0 {
+		return payPalCapture{}, fmt.Errorf("PayPal order %s came back without a capture", o.ID)
+	}
+	return o.PurchaseUnits[0].Payments.Captures[0], nil
+}
+
+func (o payPalOrder) authorization() (payPalAuthorization, error) {
+	if len(o.PurchaseUnits) == 0 || len(o.PurchaseUnits[0].Payments.Authorizations) == 0 {
+		return payPalAuthorization{}, fmt.Errorf("PayPal order %s came back without an authorization", o.ID)
+	}
+	return o.PurchaseUnits[0].Payments.Authorizations[0], nil
+}
+
+func (c *payPalAPIClient) createOrder(ctx context.Context, intent string, referenceID string, amount Money) (payPalOrder, error) {
+	var order payPalOrder
+	err := c.post(ctx, "/v2/checkout/orders", payPalCreateOrder{
+		Intent:        intent,
+		PurchaseUnits: []payPalPurchaseUnit{{ReferenceID: referenceID, Amount: newPayPalAmount(amount)}},
+	}, &order)
+	return order, err
+}
+
+func (c *payPalAPIClient) captureOrder(ctx context.Context, orderID string) (payPalCapture, error) {
+	var order payPalOrder
+	if err := c.post(ctx, "/v2/checkout/orders/"+url.PathEscape(orderID)+"/capture", struct{}{}, &order); err != nil {
+		return payPalCapture{}, err
+	}
+	return order.capture()
+}
+
+func (c *payPalAPIClient) authorizeOrder(ctx context.Context, orderID string) (payPalAuthorization, error) {
+	var order payPalOrder
+	if err := c.post(ctx, "/v2/checkout/orders/"+url.PathEscape(orderID)+"/authorize", struct{}{}, &order); err != nil {
+		return payPalAuthorization{}, err
+	}
+	return order.authorization()
+}
+
+func (c *payPalAPIClient) captureAuthorization(ctx context.Context, authorizationID string, amount Money) (payPalCapture, error) {
+	requested := newPayPalAmount(amount)
+	var capture payPalCapture
+	err := c.post(ctx, "/v2/payments/authorizations/"+url.PathEscape(authorizationID)+"/capture", payPalAmountBody{Amount: &requested}, &capture)
+	return capture, err
+}
+
+func (c *payPalAPIClient) voidAuthorization(ctx context.Context, authorizationID string) error {
+	return c.post(ctx, "/v2/payments/authorizations/"+url.PathEscape(authorizationID)+"/void", struct{}{}, nil)
+}
+
+func (c *payPalAPIClient) refundCapture(ctx context.Context, captureID string, amount Money) (payPalRefund, error) {
+	requested := newPayPalAmount(amount)
+	var refund payPalRefund
+	err := c.post(ctx, "/v2/payments/captures/"+url.PathEscape(captureID)+"/refund", payPalAmountBody{Amount: &requested}, &refund)
+	return refund, err
+}
+
+func (c *payPalAPIClient) voidCapture(ctx context.Context, captureID string) error {
+	return c.post(ctx, "/v2/payments/captures/"+url.PathEscape(captureID)+"/void", struct{}{}, nil)
+}
@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/workshop/sandbox"
+)
+
+// =============================================================================
+// PAYPAL API CLIENT TESTS
+// Testing: paypal_api_client.go
+// =============================================================================
+
+// countingHandler counts token requests and remembers the Authorization
+// and PayPal-Request-Id headers of every other call.
+type countingHandler struct {
+	next http.Handler
+
+	mu             sync.Mutex
+	tokenRequests  int
+	authorizations []string
+	requestIDs     []string
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	if strings.HasSuffix(r.URL.Path, "/oauth2/token") {
+		h.tokenRequests++
+	} else {
+		h.authorizations = append(h.authorizations, r.Header.Get("Authorization"))
+		h.requestIDs = append(h.requestIDs, r.Header.Get("PayPal-Request-Id"))
+	}
+	h.mu.Unlock()
+	h.next.ServeHTTP(w, r)
+}
+
+func newCountingAPIClient(t *testing.T, tokenTTL time.Duration) (*payPalAPIClient, *countingHandler, *ManualClock) {
+	t.Helper()
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	handler := &countingHandler{next: sandbox.NewServer(sandbox.Config{TokenTTL: tokenTTL, Now: clock.Now})}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	defaults := sandbox.DefaultConfig()
+	client := newPayPalAPIClient(PayPalHTTPConfig{
+		BaseURL:      server.URL,
+		ClientID:     defaults.ClientID,
+		ClientSecret: defaults.ClientSecret,
+		HTTPClient:   server.Client(),
+		Clock:        clock,
+	})
+	return client, handler, clock
+}
+
+func TestPayPalAPIClient_Post_ReusesTokenAsBearerHeader(t *testing.T) {
+	// Arrange
+	client, handler, _ := newCountingAPIClient(t, time.Hour)
+	ctx := context.Background()
+
+	// Act
+	_, firstErr := client.createOrder(ctx, payPalIntentCapture, "ord-1", MustParseMoney("10.00", USD))
+	_, secondErr := client.createOrder(ctx, payPalIntentCapture, "ord-2", MustParseMoney("10.00", USD))
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Expected no errors, got %v / %v", firstErr, secondErr)
+	}
+	if handler.tokenRequests != 1 {
+		t.Errorf("Expected one token request, got %d", handler.tokenRequests)
+	}
+	for _, header := range handler.authorizations {
+		if !strings.HasPrefix(header, "Bearer A21AA") {
+			t.Errorf("Expected a Bearer token header, got %q", header)
+		}
+	}
+}
+
+func TestPayPalAPIClient_Post_RenewsTokenBeforeItExpires(t *testing.T) {
+	// Arrange
+	client, handler, clock := newCountingAPIClient(t, time.Hour)
+	ctx := context.Background()
+	_, _ = client.createOrder(ctx, payPalIntentCapture, "ord-1", MustParseMoney("10.00", USD))
+
+	// Act: 90% of the token's lifetime has passed
+	clock.Advance(54 * time.Minute)
+	_, err := client.createOrder(ctx, payPalIntentCapture, "ord-2", MustParseMoney("10.00", USD))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if handler.tokenRequests != 2 {
+		t.Errorf("Expected the token to be renewed, got %d token requests", handler.tokenRequests)
+	}
+}
+
+func TestPayPalAPIClient_Post_RejectedToken_RenewsAndRetriesOnce(t *testing.T) {
+	// Arrange
+	client, handler, _ := newCountingAPIClient(t, time.Hour)
+	ctx := context.Background()
+	_, _ = client.createOrder(ctx, payPalIntentCapture, "ord-1", MustParseMoney("10.00", USD))
+	client.tokenRenewsAt = client.tokenRenewsAt.Add(time.Hour) // cached past the sandbox's expiry
+	client.token = "A21AAREVOKED"
+
+	// Act
+	_, err := client.createOrder(ctx, payPalIntentCapture, "ord-2", MustParseMoney("10.00", USD))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected the retry with a new token to succeed, got %v", err)
+	}
+	if handler.tokenRequests != 2 {
+		t.Errorf("Expected one renewal, got %d token requests", handler.tokenRequests)
+	}
+}
+
+func TestPayPalAPIClient_Post_SendsRequestIDPerKeyAndCall(t *testing.T) {
+	// Arrange
+	client, handler, _ := newCountingAPIClient(t, time.Hour)
+	keyed := WithPaymentIdempotencyKey(context.Background(), "pay-1")
+	amount := MustParseMoney("10.00", USD)
+
+	// Act
+	order, _ := client.createOrder(keyed, payPalIntentCapture, "ord-1", amount)
+	_, _ = client.createOrder(keyed, payPalIntentCapture, "ord-1", amount)
+	_, _ = client.captureOrder(keyed, order.ID)
+	_, _ = client.createOrder(context.Background(), payPalIntentCapture, "ord-2", amount)
+	_, _ = client.createOrder(context.Background(), payPalIntentCapture, "ord-3", amount)
+
+	// Assert
+	ids := handler.requestIDs
+	if len(ids) != 5 || ids[0] == "" || ids[3] == "" {
+		t.Fatalf("Expected a PayPal-Request-Id on every call, got %q", ids)
+	}
+	if ids[1] != ids[0] {
+		t.Errorf("Expected a repeated call with the same key to reuse its request ID, got %q", ids)
+	}
+	if ids[2] == ids[0] {
+		t.Error("Expected the capture to get a request ID of its own")
+	}
+	if ids[4] == ids[3] {
+		t.Error("Expected calls without a key to get distinct request IDs")
+	}
+}
+
+func TestPayPalAPIClient_NewPayPalAPIError_ReadsIssueAndOAuthErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected payPalAPIError
+	}{
+		{"rest error", `{"name":"UNPROCESSABLE_ENTITY","message":"declined","details":[{"issue":"INSTRUMENT_DECLINED"}]}`,
+			payPalAPIError{StatusCode: 422, Name: "UNPROCESSABLE_ENTITY", Issue: "INSTRUMENT_DECLINED", Message: "declined"}},
+		{"oauth error", `{"error":"invalid_client","error_description":"Client Authentication failed"}`,
+			payPalAPIError{StatusCode: 422, Name: "invalid_client", Message: "Client Authentication failed"}},
+		{"not json", "Bad Gateway\n", payPalAPIError{StatusCode: 422, Message: "Bad Gateway"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			apiErr := newPayPalAPIError(422, []byte(tc.body))
+
+			// Assert
+			if *apiErr != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, *apiErr)
+			}
+		})
+	}
+}
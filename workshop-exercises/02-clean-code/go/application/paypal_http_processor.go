@@ -0,0 +1,382 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// PAYPAL HTTP PROCESSOR
+// PayPal over its REST protocol; run ./cmd/paypal-sandbox to talk to it offline
+// =============================================================================
+
+var ErrInvalidPayPalConfig = errors.New("invalid PayPal configuration")
+
+// PayPalHTTPConfig points the processor at a PayPal-compatible API. Fees
+// nil means PayPal's standard pricing; the fee is charged on top of the
+// amount, as with PayPalProcessor. HTTPClient and Clock default to a client
+// with a 30 second timeout and the system clock.
+type PayPalHTTPConfig struct {
+	BaseURL      string
+	ClientID     string
+	ClientSecret string
+	HTTPClient   *http.Client
+	Clock        Clock
+	Fees         *FeeSchedule
+}
+
+// PayPalHTTPProcessor charges through the PayPal orders API: a payment is an
+// order created and captured at once, an authorization an order authorized
+// now and captured later. Refunds, voids and captures are checked against
+// the same local ledger the other processors keep, before the API is asked,
+// so they only work for payments that went through this processor. Each
+// transaction is locked from that check until the ledger has booked PayPal's
+// answer, so two refunds cannot both pass the check for the same balance.
+type PayPalHTTPProcessor struct {
+	api          *payPalAPIClient
+	fees         FeeSchedule
+	clock        Clock
+	ledger       *paymentLedger
+	transactions *keyedMutex
+
+	mu sync.Mutex
+	// captureIDs maps transaction IDs to PayPal capture IDs. A payment's
+	// transaction ID is its capture ID; a captured authorization keeps the
+	// authorization ID.
+	captureIDs map[string]string
+}
+
+func NewPayPalHTTPProcessor(config PayPalHTTPConfig) (PaymentProcessorInterface, error) {
+	if err := validatePayPalHTTPConfig(config); err != nil {
+		return nil, err
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if config.Clock == nil {
+		config.Clock = NewSystemClock()
+	}
+	fees, err := configuredFees(config.Fees, DefaultPayPalFeeSchedule())
+	if err != nil {
+		return nil, err
+	}
+	return &PayPalHTTPProcessor{
+		api:          newPayPalAPIClient(config),
+		fees:         fees,
+		clock:        config.Clock,
+		ledger:       newPaymentLedger(),
+		transactions: newKeyedMutex(),
+		captureIDs:   make(map[string]string),
+	}, nil
+}
+
+func validatePayPalHTTPConfig(config PayPalHTTPConfig) error {
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return fmt.Errorf("%w: base URL %q is not an absolute URL", ErrInvalidPayPalConfig, config.BaseURL)
+	}
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return fmt.Errorf("%w: client ID and secret are required", ErrInvalidPayPalConfig)
+	}
+	return nil
+}
+
+func (p *PayPalHTTPProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return p.executePaymentProcessing(ctx, request)
+}
+
+func (p *PayPalHTTPProcessor) executePaymentProcessing(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	quote := p.calculateProcessingFee(request)
+	order, err := p.api.createOrder(ctx, payPalIntentCapture, request.OrderID, quote.GrossAmount)
+	if err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrTransactionNotFound)
+	}
+	capture, err := p.api.captureOrder(ctx, order.ID)
+	if err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrTransactionNotFound)
+	}
+	if err := p.checkCaptureCompleted(capture); err != nil {
+		return PaymentResult{}, err
+	}
+
+	result := p.buildPaymentResult(quote, capture.ID)
+	p.ledger.record(result)
+	return result, nil
+}
+
+// A capture PayPal did not complete (DECLINED, or PENDING review) has not
+// paid for the order.
+func (p *PayPalHTTPProcessor) checkCaptureCompleted(capture payPalCapture) error {
+	if capture.Status == payPalStatusCompleted {
+		return nil
+	}
+	return NewDeclinedError(p.processorName(), capture.Status, "capture "+capture.ID+" was not completed")
+}
+
+func (p *PayPalHTTPProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return p.calculateProcessingFee(request), nil
+}
+
+func (p *PayPalHTTPProcessor) calculateProcessingFee(request PaymentRequest) FeeQuote {
+	return p.fees.Quote(request)
+}
+
+func (p *PayPalHTTPProcessor) buildPaymentResult(quote FeeQuote, transactionID string) PaymentResult {
+	return PaymentResult{
+		ProcessorType: ProcessorTypePayPal,
+		GrossAmount:   quote.GrossAmount,
+		Fee:           quote.Fee,
+		NetAmount:     quote.Amount,
+		TransactionID: transactionID,
+		Timestamp:     p.getCurrentTime(),
+		Status:        PaymentStatusSucceeded,
+		FeeBreakdown:  quote,
+	}
+}
+
+func (p *PayPalHTTPProcessor) getCurrentTime() time.Time {
+	return p.clock.Now().UTC()
+}
+
+func (p *PayPalHTTPProcessor) processorName() string {
+	return ProcessorTypePayPal.DisplayName()
+}
+
+func (p *PayPalHTTPProcessor) rememberCapture(transactionID string, captureID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.captureIDs[transactionID] = captureID
+}
+
+func (p *PayPalHTTPProcessor) captureIDFor(transactionID string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if captureID, ok := p.captureIDs[transactionID]; ok {
+		return captureID
+	}
+	return transactionID
+}
+
+func (p *PayPalHTTPProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	return p.executeRefund(ctx, request)
+}
+
+func (p *PayPalHTTPProcessor) executeRefund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	defer p.transactions.lock(request.TransactionID)()
+	amount, err := p.ledger.refundAmount(request)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	refund, err := p.api.refundCapture(ctx, p.captureIDFor(request.TransactionID), amount)
+	if err != nil {
+		return RefundResult{}, p.translateError(ctx, err, ErrTransactionNotFound)
+	}
+
+	request.Amount = amount
+	reversal, err := p.ledger.refund(request, p.calculateFeeReversal)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	return p.buildRefundResult(reversal, refund.ID), nil
+}
+
+// PayPal keeps its fee on refunds; only a void returns it.
+func (p *PayPalHTTPProcessor) calculateFeeReversal(entry ledgerEntry, amount Money) Money {
+	return ZeroMoney(amount.Currency())
+}
+
+func (p *PayPalHTTPProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return p.executeVoid(ctx, transactionID)
+}
+
+func (p *PayPalHTTPProcessor) executeVoid(ctx context.Context, transactionID string) (RefundResult, error) {
+	defer p.transactions.lock(transactionID)()
+	if err := p.ledger.checkVoidable(transactionID); err != nil {
+		return RefundResult{}, err
+	}
+	if err := p.api.voidCapture(ctx, p.captureIDFor(transactionID)); err != nil {
+		return RefundResult{}, p.translateError(ctx, err, ErrTransactionNotFound)
+	}
+
+	reversal, err := p.ledger.void(transactionID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	// A void has no PayPal refund behind it to take the ID from.
+	return p.buildRefundResult(reversal, newTransactionID("pp_re")), nil
+}
+
+func (p *PayPalHTTPProcessor) buildRefundResult(reversal ledgerReversal, refundID string) RefundResult {
+	return RefundResult{
+		RefundID:            refundID,
+		TransactionID:       reversal.payment.TransactionID,
+		ProcessorType:       ProcessorTypePayPal,
+		Type:                reversal.refundType(),
+		Amount:              reversal.amount,
+		FeeReversed:         reversal.feeReversed,
+		RemainingRefundable: reversal.remaining,
+		Timestamp:           p.getCurrentTime(),
+		Status:              reversal.paymentStatus(),
+	}
+}
+
+func (p *PayPalHTTPProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return p.executeAuthorization(ctx, request)
+}
+
+func (p *PayPalHTTPProcessor) executeAuthorization(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	quote := p.calculateProcessingFee(request)
+	order, err := p.api.createOrder(ctx, payPalIntentAuthorize, request.OrderID, quote.GrossAmount)
+	if err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrAuthorizationNotFound)
+	}
+	authorization, err := p.api.authorizeOrder(ctx, order.ID)
+	if err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrAuthorizationNotFound)
+	}
+	if authorization.Status != payPalStatusCreated {
+		return PaymentResult{}, NewDeclinedError(p.processorName(), authorization.Status, "authorization "+authorization.ID+" was not granted")
+	}
+
+	result := p.buildAuthorizationResult(quote, authorization)
+	p.ledger.authorize(result)
+	return result, nil
+}
+
+// The authorization expires when PayPal says it does, falling back to
+// PayPal's honor period if the API leaves that out.
+func (p *PayPalHTTPProcessor) buildAuthorizationResult(quote FeeQuote, authorization payPalAuthorization) PaymentResult {
+	result := p.buildPaymentResult(quote, authorization.ID)
+	result.Status = PaymentStatusAuthorized
+	result.ExpiresAt = result.Timestamp.Add(DefaultPayPalConfig().AuthorizationTTL)
+	if expiresAt, err := time.Parse(time.RFC3339, authorization.ExpirationTime); err == nil {
+		result.ExpiresAt = expiresAt.UTC()
+	}
+	return result
+}
+
+func (p *PayPalHTTPProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	return p.executeCapture(ctx, authorizationID, amount)
+}
+
+func (p *PayPalHTTPProcessor) executeCapture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	defer p.transactions.lock(authorizationID)()
+	authorization, captureAmount, err := p.ledger.captureAmount(authorizationID, amount, p.getCurrentTime())
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	quote := p.fees.quoteLike(captureAmount, authorization.FeeBreakdown)
+	capture, err := p.api.captureAuthorization(ctx, authorizationID, quote.GrossAmount)
+	if err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrAuthorizationNotFound)
+	}
+	if err := p.checkCaptureCompleted(capture); err != nil {
+		return PaymentResult{}, err
+	}
+
+	p.rememberCapture(authorizationID, capture.ID)
+	return p.ledger.capture(authorizationID, captureAmount, p.getCurrentTime(), p.buildCaptureResult)
+}
+
+// A capture is priced like its authorization, on the captured amount.
+func (p *PayPalHTTPProcessor) buildCaptureResult(authorization PaymentResult, amount Money) PaymentResult {
+	return p.buildPaymentResult(p.fees.quoteLike(amount, authorization.FeeBreakdown), authorization.TransactionID)
+}
+
+func (p *PayPalHTTPProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return p.executeRelease(ctx, authorizationID)
+}
+
+func (p *PayPalHTTPProcessor) executeRelease(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	defer p.transactions.lock(authorizationID)()
+	if err := p.ledger.checkReleasable(authorizationID, p.getCurrentTime()); err != nil {
+		return PaymentResult{}, err
+	}
+	if err := p.api.voidAuthorization(ctx, authorizationID); err != nil {
+		return PaymentResult{}, p.translateError(ctx, err, ErrAuthorizationNotFound)
+	}
+
+	authorization, err := p.ledger.release(authorizationID, p.getCurrentTime())
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	authorization.Status = PaymentStatusReleased
+	authorization.Timestamp = p.getCurrentTime()
+	return authorization, nil
+}
+
+// =============================================================================
+// ERROR TRANSLATION
+// API answers become the same errors the in-process processors return
+// =============================================================================
+
+// payPalDeclineIssues are the issue codes that refuse the payment itself.
+var payPalDeclineIssues = map[string]PaymentErrorKind{
+	"INSTRUMENT_DECLINED":       PaymentErrorDeclined,
+	"PAYER_CANNOT_PAY":          PaymentErrorDeclined,
+	"TRANSACTION_REFUSED":       PaymentErrorDeclined,
+	"INSUFFICIENT_FUNDS":        PaymentErrorInsufficientFunds,
+	"PAYEE_BLOCKED_TRANSACTION": PaymentErrorFraudBlocked,
+	"COMPLIANCE_VIOLATION":      PaymentErrorFraudBlocked,
+}
+
+// payPalStateIssues are the issue codes that refuse a follow-up operation
+// because of the payment's state.
+var payPalStateIssues = map[string]error{
+	"AUTHORIZATION_EXPIRED":          ErrAuthorizationExpired,
+	"AUTHORIZATION_ALREADY_CAPTURED": ErrAuthorizationNotPending,
+	"AUTHORIZATION_VOIDED":           ErrAuthorizationNotPending,
+	"MAX_CAPTURE_AMOUNT_EXCEEDED":    ErrCaptureExceedsAuthorized,
+	"REFUND_AMOUNT_EXCEEDED":         ErrRefundExceedsCaptured,
+	"CAPTURE_FULLY_REFUNDED":         ErrRefundExceedsCaptured,
+	"CAPTURE_VOIDED":                 ErrTransactionVoided,
+	"VOID_NOT_ALLOWED":               ErrVoidNotAllowed,
+}
+
+// translateError maps a failed API call. Only a call that was never sent,
+// throttled (429) or turned away as unavailable (503) is transient: PayPal
+// did nothing with it. A timeout, a broken connection or another server
+// error may come after PayPal acted, so the outcome is unknown. Declines
+// and state conflicts keep their meaning; a 404 becomes notFound; anything
+// else is an invalid request.
+func (p *PayPalHTTPProcessor) translateError(ctx context.Context, err error, notFound error) error {
+	if ctx.Err() != nil {
+		return NewPaymentCancelledError(p.processorName(), ctx.Err())
+	}
+	if errors.Is(err, ErrPayPalAuthentication) {
+		invalid := NewPaymentError(PaymentErrorInvalidRequest, p.processorName(), "credentials rejected")
+		invalid.Cause = err
+		return invalid
+	}
+	if errors.Is(err, errPayPalRequestNotSent) {
+		return NewTransientPaymentError(p.processorName(), err)
+	}
+	var apiErr *payPalAPIError
+	if !errors.As(err, &apiErr) {
+		return NewOutcomeUnknownError(p.processorName(), err)
+	}
+	if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable {
+		return NewTransientPaymentError(p.processorName(), apiErr)
+	}
+	if apiErr.StatusCode >= 500 {
+		return NewOutcomeUnknownError(p.processorName(), apiErr)
+	}
+	if apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", notFound, apiErr)
+	}
+	if sentinel, ok := payPalStateIssues[apiErr.Issue]; ok {
+		return fmt.Errorf("%w: %v", sentinel, apiErr)
+	}
+	kind, ok := payPalDeclineIssues[apiErr.Issue]
+	if !ok {
+		kind = PaymentErrorInvalidRequest
+	}
+	paymentErr := NewPaymentError(kind, p.processorName(), apiErr.Message)
+	paymentErr.Code = apiErr.Issue
+	return paymentErr
+}
@@ -0,0 +1,410 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/workshop/sandbox"
+)
+
+// =============================================================================
+// PAYPAL HTTP PROCESSOR TESTS
+// Testing: paypal_http_processor.go, end to end against the local sandbox
+// =============================================================================
+
+type payPalSandboxFixture struct {
+	sandbox   *sandbox.Server
+	processor PaymentProcessorInterface
+	clock     *ManualClock
+}
+
+func newPayPalSandboxFixture(t *testing.T) *payPalSandboxFixture {
+	t.Helper()
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	server := sandbox.NewServer(sandbox.Config{Now: clock.Now})
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	defaults := sandbox.DefaultConfig()
+	processor, err := NewPayPalHTTPProcessor(PayPalHTTPConfig{
+		BaseURL:      httpServer.URL,
+		ClientID:     defaults.ClientID,
+		ClientSecret: defaults.ClientSecret,
+		Clock:        clock,
+	})
+	if err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+	return &payPalSandboxFixture{sandbox: server, processor: processor, clock: clock}
+}
+
+func (f *payPalSandboxFixture) script(t *testing.T, scenarios ...sandbox.Scenario) {
+	t.Helper()
+	if err := f.sandbox.Script(scenarios...); err != nil {
+		t.Fatalf("Expected valid scenarios, got %v", err)
+	}
+}
+
+func payPalRequest(orderID string, amount string) PaymentRequest {
+	request := NewPaymentRequest(MustParseMoney(amount, USD))
+	request.OrderID = orderID
+	return request
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_Approved_ChargesAmountPlusFee(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+
+	// Act
+	result, err := fixture.processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "100.00"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.GrossAmount.Decimal() != "103.49" || result.Fee.Decimal() != "3.49" || result.NetAmount.Decimal() != "100.00" {
+		t.Errorf("Expected 103.49 gross with a 3.49 fee, got %s gross, %s fee", result.GrossAmount, result.Fee)
+	}
+	if result.ProcessorType != ProcessorTypePayPal || result.Status != PaymentStatusSucceeded {
+		t.Errorf("Expected a succeeded PayPal payment, got %s %s", result.ProcessorType, result.Status)
+	}
+	if result.TransactionID == "" {
+		t.Error("Expected the PayPal capture ID as transaction ID")
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_ScriptedOutcomes_MapToTypedErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		scenario  sandbox.Scenario
+		expected  error
+		retryable bool
+	}{
+		{"decline", sandbox.Scenario{Operation: sandbox.OperationCaptureOrder, Outcome: sandbox.OutcomeDecline}, ErrPaymentDeclined, false},
+		{"insufficient funds", sandbox.Scenario{Operation: sandbox.OperationCaptureOrder, Outcome: sandbox.OutcomeDecline, Issue: "INSUFFICIENT_FUNDS"}, ErrInsufficientFunds, false},
+		{"server error", sandbox.Scenario{Operation: sandbox.OperationCreateOrder, Outcome: sandbox.OutcomeServerError, Status: http.StatusBadGateway}, ErrPaymentOutcomeUnknown, false},
+		{"service unavailable", sandbox.Scenario{Operation: sandbox.OperationCaptureOrder, Outcome: sandbox.OutcomeServerError, Status: http.StatusServiceUnavailable}, ErrProcessorUnavailable, true},
+		{"token endpoint down", sandbox.Scenario{Operation: sandbox.OperationToken, Outcome: sandbox.OutcomeServerError}, ErrProcessorUnavailable, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			fixture := newPayPalSandboxFixture(t)
+			fixture.script(t, tc.scenario)
+
+			// Act
+			_, err := fixture.processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "10.00"))
+
+			// Assert
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, err)
+			}
+			if IsRetryablePaymentError(err) != tc.retryable {
+				t.Errorf("Expected retryable=%v for %v", tc.retryable, err)
+			}
+		})
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_ScriptedForOneOrder_OtherOrdersSucceed(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	fixture.script(t, sandbox.Scenario{ReferenceID: "ord-declined", Outcome: sandbox.OutcomeDecline})
+
+	// Act
+	_, declinedErr := fixture.processor.ProcessPayment(context.Background(), payPalRequest("ord-declined", "10.00"))
+	_, otherErr := fixture.processor.ProcessPayment(context.Background(), payPalRequest("ord-fine", "10.00"))
+
+	// Assert
+	if !errors.Is(declinedErr, ErrPaymentDeclined) {
+		t.Errorf("Expected the scripted order to be declined, got %v", declinedErr)
+	}
+	if otherErr != nil {
+		t.Errorf("Expected other orders to go through, got %v", otherErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_SlowSandbox_ReturnsCancellationError(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	fixture.script(t, sandbox.Scenario{Operation: sandbox.OperationCaptureOrder, Delay: 300 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := fixture.processor.ProcessPayment(ctx, payPalRequest("ord-1", "10.00"))
+
+	// Assert
+	var cancelled *PaymentCancelledError
+	if !errors.As(err, &cancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a PaymentCancelledError for the deadline, got %v", err)
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_TimeoutAfterSending_IsOutcomeUnknown(t *testing.T) {
+	// Arrange
+	server := sandbox.NewServer(sandbox.Config{})
+	_ = server.Script(sandbox.Scenario{Operation: sandbox.OperationCaptureOrder, Delay: 300 * time.Millisecond})
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	defaults := sandbox.DefaultConfig()
+	processor, _ := NewPayPalHTTPProcessor(PayPalHTTPConfig{
+		BaseURL:      httpServer.URL,
+		ClientID:     defaults.ClientID,
+		ClientSecret: defaults.ClientSecret,
+		HTTPClient:   &http.Client{Timeout: 50 * time.Millisecond},
+	})
+
+	// Act
+	_, err := processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "10.00"))
+
+	// Assert
+	if !errors.Is(err, ErrPaymentOutcomeUnknown) || IsRetryablePaymentError(err) {
+		t.Errorf("Expected a capture that may have gone through not to be retryable, got %v", err)
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_Unreachable_IsTransient(t *testing.T) {
+	// Arrange
+	httpServer := httptest.NewServer(http.NotFoundHandler())
+	baseURL := httpServer.URL
+	httpServer.Close()
+	processor, _ := NewPayPalHTTPProcessor(PayPalHTTPConfig{BaseURL: baseURL, ClientID: "id", ClientSecret: "secret"})
+
+	// Act
+	_, err := processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "10.00"))
+
+	// Assert
+	if !errors.Is(err, ErrProcessorUnavailable) || !IsRetryablePaymentError(err) {
+		t.Errorf("Expected a payment that never left to be retryable, got %v", err)
+	}
+}
+
+func TestPayPalHTTPProcessor_ProcessPayment_WrongCredentials_IsInvalidRequest(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(sandbox.NewServer(sandbox.Config{}))
+	defer server.Close()
+	processor, _ := NewPayPalHTTPProcessor(PayPalHTTPConfig{BaseURL: server.URL, ClientID: "someone", ClientSecret: "else"})
+
+	// Act
+	_, err := processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "10.00"))
+
+	// Assert
+	if !errors.Is(err, ErrInvalidPaymentRequest) || !errors.Is(err, ErrPayPalAuthentication) {
+		t.Errorf("Expected an invalid request caused by authentication, got %v", err)
+	}
+	if IsRetryablePaymentError(err) {
+		t.Errorf("Expected bad credentials not to be retried, got %v", err)
+	}
+}
+
+func TestPayPalHTTPProcessor_RefundAndVoid_KeepLedgerInStep(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	payment, _ := fixture.processor.ProcessPayment(ctx, payPalRequest("ord-1", "100.00"))
+
+	// Act
+	partial, partialErr := fixture.processor.Refund(ctx, NewPartialRefundRequest(payment.TransactionID, MustParseMoney("40.00", USD)))
+	_, voidErr := fixture.processor.Void(ctx, payment.TransactionID)
+	rest, restErr := fixture.processor.Refund(ctx, NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if partialErr != nil || partial.Type != RefundTypePartial || partial.RemainingRefundable.Decimal() != "63.49" {
+		t.Errorf("Expected a partial refund leaving 63.49, got %+v (%v)", partial, partialErr)
+	}
+	if !partial.FeeReversed.IsZero() {
+		t.Errorf("Expected PayPal to keep its fee on refunds, got %s back", partial.FeeReversed)
+	}
+	if !errors.Is(voidErr, ErrVoidNotAllowed) {
+		t.Errorf("Expected no void after a refund, got %v", voidErr)
+	}
+	if restErr != nil || rest.Amount.Decimal() != "63.49" || rest.Status != PaymentStatusRefunded {
+		t.Errorf("Expected the remaining 63.49 refunded, got %+v (%v)", rest, restErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_Refund_ConcurrentFullRefunds_OnlyOneReachesPayPal(t *testing.T) {
+	// Arrange: A slow sandbox, so both refunds would be in flight together
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	server := sandbox.NewServer(sandbox.Config{Now: clock.Now})
+	var refundCalls atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/refund") {
+			refundCalls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	defaults := sandbox.DefaultConfig()
+	processor, _ := NewPayPalHTTPProcessor(PayPalHTTPConfig{BaseURL: httpServer.URL, ClientID: defaults.ClientID, ClientSecret: defaults.ClientSecret, Clock: clock})
+	payment, _ := processor.ProcessPayment(context.Background(), payPalRequest("ord-1", "100.00"))
+
+	// Act
+	errs := make([]error, 2)
+	var refunds sync.WaitGroup
+	for i := range errs {
+		refunds.Add(1)
+		go func(i int) {
+			defer refunds.Done()
+			_, errs[i] = processor.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID))
+		}(i)
+	}
+	refunds.Wait()
+
+	// Assert
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrRefundExceedsCaptured) {
+			t.Errorf("Expected the second refund turned away by the ledger, got %v", err)
+		}
+	}
+	if succeeded != 1 || refundCalls.Load() != 1 {
+		t.Errorf("Expected one refund, sent to PayPal once, got %d succeeded and %d calls", succeeded, refundCalls.Load())
+	}
+}
+
+func TestPayPalHTTPProcessor_Void_UnrefundedPayment_ReturnsFee(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	payment, _ := fixture.processor.ProcessPayment(ctx, payPalRequest("ord-1", "100.00"))
+
+	// Act
+	void, err := fixture.processor.Void(ctx, payment.TransactionID)
+	_, refundErr := fixture.processor.Refund(ctx, NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if err != nil || void.Type != RefundTypeVoid || void.FeeReversed.Decimal() != "3.49" {
+		t.Errorf("Expected a void returning the 3.49 fee, got %+v (%v)", void, err)
+	}
+	if !errors.Is(refundErr, ErrTransactionVoided) {
+		t.Errorf("Expected no refunds after a void, got %v", refundErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_Refund_SandboxFails_LedgerUnchanged(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	payment, _ := fixture.processor.ProcessPayment(ctx, payPalRequest("ord-1", "100.00"))
+	fixture.script(t, sandbox.Scenario{Operation: sandbox.OperationRefundCapture, Outcome: sandbox.OutcomeServerError, Status: http.StatusServiceUnavailable, Times: 1})
+
+	// Act
+	_, failedErr := fixture.processor.Refund(ctx, NewFullRefundRequest(payment.TransactionID))
+	retried, retryErr := fixture.processor.Refund(ctx, NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if !errors.Is(failedErr, ErrProcessorUnavailable) {
+		t.Errorf("Expected a transient failure, got %v", failedErr)
+	}
+	if retryErr != nil || retried.Amount.Decimal() != "103.49" {
+		t.Errorf("Expected the retry to refund the full 103.49, got %+v (%v)", retried, retryErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_AuthorizeAndCapture_PartialCapture(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	authorization, authErr := fixture.processor.Authorize(ctx, payPalRequest("ord-1", "100.00"))
+
+	// Act
+	capture, err := fixture.processor.Capture(ctx, authorization.TransactionID, MustParseMoney("50.00", USD))
+	refund, refundErr := fixture.processor.Refund(ctx, NewFullRefundRequest(authorization.TransactionID))
+
+	// Assert
+	if authErr != nil || authorization.Status != PaymentStatusAuthorized {
+		t.Fatalf("Expected an authorization, got %+v (%v)", authorization, authErr)
+	}
+	if !authorization.ExpiresAt.Equal(fixture.clock.Now().Add(3 * 24 * time.Hour)) {
+		t.Errorf("Expected the sandbox's expiration time, got %s", authorization.ExpiresAt)
+	}
+	if err != nil || capture.GrossAmount.Decimal() != "51.75" || capture.TransactionID != authorization.TransactionID {
+		t.Errorf("Expected 51.75 captured under the authorization ID, got %+v (%v)", capture, err)
+	}
+	if refundErr != nil || refund.Amount.Decimal() != "51.75" {
+		t.Errorf("Expected the capture to be refundable by authorization ID, got %+v (%v)", refund, refundErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_ReleaseAuthorization_VoidsRemotely(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	authorization, _ := fixture.processor.Authorize(ctx, payPalRequest("ord-1", "100.00"))
+
+	// Act
+	released, err := fixture.processor.ReleaseAuthorization(ctx, authorization.TransactionID)
+	_, captureErr := fixture.processor.Capture(ctx, authorization.TransactionID, Money{})
+
+	// Assert
+	if err != nil || released.Status != PaymentStatusReleased {
+		t.Errorf("Expected a released authorization, got %+v (%v)", released, err)
+	}
+	if !errors.Is(captureErr, ErrAuthorizationNotPending) {
+		t.Errorf("Expected no capture after release, got %v", captureErr)
+	}
+}
+
+func TestPayPalHTTPProcessor_Capture_SandboxSaysExpired_MapsToErrAuthorizationExpired(t *testing.T) {
+	// Arrange
+	fixture := newPayPalSandboxFixture(t)
+	ctx := context.Background()
+	authorization, _ := fixture.processor.Authorize(ctx, payPalRequest("ord-1", "100.00"))
+	fixture.script(t, sandbox.Scenario{Operation: sandbox.OperationCaptureAuthorization, Outcome: sandbox.OutcomeDecline, Issue: "AUTHORIZATION_EXPIRED"})
+
+	// Act
+	_, err := fixture.processor.Capture(ctx, authorization.TransactionID, Money{})
+
+	// Assert
+	if !errors.Is(err, ErrAuthorizationExpired) {
+		t.Errorf("Expected ErrAuthorizationExpired, got %v", err)
+	}
+}
+
+func TestPayPalHTTPProcessor_NewPayPalHTTPProcessor_InvalidConfig_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config PayPalHTTPConfig
+	}{
+		{"relative URL", PayPalHTTPConfig{BaseURL: "/v2", ClientID: "id", ClientSecret: "secret"}},
+		{"missing secret", PayPalHTTPConfig{BaseURL: "http://127.0.0.1:8089", ClientID: "id"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := NewPayPalHTTPProcessor(tc.config)
+
+			// Assert
+			if !errors.Is(err, ErrInvalidPayPalConfig) {
+				t.Errorf("Expected ErrInvalidPayPalConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPayPalHTTPProcessor_NewPayPalHTTPProcessor_InvalidFeeSchedule_ReturnsError(t *testing.T) {
+	// Arrange
+	schedule := FeeSchedule{Percent: NewPercentageFromFloat(101)}
+
+	// Act
+	_, err := NewPayPalHTTPProcessor(PayPalHTTPConfig{BaseURL: "http://127.0.0.1:8089", ClientID: "id", ClientSecret: "secret", Fees: &schedule})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidFeeSchedule) {
+		t.Errorf("Expected ErrInvalidFeeSchedule, got %v", err)
+	}
+}
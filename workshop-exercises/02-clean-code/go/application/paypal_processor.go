@@ -1,7 +1,7 @@
 package application
 
 import (
-	"fmt"
+	"context"
 	"time"
 )
 
@@ -10,50 +10,201 @@ import (
 // =============================================================================
 
 type PayPalProcessor struct {
-	feePercent float64
+	fees             FeeSchedule
+	authorizationTTL time.Duration
+	clock            Clock
+	ledger           *paymentLedger
 }
 
 func NewPayPalProcessor() PaymentProcessorInterface {
+	processor, _ := NewPayPalProcessorWithConfig(DefaultPayPalConfig())
+	return processor
+}
+
+func NewPayPalProcessorWithConfig(config ProcessorConfig) (PaymentProcessorInterface, error) {
+	config = config.withDefaults(DefaultPayPalConfig())
+	fees, err := configuredFees(config.Fees, DefaultPayPalFeeSchedule())
+	if err != nil {
+		return nil, err
+	}
 	return &PayPalProcessor{
-		feePercent: 3.49,
+		fees:             fees,
+		authorizationTTL: config.AuthorizationTTL,
+		clock:            config.Clock,
+		ledger:           newPaymentLedger(),
+	}, nil
+}
+
+func (p *PayPalProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return p.executePaymentProcessing(ctx, request)
+}
+
+func (p *PayPalProcessor) executePaymentProcessing(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	quote := p.calculateProcessingFee(request)
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, p.createCancellationError(err)
+	}
+	return p.recordPayment(p.buildPaymentResult(quote)), nil
+}
+
+func (p *PayPalProcessor) recordPayment(result PaymentResult) PaymentResult {
+	p.ledger.record(result)
+	return result
+}
+
+func (p *PayPalProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return p.calculateProcessingFee(request), nil
+}
+
+func (p *PayPalProcessor) calculateProcessingFee(request PaymentRequest) FeeQuote {
+	return p.fees.Quote(request)
+}
+
+func (p *PayPalProcessor) simulateProcessingDelay(ctx context.Context) error {
+	return p.waitForProcessing(ctx, 150*time.Millisecond)
+}
+
+func (p *PayPalProcessor) waitForProcessing(ctx context.Context, duration time.Duration) error {
+	return waitOrCancel(ctx, duration)
+}
+
+func (p *PayPalProcessor) createCancellationError(cause error) error {
+	return NewPaymentCancelledError(ProcessorTypePayPal.DisplayName(), cause)
+}
+
+func (p *PayPalProcessor) buildPaymentResult(quote FeeQuote) PaymentResult {
+	return PaymentResult{
+		ProcessorType: ProcessorTypePayPal,
+		GrossAmount:   quote.GrossAmount,
+		Fee:           quote.Fee,
+		NetAmount:     quote.Amount,
+		TransactionID: p.generateTransactionID(),
+		Timestamp:     p.getCurrentTime(),
+		Status:        PaymentStatusSucceeded,
+		FeeBreakdown:  quote,
+	}
+}
+
+func (p *PayPalProcessor) generateTransactionID() string {
+	return newTransactionID("pp")
+}
+
+func (p *PayPalProcessor) getCurrentTime() time.Time {
+	return p.clock.Now().UTC()
+}
+
+func (p *PayPalProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	return p.executeRefund(ctx, request)
+}
+
+func (p *PayPalProcessor) executeRefund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return RefundResult{}, p.createCancellationError(err)
+	}
+	reversal, err := p.ledger.refund(request, p.calculateFeeReversal)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	return p.buildRefundResult(reversal), nil
+}
+
+// PayPal keeps its fee on refunds; only a void returns it.
+func (p *PayPalProcessor) calculateFeeReversal(entry ledgerEntry, amount Money) Money {
+	return ZeroMoney(amount.Currency())
+}
+
+func (p *PayPalProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return p.executeVoid(ctx, transactionID)
+}
+
+func (p *PayPalProcessor) executeVoid(ctx context.Context, transactionID string) (RefundResult, error) {
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return RefundResult{}, p.createCancellationError(err)
 	}
+	reversal, err := p.ledger.void(transactionID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	return p.buildRefundResult(reversal), nil
 }
 
-func (p *PayPalProcessor) ProcessPayment(amount float64) (string, error) {
-	return p.executePaymentProcessing(amount)
+func (p *PayPalProcessor) buildRefundResult(reversal ledgerReversal) RefundResult {
+	return RefundResult{
+		RefundID:            p.generateRefundID(),
+		TransactionID:       reversal.payment.TransactionID,
+		ProcessorType:       ProcessorTypePayPal,
+		Type:                reversal.refundType(),
+		Amount:              reversal.amount,
+		FeeReversed:         reversal.feeReversed,
+		RemainingRefundable: reversal.remaining,
+		Timestamp:           p.getCurrentTime(),
+		Status:              reversal.paymentStatus(),
+	}
 }
 
-func (p *PayPalProcessor) executePaymentProcessing(amount float64) (string, error) {
-	fee := p.calculateProcessingFee(amount)
-	total := p.calculateTotalAmount(amount, fee)
-	p.simulateProcessingDelay()
-	return p.formatPaymentResult(total, fee), nil
+func (p *PayPalProcessor) generateRefundID() string {
+	return newTransactionID("pp_re")
 }
 
-func (p *PayPalProcessor) calculateProcessingFee(amount float64) float64 {
-	return p.computeFeeFromAmount(amount)
+func (p *PayPalProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return p.executeAuthorization(ctx, request)
 }
 
-func (p *PayPalProcessor) computeFeeFromAmount(amount float64) float64 {
-	return amount * p.feePercent / 100
+func (p *PayPalProcessor) executeAuthorization(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	quote := p.calculateProcessingFee(request)
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, p.createCancellationError(err)
+	}
+	return p.recordAuthorization(p.buildAuthorizationResult(quote)), nil
 }
 
-func (p *PayPalProcessor) calculateTotalAmount(amount float64, fee float64) float64 {
-	return p.addFeeToAmount(amount, fee)
+func (p *PayPalProcessor) buildAuthorizationResult(quote FeeQuote) PaymentResult {
+	result := p.buildPaymentResult(quote)
+	result.Status = PaymentStatusAuthorized
+	result.ExpiresAt = result.Timestamp.Add(p.authorizationTTL)
+	return result
 }
 
-func (p *PayPalProcessor) addFeeToAmount(amount float64, fee float64) float64 {
-	return amount + fee
+func (p *PayPalProcessor) recordAuthorization(result PaymentResult) PaymentResult {
+	p.ledger.authorize(result)
+	return result
 }
 
-func (p *PayPalProcessor) simulateProcessingDelay() {
-	p.waitForProcessing(150 * time.Millisecond)
+func (p *PayPalProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	return p.executeCapture(ctx, authorizationID, amount)
 }
 
-func (p *PayPalProcessor) waitForProcessing(duration time.Duration) {
-	time.Sleep(duration)
+func (p *PayPalProcessor) executeCapture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, p.createCancellationError(err)
+	}
+	return p.ledger.capture(authorizationID, amount, p.getCurrentTime(), p.buildCaptureResult)
 }
 
-func (p *PayPalProcessor) formatPaymentResult(total float64, fee float64) string {
-	return fmt.Sprintf("PayPal: $%.2f (fee: $%.2f)", total, fee)
-}
\ No newline at end of file
+// A capture is priced like its authorization, on the captured amount.
+func (p *PayPalProcessor) buildCaptureResult(authorization PaymentResult, amount Money) PaymentResult {
+	result := p.buildPaymentResult(p.fees.quoteLike(amount, authorization.FeeBreakdown))
+	result.TransactionID = authorization.TransactionID
+	return result
+}
+
+func (p *PayPalProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return p.executeRelease(ctx, authorizationID)
+}
+
+func (p *PayPalProcessor) executeRelease(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	if err := p.simulateProcessingDelay(ctx); err != nil {
+		return PaymentResult{}, p.createCancellationError(err)
+	}
+	authorization, err := p.ledger.release(authorizationID, p.getCurrentTime())
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	return p.buildReleaseResult(authorization), nil
+}
+
+func (p *PayPalProcessor) buildReleaseResult(authorization PaymentResult) PaymentResult {
+	authorization.Status = PaymentStatusReleased
+	authorization.Timestamp = p.getCurrentTime()
+	return authorization
+}
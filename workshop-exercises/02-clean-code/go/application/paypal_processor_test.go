@@ -1,8 +1,11 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -10,100 +13,100 @@ import (
 // Testing: paypal_processor.go
 // =============================================================================
 
-func TestPayPalProcessor_ProcessPayment_ValidAmount_ReturnsSuccessMessage(t *testing.T) {
+func TestPayPalProcessor_ProcessPayment_ValidAmount_ReturnsPaymentResult(t *testing.T) {
 	// Arrange
 	processor := NewPayPalProcessor()
-	amount := 100.0
+	amount := MustParseMoney("100.00", USD)
 
 	// Act: Call the method that goes through multiple abstraction layers
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify payment processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if result == "" {
-		t.Error("Expected non-empty result")
+	if result.TransactionID == "" {
+		t.Error("Expected a transaction ID")
 	}
-	if !strings.Contains(result, "PayPal") {
-		t.Errorf("Expected result to contain 'PayPal', got '%s'", result)
+	if result.ProcessorType != ProcessorTypePayPal {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypePayPal, result.ProcessorType)
 	}
-	if !strings.Contains(result, "103.49") { // Amount + 3.49% fee
-		t.Errorf("Expected result to contain '103.49', got '%s'", result)
+	if result.GrossAmount.Decimal() != "103.49" { // Amount + 3.49% fee
+		t.Errorf("Expected result gross amount 103.49, got %s", result.GrossAmount)
 	}
-	if !strings.Contains(result, "fee:") {
-		t.Errorf("Expected result to contain 'fee:', got '%s'", result)
+	if result.Fee.Decimal() != "3.49" {
+		t.Errorf("Expected fee 3.49, got %s", result.Fee)
 	}
 }
 
 func TestPayPalProcessor_ProcessPayment_SmallAmount_CalculatesCorrectFee(t *testing.T) {
 	// Arrange: Test small amount processing
 	processor := NewPayPalProcessor()
-	amount := 10.0
+	amount := MustParseMoney("10.00", USD)
 
 	// Act: Process through the abstraction layers
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify fee calculation (10 + 3.49% = 10.349 ≈ 10.35)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "10.35") {
-		t.Errorf("Expected result to contain '10.35', got '%s'", result)
+	if result.GrossAmount.Decimal() != "10.35" {
+		t.Errorf("Expected result gross amount 10.35, got %s", result.GrossAmount)
 	}
 }
 
 func TestPayPalProcessor_ProcessPayment_LargeAmount_CalculatesCorrectFee(t *testing.T) {
 	// Arrange: Test large amount processing
 	processor := NewPayPalProcessor()
-	amount := 1000.0
+	amount := MustParseMoney("1000.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify fee calculation (1000 + 3.49% = 1034.90)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "1034.90") {
-		t.Errorf("Expected result to contain '1034.90', got '%s'", result)
+	if result.GrossAmount.Decimal() != "1034.90" {
+		t.Errorf("Expected result gross amount 1034.90, got %s", result.GrossAmount)
 	}
 }
 
 func TestPayPalProcessor_ProcessPayment_ZeroAmount_ProcessesWithoutFee(t *testing.T) {
 	// Arrange: Test zero amount
 	processor := NewPayPalProcessor()
-	amount := 0.0
+	amount := MustParseMoney("0.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify zero amount processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "0.00") {
-		t.Errorf("Expected result to contain '0.00', got '%s'", result)
+	if result.GrossAmount.Decimal() != "0.00" {
+		t.Errorf("Expected result gross amount 0.00, got %s", result.GrossAmount)
 	}
 }
 
 func TestPayPalProcessor_ProcessPayment_NegativeAmount_ProcessesNegativeFee(t *testing.T) {
 	// Arrange: Test negative amount (edge case)
 	processor := NewPayPalProcessor()
-	amount := -50.0
+	amount := MustParseMoney("-50.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify negative processing
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if result == "" {
-		t.Error("Expected non-empty result")
+	if result.TransactionID == "" {
+		t.Error("Expected a transaction ID")
 	}
-	if !strings.Contains(result, "PayPal") {
-		t.Errorf("Expected result to contain 'PayPal', got '%s'", result)
+	if result.ProcessorType != ProcessorTypePayPal {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypePayPal, result.ProcessorType)
 	}
 }
 
@@ -111,26 +114,26 @@ func TestPayPalProcessor_ProcessPayment_VariousAmounts_CalculatesCorrectTotals(t
 	processor := NewPayPalProcessor()
 
 	testCases := []struct {
-		amount        float64
+		amount        Money
 		expectedTotal string
 	}{
-		{100.0, "103.49"},
-		{50.0, "51.74"}, // Fixed from 51.75 to match actual calculation
-		{200.0, "206.98"},
-		{1.0, "1.03"},
+		{MustParseMoney("100.00", USD), "103.49"},
+		{MustParseMoney("50.00", USD), "51.75"}, // 1.745 fee rounds half-up to 1.75
+		{MustParseMoney("200.00", USD), "206.98"},
+		{MustParseMoney("1.00", USD), "1.03"},
 	}
 
 	for _, tc := range testCases {
 		t.Run("Amount_"+strings.ReplaceAll(tc.expectedTotal, ".", "_"), func(t *testing.T) {
 			// Act: Process through all the abstraction layers
-			result, err := processor.ProcessPayment(tc.amount)
+			result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(tc.amount))
 
 			// Assert: Verify correct total calculation
 			if err != nil {
 				t.Errorf("Expected no error, got %v", err)
 			}
-			if !strings.Contains(result, tc.expectedTotal) {
-				t.Errorf("Expected result to contain '%s', got '%s'", tc.expectedTotal, result)
+			if result.GrossAmount.Decimal() != tc.expectedTotal {
+				t.Errorf("Expected gross amount %s, got %s", tc.expectedTotal, result.GrossAmount)
 			}
 		})
 	}
@@ -141,45 +144,45 @@ func TestPayPalProcessor_ProcessPayment_MultipleCalls_AllSucceed(t *testing.T) {
 	processor := NewPayPalProcessor()
 
 	// Act: Process multiple payments
-	result1, err1 := processor.ProcessPayment(10.0)
-	result2, err2 := processor.ProcessPayment(20.0)
-	result3, err3 := processor.ProcessPayment(30.0)
+	result1, err1 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+	result2, err2 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("20.00", USD)))
+	result3, err3 := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("30.00", USD)))
 
 	// Assert: Verify all calls succeed
 	if err1 != nil || err2 != nil || err3 != nil {
 		t.Error("Expected no errors for multiple calls")
 	}
-	if !strings.Contains(result1, "10.35") {
-		t.Errorf("Expected result1 to contain '10.35', got '%s'", result1)
+	if result1.GrossAmount.Decimal() != "10.35" {
+		t.Errorf("Expected result1 gross amount 10.35, got %s", result1.GrossAmount)
 	}
-	if !strings.Contains(result2, "20.70") {
-		t.Errorf("Expected result2 to contain '20.70', got '%s'", result2)
+	if result2.GrossAmount.Decimal() != "20.70" {
+		t.Errorf("Expected result2 gross amount 20.70, got %s", result2.GrossAmount)
 	}
-	if !strings.Contains(result3, "31.05") {
-		t.Errorf("Expected result3 to contain '31.05', got '%s'", result3)
+	if result3.GrossAmount.Decimal() != "31.05" {
+		t.Errorf("Expected result3 gross amount 31.05, got %s", result3.GrossAmount)
 	}
 }
 
 func TestPayPalProcessor_ProcessPayment_ContainsExpectedElements(t *testing.T) {
 	// Arrange: Test result format
 	processor := NewPayPalProcessor()
-	amount := 75.0
+	amount := MustParseMoney("75.00", USD)
 
 	// Act: Process payment
-	result, err := processor.ProcessPayment(amount)
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: Verify result contains all expected elements
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	if !strings.Contains(result, "PayPal") {
-		t.Errorf("Expected result to contain 'PayPal', got '%s'", result)
+	if result.ProcessorType != ProcessorTypePayPal {
+		t.Errorf("Expected result processor type %s, got %s", ProcessorTypePayPal, result.ProcessorType)
 	}
-	if !strings.Contains(result, "fee:") {
-		t.Errorf("Expected result to contain 'fee:', got '%s'", result)
+	if result.Fee.Decimal() != "2.62" {
+		t.Errorf("Expected fee 2.62, got %s", result.Fee)
 	}
-	if !strings.Contains(result, "77.62") { // 75 + 3.49% = 77.6175 ≈ 77.62
-		t.Errorf("Expected result to contain '77.62', got '%s'", result)
+	if result.GrossAmount.Decimal() != "77.62" { // 75 + 3.49% = 77.6175 ≈ 77.62
+		t.Errorf("Expected result gross amount 77.62, got %s", result.GrossAmount)
 	}
 }
 
@@ -187,20 +190,241 @@ func TestPayPalProcessor_ProcessPayment_CompareWithCreditCard_HasHigherFee(t *te
 	// Arrange: Compare PayPal vs Credit Card fees
 	paypalProcessor := NewPayPalProcessor()
 	creditCardProcessor := NewCreditCardProcessor()
-	amount := 100.0
+	amount := MustParseMoney("100.00", USD)
 
 	// Act: Process same amount with both processors
-	paypalResult, err1 := paypalProcessor.ProcessPayment(amount)
-	creditCardResult, err2 := creditCardProcessor.ProcessPayment(amount)
+	paypalResult, err1 := paypalProcessor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
+	creditCardResult, err2 := creditCardProcessor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
 
 	// Assert: PayPal should have higher total (3.49% vs 2.9%)
 	if err1 != nil || err2 != nil {
 		t.Error("Expected no errors for comparison test")
 	}
-	if !strings.Contains(paypalResult, "103.49") { // PayPal: higher fee
-		t.Errorf("Expected PayPal result to contain '103.49', got '%s'", paypalResult)
+	if paypalResult.GrossAmount.Decimal() != "103.49" { // PayPal: higher fee
+		t.Errorf("Expected PayPal result gross amount 103.49, got %s", paypalResult.GrossAmount)
 	}
-	if !strings.Contains(creditCardResult, "102.90") { // Credit Card: lower fee
-		t.Errorf("Expected Credit Card result to contain '102.90', got '%s'", creditCardResult)
+	if creditCardResult.GrossAmount.Decimal() != "102.90" { // Credit Card: lower fee
+		t.Errorf("Expected Credit Card result gross amount 102.90, got %s", creditCardResult.GrossAmount)
 	}
-}
\ No newline at end of file
+}
+
+func TestPayPalProcessor_ProcessPayment_CancelledContext_ReturnsCancellationError(t *testing.T) {
+	// Arrange: Context is cancelled before processing starts
+	processor := NewPayPalProcessor()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	result, err := processor.ProcessPayment(ctx, NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert: Typed error that still matches the context error
+	var cancelled *PaymentCancelledError
+	if !errors.As(err, &cancelled) {
+		t.Fatalf("Expected PaymentCancelledError, got %v", err)
+	}
+	if cancelled.Processor != "PayPal" {
+		t.Errorf("Expected processor 'PayPal', got '%s'", cancelled.Processor)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error to match context.Canceled, got %v", err)
+	}
+	if result.TransactionID != "" {
+		t.Error("Expected empty result on cancellation")
+	}
+}
+
+func TestPayPalProcessor_ProcessPayment_ValidAmount_ReturnsStructuredBreakdown(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+	amount := MustParseMoney("100.00", USD)
+
+	// Act
+	result, err := processor.ProcessPayment(context.Background(), NewPaymentRequest(amount))
+
+	// Assert: Gross minus fee equals the net amount
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.NetAmount != amount {
+		t.Errorf("Expected net amount %s, got %s", amount, result.NetAmount)
+	}
+	if result.Status != PaymentStatusSucceeded {
+		t.Errorf("Expected status %s, got %s", PaymentStatusSucceeded, result.Status)
+	}
+	if result.Timestamp.IsZero() {
+		t.Error("Expected a timestamp")
+	}
+}
+
+func TestPayPalProcessor_ProcessPayment_MultipleCalls_UseDistinctTransactionIDs(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+
+	// Act
+	first, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+	second, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if first.TransactionID == second.TransactionID {
+		t.Errorf("Expected distinct transaction IDs, got '%s' twice", first.TransactionID)
+	}
+}
+
+func TestPayPalProcessor_Refund_PartialAmount_KeepsFee(t *testing.T) {
+	// Arrange: $100 charge captures $103.49 with a $3.49 fee
+	processor := NewPayPalProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	refund, err := processor.Refund(context.Background(), NewPartialRefundRequest(payment.TransactionID, MustParseMoney("50.00", USD)))
+
+	// Assert: PayPal does not return its fee on refunds
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !refund.FeeReversed.IsZero() {
+		t.Errorf("Expected no fee reversed, got %s", refund.FeeReversed)
+	}
+	if refund.RemainingRefundable.Decimal() != "53.49" {
+		t.Errorf("Expected remaining 53.49, got %s", refund.RemainingRefundable)
+	}
+}
+
+func TestPayPalProcessor_Refund_FullRefundTwice_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("20.00", USD)))
+	if _, err := processor.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID)); err != nil {
+		t.Fatalf("Expected first refund to succeed, got %v", err)
+	}
+
+	// Act
+	_, err := processor.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if !errors.Is(err, ErrRefundExceedsCaptured) {
+		t.Errorf("Expected ErrRefundExceedsCaptured, got %v", err)
+	}
+}
+
+func TestPayPalProcessor_Void_UnrefundedPayment_ReturnsFee(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Act
+	void, err := processor.Void(context.Background(), payment.TransactionID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if void.FeeReversed.Decimal() != "3.49" {
+		t.Errorf("Expected fee reversed 3.49, got %s", void.FeeReversed)
+	}
+}
+
+func TestPayPalProcessor_Refund_AfterVoid_ReturnsError(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+	payment, _ := processor.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+	_, _ = processor.Void(context.Background(), payment.TransactionID)
+
+	// Act
+	_, err := processor.Refund(context.Background(), NewFullRefundRequest(payment.TransactionID))
+
+	// Assert
+	if !errors.Is(err, ErrTransactionVoided) {
+		t.Errorf("Expected ErrTransactionVoided, got %v", err)
+	}
+}
+
+func TestPayPalProcessor_ReleaseAuthorization_PendingAuthorization_PreventsCapture(t *testing.T) {
+	// Arrange
+	processor := NewPayPalProcessor()
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("80.00", USD)))
+
+	// Act
+	release, err := processor.ReleaseAuthorization(context.Background(), authorization.TransactionID)
+	_, captureErr := processor.Capture(context.Background(), authorization.TransactionID, Money{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if release.Status != PaymentStatusReleased {
+		t.Errorf("Expected status %s, got %s", PaymentStatusReleased, release.Status)
+	}
+	if !errors.Is(captureErr, ErrAuthorizationNotPending) {
+		t.Errorf("Expected ErrAuthorizationNotPending, got %v", captureErr)
+	}
+}
+
+func TestPayPalProcessor_Authorize_DefaultConfig_ExpiresAfterHonorPeriod(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	config := DefaultPayPalConfig()
+	config.Clock = clock
+	processor, _ := NewPayPalProcessorWithConfig(config)
+	authorization, _ := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("80.00", USD)))
+
+	// Act
+	clock.Advance(72 * time.Hour)
+	_, err := processor.ReleaseAuthorization(context.Background(), authorization.TransactionID)
+
+	// Assert
+	if !errors.Is(err, ErrAuthorizationExpired) {
+		t.Errorf("Expected ErrAuthorizationExpired, got %v", err)
+	}
+}
+
+func TestPayPalProcessor_WithInvalidFeeSchedule_ReturnsError(t *testing.T) {
+	// Arrange
+	config := DefaultPayPalConfig()
+	config.Fees = &FeeSchedule{Percent: NewPercentageFromFloat(-1)}
+
+	// Act
+	_, err := NewPayPalProcessorWithConfig(config)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidFeeSchedule) {
+		t.Errorf("Expected ErrInvalidFeeSchedule, got %v", err)
+	}
+}
+
+func TestPayPalProcessor_ZeroConfig_UsesDefaultClockAndTTL(t *testing.T) {
+	// Arrange
+	processor, _ := NewPayPalProcessorWithConfig(ProcessorConfig{})
+
+	// Act
+	authorization, err := processor.Authorize(context.Background(), NewPaymentRequest(MustParseMoney("100.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ttl := authorization.ExpiresAt.Sub(authorization.Timestamp); ttl != DefaultPayPalConfig().AuthorizationTTL {
+		t.Errorf("Expected the default authorization TTL, got %s", ttl)
+	}
+}
+
+func TestPayPalProcessor_QuoteFee_MatchesChargedFee(t *testing.T) {
+	// Arrange
+	config := DefaultPayPalConfig()
+	schedule := FeeSchedule{Percent: NewPercentageFromFloat(3.49), FixedFees: []Money{MustParseMoney("0.49", USD)}}
+	config.Fees = &schedule
+	processor, _ := NewPayPalProcessorWithConfig(config)
+	request := NewPaymentRequest(MustParseMoney("20.00", USD))
+
+	// Act
+	quote, quoteErr := QuoteFee(context.Background(), processor, request)
+	payment, payErr := processor.ProcessPayment(context.Background(), request)
+
+	// Assert
+	if quoteErr != nil || payErr != nil {
+		t.Fatalf("Expected no errors, got %v / %v", quoteErr, payErr)
+	}
+	if quote.Fee.Decimal() != "1.19" || quote.Fee != payment.Fee {
+		t.Errorf("Expected the quoted 1.19 fee to be charged, got quote %s and charge %s", quote.Fee, payment.Fee)
+	}
+}
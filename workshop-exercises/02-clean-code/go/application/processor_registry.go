@@ -0,0 +1,75 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// =============================================================================
+// PROCESSOR REGISTRY
+// Payment processors registered by name, e.g. "credit_card" or "paypal"
+// =============================================================================
+
+var (
+	ErrProcessorNotFound          = errors.New("payment processor not registered")
+	ErrProcessorAlreadyRegistered = errors.New("payment processor already registered")
+)
+
+type ProcessorRegistryInterface interface {
+	Register(name string, processor PaymentProcessorInterface) error
+	Lookup(name string) (PaymentProcessorInterface, error)
+	Names() []string
+}
+
+type ProcessorRegistry struct {
+	mu         sync.RWMutex
+	processors map[string]PaymentProcessorInterface
+	names      []string
+}
+
+func NewProcessorRegistry() ProcessorRegistryInterface {
+	return &ProcessorRegistry{
+		processors: make(map[string]PaymentProcessorInterface),
+	}
+}
+
+// NewDefaultProcessorRegistry registers the built-in processors under their
+// ProcessorType names.
+func NewDefaultProcessorRegistry() ProcessorRegistryInterface {
+	registry := NewProcessorRegistry()
+	_ = registry.Register(string(ProcessorTypeCreditCard), NewCreditCardProcessor())
+	_ = registry.Register(string(ProcessorTypePayPal), NewPayPalProcessor())
+	return registry
+}
+
+func (r *ProcessorRegistry) Register(name string, processor PaymentProcessorInterface) error {
+	if name == "" || processor == nil {
+		return fmt.Errorf("register payment processor: name and processor are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.processors[name]; exists {
+		return fmt.Errorf("%w: %s", ErrProcessorAlreadyRegistered, name)
+	}
+	r.processors[name] = processor
+	r.names = append(r.names, name)
+	return nil
+}
+
+func (r *ProcessorRegistry) Lookup(name string) (PaymentProcessorInterface, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	processor, ok := r.processors[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProcessorNotFound, name)
+	}
+	return processor, nil
+}
+
+// Names lists the registered processors in registration order.
+func (r *ProcessorRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.names...)
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"errors"
+	"testing"
+)
+
+// =============================================================================
+// PROCESSOR REGISTRY TESTS
+// Testing: processor_registry.go
+// =============================================================================
+
+func TestProcessorRegistry_Lookup_RegisteredName_ReturnsProcessor(t *testing.T) {
+	// Arrange
+	registry := NewProcessorRegistry()
+	processor := NewMockPaymentProcessor(false)
+	_ = registry.Register("mock", processor)
+
+	// Act
+	found, err := registry.Lookup("mock")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if found != processor {
+		t.Error("Expected the registered processor")
+	}
+}
+
+func TestProcessorRegistry_Lookup_UnknownName_ReturnsError(t *testing.T) {
+	// Act
+	_, err := NewProcessorRegistry().Lookup("stripe")
+
+	// Assert
+	if !errors.Is(err, ErrProcessorNotFound) {
+		t.Errorf("Expected ErrProcessorNotFound, got %v", err)
+	}
+}
+
+func TestProcessorRegistry_Register_DuplicateName_ReturnsError(t *testing.T) {
+	// Arrange
+	registry := NewProcessorRegistry()
+	_ = registry.Register("mock", NewMockPaymentProcessor(false))
+
+	// Act
+	err := registry.Register("mock", NewMockPaymentProcessor(false))
+
+	// Assert
+	if !errors.Is(err, ErrProcessorAlreadyRegistered) {
+		t.Errorf("Expected ErrProcessorAlreadyRegistered, got %v", err)
+	}
+}
+
+func TestProcessorRegistry_NewDefault_RegistersBuiltInProcessors(t *testing.T) {
+	// Act
+	names := NewDefaultProcessorRegistry().Names()
+
+	// Assert
+	if len(names) != 2 || names[0] != "credit_card" || names[1] != "paypal" {
+		t.Errorf("Expected [credit_card paypal], got %v", names)
+	}
+}
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"errors"
+	"time"
+)
+
+// =============================================================================
+// REFUNDS AND VOIDS
+// Reversals tracked against the original transaction ID
+// =============================================================================
+
+var (
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrInvalidRefundAmount   = errors.New("refund amount must be positive")
+	ErrRefundExceedsCaptured = errors.New("refund exceeds captured amount")
+	ErrTransactionVoided     = errors.New("transaction already voided")
+	ErrVoidNotAllowed        = errors.New("transaction cannot be voided after a refund")
+)
+
+type RefundType string
+
+const (
+	RefundTypeFull    RefundType = "full"
+	RefundTypePartial RefundType = "partial"
+	RefundTypeVoid    RefundType = "void"
+	// RefundTypeChargeback is money taken back through a lost dispute.
+	RefundTypeChargeback RefundType = "chargeback"
+)
+
+// RefundRequest refunds part of a captured payment. A zero Amount refunds
+// whatever is still refundable on the transaction.
+type RefundRequest struct {
+	TransactionID string
+	Amount        Money
+	Reason        string
+}
+
+func NewFullRefundRequest(transactionID string) RefundRequest {
+	return RefundRequest{TransactionID: transactionID}
+}
+
+func NewPartialRefundRequest(transactionID string, amount Money) RefundRequest {
+	return RefundRequest{TransactionID: transactionID, Amount: amount}
+}
+
+func (r RefundRequest) IsFull() bool {
+	return r.Amount.IsZero()
+}
+
+type RefundResult struct {
+	RefundID            string
+	TransactionID       string
+	ProcessorType       ProcessorType
+	Type                RefundType
+	Amount              Money
+	FeeReversed         Money
+	RemainingRefundable Money
+	Timestamp           time.Time
+	Status              PaymentStatus
+}
@@ -0,0 +1,93 @@
+package application
+
+import "fmt"
+
+// =============================================================================
+// RESULT FORMATTER
+// Human-readable text for structured payment and order results
+// =============================================================================
+
+type ResultFormatterInterface interface {
+	FormatPaymentResult(result PaymentResult) string
+	FormatOrderResult(result OrderResult) string
+	FormatRefundResult(result RefundResult) string
+	FormatLineBreakdown(line LineBreakdown) string
+	FormatTaxResult(result TaxResult) string
+}
+
+type TextResultFormatter struct{}
+
+func NewTextResultFormatter() ResultFormatterInterface {
+	return &TextResultFormatter{}
+}
+
+func (f *TextResultFormatter) FormatPaymentResult(result PaymentResult) string {
+	return fmt.Sprintf("%s: %s (fee: %s)", result.ProcessorType.DisplayName(), result.GrossAmount, result.Fee)
+}
+
+func (f *TextResultFormatter) FormatOrderResult(result OrderResult) string {
+	text := fmt.Sprintf("Order %s %s: %s (Final: %s)", result.OrderID, f.describeOrderStatus(result.Status), f.FormatPaymentResult(result.Payment), result.FinalAmount)
+	if result.Tax.TaxAmount.IsPositive() {
+		text += fmt.Sprintf(" (%s: %s)", result.Tax.TaxName, result.Tax.TaxAmount)
+	}
+	if result.RefundedAmount.IsPositive() {
+		text += fmt.Sprintf(" (Refunded: %s)", result.RefundedAmount)
+	}
+	return text
+}
+
+func (f *TextResultFormatter) describeOrderStatus(status OrderStatus) string {
+	switch status {
+	case OrderStatusCreated:
+		return "created"
+	case OrderStatusValidated:
+		return "validated"
+	case OrderStatusPending:
+		return "pending"
+	case OrderStatusFailed:
+		return "failed"
+	case OrderStatusAuthorized:
+		return "authorized"
+	case OrderStatusCancelled:
+		return "cancelled"
+	case OrderStatusRefunded:
+		return "refunded"
+	case OrderStatusDisputed:
+		return "disputed"
+	case OrderStatusFulfilled:
+		return "fulfilled"
+	default:
+		return "completed"
+	}
+}
+
+func (f *TextResultFormatter) FormatRefundResult(result RefundResult) string {
+	return fmt.Sprintf("%s %s of %s: %s (fee reversed: %s, remaining: %s)",
+		result.ProcessorType.DisplayName(), f.describeRefundType(result.Type), result.TransactionID, result.Amount, result.FeeReversed, result.RemainingRefundable)
+}
+
+// FormatLineBreakdown renders "2 x SKU-1 @ $12.50 = $25.00 (discount: $2.50) -> $22.50";
+// the discount part is left out for lines without any.
+func (f *TextResultFormatter) FormatLineBreakdown(line LineBreakdown) string {
+	text := fmt.Sprintf("%d x %s @ %s = %s", line.Quantity, line.ProductID, line.UnitPrice, line.Subtotal)
+	if discount, _ := line.Subtotal.Subtract(line.Total); !discount.IsZero() {
+		text += fmt.Sprintf(" (discount: %s)", discount)
+	}
+	return text + fmt.Sprintf(" -> %s", line.Total)
+}
+
+// FormatTaxResult renders "Sales tax (US-CA): $5.08 on $70.00 net = $75.08".
+func (f *TextResultFormatter) FormatTaxResult(result TaxResult) string {
+	text := fmt.Sprintf("%s (%s): %s on %s net = %s", result.TaxName, result.Jurisdiction, result.TaxAmount, result.NetAmount, result.GrossAmount)
+	if result.ReverseCharge {
+		text += " (reverse charge)"
+	}
+	return text
+}
+
+func (f *TextResultFormatter) describeRefundType(refundType RefundType) string {
+	if refundType == RefundTypeVoid || refundType == RefundTypeChargeback {
+		return string(refundType)
+	}
+	return string(refundType) + " refund"
+}
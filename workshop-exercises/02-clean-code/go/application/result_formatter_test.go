@@ -0,0 +1,167 @@
+package application
+
+import "testing"
+
+// =============================================================================
+// RESULT FORMATTER TESTS
+// Testing: result_formatter.go
+// =============================================================================
+
+func TestTextResultFormatter_FormatPaymentResult_CreditCard_ReturnsLegacyText(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := PaymentResult{
+		ProcessorType: ProcessorTypeCreditCard,
+		GrossAmount:   MustParseMoney("103.41", USD),
+		Fee:           MustParseMoney("2.91", USD),
+		NetAmount:     MustParseMoney("100.50", USD),
+	}
+
+	// Act
+	text := formatter.FormatPaymentResult(result)
+
+	// Assert
+	expected := "Credit Card: $103.41 (fee: $2.91)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
+
+func TestTextResultFormatter_FormatOrderResult_PayPal_NestsPaymentText(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := OrderResult{
+		OrderID:     "order_1",
+		FinalAmount: MustParseMoney("85.00", EUR),
+		Payment: PaymentResult{
+			ProcessorType: ProcessorTypePayPal,
+			GrossAmount:   MustParseMoney("87.97", EUR),
+			Fee:           MustParseMoney("2.97", EUR),
+		},
+	}
+
+	// Act
+	text := formatter.FormatOrderResult(result)
+
+	// Assert
+	expected := "Order order_1 completed: PayPal: €87.97 (fee: €2.97) (Final: €85.00)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
+
+func TestTextResultFormatter_FormatOrderResult_RefundedOrder_ShowsRefund(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := OrderResult{
+		OrderID:        "order_2",
+		Status:         OrderStatusRefunded,
+		FinalAmount:    MustParseMoney("10.00", USD),
+		RefundedAmount: MustParseMoney("10.29", USD),
+		Payment: PaymentResult{
+			ProcessorType: ProcessorTypeCreditCard,
+			GrossAmount:   MustParseMoney("10.29", USD),
+			Fee:           MustParseMoney("0.29", USD),
+		},
+	}
+
+	// Act
+	text := formatter.FormatOrderResult(result)
+
+	// Assert
+	expected := "Order order_2 refunded: Credit Card: $10.29 (fee: $0.29) (Final: $10.00) (Refunded: $10.29)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
+
+func TestTextResultFormatter_FormatRefundResult_PartialRefund_DescribesRemaining(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := RefundResult{
+		TransactionID:       "pp_1",
+		ProcessorType:       ProcessorTypePayPal,
+		Type:                RefundTypePartial,
+		Amount:              MustParseMoney("50.00", USD),
+		FeeReversed:         ZeroMoney(USD),
+		RemainingRefundable: MustParseMoney("53.49", USD),
+	}
+
+	// Act
+	text := formatter.FormatRefundResult(result)
+
+	// Assert
+	expected := "PayPal partial refund of pp_1: $50.00 (fee reversed: $0.00, remaining: $53.49)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
+
+func TestTextResultFormatter_FormatLineBreakdown_ShowsDiscountAndTotal(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	discounted := LineBreakdown{
+		ProductID: "SKU-MUG",
+		Quantity:  3,
+		UnitPrice: MustParseMoney("25.00", USD),
+		Subtotal:  MustParseMoney("75.00", USD),
+		Total:     MustParseMoney("63.75", USD),
+	}
+	plain := discounted
+	plain.Total = plain.Subtotal
+
+	// Act & Assert
+	if text := formatter.FormatLineBreakdown(discounted); text != "3 x SKU-MUG @ $25.00 = $75.00 (discount: $11.25) -> $63.75" {
+		t.Errorf("Unexpected discounted line text %q", text)
+	}
+	if text := formatter.FormatLineBreakdown(plain); text != "3 x SKU-MUG @ $25.00 = $75.00 -> $75.00" {
+		t.Errorf("Unexpected plain line text %q", text)
+	}
+}
+
+func TestTextResultFormatter_FormatOrderResult_TaxedOrder_ShowsTax(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := OrderResult{
+		OrderID:     "order_3",
+		Status:      OrderStatusPaid,
+		FinalAmount: MustParseMoney("107.25", USD),
+		Tax:         TaxResult{TaxName: "Sales tax", TaxAmount: MustParseMoney("7.25", USD)},
+		Payment: PaymentResult{
+			ProcessorType: ProcessorTypeCreditCard,
+			GrossAmount:   MustParseMoney("110.36", USD),
+			Fee:           MustParseMoney("3.11", USD),
+		},
+	}
+
+	// Act
+	text := formatter.FormatOrderResult(result)
+
+	// Assert
+	expected := "Order order_3 completed: Credit Card: $110.36 (fee: $3.11) (Final: $107.25) (Sales tax: $7.25)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
+
+func TestTextResultFormatter_FormatTaxResult_ReverseCharge_SaysSo(t *testing.T) {
+	// Arrange
+	formatter := NewTextResultFormatter()
+	result := TaxResult{
+		TaxName:       "VAT",
+		Jurisdiction:  "FR",
+		ReverseCharge: true,
+		NetAmount:     MustParseMoney("100.00", EUR),
+		TaxAmount:     MustParseMoney("0.00", EUR),
+		GrossAmount:   MustParseMoney("100.00", EUR),
+	}
+
+	// Act
+	text := formatter.FormatTaxResult(result)
+
+	// Assert
+	expected := "VAT (FR): €0.00 on €100.00 net = €100.00 (reverse charge)"
+	if text != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, text)
+	}
+}
@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// RETRYING PROCESSOR
+// Decorator that retries transient failures with exponential backoff
+// =============================================================================
+
+// RetryPolicy doubles (by Multiplier) the wait after every failed attempt,
+// starting at InitialBackoff and never above MaxBackoff. Jitter takes up to
+// that fraction off each wait so clients that failed together do not all
+// retry at the same moment.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff is the wait before attempt+1, given a random value in [0, 1).
+func (p RetryPolicy) Backoff(attempt int, random float64) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	delay = math.Min(delay, float64(p.MaxBackoff))
+	return time.Duration(delay * (1 - p.Jitter*random))
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("retry policy: at least one attempt is required")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < p.InitialBackoff || p.Multiplier < 1 {
+		return fmt.Errorf("retry policy: backoff must grow from InitialBackoff up to MaxBackoff")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("retry policy: jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// RetryConfig leaves Sleeper and Random at the system defaults when nil.
+type RetryConfig struct {
+	Policy  RetryPolicy
+	Sleeper Sleeper
+	Random  func() float64
+}
+
+type RetryingProcessor struct {
+	processor PaymentProcessorInterface
+	policy    RetryPolicy
+	sleeper   Sleeper
+	random    func() float64
+}
+
+func NewRetryingProcessor(processor PaymentProcessorInterface, config RetryConfig) (PaymentProcessorInterface, error) {
+	if err := config.Policy.validate(); err != nil {
+		return nil, err
+	}
+	retrying := &RetryingProcessor{
+		processor: processor,
+		policy:    config.Policy,
+		sleeper:   config.Sleeper,
+		random:    config.Random,
+	}
+	if retrying.sleeper == nil {
+		retrying.sleeper = NewSystemSleeper()
+	}
+	if retrying.random == nil {
+		retrying.random = newLockedRandom()
+	}
+	return retrying, nil
+}
+
+func (r *RetryingProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (PaymentResult, error) {
+		return r.processor.ProcessPayment(ctx, request)
+	})
+}
+
+func (r *RetryingProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (PaymentResult, error) {
+		return r.processor.Authorize(ctx, request)
+	})
+}
+
+func (r *RetryingProcessor) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (PaymentResult, error) {
+		return r.processor.Capture(ctx, authorizationID, amount)
+	})
+}
+
+func (r *RetryingProcessor) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (PaymentResult, error) {
+		return r.processor.ReleaseAuthorization(ctx, authorizationID)
+	})
+}
+
+func (r *RetryingProcessor) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (RefundResult, error) {
+		return r.processor.Refund(ctx, request)
+	})
+}
+
+func (r *RetryingProcessor) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	return retryPayment(ctx, r, func(ctx context.Context) (RefundResult, error) {
+		return r.processor.Void(ctx, transactionID)
+	})
+}
+
+func (r *RetryingProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	return QuoteFee(ctx, r.processor, request)
+}
+
+// retryPayment only repeats failures the processor marked transient, which
+// it may only do when the request was never acted on; an outcome it cannot
+// tell is returned as is. Every attempt carries the same payment idempotency
+// key, so a processor that did act after all can answer the repeat with the
+// first result instead of charging or refunding twice.
+func retryPayment[T any](ctx context.Context, r *RetryingProcessor, operation func(context.Context) (T, error)) (T, error) {
+	ctx = ensurePaymentIdempotencyKey(ctx)
+	var result T
+	var err error
+	for attempt := 1; ; attempt++ {
+		result, err = operation(ctx)
+		if err == nil || !IsRetryablePaymentError(err) {
+			return result, err
+		}
+		if attempt == r.policy.MaxAttempts {
+			return result, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		if sleepErr := r.sleeper.Sleep(ctx, r.policy.Backoff(attempt, r.random())); sleepErr != nil {
+			return result, fmt.Errorf("retry abandoned after %d attempts: %w (last error: %w)", attempt, sleepErr, err)
+		}
+	}
+}
+
+// newLockedRandom is a jitter source that is safe for concurrent payments.
+func newLockedRandom() func() float64 {
+	var mu sync.Mutex
+	source := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return func() float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return source.Float64()
+	}
+}
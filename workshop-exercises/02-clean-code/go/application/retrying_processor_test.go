@@ -0,0 +1,218 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// RETRYING PROCESSOR TESTS
+// Testing: retrying_processor.go
+// =============================================================================
+
+// recordingSleeper advances a manual clock instead of sleeping
+type recordingSleeper struct {
+	clock  *ManualClock
+	slept  []time.Duration
+	failOn int
+}
+
+func (s *recordingSleeper) Sleep(ctx context.Context, duration time.Duration) error {
+	s.slept = append(s.slept, duration)
+	if s.failOn == len(s.slept) {
+		return context.DeadlineExceeded
+	}
+	s.clock.Advance(duration)
+	return nil
+}
+
+// flakyProcessor fails with the given errors before it starts succeeding,
+// noting the payment idempotency key each attempt came with
+type flakyProcessor struct {
+	*MockPaymentProcessor
+	failures []error
+	keys     []string
+}
+
+func (f *flakyProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	key, _ := PaymentIdempotencyKey(ctx)
+	f.keys = append(f.keys, key)
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		f.calls++
+		return PaymentResult{}, err
+	}
+	return f.MockPaymentProcessor.ProcessPayment(ctx, request)
+}
+
+func newRetryTestProcessor(t *testing.T, processor PaymentProcessorInterface, sleeper Sleeper) PaymentProcessorInterface {
+	t.Helper()
+	retrying, err := NewRetryingProcessor(processor, RetryConfig{
+		Policy:  RetryPolicy{MaxAttempts: 4, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2, Jitter: 0.5},
+		Sleeper: sleeper,
+		Random:  func() float64 { return 0 },
+	})
+	if err != nil {
+		t.Fatalf("Expected valid retry policy, got %v", err)
+	}
+	return retrying
+}
+
+func TestRetryingProcessor_ProcessPayment_TransientThenSuccess_RetriesWithBackoff(t *testing.T) {
+	// Arrange
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	sleeper := &recordingSleeper{clock: clock}
+	transient := NewTransientPaymentError("Credit Card", errors.New("connection reset"))
+	processor := &flakyProcessor{MockPaymentProcessor: NewMockPaymentProcessor(false), failures: []error{transient, transient, transient}}
+	retrying := newRetryTestProcessor(t, processor, sleeper)
+
+	// Act
+	result, err := retrying.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected the fourth attempt to succeed, got %v", err)
+	}
+	if result.TransactionID != "mock_txn" || processor.callCount() != 4 {
+		t.Errorf("Expected 4 attempts, got %d", processor.callCount())
+	}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	if len(sleeper.slept) != 3 || sleeper.slept[0] != expected[0] || sleeper.slept[1] != expected[1] || sleeper.slept[2] != expected[2] {
+		t.Errorf("Expected capped exponential backoff %v, got %v", expected, sleeper.slept)
+	}
+}
+
+func TestRetryingProcessor_ProcessPayment_AttemptsExhausted_ReturnsLastError(t *testing.T) {
+	// Arrange
+	sleeper := &recordingSleeper{clock: NewManualClock(time.Now())}
+	processor := NewFailingMockPaymentProcessor(NewTransientPaymentError("PayPal", nil))
+	retrying := newRetryTestProcessor(t, processor, sleeper)
+
+	// Act
+	_, err := retrying.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrProcessorUnavailable) {
+		t.Errorf("Expected ErrProcessorUnavailable, got %v", err)
+	}
+	if processor.callCount() != 4 || len(sleeper.slept) != 3 {
+		t.Errorf("Expected 4 attempts and 3 waits, got %d and %d", processor.callCount(), len(sleeper.slept))
+	}
+}
+
+func TestRetryingProcessor_ProcessPayment_Decline_DoesNotRetry(t *testing.T) {
+	// Arrange
+	sleeper := &recordingSleeper{clock: NewManualClock(time.Now())}
+	processor := NewFailingMockPaymentProcessor(NewPaymentError(PaymentErrorInsufficientFunds, "Credit Card", "balance too low"))
+	retrying := newRetryTestProcessor(t, processor, sleeper)
+
+	// Act
+	_, err := retrying.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+	}
+	if processor.callCount() != 1 || len(sleeper.slept) != 0 {
+		t.Errorf("Expected a single attempt, got %d", processor.callCount())
+	}
+}
+
+func TestRetryingProcessor_ProcessPayment_OutcomeUnknown_DoesNotRetry(t *testing.T) {
+	// Arrange
+	sleeper := &recordingSleeper{clock: NewManualClock(time.Now())}
+	processor := NewFailingMockPaymentProcessor(NewOutcomeUnknownError("PayPal", errors.New("read timeout")))
+	retrying := newRetryTestProcessor(t, processor, sleeper)
+
+	// Act
+	_, err := retrying.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if !errors.Is(err, ErrPaymentOutcomeUnknown) {
+		t.Errorf("Expected ErrPaymentOutcomeUnknown, got %v", err)
+	}
+	if processor.callCount() != 1 {
+		t.Errorf("Expected a payment that may have gone through not to be repeated, got %d attempts", processor.callCount())
+	}
+}
+
+func TestRetryingProcessor_ProcessPayment_Retries_ShareIdempotencyKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"generated", context.Background()},
+		{"from caller", WithPaymentIdempotencyKey(context.Background(), "order-key")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			transient := NewTransientPaymentError("Credit Card", nil)
+			processor := &flakyProcessor{MockPaymentProcessor: NewMockPaymentProcessor(false), failures: []error{transient, transient}}
+			retrying := newRetryTestProcessor(t, processor, &recordingSleeper{clock: NewManualClock(time.Now())})
+
+			// Act
+			_, err := retrying.ProcessPayment(tc.ctx, NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected the third attempt to succeed, got %v", err)
+			}
+			if len(processor.keys) != 3 || processor.keys[0] == "" || processor.keys[1] != processor.keys[0] || processor.keys[2] != processor.keys[0] {
+				t.Fatalf("Expected every attempt to carry the same key, got %q", processor.keys)
+			}
+			if key, ok := PaymentIdempotencyKey(tc.ctx); ok && processor.keys[0] != key {
+				t.Errorf("Expected the caller's key %q, got %q", key, processor.keys[0])
+			}
+		})
+	}
+}
+
+func TestRetryingProcessor_ProcessPayment_ContextDoneWhileWaiting_StopsRetrying(t *testing.T) {
+	// Arrange
+	sleeper := &recordingSleeper{clock: NewManualClock(time.Now()), failOn: 1}
+	processor := NewFailingMockPaymentProcessor(NewTransientPaymentError("PayPal", nil))
+	retrying := newRetryTestProcessor(t, processor, sleeper)
+
+	// Act
+	_, err := retrying.ProcessPayment(context.Background(), NewPaymentRequest(MustParseMoney("10.00", USD)))
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrProcessorUnavailable) {
+		t.Errorf("Expected both the deadline and the last failure, got %v", err)
+	}
+	if processor.callCount() != 1 {
+		t.Errorf("Expected a single attempt, got %d", processor.callCount())
+	}
+}
+
+func TestRetryPolicy_Backoff_JitterShortensWait(t *testing.T) {
+	// Arrange
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, Multiplier: 2, Jitter: 0.25}
+
+	// Act
+	longest := policy.Backoff(2, 0)
+	shortest := policy.Backoff(2, 0.999999)
+
+	// Assert
+	if longest != 2*time.Second {
+		t.Errorf("Expected 2s without jitter, got %s", longest)
+	}
+	if shortest <= 1500*time.Millisecond || shortest >= longest {
+		t.Errorf("Expected jitter to take at most 25%% off, got %s", shortest)
+	}
+}
+
+func TestNewRetryingProcessor_InvalidPolicy_ReturnsError(t *testing.T) {
+	// Act
+	_, err := NewRetryingProcessor(NewMockPaymentProcessor(false), RetryConfig{Policy: RetryPolicy{}})
+
+	// Assert
+	if err == nil {
+		t.Error("Expected a policy without attempts to be rejected")
+	}
+}
@@ -0,0 +1,259 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// ROUTING PROCESSOR
+// Picks a registered processor per payment; refunds, captures and voids go
+// back to the processor that handled the original transaction
+// =============================================================================
+
+// RoutingRule sends payments matching every condition it sets to Processor.
+// MinAmount is inclusive, MaxAmount exclusive; an amount bound in another
+// currency never matches.
+type RoutingRule struct {
+	Name          string
+	Processor     string
+	CustomerTypes []string
+	Currencies    []Currency
+	MinAmount     *Money
+	MaxAmount     *Money
+}
+
+// RoutingConfig lists rules in the order they are tried. An order's explicit
+// processor preference wins over the rules; DefaultProcessor takes whatever
+// no rule matches.
+type RoutingConfig struct {
+	DefaultProcessor string
+	Rules            []RoutingRule
+	DecisionLog      RoutingDecisionLogInterface
+	Clock            Clock
+}
+
+// RoutingDecision records where a payment was sent. Err is set when the
+// chosen processor is not registered and the payment went nowhere.
+type RoutingDecision struct {
+	OrderID   string
+	Customer  string
+	Operation PaymentOperation
+	Amount    Money
+	Processor string
+	Reason    string
+	Err       error
+	Timestamp time.Time
+}
+
+type RoutingProcessor struct {
+	*transactionRouter
+	registry         ProcessorRegistryInterface
+	defaultProcessor string
+	rules            []RoutingRule
+	decisions        RoutingDecisionLogInterface
+	clock            Clock
+}
+
+func NewRoutingProcessor(registry ProcessorRegistryInterface, config RoutingConfig) (PaymentProcessorInterface, error) {
+	if err := validateRoutingConfig(registry, config); err != nil {
+		return nil, err
+	}
+	router := &RoutingProcessor{
+		transactionRouter: newTransactionRouter(registry),
+		registry:          registry,
+		defaultProcessor:  config.DefaultProcessor,
+		rules:             append([]RoutingRule(nil), config.Rules...),
+		decisions:         config.DecisionLog,
+		clock:             config.Clock,
+	}
+	if router.decisions == nil {
+		router.decisions = NewInMemoryRoutingDecisionLog()
+	}
+	if router.clock == nil {
+		router.clock = NewSystemClock()
+	}
+	return router, nil
+}
+
+func validateRoutingConfig(registry ProcessorRegistryInterface, config RoutingConfig) error {
+	if _, err := registry.Lookup(config.DefaultProcessor); err != nil {
+		return fmt.Errorf("default route: %w", err)
+	}
+	for _, rule := range config.Rules {
+		if _, err := registry.Lookup(rule.Processor); err != nil {
+			return fmt.Errorf("routing rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *RoutingProcessor) ProcessPayment(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	name, processor, err := r.route(request, PaymentOperationCharge)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	result, err := processor.ProcessPayment(ctx, request)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	r.remember(result.TransactionID, name)
+	result.Processor = name
+	return result, nil
+}
+
+func (r *RoutingProcessor) Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error) {
+	name, processor, err := r.route(request, PaymentOperationAuthorization)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	result, err := processor.Authorize(ctx, request)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	r.remember(result.TransactionID, name)
+	result.Processor = name
+	return result, nil
+}
+
+// QuoteFee quotes from the processor the payment would be routed to, without
+// recording a routing decision.
+func (r *RoutingProcessor) QuoteFee(ctx context.Context, request PaymentRequest) (FeeQuote, error) {
+	name, _ := r.chooseProcessor(request)
+	processor, err := r.registry.Lookup(name)
+	if err != nil {
+		return FeeQuote{}, fmt.Errorf("route payment: %w", err)
+	}
+	return QuoteFee(ctx, processor, request)
+}
+
+func (r *RoutingProcessor) route(request PaymentRequest, operation PaymentOperation) (string, PaymentProcessorInterface, error) {
+	name, reason := r.chooseProcessor(request)
+	processor, err := r.registry.Lookup(name)
+	if err != nil {
+		err = fmt.Errorf("route payment: %w", err)
+	}
+	r.recordDecision(request, operation, name, reason, err)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, processor, nil
+}
+
+func (r *RoutingProcessor) chooseProcessor(request PaymentRequest) (string, string) {
+	if request.PreferredProcessor != "" {
+		return request.PreferredProcessor, "preferred by the order"
+	}
+	for _, rule := range r.rules {
+		if rule.matches(request) {
+			return rule.Processor, fmt.Sprintf("rule %q matched", rule.Name)
+		}
+	}
+	return r.defaultProcessor, "no rule matched, using the default"
+}
+
+func (r *RoutingProcessor) recordDecision(request PaymentRequest, operation PaymentOperation, name string, reason string, err error) {
+	r.decisions.Record(RoutingDecision{
+		OrderID:   request.OrderID,
+		Customer:  request.Customer,
+		Operation: operation,
+		Amount:    request.Amount,
+		Processor: name,
+		Reason:    reason,
+		Err:       err,
+		Timestamp: r.clock.Now().UTC(),
+	})
+}
+
+func (rule RoutingRule) matches(request PaymentRequest) bool {
+	return rule.matchesCustomerType(request.CustomerType) &&
+		rule.matchesCurrency(request.Amount.Currency()) &&
+		rule.matchesAmount(request.Amount)
+}
+
+func (rule RoutingRule) matchesCustomerType(customerType string) bool {
+	return len(rule.CustomerTypes) == 0 || containsString(rule.CustomerTypes, customerType)
+}
+
+func (rule RoutingRule) matchesCurrency(currency Currency) bool {
+	if len(rule.Currencies) == 0 {
+		return true
+	}
+	for _, candidate := range rule.Currencies {
+		if candidate == currency {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule RoutingRule) matchesAmount(amount Money) bool {
+	if rule.MinAmount != nil {
+		comparison, err := amount.Compare(*rule.MinAmount)
+		if err != nil || comparison < 0 {
+			return false
+		}
+	}
+	if rule.MaxAmount != nil {
+		comparison, err := amount.Compare(*rule.MaxAmount)
+		if err != nil || comparison >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// =============================================================================
+// ROUTING DECISION LOG
+// =============================================================================
+
+type RoutingDecisionLogInterface interface {
+	Record(decision RoutingDecision)
+	Decisions() []RoutingDecision
+}
+
+// DefaultRoutingDecisionLogSize is how many decisions an in-memory log keeps.
+const DefaultRoutingDecisionLogSize = 10000
+
+// InMemoryRoutingDecisionLog keeps the most recent decisions; the oldest go
+// once it holds limit of them. Once full, next is where the oldest sits and
+// the next decision overwrites it.
+type InMemoryRoutingDecisionLog struct {
+	mu        sync.Mutex
+	limit     int
+	decisions []RoutingDecision
+	next      int
+}
+
+func NewInMemoryRoutingDecisionLog() RoutingDecisionLogInterface {
+	return NewInMemoryRoutingDecisionLogWithLimit(DefaultRoutingDecisionLogSize)
+}
+
+func NewInMemoryRoutingDecisionLogWithLimit(limit int) RoutingDecisionLogInterface {
+	if limit < 1 {
+		limit = DefaultRoutingDecisionLogSize
+	}
+	return &InMemoryRoutingDecisionLog{limit: limit}
+}
+
+func (l *InMemoryRoutingDecisionLog) Record(decision RoutingDecision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.decisions) < l.limit {
+		l.decisions = append(l.decisions, decision)
+	} else {
+		l.decisions[l.next] = decision
+	}
+	l.next = (l.next + 1) % l.limit
+}
+
+// Decisions lists the kept decisions oldest first.
+func (l *InMemoryRoutingDecisionLog) Decisions() []RoutingDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	decisions := make([]RoutingDecision, 0, len(l.decisions))
+	decisions = append(decisions, l.decisions[l.next:]...)
+	return append(decisions, l.decisions[:l.next]...)
+}
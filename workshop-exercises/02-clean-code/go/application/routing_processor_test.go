@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// ROUTING PROCESSOR TESTS
+// Testing: routing_processor.go
+// =============================================================================
+
+type routingFixture struct {
+	router    PaymentProcessorInterface
+	card      *MockPaymentProcessor
+	wallet    *MockPaymentProcessor
+	decisions RoutingDecisionLogInterface
+}
+
+func newRoutingFixture(t *testing.T) routingFixture {
+	t.Helper()
+	fixture := routingFixture{
+		card:      NewMockPaymentProcessor(false),
+		wallet:    NewMockPaymentProcessor(false),
+		decisions: NewInMemoryRoutingDecisionLog(),
+	}
+	registry := NewProcessorRegistry()
+	_ = registry.Register("card", fixture.card)
+	_ = registry.Register("wallet", fixture.wallet)
+	largeOrder := MustParseMoney("1000.00", USD)
+	router, err := NewRoutingProcessor(registry, RoutingConfig{
+		DefaultProcessor: "card",
+		Rules: []RoutingRule{
+			{Name: "distributors", Processor: "wallet", CustomerTypes: []string{"distributor"}},
+			{Name: "euro", Processor: "wallet", Currencies: []Currency{EUR}},
+			{Name: "large", Processor: "wallet", MinAmount: &largeOrder},
+		},
+		DecisionLog: fixture.decisions,
+		Clock:       NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatalf("Expected valid routing config, got %v", err)
+	}
+	fixture.router = router
+	return fixture
+}
+
+func TestRoutingProcessor_ProcessPayment_RoutesByRule(t *testing.T) {
+	testCases := []struct {
+		name      string
+		request   PaymentRequest
+		processor string
+		reason    string
+	}{
+		{"customer type", PaymentRequest{Amount: MustParseMoney("10.00", USD), CustomerType: "distributor"}, "wallet", `rule "distributors" matched`},
+		{"currency", PaymentRequest{Amount: MustParseMoney("10.00", EUR), CustomerType: "regular"}, "wallet", `rule "euro" matched`},
+		{"amount", PaymentRequest{Amount: MustParseMoney("1000.00", USD), CustomerType: "regular"}, "wallet", `rule "large" matched`},
+		{"default", PaymentRequest{Amount: MustParseMoney("999.99", USD), CustomerType: "regular"}, "card", "no rule matched, using the default"},
+		{"preference", PaymentRequest{Amount: MustParseMoney("10.00", EUR), PreferredProcessor: "card"}, "card", "preferred by the order"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			fixture := newRoutingFixture(t)
+
+			// Act
+			result, err := fixture.router.ProcessPayment(context.Background(), tc.request)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Processor != tc.processor {
+				t.Errorf("Expected the result to name %s, got %q", tc.processor, result.Processor)
+			}
+			decisions := fixture.decisions.Decisions()
+			if len(decisions) != 1 || decisions[0].Processor != tc.processor || decisions[0].Reason != tc.reason {
+				t.Errorf("Expected %s (%s), got %+v", tc.processor, tc.reason, decisions)
+			}
+			if fixture.card.callCount()+fixture.wallet.callCount() != 1 {
+				t.Errorf("Expected exactly one processor to be charged")
+			}
+		})
+	}
+}
+
+func TestRoutingProcessor_ProcessPayment_RecordsDecisionDetails(t *testing.T) {
+	// Arrange
+	fixture := newRoutingFixture(t)
+	request := PaymentRequest{Amount: MustParseMoney("10.00", EUR), OrderID: "order_000001", Customer: "a@example.com"}
+
+	// Act
+	_, _ = fixture.router.Authorize(context.Background(), request)
+
+	// Assert
+	decision := fixture.decisions.Decisions()[0]
+	if decision.OrderID != "order_000001" || decision.Customer != "a@example.com" || decision.Amount != request.Amount {
+		t.Errorf("Expected the order details on the decision, got %+v", decision)
+	}
+	if decision.Operation != PaymentOperationAuthorization || decision.Timestamp.IsZero() {
+		t.Errorf("Expected a timestamped authorization decision, got %+v", decision)
+	}
+}
+
+func TestRoutingProcessor_ProcessPayment_UnknownPreference_ReturnsError(t *testing.T) {
+	// Arrange
+	fixture := newRoutingFixture(t)
+
+	// Act
+	_, err := fixture.router.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("10.00", USD), PreferredProcessor: "stripe"})
+
+	// Assert
+	if !errors.Is(err, ErrProcessorNotFound) {
+		t.Errorf("Expected ErrProcessorNotFound, got %v", err)
+	}
+	decisions := fixture.decisions.Decisions()
+	if len(decisions) != 1 || decisions[0].Processor != "stripe" || !errors.Is(decisions[0].Err, ErrProcessorNotFound) {
+		t.Errorf("Expected the failed decision recorded with its error, got %+v", decisions)
+	}
+}
+
+func TestRoutingProcessor_Refund_GoesToOriginalProcessor(t *testing.T) {
+	// Arrange
+	registry := NewDefaultProcessorRegistry()
+	router, _ := NewRoutingProcessor(registry, RoutingConfig{DefaultProcessor: "credit_card"})
+	payment, _ := router.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("100.00", USD), PreferredProcessor: "paypal"})
+
+	// Act
+	refund, err := router.Refund(context.Background(), RefundRequest{TransactionID: payment.TransactionID, Amount: MustParseMoney("10.00", USD)})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if refund.ProcessorType != ProcessorTypePayPal {
+		t.Errorf("Expected the refund to go to PayPal, got %s", refund.ProcessorType)
+	}
+}
+
+func TestRoutingProcessor_Refund_FullRefund_ForgetsRoute(t *testing.T) {
+	// Arrange
+	registry := NewDefaultProcessorRegistry()
+	router, _ := NewRoutingProcessor(registry, RoutingConfig{DefaultProcessor: "credit_card"})
+	payment, _ := router.ProcessPayment(context.Background(), PaymentRequest{Amount: MustParseMoney("100.00", USD)})
+	_, _ = router.Refund(context.Background(), RefundRequest{TransactionID: payment.TransactionID, Amount: MustParseMoney("40.00", USD)})
+
+	// Act
+	rest, _ := payment.GrossAmount.Subtract(MustParseMoney("40.00", USD))
+	_, partialErr := router.Refund(context.Background(), RefundRequest{TransactionID: payment.TransactionID, Amount: rest})
+	_, afterErr := router.Refund(context.Background(), RefundRequest{TransactionID: payment.TransactionID, Amount: MustParseMoney("1.00", USD)})
+
+	// Assert
+	if partialErr != nil {
+		t.Fatalf("Expected the route kept after a partial refund, got %v", partialErr)
+	}
+	if !errors.Is(afterErr, ErrTransactionNotFound) {
+		t.Errorf("Expected the route forgotten after a full refund, got %v", afterErr)
+	}
+}
+
+func TestInMemoryRoutingDecisionLog_Record_KeepsMostRecent(t *testing.T) {
+	// Arrange
+	log := NewInMemoryRoutingDecisionLogWithLimit(2)
+
+	// Act
+	for _, orderID := range []string{"order_1", "order_2", "order_3"} {
+		log.Record(RoutingDecision{OrderID: orderID})
+	}
+
+	// Assert
+	decisions := log.Decisions()
+	if len(decisions) != 2 || decisions[0].OrderID != "order_2" || decisions[1].OrderID != "order_3" {
+		t.Errorf("Expected the two most recent decisions, got %+v", decisions)
+	}
+}
+
+func TestInMemoryRoutingDecisionLog_Record_WrapsAroundOldestFirst(t *testing.T) {
+	// Arrange
+	log := NewInMemoryRoutingDecisionLogWithLimit(3)
+
+	// Act
+	for i := 1; i <= 8; i++ {
+		log.Record(RoutingDecision{OrderID: fmt.Sprintf("order_%d", i)})
+	}
+
+	// Assert
+	decisions := log.Decisions()
+	if len(decisions) != 3 || decisions[0].OrderID != "order_6" || decisions[1].OrderID != "order_7" || decisions[2].OrderID != "order_8" {
+		t.Errorf("Expected the three most recent decisions oldest first, got %+v", decisions)
+	}
+}
+
+func TestRoutingProcessor_Capture_UnknownAuthorization_ReturnsError(t *testing.T) {
+	// Arrange
+	fixture := newRoutingFixture(t)
+
+	// Act
+	_, err := fixture.router.Capture(context.Background(), "auth_unknown", MustParseMoney("10.00", USD))
+
+	// Assert
+	if !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Expected ErrTransactionNotFound, got %v", err)
+	}
+}
+
+func TestNewRoutingProcessor_RuleForUnregisteredProcessor_ReturnsError(t *testing.T) {
+	// Arrange
+	registry := NewProcessorRegistry()
+	_ = registry.Register("card", NewMockPaymentProcessor(false))
+
+	// Act
+	_, err := NewRoutingProcessor(registry, RoutingConfig{
+		DefaultProcessor: "card",
+		Rules:            []RoutingRule{{Name: "euro", Processor: "wallet", Currencies: []Currency{EUR}}},
+	})
+
+	// Assert
+	if !errors.Is(err, ErrProcessorNotFound) {
+		t.Errorf("Expected ErrProcessorNotFound, got %v", err)
+	}
+}
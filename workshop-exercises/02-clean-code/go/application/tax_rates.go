@@ -0,0 +1,153 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// =============================================================================
+// TAX RATE TABLE
+// VAT and sales tax rates per country or region and product tax category,
+// loaded from a local JSON file
+// =============================================================================
+
+var (
+	ErrInvalidTaxRateTable = errors.New("invalid tax rate table")
+	ErrNoTaxJurisdiction   = errors.New("no tax rates for jurisdiction")
+)
+
+// TaxJurisdiction holds the rates for a country, or for one region of it
+// when Region is set. ReverseCharge marks VAT systems where a business
+// customer from another country accounts for the tax itself.
+type TaxJurisdiction struct {
+	Country       string                `json:"country"`
+	Region        string                `json:"region,omitempty"`
+	TaxName       string                `json:"taxName"`
+	ReverseCharge bool                  `json:"reverseCharge,omitempty"`
+	Rates         map[string]Percentage `json:"rates"`
+}
+
+// TaxRateTable is the layout of the rate file. SellerCountry is where the
+// merchant is registered; reverse charge only applies across borders.
+type TaxRateTable struct {
+	SellerCountry string            `json:"sellerCountry"`
+	Jurisdictions []TaxJurisdiction `json:"jurisdictions"`
+}
+
+func LoadTaxRateTable(path string) (TaxRateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TaxRateTable{}, fmt.Errorf("read tax rates: %w", err)
+	}
+	table, err := ParseTaxRateTable(data)
+	if err != nil {
+		return TaxRateTable{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return table, nil
+}
+
+func ParseTaxRateTable(data []byte) (TaxRateTable, error) {
+	var table TaxRateTable
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&table); err != nil {
+		return TaxRateTable{}, fmt.Errorf("decode tax rates: %w", err)
+	}
+	if err := table.Validate(); err != nil {
+		return TaxRateTable{}, err
+	}
+	return table, nil
+}
+
+func (t TaxRateTable) Validate() error {
+	if !isCountryCode(t.SellerCountry) {
+		return fmt.Errorf("%w: seller country %q is not a two-letter code", ErrInvalidTaxRateTable, t.SellerCountry)
+	}
+	seen := make(map[string]bool, len(t.Jurisdictions))
+	for _, jurisdiction := range t.Jurisdictions {
+		if err := jurisdiction.validate(); err != nil {
+			return err
+		}
+		key := jurisdiction.key()
+		if seen[key] {
+			return fmt.Errorf("%w: duplicate jurisdiction %s", ErrInvalidTaxRateTable, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (j TaxJurisdiction) validate() error {
+	if !isCountryCode(j.Country) {
+		return fmt.Errorf("%w: country %q is not a two-letter code", ErrInvalidTaxRateTable, j.Country)
+	}
+	if j.TaxName == "" {
+		return fmt.Errorf("%w: %s has no tax name", ErrInvalidTaxRateTable, j.key())
+	}
+	if _, ok := j.Rates[DefaultTaxCategory]; !ok {
+		return fmt.Errorf("%w: %s has no %q rate", ErrInvalidTaxRateTable, j.key(), DefaultTaxCategory)
+	}
+	for category, rate := range j.Rates {
+		if rate < 0 || rate > NewPercentageFromFloat(100) {
+			return fmt.Errorf("%w: %s %s rate %s out of range", ErrInvalidTaxRateTable, j.key(), category, rate)
+		}
+	}
+	return nil
+}
+
+func (j TaxJurisdiction) key() string {
+	if j.Region == "" {
+		return j.Country
+	}
+	return j.Country + "-" + j.Region
+}
+
+// rateFor falls back to the standard rate for categories the jurisdiction
+// does not list, which is what applies unless a reduced rate is granted.
+func (j TaxJurisdiction) rateFor(category string) (string, Percentage) {
+	if category == "" {
+		category = DefaultTaxCategory
+	}
+	if rate, ok := j.Rates[category]; ok {
+		return category, rate
+	}
+	return category, j.Rates[DefaultTaxCategory]
+}
+
+// lookup prefers the region's own rates and falls back to the country's.
+func (t TaxRateTable) lookup(country string, region string) (TaxJurisdiction, error) {
+	country = strings.ToUpper(country)
+	region = strings.ToUpper(region)
+	var countryWide *TaxJurisdiction
+	for i, jurisdiction := range t.Jurisdictions {
+		if jurisdiction.Country != country {
+			continue
+		}
+		if region != "" && jurisdiction.Region == region {
+			return jurisdiction, nil
+		}
+		if jurisdiction.Region == "" {
+			countryWide = &t.Jurisdictions[i]
+		}
+	}
+	if countryWide == nil {
+		return TaxJurisdiction{}, fmt.Errorf("%w: %s", ErrNoTaxJurisdiction, strings.Trim(country+"-"+region, "-"))
+	}
+	return *countryWide, nil
+}
+
+func isCountryCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// =============================================================================
+// TAX RATE TABLE TESTS
+// Testing: tax_rates.go
+// =============================================================================
+
+const testTaxRatesJSON = `{
+  "sellerCountry": "DE",
+  "jurisdictions": [
+    { "country": "DE", "taxName": "VAT", "reverseCharge": true, "rates": { "standard": "19", "reduced": "7" } },
+    { "country": "FR", "taxName": "VAT", "reverseCharge": true, "rates": { "standard": "20", "reduced": "5.5" } },
+    { "country": "US", "taxName": "Sales tax", "rates": { "standard": 0 } },
+    { "country": "US", "region": "CA", "taxName": "Sales tax", "rates": { "standard": "7.25" } }
+  ]
+}`
+
+func newTestTaxRateTable(t *testing.T) TaxRateTable {
+	t.Helper()
+	table, err := ParseTaxRateTable([]byte(testTaxRatesJSON))
+	if err != nil {
+		t.Fatalf("Expected valid tax rates, got %v", err)
+	}
+	return table
+}
+
+func TestParseTaxRateTable_ValidTable_ParsesRates(t *testing.T) {
+	// Act
+	table := newTestTaxRateTable(t)
+
+	// Assert
+	if table.SellerCountry != "DE" || len(table.Jurisdictions) != 4 {
+		t.Fatalf("Expected 4 jurisdictions for seller DE, got %+v", table)
+	}
+	if table.Jurisdictions[1].Rates["reduced"] != NewPercentageFromFloat(5.5) {
+		t.Errorf("Expected FR reduced rate 5.5%%, got %s", table.Jurisdictions[1].Rates["reduced"])
+	}
+}
+
+func TestParseTaxRateTable_InvalidTables_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+	}{
+		{"missing seller country", `{"jurisdictions": []}`},
+		{"lower-case country", `{"sellerCountry": "DE", "jurisdictions": [{"country": "de", "taxName": "VAT", "rates": {"standard": "19"}}]}`},
+		{"no tax name", `{"sellerCountry": "DE", "jurisdictions": [{"country": "DE", "rates": {"standard": "19"}}]}`},
+		{"no standard rate", `{"sellerCountry": "DE", "jurisdictions": [{"country": "DE", "taxName": "VAT", "rates": {"reduced": "7"}}]}`},
+		{"rate above 100%", `{"sellerCountry": "DE", "jurisdictions": [{"country": "DE", "taxName": "VAT", "rates": {"standard": "119"}}]}`},
+		{"duplicate jurisdiction", `{"sellerCountry": "DE", "jurisdictions": [{"country": "DE", "taxName": "VAT", "rates": {"standard": "19"}}, {"country": "DE", "taxName": "VAT", "rates": {"standard": "7"}}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := ParseTaxRateTable([]byte(tc.json))
+
+			// Assert
+			if !errors.Is(err, ErrInvalidTaxRateTable) {
+				t.Errorf("Expected ErrInvalidTaxRateTable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseTaxRateTable_UnknownField_ReturnsError(t *testing.T) {
+	// Act
+	_, err := ParseTaxRateTable([]byte(`{"sellerCountry": "DE", "jurisdictions": [], "vat": 19}`))
+
+	// Assert
+	if err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+}
+
+func TestTaxRateTable_Lookup_RegionFallsBackToCountry(t *testing.T) {
+	testCases := []struct {
+		country  string
+		region   string
+		expected string
+	}{
+		{"US", "CA", "US-CA"},
+		{"us", "ca", "US-CA"},
+		{"US", "OR", "US"},
+		{"DE", "", "DE"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.country+"-"+tc.region, func(t *testing.T) {
+			// Arrange
+			table := newTestTaxRateTable(t)
+
+			// Act
+			jurisdiction, err := table.lookup(tc.country, tc.region)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if jurisdiction.key() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, jurisdiction.key())
+			}
+		})
+	}
+}
+
+func TestTaxRateTable_Lookup_UnknownCountry_ReturnsError(t *testing.T) {
+	// Arrange
+	table := newTestTaxRateTable(t)
+
+	// Act
+	_, err := table.lookup("JP", "")
+
+	// Assert
+	if !errors.Is(err, ErrNoTaxJurisdiction) {
+		t.Errorf("Expected ErrNoTaxJurisdiction, got %v", err)
+	}
+}
+
+func TestTaxJurisdiction_RateFor_UnknownCategory_UsesStandardRate(t *testing.T) {
+	// Arrange
+	table := newTestTaxRateTable(t)
+
+	// Act
+	category, rate := table.Jurisdictions[0].rateFor("luxury")
+
+	// Assert
+	if category != "luxury" || rate != NewPercentageFromFloat(19) {
+		t.Errorf("Expected luxury at the 19%% standard rate, got %s at %s", category, rate)
+	}
+}
+
+func TestLoadTaxRateTable_ExampleFile_IsValid(t *testing.T) {
+	// Act
+	table, err := LoadTaxRateTable(filepath.Join("..", "tax_rates.example.json"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected example tax rates to load, got %v", err)
+	}
+	if len(table.Jurisdictions) == 0 {
+		t.Error("Expected example jurisdictions")
+	}
+}
@@ -0,0 +1,47 @@
+package application
+
+// =============================================================================
+// TAX RESULT
+// What TaxService is asked to tax and the per-line result it hands back
+// =============================================================================
+
+const DefaultTaxCategory = "standard"
+
+// TaxRequest describes where the customer is and what is being sold. Line
+// amounts are after discounts. Without lines, Amount is taxed as a single
+// standard-category line.
+type TaxRequest struct {
+	Country          string
+	Region           string
+	VATID            string
+	PricesIncludeTax bool
+	Amount           Money
+	Lines            []TaxableLine
+}
+
+type TaxableLine struct {
+	ProductID   string
+	TaxCategory string
+	Amount      Money
+}
+
+type LineTax struct {
+	ProductID   string
+	TaxCategory string
+	Rate        Percentage
+	NetAmount   Money
+	TaxAmount   Money
+	GrossAmount Money
+}
+
+// TaxResult splits the order into net, tax and gross. GrossAmount is what the
+// customer pays; under reverse charge it equals NetAmount.
+type TaxResult struct {
+	TaxName       string
+	Jurisdiction  string
+	ReverseCharge bool
+	Lines         []LineTax
+	NetAmount     Money
+	TaxAmount     Money
+	GrossAmount   Money
+}
@@ -0,0 +1,137 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// TAX SERVICE
+// VAT and sales tax from the rate table; see tax_rates.go for the file format
+// =============================================================================
+
+var ErrInvalidVATID = errors.New("invalid VAT ID")
+
+type TaxService struct {
+	table TaxRateTable
+}
+
+func NewTaxService(table TaxRateTable) (TaxServiceInterface, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	return &TaxService{table: table}, nil
+}
+
+func NewTaxServiceFromFile(path string) (TaxServiceInterface, error) {
+	table, err := LoadTaxRateTable(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewTaxService(table)
+}
+
+func (t *TaxService) CalculateTax(ctx context.Context, request TaxRequest) (TaxResult, error) {
+	if err := ctx.Err(); err != nil {
+		return TaxResult{}, err
+	}
+	jurisdiction, err := t.table.lookup(request.Country, request.Region)
+	if err != nil {
+		return TaxResult{}, err
+	}
+	reverseCharge, err := t.appliesReverseCharge(jurisdiction, request)
+	if err != nil {
+		return TaxResult{}, err
+	}
+	return t.buildTaxResult(jurisdiction, reverseCharge, t.taxLines(jurisdiction, reverseCharge, request)), nil
+}
+
+// Reverse charge applies to business customers with a VAT ID in another
+// country of a reverse-charge system; at home the seller charges VAT as usual.
+func (t *TaxService) appliesReverseCharge(jurisdiction TaxJurisdiction, request TaxRequest) (bool, error) {
+	if request.VATID == "" || !jurisdiction.ReverseCharge {
+		return false, nil
+	}
+	if err := validateVATID(request.VATID, jurisdiction.Country); err != nil {
+		return false, err
+	}
+	return jurisdiction.Country != t.table.SellerCountry, nil
+}
+
+func (t *TaxService) taxLines(jurisdiction TaxJurisdiction, reverseCharge bool, request TaxRequest) []LineTax {
+	lines := request.Lines
+	if len(lines) == 0 {
+		lines = []TaxableLine{{TaxCategory: DefaultTaxCategory, Amount: request.Amount}}
+	}
+	taxed := make([]LineTax, 0, len(lines))
+	for _, line := range lines {
+		category, rate := jurisdiction.rateFor(line.TaxCategory)
+		if reverseCharge {
+			rate = 0
+		}
+		taxed = append(taxed, t.taxLine(line, category, rate, request.PricesIncludeTax))
+	}
+	return taxed
+}
+
+// With tax-inclusive prices the tax is taken out of the price; under reverse
+// charge that leaves the customer paying the net price only. Rates are
+// checked to be at most 100% when loaded, so neither share overflows.
+func (t *TaxService) taxLine(line TaxableLine, category string, rate Percentage, pricesIncludeTax bool) LineTax {
+	result := LineTax{ProductID: line.ProductID, TaxCategory: category, Rate: rate}
+	if pricesIncludeTax {
+		base := int64(100) * pow10(percentageScale)
+		result.NetAmount, _ = line.Amount.ScaleByRatio(base, base+int64(rate))
+		result.TaxAmount, _ = line.Amount.Subtract(result.NetAmount)
+	} else {
+		result.NetAmount = line.Amount
+		result.TaxAmount, _ = line.Amount.ApplyPercentage(rate)
+	}
+	if rate == 0 {
+		result.TaxAmount = ZeroMoney(line.Amount.Currency())
+	}
+	result.GrossAmount, _ = result.NetAmount.Add(result.TaxAmount)
+	return result
+}
+
+func (t *TaxService) buildTaxResult(jurisdiction TaxJurisdiction, reverseCharge bool, lines []LineTax) TaxResult {
+	currency := lines[0].NetAmount.Currency()
+	result := TaxResult{
+		TaxName:       jurisdiction.TaxName,
+		Jurisdiction:  jurisdiction.key(),
+		ReverseCharge: reverseCharge,
+		Lines:         lines,
+		NetAmount:     ZeroMoney(currency),
+		TaxAmount:     ZeroMoney(currency),
+		GrossAmount:   ZeroMoney(currency),
+	}
+	for _, line := range lines {
+		result.NetAmount, _ = result.NetAmount.Add(line.NetAmount)
+		result.TaxAmount, _ = result.TaxAmount.Add(line.TaxAmount)
+		result.GrossAmount, _ = result.GrossAmount.Add(line.GrossAmount)
+	}
+	return result
+}
+
+// validateVATID checks the shape of an EU-style VAT ID: the country prefix
+// (EL for Greece) followed by 2 to 12 letters or digits. It does not ask the
+// tax authority whether the number is registered.
+func validateVATID(vatID string, country string) error {
+	normalized := strings.ToUpper(strings.ReplaceAll(vatID, " ", ""))
+	prefix := country
+	if country == "GR" {
+		prefix = "EL"
+	}
+	number, found := strings.CutPrefix(normalized, prefix)
+	if !found || len(number) < 2 || len(number) > 12 {
+		return fmt.Errorf("%w: %q for %s", ErrInvalidVATID, vatID, country)
+	}
+	for _, r := range number {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'Z') {
+			return fmt.Errorf("%w: %q for %s", ErrInvalidVATID, vatID, country)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// =============================================================================
+// TAX SERVICE TESTS
+// Testing: tax_service.go
+// =============================================================================
+
+func newTestTaxService(t *testing.T) TaxServiceInterface {
+	t.Helper()
+	taxService, err := NewTaxService(newTestTaxRateTable(t))
+	if err != nil {
+		t.Fatalf("Expected valid tax service, got %v", err)
+	}
+	return taxService
+}
+
+func TestTaxService_CalculateTax_ExclusivePrices_AddsTaxPerLine(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{
+		Country: "DE",
+		Lines: []TaxableLine{
+			{ProductID: "SKU-BOOK", TaxCategory: "reduced", Amount: MustParseMoney("20.00", EUR)},
+			{ProductID: "SKU-MUG", Amount: MustParseMoney("10.00", EUR)},
+		},
+	}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Lines[0].TaxAmount != MustParseMoney("1.40", EUR) || result.Lines[1].TaxAmount != MustParseMoney("1.90", EUR) {
+		t.Errorf("Expected 7%% and 19%% line tax, got %+v", result.Lines)
+	}
+	if result.Lines[1].TaxCategory != DefaultTaxCategory {
+		t.Errorf("Expected an uncategorised line to be standard, got %s", result.Lines[1].TaxCategory)
+	}
+	if result.NetAmount != MustParseMoney("30.00", EUR) || result.TaxAmount != MustParseMoney("3.30", EUR) || result.GrossAmount != MustParseMoney("33.30", EUR) {
+		t.Errorf("Expected 30.00 + 3.30 = 33.30, got %s + %s = %s", result.NetAmount, result.TaxAmount, result.GrossAmount)
+	}
+	if result.TaxName != "VAT" || result.Jurisdiction != "DE" {
+		t.Errorf("Expected German VAT, got %s %s", result.Jurisdiction, result.TaxName)
+	}
+}
+
+func TestTaxService_CalculateTax_InclusivePrices_ExtractsTax(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "DE", PricesIncludeTax: true, Amount: MustParseMoney("119.00", EUR)}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.NetAmount != MustParseMoney("100.00", EUR) || result.TaxAmount != MustParseMoney("19.00", EUR) {
+		t.Errorf("Expected 100.00 net and 19.00 VAT, got %s and %s", result.NetAmount, result.TaxAmount)
+	}
+	if result.GrossAmount != request.Amount {
+		t.Errorf("Expected the customer to pay the shelf price %s, got %s", request.Amount, result.GrossAmount)
+	}
+}
+
+func TestTaxService_CalculateTax_ForeignVATID_AppliesReverseCharge(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "FR", VATID: "FR 12345678901", Amount: MustParseMoney("100.00", EUR)}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.ReverseCharge || !result.TaxAmount.IsZero() || result.GrossAmount != request.Amount {
+		t.Errorf("Expected reverse charge without tax, got %+v", result)
+	}
+}
+
+func TestTaxService_CalculateTax_ReverseChargeInclusivePrices_ChargesNetOnly(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "FR", VATID: "FR12345678901", PricesIncludeTax: true, Amount: MustParseMoney("120.00", EUR)}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.GrossAmount != request.Amount || !result.TaxAmount.IsZero() {
+		t.Errorf("Expected a zero-rated %s, got %+v", request.Amount, result)
+	}
+}
+
+func TestTaxService_CalculateTax_DomesticVATID_ChargesVAT(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "DE", VATID: "DE123456789", Amount: MustParseMoney("100.00", EUR)}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.ReverseCharge || result.TaxAmount != MustParseMoney("19.00", EUR) {
+		t.Errorf("Expected domestic VAT of 19.00, got %+v", result)
+	}
+}
+
+func TestTaxService_CalculateTax_MalformedVATID_ReturnsError(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "FR", VATID: "DE123456789", Amount: MustParseMoney("100.00", EUR)}
+
+	// Act
+	_, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if !errors.Is(err, ErrInvalidVATID) {
+		t.Errorf("Expected ErrInvalidVATID, got %v", err)
+	}
+}
+
+func TestTaxService_CalculateTax_SalesTaxRegion_UsesRegionRate(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+	request := TaxRequest{Country: "US", Region: "CA", VATID: "ignored", Amount: MustParseMoney("100.00", USD)}
+
+	// Act
+	result, err := taxService.CalculateTax(context.Background(), request)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.TaxAmount != MustParseMoney("7.25", USD) || result.Jurisdiction != "US-CA" {
+		t.Errorf("Expected 7.25 California sales tax, got %s for %s", result.TaxAmount, result.Jurisdiction)
+	}
+}
+
+func TestTaxService_CalculateTax_UnknownCountry_ReturnsError(t *testing.T) {
+	// Arrange
+	taxService := newTestTaxService(t)
+
+	// Act
+	_, err := taxService.CalculateTax(context.Background(), TaxRequest{Country: "JP", Amount: MustParseMoney("1000", JPY)})
+
+	// Assert
+	if !errors.Is(err, ErrNoTaxJurisdiction) {
+		t.Errorf("Expected ErrNoTaxJurisdiction, got %v", err)
+	}
+}
+
+func TestValidateVATID_Formats(t *testing.T) {
+	testCases := []struct {
+		vatID   string
+		country string
+		valid   bool
+	}{
+		{"FR12345678901", "FR", true},
+		{"fr 1234 5678 901", "FR", true},
+		{"EL123456789", "GR", true},
+		{"GR123456789", "GR", false},
+		{"FR1", "FR", false},
+		{"FR12-345", "FR", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.vatID, func(t *testing.T) {
+			// Act
+			err := validateVATID(tc.vatID, tc.country)
+
+			// Assert
+			if (err == nil) != tc.valid {
+				t.Errorf("Expected valid=%v, got %v", tc.valid, err)
+			}
+		})
+	}
+}
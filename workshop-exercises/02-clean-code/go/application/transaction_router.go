@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// =============================================================================
+// TRANSACTION ROUTER
+// Remembers which registered processor handled each transaction so that
+// composites can send refunds, captures and voids back to it. A route is
+// forgotten once the transaction is fully refunded, voided or released.
+// =============================================================================
+
+type transactionRouter struct {
+	registry ProcessorRegistryInterface
+
+	mu     sync.Mutex
+	routes map[string]string
+}
+
+func newTransactionRouter(registry ProcessorRegistryInterface) *transactionRouter {
+	return &transactionRouter{
+		registry: registry,
+		routes:   make(map[string]string),
+	}
+}
+
+func (t *transactionRouter) remember(transactionID string, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes[transactionID] = name
+}
+
+func (t *transactionRouter) forget(transactionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.routes, transactionID)
+}
+
+// forgetFinished drops the route of a transaction nothing more can be done
+// with.
+func (t *transactionRouter) forgetFinished(transactionID string, status PaymentStatus) {
+	switch status {
+	case PaymentStatusRefunded, PaymentStatusVoided, PaymentStatusReleased:
+		t.forget(transactionID)
+	}
+}
+
+// processorFor finds the processor that handled transactionID. Routes are
+// kept in memory, so transactions from before a restart are not found.
+func (t *transactionRouter) processorFor(transactionID string) (PaymentProcessorInterface, error) {
+	t.mu.Lock()
+	name, ok := t.routes[transactionID]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: no route recorded for %s", ErrTransactionNotFound, transactionID)
+	}
+	return t.registry.Lookup(name)
+}
+
+func (t *transactionRouter) Refund(ctx context.Context, request RefundRequest) (RefundResult, error) {
+	processor, err := t.processorFor(request.TransactionID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	result, err := processor.Refund(ctx, request)
+	if err == nil {
+		t.forgetFinished(request.TransactionID, result.Status)
+	}
+	return result, err
+}
+
+func (t *transactionRouter) Void(ctx context.Context, transactionID string) (RefundResult, error) {
+	processor, err := t.processorFor(transactionID)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	result, err := processor.Void(ctx, transactionID)
+	if err == nil {
+		t.forgetFinished(transactionID, result.Status)
+	}
+	return result, err
+}
+
+func (t *transactionRouter) Capture(ctx context.Context, authorizationID string, amount Money) (PaymentResult, error) {
+	processor, err := t.processorFor(authorizationID)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	return processor.Capture(ctx, authorizationID, amount)
+}
+
+func (t *transactionRouter) ReleaseAuthorization(ctx context.Context, authorizationID string) (PaymentResult, error) {
+	processor, err := t.processorFor(authorizationID)
+	if err != nil {
+		return PaymentResult{}, err
+	}
+	result, err := processor.ReleaseAuthorization(ctx, authorizationID)
+	if err == nil {
+		t.forgetFinished(authorizationID, result.Status)
+	}
+	return result, err
+}
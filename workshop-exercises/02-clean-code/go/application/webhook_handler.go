@@ -0,0 +1,267 @@
+package application
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// WEBHOOK HANDLER
+// Verifies, deduplicates and applies provider payment events
+// =============================================================================
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrWebhookTimestampStale   = errors.New("webhook timestamp outside tolerance")
+	ErrInvalidWebhookConfig    = errors.New("invalid webhook configuration")
+)
+
+const (
+	WebhookSignatureHeader = "Webhook-Signature"
+	// WebhookAuditActor is who the audit log names for changes a webhook made.
+	WebhookAuditActor       = "webhook"
+	DefaultWebhookTolerance = 5 * time.Minute
+	maxWebhookBodyBytes     = 1 << 20
+)
+
+// WebhookConfig leaves nil stores and clock at in-memory stores and the
+// system clock.
+type WebhookConfig struct {
+	Secret          string
+	Tolerance       time.Duration
+	Clock           Clock
+	ProcessedEvents ProcessedEventStoreInterface
+	DeadLetters     DeadLetterStoreInterface
+}
+
+func DefaultWebhookConfig(secret string) WebhookConfig {
+	return WebhookConfig{
+		Secret:          secret,
+		Tolerance:       DefaultWebhookTolerance,
+		Clock:           NewSystemClock(),
+		ProcessedEvents: NewInMemoryProcessedEventStore(DefaultWebhookDedupeRetention),
+		DeadLetters:     NewInMemoryDeadLetterStore(),
+	}
+}
+
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	defaults := DefaultWebhookConfig(c.Secret)
+	if c.Tolerance <= 0 {
+		c.Tolerance = defaults.Tolerance
+	}
+	if c.Clock == nil {
+		c.Clock = defaults.Clock
+	}
+	if c.ProcessedEvents == nil {
+		c.ProcessedEvents = defaults.ProcessedEvents
+	}
+	if c.DeadLetters == nil {
+		c.DeadLetters = defaults.DeadLetters
+	}
+	return c
+}
+
+// WebhookHandler answers 200 once an event is applied, was already seen, or
+// has been dead-lettered because it can never apply (unknown type, unknown
+// order, or a state the order is not in). Bad signatures get 401, malformed
+// bodies 400, and anything worth redelivering 500.
+type WebhookHandler struct {
+	events PaymentEventHandlerInterface
+	config WebhookConfig
+}
+
+type webhookResponse struct {
+	Status  string `json:"status"`
+	EventID string `json:"eventId,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+const (
+	webhookStatusProcessed    = "processed"
+	webhookStatusDuplicate    = "duplicate"
+	webhookStatusDeadLettered = "dead_lettered"
+)
+
+func NewWebhookHandler(events PaymentEventHandlerInterface, config WebhookConfig) (http.Handler, error) {
+	if config.Secret == "" {
+		return nil, fmt.Errorf("%w: a signing secret is required", ErrInvalidWebhookConfig)
+	}
+	return &WebhookHandler{events: events, config: config.withDefaults()}, nil
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, r.Method+" is not allowed")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes+1))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body) > maxWebhookBodyBytes {
+		h.writeError(w, http.StatusRequestEntityTooLarge, "payload too large")
+		return
+	}
+
+	now := h.config.Clock.Now()
+	if err := VerifyWebhookSignature(h.config.Secret, r.Header.Get(WebhookSignatureHeader), body, now, h.config.Tolerance); err != nil {
+		h.writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	event, err := h.parseEvent(body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.handleEvent(w, r, event, body, now)
+}
+
+func (h *WebhookHandler) parseEvent(body []byte) (PaymentEvent, error) {
+	var event PaymentEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return PaymentEvent{}, fmt.Errorf("%w: %v", ErrInvalidPaymentEvent, err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return PaymentEvent{}, fmt.Errorf("%w: id and type are required", ErrInvalidPaymentEvent)
+	}
+	return event, nil
+}
+
+func (h *WebhookHandler) handleEvent(w http.ResponseWriter, r *http.Request, event PaymentEvent, body []byte, now time.Time) {
+	first, err := h.config.ProcessedEvents.Claim(event.ID, now)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !first {
+		h.writeResult(w, webhookResponse{Status: webhookStatusDuplicate, EventID: event.ID})
+		return
+	}
+
+	_, err = h.events.ApplyPaymentEvent(WithAuditActor(r.Context(), WebhookAuditActor), event)
+	switch {
+	case err == nil:
+		h.writeResult(w, webhookResponse{Status: webhookStatusProcessed, EventID: event.ID})
+	case h.isPermanent(err):
+		h.deadLetter(w, event, body, err, now)
+	default:
+		h.failRedeliverable(w, event, err)
+	}
+}
+
+// isPermanent reports whether redelivering the event could ever succeed.
+func (h *WebhookHandler) isPermanent(err error) bool {
+	return errors.Is(err, ErrUnknownPaymentEventType) ||
+		errors.Is(err, ErrInvalidPaymentEvent) ||
+		errors.Is(err, ErrPaymentEventNotApplicable) ||
+		errors.Is(err, ErrOrderNotFound)
+}
+
+func (h *WebhookHandler) deadLetter(w http.ResponseWriter, event PaymentEvent, body []byte, cause error, now time.Time) {
+	err := h.config.DeadLetters.Add(DeadLetter{
+		EventID:    event.ID,
+		EventType:  event.Type,
+		Payload:    json.RawMessage(body),
+		Reason:     cause.Error(),
+		ReceivedAt: now,
+	})
+	if err != nil {
+		h.failRedeliverable(w, event, fmt.Errorf("dead-lettering failed: %w", err))
+		return
+	}
+	h.writeResult(w, webhookResponse{Status: webhookStatusDeadLettered, EventID: event.ID, Reason: cause.Error()})
+}
+
+// failRedeliverable gives the event ID back so the provider's redelivery is
+// not mistaken for a duplicate.
+func (h *WebhookHandler) failRedeliverable(w http.ResponseWriter, event PaymentEvent, cause error) {
+	if err := h.config.ProcessedEvents.Release(event.ID); err != nil {
+		cause = fmt.Errorf("%w (releasing event ID failed: %w)", cause, err)
+	}
+	h.writeError(w, http.StatusInternalServerError, cause.Error())
+}
+
+func (h *WebhookHandler) writeResult(w http.ResponseWriter, response webhookResponse) {
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func (h *WebhookHandler) writeError(w http.ResponseWriter, status int, reason string) {
+	h.writeJSON(w, status, webhookResponse{Status: "error", Reason: reason})
+}
+
+func (h *WebhookHandler) writeJSON(w http.ResponseWriter, status int, response webhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// =============================================================================
+// SIGNATURES
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">"
+// =============================================================================
+
+// SignWebhookPayload returns the signature header value a provider sends
+// with body at timestamp.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + computeWebhookSignature(secret, unix, body)
+}
+
+// VerifyWebhookSignature accepts the header if any of its v1 signatures
+// matches and its timestamp is within tolerance of now, either way.
+// Several v1 entries let a provider sign with an old and a new secret
+// while they are being rotated.
+func VerifyWebhookSignature(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, signatures, err := parseWebhookSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+	signedAt, _ := strconv.ParseInt(timestamp, 10, 64)
+	if age := now.Sub(time.Unix(signedAt, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed at %s", ErrWebhookTimestampStale, time.Unix(signedAt, 0).UTC().Format(time.RFC3339))
+	}
+
+	expected := []byte(computeWebhookSignature(secret, timestamp, body))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no matching signature", ErrInvalidWebhookSignature)
+}
+
+func parseWebhookSignatureHeader(header string) (string, []string, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil || len(signatures) == 0 {
+		return "", nil, fmt.Errorf("%w: expected %s: t=<timestamp>,v1=<signature>", ErrInvalidWebhookSignature, WebhookSignatureHeader)
+	}
+	return timestamp, signatures, nil
+}
+
+func computeWebhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,323 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// WEBHOOK HANDLER TESTS
+// Testing: webhook_handler.go
+// =============================================================================
+
+const testWebhookSecret = "whsec_test"
+
+type stubPaymentEventHandler struct {
+	err    error
+	events []PaymentEvent
+}
+
+func (s *stubPaymentEventHandler) ApplyPaymentEvent(ctx context.Context, event PaymentEvent) (OrderResult, error) {
+	s.events = append(s.events, event)
+	return OrderResult{}, s.err
+}
+
+type failingDeadLetterStore struct{}
+
+func (failingDeadLetterStore) Add(letter DeadLetter) error { return errors.New("disk full") }
+
+func (failingDeadLetterStore) List() ([]DeadLetter, error) { return nil, nil }
+
+type webhookFixture struct {
+	handler     http.Handler
+	clock       *ManualClock
+	deadLetters DeadLetterStoreInterface
+}
+
+func newWebhookFixture(t *testing.T, events PaymentEventHandlerInterface) webhookFixture {
+	t.Helper()
+	fixture := webhookFixture{
+		clock:       NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)),
+		deadLetters: NewInMemoryDeadLetterStore(),
+	}
+	handler, err := NewWebhookHandler(events, WebhookConfig{
+		Secret:      testWebhookSecret,
+		Clock:       fixture.clock,
+		DeadLetters: fixture.deadLetters,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error creating handler, got %v", err)
+	}
+	fixture.handler = handler
+	return fixture
+}
+
+func (f webhookFixture) deliver(t *testing.T, body string, signature string) (int, webhookResponse) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/webhooks/payments", strings.NewReader(body))
+	if signature != "" {
+		request.Header.Set(WebhookSignatureHeader, signature)
+	}
+	recorder := httptest.NewRecorder()
+	f.handler.ServeHTTP(recorder, request)
+
+	var response webhookResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Expected a JSON response, got %q", recorder.Body.String())
+	}
+	return recorder.Code, response
+}
+
+func (f webhookFixture) deliverSigned(t *testing.T, body string) (int, webhookResponse) {
+	t.Helper()
+	return f.deliver(t, body, SignWebhookPayload(testWebhookSecret, f.clock.Now(), []byte(body)))
+}
+
+func encodeWebhookEvent(t *testing.T, event PaymentEvent) string {
+	t.Helper()
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Expected event to encode, got %v", err)
+	}
+	return string(body)
+}
+
+func TestVerifyWebhookSignature_Cases(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_1"}`)
+	valid := SignWebhookPayload(testWebhookSecret, now, body)
+	validSignature := strings.SplitN(valid, "v1=", 2)[1]
+
+	tests := []struct {
+		name    string
+		header  string
+		body    []byte
+		wantErr error
+	}{
+		{"valid", valid, body, nil},
+		{"rotated secret alongside current", "t=" + strconv.FormatInt(now.Unix(), 10) + ",v1=deadbeef,v1=" + validSignature, body, nil},
+		{"wrong secret", SignWebhookPayload("other", now, body), body, ErrInvalidWebhookSignature},
+		{"tampered body", valid, []byte(`{"id":"evt_2"}`), ErrInvalidWebhookSignature},
+		{"missing header", "", body, ErrInvalidWebhookSignature},
+		{"missing signature", "t=" + strconv.FormatInt(now.Unix(), 10), body, ErrInvalidWebhookSignature},
+		{"too old", SignWebhookPayload(testWebhookSecret, now.Add(-6*time.Minute), body), body, ErrWebhookTimestampStale},
+		{"too far ahead", SignWebhookPayload(testWebhookSecret, now.Add(6*time.Minute), body), body, ErrWebhookTimestampStale},
+		{"within tolerance", SignWebhookPayload(testWebhookSecret, now.Add(-4*time.Minute), body), body, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := VerifyWebhookSignature(testWebhookSecret, tt.header, tt.body, now, DefaultWebhookTolerance)
+
+			// Assert
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewWebhookHandler_NoSecret_ReturnsConfigError(t *testing.T) {
+	// Act
+	_, err := NewWebhookHandler(&stubPaymentEventHandler{}, WebhookConfig{})
+
+	// Assert
+	if !errors.Is(err, ErrInvalidWebhookConfig) {
+		t.Errorf("Expected ErrInvalidWebhookConfig, got %v", err)
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_RejectedRequests(t *testing.T) {
+	body := `{"id":"evt_1","type":"payment.capture.completed","data":{"orderId":"order_1"}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		sign       bool
+		wantStatus int
+	}{
+		{"GET is not allowed", http.MethodGet, body, true, http.StatusMethodNotAllowed},
+		{"unsigned", http.MethodPost, body, false, http.StatusUnauthorized},
+		{"malformed JSON", http.MethodPost, `{"id":`, true, http.StatusBadRequest},
+		{"missing ID", http.MethodPost, `{"type":"dispute.opened"}`, true, http.StatusBadRequest},
+		{"too large", http.MethodPost, strings.Repeat(" ", maxWebhookBodyBytes+1), true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			events := &stubPaymentEventHandler{}
+			fixture := newWebhookFixture(t, events)
+			request := httptest.NewRequest(tt.method, "/webhooks/payments", strings.NewReader(tt.body))
+			if tt.sign {
+				request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(testWebhookSecret, fixture.clock.Now(), []byte(tt.body)))
+			}
+			recorder := httptest.NewRecorder()
+
+			// Act
+			fixture.handler.ServeHTTP(recorder, request)
+
+			// Assert
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if len(events.events) != 0 {
+				t.Errorf("Expected no event applied, got %d", len(events.events))
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_StaleSignature_ReturnsUnauthorized(t *testing.T) {
+	// Arrange: A captured request replayed after the tolerance window
+	events := &stubPaymentEventHandler{}
+	fixture := newWebhookFixture(t, events)
+	body := `{"id":"evt_1","type":"dispute.opened","data":{"orderId":"order_1"}}`
+	signature := SignWebhookPayload(testWebhookSecret, fixture.clock.Now(), []byte(body))
+	fixture.clock.Advance(DefaultWebhookTolerance + time.Second)
+
+	// Act
+	status, response := fixture.deliver(t, body, signature)
+
+	// Assert
+	if status != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", status)
+	}
+	if !strings.Contains(response.Reason, ErrWebhookTimestampStale.Error()) {
+		t.Errorf("Expected a stale timestamp reason, got %q", response.Reason)
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_Redelivery_AppliesEventOnce(t *testing.T) {
+	// Arrange
+	events := &stubPaymentEventHandler{}
+	fixture := newWebhookFixture(t, events)
+	body := `{"id":"evt_1","type":"dispute.opened","data":{"orderId":"order_1"}}`
+
+	// Act
+	firstStatus, first := fixture.deliverSigned(t, body)
+	fixture.clock.Advance(time.Minute)
+	secondStatus, second := fixture.deliverSigned(t, body)
+
+	// Assert
+	if firstStatus != http.StatusOK || first.Status != webhookStatusProcessed {
+		t.Errorf("Expected first delivery processed, got %d %s", firstStatus, first.Status)
+	}
+	if secondStatus != http.StatusOK || second.Status != webhookStatusDuplicate {
+		t.Errorf("Expected redelivery acknowledged as duplicate, got %d %s", secondStatus, second.Status)
+	}
+	if len(events.events) != 1 {
+		t.Errorf("Expected the event applied once, got %d", len(events.events))
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_UnknownEventType_IsDeadLettered(t *testing.T) {
+	// Arrange: The real service rejects the type before touching any order
+	service, _ := newEventOrderService(t)
+	fixture := newWebhookFixture(t, service)
+	body := `{"id":"evt_1","type":"payment.capture.reversed","data":{"orderId":"order_1"}}`
+
+	// Act
+	status, response := fixture.deliverSigned(t, body)
+
+	// Assert
+	if status != http.StatusOK || response.Status != webhookStatusDeadLettered {
+		t.Errorf("Expected dead-lettered with 200, got %d %s", status, response.Status)
+	}
+	letters, _ := fixture.deadLetters.List()
+	if len(letters) != 1 || letters[0].EventType != "payment.capture.reversed" || string(letters[0].Payload) != body {
+		t.Errorf("Expected the raw event dead-lettered, got %+v", letters)
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_EventNotApplicable_IsDeadLettered(t *testing.T) {
+	// Arrange
+	events := &stubPaymentEventHandler{err: ErrPaymentEventNotApplicable}
+	fixture := newWebhookFixture(t, events)
+
+	// Act
+	status, response := fixture.deliverSigned(t, `{"id":"evt_1","type":"dispute.opened","data":{"orderId":"order_1"}}`)
+
+	// Assert
+	if status != http.StatusOK || response.Status != webhookStatusDeadLettered {
+		t.Errorf("Expected dead-lettered with 200, got %d %s", status, response.Status)
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_TransientFailure_AllowsRedelivery(t *testing.T) {
+	// Arrange
+	events := &stubPaymentEventHandler{err: errors.New("order storage failed: disk full")}
+	fixture := newWebhookFixture(t, events)
+	body := `{"id":"evt_1","type":"dispute.opened","data":{"orderId":"order_1"}}`
+
+	// Act
+	firstStatus, _ := fixture.deliverSigned(t, body)
+	events.err = nil
+	secondStatus, second := fixture.deliverSigned(t, body)
+
+	// Assert
+	if firstStatus != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 so the provider retries, got %d", firstStatus)
+	}
+	if secondStatus != http.StatusOK || second.Status != webhookStatusProcessed {
+		t.Errorf("Expected the redelivery processed, got %d %s", secondStatus, second.Status)
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_DeadLetterStoreFails_AllowsRedelivery(t *testing.T) {
+	// Arrange
+	events := &stubPaymentEventHandler{err: ErrPaymentEventNotApplicable}
+	handler, _ := NewWebhookHandler(events, WebhookConfig{Secret: testWebhookSecret, DeadLetters: failingDeadLetterStore{}})
+	body := `{"id":"evt_1","type":"dispute.opened","data":{"orderId":"order_1"}}`
+	deliver := func() int {
+		request := httptest.NewRequest(http.MethodPost, "/webhooks/payments", strings.NewReader(body))
+		request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(testWebhookSecret, time.Now(), []byte(body)))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// Act
+	first := deliver()
+	second := deliver()
+
+	// Assert
+	if first != http.StatusInternalServerError || second != http.StatusInternalServerError {
+		t.Errorf("Expected both deliveries to fail with 500, got %d and %d", first, second)
+	}
+	if len(events.events) != 2 {
+		t.Errorf("Expected the redelivery to be attempted again, got %d attempts", len(events.events))
+	}
+}
+
+func TestWebhookHandler_ServeHTTP_ProviderRefund_UpdatesOrder(t *testing.T) {
+	// Arrange: End to end through a real OrderService
+	service, _ := newEventOrderService(t)
+	placed := placeEventOrder(t, service, "regular")
+	fixture := newWebhookFixture(t, service)
+	body := encodeWebhookEvent(t, newRefundEvent("evt_1", placed.OrderID, "re_1", "100.00"))
+
+	// Act
+	status, response := fixture.deliverSigned(t, body)
+
+	// Assert
+	if status != http.StatusOK || response.Status != webhookStatusProcessed {
+		t.Fatalf("Expected processed, got %d %s (%s)", status, response.Status, response.Reason)
+	}
+	stored, _ := service.GetOrder(placed.OrderID)
+	if stored.Status != OrderStatusRefunded {
+		t.Errorf("Expected status %s, got %s", OrderStatusRefunded, stored.Status)
+	}
+}
@@ -0,0 +1,104 @@
+package application
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// WEBHOOK STORES
+// Event IDs already handled, and events that could not be applied
+// =============================================================================
+
+// DefaultWebhookDedupeRetention is how long an event ID is remembered.
+// Providers stop redelivering well before that.
+const DefaultWebhookDedupeRetention = 7 * 24 * time.Hour
+
+// ProcessedEventStoreInterface deduplicates webhook deliveries. Claim
+// reports whether the event ID is new and reserves it; Release gives it up
+// again when handling failed and the provider should redeliver. A claim held
+// when the process dies was never released, so a store kept across restarts
+// must not treat it as handled.
+type ProcessedEventStoreInterface interface {
+	Claim(eventID string, now time.Time) (bool, error)
+	Release(eventID string) error
+}
+
+type InMemoryProcessedEventStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	claimedAt map[string]time.Time
+}
+
+func NewInMemoryProcessedEventStore(retention time.Duration) ProcessedEventStoreInterface {
+	return &InMemoryProcessedEventStore{
+		retention: retention,
+		claimedAt: make(map[string]time.Time),
+	}
+}
+
+// Claim sweeps IDs past their retention first, so the store only grows with
+// IDs that can still be redelivered.
+func (s *InMemoryProcessedEventStore) Claim(eventID string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeBefore(now.Add(-s.retention))
+	if _, claimed := s.claimedAt[eventID]; claimed {
+		return false, nil
+	}
+	s.claimedAt[eventID] = now
+	return true, nil
+}
+
+func (s *InMemoryProcessedEventStore) Release(eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claimedAt, eventID)
+	return nil
+}
+
+func (s *InMemoryProcessedEventStore) purgeBefore(cutoff time.Time) {
+	for eventID, claimedAt := range s.claimedAt {
+		if claimedAt.Before(cutoff) {
+			delete(s.claimedAt, eventID)
+		}
+	}
+}
+
+// DeadLetter is a webhook event that was received and acknowledged but could
+// not be applied, kept with its raw payload for someone to look at.
+type DeadLetter struct {
+	EventID    string
+	EventType  PaymentEventType
+	Payload    json.RawMessage
+	Reason     string
+	ReceivedAt time.Time
+}
+
+type DeadLetterStoreInterface interface {
+	Add(letter DeadLetter) error
+	List() ([]DeadLetter, error)
+}
+
+type InMemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func NewInMemoryDeadLetterStore() DeadLetterStoreInterface {
+	return &InMemoryDeadLetterStore{}
+}
+
+func (s *InMemoryDeadLetterStore) Add(letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *InMemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...), nil
+}
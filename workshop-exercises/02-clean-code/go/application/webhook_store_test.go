@@ -0,0 +1,74 @@
+package application
+
+import (
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// WEBHOOK STORE TESTS
+// Testing: webhook_store.go
+// =============================================================================
+
+func TestInMemoryProcessedEventStore_Claim_SecondClaimIsDuplicate(t *testing.T) {
+	// Arrange
+	store := NewInMemoryProcessedEventStore(time.Hour)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	first, _ := store.Claim("evt_1", now)
+	second, _ := store.Claim("evt_1", now.Add(time.Minute))
+
+	// Assert
+	if !first || second {
+		t.Errorf("Expected first claim to win and second to be a duplicate, got %v and %v", first, second)
+	}
+}
+
+func TestInMemoryProcessedEventStore_Release_AllowsClaimAgain(t *testing.T) {
+	// Arrange
+	store := NewInMemoryProcessedEventStore(time.Hour)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Claim("evt_1", now)
+
+	// Act
+	store.Release("evt_1")
+	claimed, _ := store.Claim("evt_1", now)
+
+	// Assert
+	if !claimed {
+		t.Error("Expected a released event ID to be claimable again")
+	}
+}
+
+func TestInMemoryProcessedEventStore_Claim_ForgetsIDsPastRetention(t *testing.T) {
+	// Arrange
+	store := NewInMemoryProcessedEventStore(time.Hour)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Claim("evt_1", now)
+
+	// Act
+	claimed, _ := store.Claim("evt_1", now.Add(2*time.Hour))
+
+	// Assert
+	if !claimed {
+		t.Error("Expected the event ID to be forgotten after the retention period")
+	}
+}
+
+func TestInMemoryDeadLetterStore_List_ReturnsCopyInArrivalOrder(t *testing.T) {
+	// Arrange
+	store := NewInMemoryDeadLetterStore()
+	store.Add(DeadLetter{EventID: "evt_1"})
+	store.Add(DeadLetter{EventID: "evt_2"})
+
+	// Act
+	letters, _ := store.List()
+	letters[0].EventID = "changed"
+	again, _ := store.List()
+
+	// Assert
+	if len(again) != 2 || again[0].EventID != "evt_1" || again[1].EventID != "evt_2" {
+		t.Errorf("Expected evt_1 and evt_2 unchanged, got %+v", again)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/workshop/application"
+)
+
+// audit-verify checks that an audit log written by FileAuditLog is one
+// unbroken hash chain, and lists every gap or altered entry it finds:
+//
+//	go run ./cmd/audit-verify -file audit.log -head <hash recorded earlier>
+//
+// It exits 1 when the log does not verify. Entries cut off the end leave a
+// valid chain, so pass the head hash printed by an earlier run to check
+// that it is still part of the log.
+func main() {
+	path := flag.String("file", "audit.log", "audit log to verify")
+	head := flag.String("head", "", "hash of an entry that must still be in the log")
+	flag.Parse()
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("Opening audit log failed: %v", err)
+	}
+	defer file.Close()
+
+	report, err := application.VerifyAuditLog(file)
+	if err != nil {
+		log.Fatalf("Verifying audit log failed: %v", err)
+	}
+	for _, violation := range report.Violations {
+		fmt.Println(violation)
+	}
+	if *head != "" && !report.Contains(*head) {
+		fmt.Printf("head %s is not in the log: entries were removed from the end\n", *head)
+		os.Exit(1)
+	}
+	fmt.Printf("%d entries, head %s\n", report.Entries, report.Head)
+	if !report.Valid() {
+		os.Exit(1)
+	}
+}
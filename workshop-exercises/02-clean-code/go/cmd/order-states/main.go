@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/workshop/application"
+)
+
+// order-states prints the order state machine as a Graphviz graph:
+//
+//	go run ./cmd/order-states | dot -Tsvg > order-states.svg
+func main() {
+	fmt.Print(application.NewOrderStateMachine().DOT())
+}
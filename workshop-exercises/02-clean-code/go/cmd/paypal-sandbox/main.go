@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/workshop/sandbox"
+)
+
+// paypal-sandbox serves the PayPal-compatible sandbox API locally, so the
+// HTTP PayPal processor can be exercised end to end without network access:
+//
+//	go run ./cmd/paypal-sandbox -addr 127.0.0.1:8089 -scenarios scenarios.json
+//
+// Scenarios can also be changed while it runs, through /sandbox/scenarios
+// (GET lists, POST appends a JSON array, DELETE clears).
+func main() {
+	defaults := sandbox.DefaultConfig()
+	addr := flag.String("addr", "127.0.0.1:8089", "address to listen on")
+	clientID := flag.String("client-id", defaults.ClientID, "OAuth client ID to accept")
+	clientSecret := flag.String("client-secret", defaults.ClientSecret, "OAuth client secret to accept")
+	tokenTTL := flag.Duration("token-ttl", defaults.TokenTTL, "lifetime of issued access tokens")
+	authorizationTTL := flag.Duration("authorization-ttl", defaults.AuthorizationTTL, "how long authorizations stay capturable")
+	scenariosPath := flag.String("scenarios", "", "JSON file of scripted scenarios to start with")
+	flag.Parse()
+
+	server := sandbox.NewServer(sandbox.Config{
+		ClientID:         *clientID,
+		ClientSecret:     *clientSecret,
+		TokenTTL:         *tokenTTL,
+		AuthorizationTTL: *authorizationTTL,
+	})
+	if err := loadScenarios(server, *scenariosPath); err != nil {
+		log.Fatalf("Loading sandbox scenarios failed: %v", err)
+	}
+	if err := serve(*addr, server); err != nil {
+		log.Fatalf("Sandbox server failed: %v", err)
+	}
+}
+
+func loadScenarios(server *sandbox.Server, path string) error {
+	if path == "" {
+		return nil
+	}
+	scenarios, err := sandbox.LoadScenarios(path)
+	if err != nil {
+		return err
+	}
+	return server.Script(scenarios...)
+}
+
+func serve(addr string, handler http.Handler) error {
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("PayPal sandbox listening on http://%s", addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
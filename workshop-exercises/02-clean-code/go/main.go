@@ -1,63 +1,368 @@
 package main
 
 import (
+	"context"
+	_ "embed"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/workshop/application"
 )
 
+//go:embed tax_rates.example.json
+var defaultTaxRates []byte
+
 func main() {
 	startApplication()
 }
 
 func startApplication() {
-	orderService := buildOrderService()
+	metrics := application.NewMetricsRegistry()
+	bus := buildEventBus()
+	outbox := buildOutbox()
+	go runOutboxRelay(buildOutboxRelay(outbox, bus))
+	orderService := buildOrderService(application.NewOrderMetrics(metrics), bus, outbox)
 	runDemo(orderService)
+	serveHTTP(orderService, metrics)
 }
 
-func buildOrderService() application.OrderServiceInterface {
-	paymentProcessor := buildPaymentProcessor()
+// Once the demo has run, provider webhooks are served on WEBHOOK_ADDR,
+// signed with WEBHOOK_SECRET, and Prometheus metrics on METRICS_ADDR.
+// Without either address the program just exits.
+func serveHTTP(orderService application.OrderServiceInterface, metrics application.MetricsRegistryInterface) {
+	failed := make(chan error)
+	servers := 0
+	if addr := os.Getenv("WEBHOOK_ADDR"); addr != "" {
+		servers++
+		go serve(addr, "/webhooks/payments", buildWebhookHandler(orderService), failed)
+	}
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		servers++
+		go serve(addr, "/metrics", application.NewMetricsHandler(metrics), failed)
+	}
+	if servers > 0 {
+		log.Fatal(<-failed)
+	}
+}
+
+func serve(addr string, path string, handler http.Handler, failed chan<- error) {
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	log.Printf("Serving http://%s%s", addr, path)
+	failed <- http.ListenAndServe(addr, mux)
+}
+
+func buildWebhookHandler(orderService application.OrderServiceInterface) http.Handler {
+	handler, err := application.NewWebhookHandler(orderService, application.DefaultWebhookConfig(os.Getenv("WEBHOOK_SECRET")))
+	if err != nil {
+		log.Fatalf("Configuring webhooks failed: %v", err)
+	}
+	return handler
+}
+
+func buildOrderService(metrics application.OrderMetricsInterface, bus application.EventBusInterface, outbox application.OutboxRepository) application.OrderServiceInterface {
+	paymentProcessor := buildPaymentProcessor(metrics)
 	discountService := buildDiscountService()
-	
-	return application.NewOrderService(paymentProcessor, discountService)
+	taxService := buildTaxService()
+
+	options := []application.OrderServiceOption{application.WithDeferredCapture("distributor"), application.WithEventBus(bus), application.WithOutbox(outbox), application.WithMetrics(metrics)}
+	if auditor := buildAuditor(); auditor != nil {
+		paymentProcessor = application.NewAuditingProcessor(paymentProcessor, auditor)
+		options = append(options, application.WithAuditor(auditor))
+	}
+
+	return application.NewOrderService(paymentProcessor, discountService, taxService, application.NewULIDOrderIDGenerator(), options...)
 }
 
-func buildPaymentProcessor() application.PaymentProcessorInterface {
-	return createPaymentProcessor()
+// Orders and payments are audited to the file named by AUDIT_LOG; check it
+// with ./cmd/audit-verify. An entry that cannot be written is logged, the
+// operation it describes has happened regardless.
+func buildAuditor() application.AuditorInterface {
+	path := os.Getenv("AUDIT_LOG")
+	if path == "" {
+		return nil
+	}
+	auditLog, err := application.NewFileAuditLog(path)
+	if err != nil {
+		log.Fatalf("Opening audit log failed: %v", err)
+	}
+	return application.NewAuditor(auditLog, application.AuditorConfig{
+		OnFailure: func(entry application.AuditEntry, err error) {
+			log.Printf("Audit entry %s for order %q not written: %v", entry.Action, entry.OrderID, err)
+		},
+	})
 }
 
-func createPaymentProcessor() application.PaymentProcessorInterface {
-	return application.NewCreditCardProcessor()
-	// Alternative: return application.NewPayPalProcessor()
+// Emails, loyalty and analytics subscribe here; a subscriber that fails is
+// logged and never fails the order.
+func buildEventBus() application.EventBusInterface {
+	return application.NewEventBusWithConfig(application.EventBusConfig{
+		OnFailure: func(failure *application.SubscriberFailure) {
+			log.Print(failure)
+		},
+	})
+}
+
+// Orders are stored with the events describing them in the file named by
+// ORDER_LOG, or in memory without it. Events still in the file when the
+// program stops are relayed on the next start.
+func buildOutbox() application.OutboxRepository {
+	path := os.Getenv("ORDER_LOG")
+	if path == "" {
+		return application.NewInMemoryOutboxRepository()
+	}
+	outbox, err := application.NewFileOutboxRepository(path)
+	if err != nil {
+		log.Fatalf("Opening order log failed: %v", err)
+	}
+	return outbox
+}
+
+// The relay moves events from the outbox to the bus. Failures are logged,
+// including events parked after too many failed deliveries.
+func buildOutboxRelay(outbox application.OutboxRepository, bus application.EventBusInterface) application.OutboxRelayInterface {
+	config := application.DefaultOutboxRelayConfig()
+	config.OnFailure = func(err error) {
+		log.Printf("Relaying order events failed: %v", err)
+	}
+	relay, err := application.NewOutboxRelay(outbox, application.NewEventBusOutboxConsumer(bus), config)
+	if err != nil {
+		log.Fatalf("Configuring outbox relay failed: %v", err)
+	}
+	return relay
+}
+
+func runOutboxRelay(relay application.OutboxRelayInterface) {
+	if err := relay.Run(context.Background()); err != nil {
+		log.Printf("Outbox relay stopped: %v", err)
+	}
+}
+
+func buildPaymentProcessor(metrics application.OrderMetricsInterface) application.PaymentProcessorInterface {
+	return createPaymentProcessor(metrics)
+}
+
+// Card payments are the default: transient card failures are retried, then
+// fail over to PayPal. Euro payments go through PayPal unless the order asks
+// for a processor itself.
+func createPaymentProcessor(metrics application.OrderMetricsInterface) application.PaymentProcessorInterface {
+	registry := createProcessorRegistry(metrics)
+	cardWithFallback, err := application.NewFailoverProcessor(registry, application.FailoverConfig{
+		Processors: []string{string(application.ProcessorTypeCreditCard), string(application.ProcessorTypePayPal)},
+	})
+	if err != nil {
+		log.Fatalf("Configuring payment failover failed: %v", err)
+	}
+	if err := registry.Register("card_then_paypal", cardWithFallback); err != nil {
+		log.Fatalf("Configuring payment failover failed: %v", err)
+	}
+	processor, err := application.NewRoutingProcessor(registry, application.RoutingConfig{
+		DefaultProcessor: "card_then_paypal",
+		Rules: []application.RoutingRule{
+			{Name: "euro-via-paypal", Processor: string(application.ProcessorTypePayPal), Currencies: []application.Currency{application.EUR}},
+		},
+	})
+	if err != nil {
+		log.Fatalf("Configuring payment routing failed: %v", err)
+	}
+	return processor
+}
+
+// Each processor reports its payment latency and fees under the name it is
+// registered with.
+func createProcessorRegistry(metrics application.OrderMetricsInterface) application.ProcessorRegistryInterface {
+	fees := loadFeeSchedules()
+	creditCardConfig := application.DefaultCreditCardConfig()
+	creditCardConfig.Fees = feeScheduleFor(fees, application.ProcessorTypeCreditCard)
+	payPalProcessorConfig := application.DefaultPayPalConfig()
+	payPalProcessorConfig.Fees = feeScheduleFor(fees, application.ProcessorTypePayPal)
+
+	creditCardProcessor, err := application.NewCreditCardProcessorWithConfig(creditCardConfig)
+	if err != nil {
+		log.Fatalf("Configuring credit card processor failed: %v", err)
+	}
+	creditCard, err := application.NewRetryingProcessor(creditCardProcessor, application.RetryConfig{
+		Policy: application.DefaultRetryPolicy(),
+	})
+	if err != nil {
+		log.Fatalf("Configuring payment retries failed: %v", err)
+	}
+	payPalConfig := application.DefaultCircuitBreakerConfig(application.ProcessorTypePayPal.DisplayName())
+	payPalConfig.OnStateChange = func(change application.CircuitStateChange) {
+		log.Printf("%s circuit %s -> %s (failure rate %.0f%%)", change.Name, change.From, change.To, change.FailureRate*100)
+	}
+	payPal, err := application.NewCircuitBreaker(createPayPalProcessor(payPalProcessorConfig), payPalConfig)
+	if err != nil {
+		log.Fatalf("Configuring circuit breaker failed: %v", err)
+	}
+	registry := application.NewProcessorRegistry()
+	creditCardName := string(application.ProcessorTypeCreditCard)
+	if err := registry.Register(creditCardName, application.NewMetricsProcessor(creditCard, creditCardName, metrics)); err != nil {
+		log.Fatalf("Registering payment processor failed: %v", err)
+	}
+	payPalName := string(application.ProcessorTypePayPal)
+	if err := registry.Register(payPalName, application.NewMetricsProcessor(payPal, payPalName, metrics)); err != nil {
+		log.Fatalf("Registering payment processor failed: %v", err)
+	}
+	return registry
+}
+
+// PayPal goes over HTTP when PAYPAL_API_URL is set, e.g. to a local
+// sandbox from ./cmd/paypal-sandbox; otherwise it is simulated in process.
+func createPayPalProcessor(config application.ProcessorConfig) application.PaymentProcessorInterface {
+	baseURL := os.Getenv("PAYPAL_API_URL")
+	if baseURL == "" {
+		processor, err := application.NewPayPalProcessorWithConfig(config)
+		if err != nil {
+			log.Fatalf("Configuring PayPal failed: %v", err)
+		}
+		return processor
+	}
+	processor, err := application.NewPayPalHTTPProcessor(application.PayPalHTTPConfig{
+		BaseURL:      baseURL,
+		ClientID:     os.Getenv("PAYPAL_CLIENT_ID"),
+		ClientSecret: os.Getenv("PAYPAL_CLIENT_SECRET"),
+		Clock:        config.Clock,
+		Fees:         config.Fees,
+	})
+	if err != nil {
+		log.Fatalf("Configuring PayPal failed: %v", err)
+	}
+	return processor
+}
+
+// Fee schedules come from the file named by FEE_SCHEDULE_FILE (see
+// fee_schedules.example.json); processors missing from it keep their
+// standard pricing.
+func loadFeeSchedules() map[string]application.FeeSchedule {
+	path := os.Getenv("FEE_SCHEDULE_FILE")
+	if path == "" {
+		return nil
+	}
+	schedules, err := application.LoadFeeSchedules(path)
+	if err != nil {
+		log.Fatalf("Loading fee schedules failed: %v", err)
+	}
+	return schedules
+}
+
+func feeScheduleFor(schedules map[string]application.FeeSchedule, processorType application.ProcessorType) *application.FeeSchedule {
+	schedule, ok := schedules[string(processorType)]
+	if !ok {
+		return nil
+	}
+	return &schedule
 }
 
 func buildDiscountService() application.DiscountServiceInterface {
 	return createDiscountService()
 }
 
+// Discount rules come from the file named by DISCOUNT_RULES_FILE (see
+// discount_rules.example.json); without it the built-in rates apply.
 func createDiscountService() application.DiscountServiceInterface {
-	return application.NewDiscountService()
+	path := os.Getenv("DISCOUNT_RULES_FILE")
+	if path == "" {
+		return application.NewDiscountService()
+	}
+	discountService, err := application.NewDiscountServiceFromFile(path, application.NewSystemClock())
+	if err != nil {
+		log.Fatalf("Loading discount rules failed: %v", err)
+	}
+	return discountService
+}
+
+func buildTaxService() application.TaxServiceInterface {
+	return createTaxService()
+}
+
+// Tax rates come from the file named by TAX_RATES_FILE; without it the
+// rates in tax_rates.example.json apply.
+func createTaxService() application.TaxServiceInterface {
+	table, err := loadTaxRateTable(os.Getenv("TAX_RATES_FILE"))
+	if err != nil {
+		log.Fatalf("Loading tax rates failed: %v", err)
+	}
+	taxService, err := application.NewTaxService(table)
+	if err != nil {
+		log.Fatalf("Loading tax rates failed: %v", err)
+	}
+	return taxService
+}
+
+func loadTaxRateTable(path string) (application.TaxRateTable, error) {
+	if path == "" {
+		return application.ParseTaxRateTable(defaultTaxRates)
+	}
+	return application.LoadTaxRateTable(path)
 }
 
 func runDemo(orderService application.OrderServiceInterface) {
 	fmt.Println("=== Clean Code Demo ===")
-	
+
 	// Demo order
 	order := application.OrderData{
-		Amount:       100.50,
+		LineItems: []application.LineItem{
+			{ProductID: "SKU-COFFEE", Quantity: 2, UnitPrice: application.MustParseMoney("12.75", application.USD)},
+			{ProductID: "SKU-MUG", Quantity: 3, UnitPrice: application.MustParseMoney("25.00", application.USD)},
+		},
 		Customer:     "john.doe@example.com",
 		CustomerType: "premium",
+		Country:      "US",
+		Region:       "CA",
+		Card: &application.Card{
+			Number:      "4111 1111 1111 1111",
+			ExpiryMonth: 12,
+			ExpiryYear:  time.Now().Year() + 2,
+			CVC:         "123",
+			HolderName:  "John Doe",
+		},
 	}
-	
-	fmt.Printf("Processing order for %s (%s): $%.2f\n", 
-		order.Customer, order.CustomerType, order.Amount)
-	
-	result, err := orderService.ProcessOrder(order)
+
+	total, err := application.LineItemsTotal(order.LineItems)
+	if err != nil {
+		log.Printf("Invalid order: %v", err)
+		return
+	}
+
+	fmt.Printf("Processing order for %s (%s): %s\n",
+		order.Customer, order.CustomerType, total)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result, err := orderService.ProcessOrder(ctx, order)
 	if err != nil {
 		log.Printf("Order failed: %v", err)
 		return
 	}
-	
-	fmt.Printf("Success: %s\n", result)
-}
\ No newline at end of file
+
+	formatter := application.NewTextResultFormatter()
+	fmt.Printf("Success: %s\n", formatter.FormatOrderResult(result))
+	for _, line := range result.Lines {
+		fmt.Printf("  Line: %s\n", formatter.FormatLineBreakdown(line))
+	}
+	for _, discount := range result.Discounts {
+		fmt.Printf("  Discount: %s\n", discount.Explanation)
+	}
+	fmt.Printf("  Tax: %s\n", formatter.FormatTaxResult(result.Tax))
+
+	refunded, err := orderService.RefundOrder(ctx, result.OrderID, application.MustParseMoney("10.00", application.USD))
+	if err != nil {
+		log.Printf("Refund failed: %v", err)
+		return
+	}
+
+	fmt.Printf("Refund: %s\n", formatter.FormatRefundResult(refunded.Refunds[len(refunded.Refunds)-1]))
+
+	orders, err := orderService.ListCustomerOrders(order.Customer)
+	if err != nil {
+		log.Printf("Order lookup failed: %v", err)
+		return
+	}
+
+	fmt.Printf("Orders on file for %s: %d\n", order.Customer, len(orders))
+}
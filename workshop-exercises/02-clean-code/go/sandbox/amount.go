@@ -0,0 +1,89 @@
+package sandbox
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// =============================================================================
+// AMOUNTS
+// PayPal-style decimal strings, compared and subtracted exactly
+// =============================================================================
+
+type Amount struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KWD": 3,
+}
+
+func (a Amount) decimals() int {
+	if decimals, ok := currencyDecimals[a.CurrencyCode]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// validate accepts a positive decimal in an upper-case ISO-4217 currency.
+func (a Amount) validate() error {
+	if len(a.CurrencyCode) != 3 || strings.ToUpper(a.CurrencyCode) != a.CurrencyCode {
+		return fmt.Errorf("invalid currency_code %q", a.CurrencyCode)
+	}
+	value, ok := a.parse()
+	if !ok {
+		return fmt.Errorf("invalid amount value %q", a.Value)
+	}
+	if value.Sign() <= 0 {
+		return fmt.Errorf("amount must be positive, got %s", a.Value)
+	}
+	if _, fraction, found := strings.Cut(a.Value, "."); found && len(fraction) > a.decimals() {
+		return fmt.Errorf("%s takes at most %d decimals, got %s", a.CurrencyCode, a.decimals(), a.Value)
+	}
+	return nil
+}
+
+func (a Amount) parse() (*big.Rat, bool) {
+	if strings.ContainsAny(a.Value, "eE/") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(a.Value)
+}
+
+// rat reads an amount that has already been validated.
+func (a Amount) rat() *big.Rat {
+	value, ok := a.parse()
+	if !ok {
+		return new(big.Rat)
+	}
+	return value
+}
+
+func (a Amount) withValue(value *big.Rat) Amount {
+	return Amount{CurrencyCode: a.CurrencyCode, Value: value.FloatString(a.decimals())}
+}
+
+// compareAmounts compares two validated amounts of the same currency.
+func compareAmounts(a Amount, b Amount) int {
+	return a.rat().Cmp(b.rat())
+}
+
+func subtractAmounts(a Amount, b Amount) Amount {
+	return a.withValue(new(big.Rat).Sub(a.rat(), b.rat()))
+}
+
+func addAmounts(a Amount, b Amount) Amount {
+	return a.withValue(new(big.Rat).Add(a.rat(), b.rat()))
+}
+
+func zeroAmount(currencyCode string) Amount {
+	amount := Amount{CurrencyCode: currencyCode}
+	return amount.withValue(new(big.Rat))
+}
+
+func (a Amount) isZero() bool {
+	return a.rat().Sign() == 0
+}
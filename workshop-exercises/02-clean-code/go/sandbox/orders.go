@@ -0,0 +1,570 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// =============================================================================
+// ORDERS AND PAYMENTS
+// Create, capture and authorize orders; capture, void and refund payments
+// =============================================================================
+
+const (
+	IntentCapture   = "CAPTURE"
+	IntentAuthorize = "AUTHORIZE"
+)
+
+const (
+	statusApproved          = "APPROVED"
+	statusCompleted         = "COMPLETED"
+	statusCreated           = "CREATED"
+	statusCaptured          = "CAPTURED"
+	statusVoided            = "VOIDED"
+	statusExpired           = "EXPIRED"
+	statusPartiallyRefunded = "PARTIALLY_REFUNDED"
+	statusRefunded          = "REFUNDED"
+)
+
+type order struct {
+	id          string
+	intent      string
+	status      string
+	referenceID string
+	amount      Amount
+	createdAt   time.Time
+}
+
+type authorization struct {
+	id          string
+	referenceID string
+	status      string
+	amount      Amount
+	createdAt   time.Time
+	expiresAt   time.Time
+}
+
+type capture struct {
+	id          string
+	referenceID string
+	status      string
+	amount      Amount
+	refunded    Amount
+	createdAt   time.Time
+}
+
+func (c *capture) remaining() Amount {
+	return subtractAmounts(c.amount, c.refunded)
+}
+
+// =============================================================================
+// WIRE FORMAT
+// =============================================================================
+
+type purchaseUnitRequest struct {
+	ReferenceID string `json:"reference_id"`
+	Amount      Amount `json:"amount"`
+}
+
+type createOrderRequest struct {
+	Intent        string                `json:"intent"`
+	PurchaseUnits []purchaseUnitRequest `json:"purchase_units"`
+}
+
+// amountRequest is the body of an authorization capture or a refund; a
+// missing amount means the full remaining amount.
+type amountRequest struct {
+	Amount *Amount `json:"amount,omitempty"`
+}
+
+type orderResponse struct {
+	ID            string                 `json:"id"`
+	Intent        string                 `json:"intent"`
+	Status        string                 `json:"status"`
+	PurchaseUnits []purchaseUnitResponse `json:"purchase_units"`
+	CreateTime    string                 `json:"create_time"`
+}
+
+type purchaseUnitResponse struct {
+	ReferenceID string            `json:"reference_id"`
+	Amount      Amount            `json:"amount"`
+	Payments    *paymentsResponse `json:"payments,omitempty"`
+}
+
+type paymentsResponse struct {
+	Captures       []captureResponse       `json:"captures,omitempty"`
+	Authorizations []authorizationResponse `json:"authorizations,omitempty"`
+}
+
+type captureResponse struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Amount     Amount `json:"amount"`
+	CreateTime string `json:"create_time"`
+}
+
+type authorizationResponse struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Amount         Amount `json:"amount"`
+	CreateTime     string `json:"create_time"`
+	ExpirationTime string `json:"expiration_time"`
+}
+
+type refundResponse struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	Amount     Amount `json:"amount"`
+	CreateTime string `json:"create_time"`
+}
+
+func (o *order) response(payments *paymentsResponse) orderResponse {
+	return orderResponse{
+		ID:     o.id,
+		Intent: o.intent,
+		Status: o.status,
+		PurchaseUnits: []purchaseUnitResponse{{
+			ReferenceID: o.referenceID,
+			Amount:      o.amount,
+			Payments:    payments,
+		}},
+		CreateTime: formatTime(o.createdAt),
+	}
+}
+
+func (c *capture) response() captureResponse {
+	return captureResponse{ID: c.id, Status: c.status, Amount: c.amount, CreateTime: formatTime(c.createdAt)}
+}
+
+func (a *authorization) response() authorizationResponse {
+	return authorizationResponse{
+		ID:             a.id,
+		Status:         a.status,
+		Amount:         a.amount,
+		CreateTime:     formatTime(a.createdAt),
+		ExpirationTime: formatTime(a.expiresAt),
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// apiFailure is a PayPal error response a handler decided on while holding
+// the lock; it is written after the lock is released.
+type apiFailure struct {
+	status  int
+	name    string
+	issue   string
+	message string
+}
+
+func unprocessable(issue string, message string) *apiFailure {
+	return &apiFailure{status: http.StatusUnprocessableEntity, name: "UNPROCESSABLE_ENTITY", issue: issue, message: message}
+}
+
+func invalidRequest(issue string, message string) *apiFailure {
+	return &apiFailure{status: http.StatusBadRequest, name: "INVALID_REQUEST", issue: issue, message: message}
+}
+
+func resourceNotFound(id string) *apiFailure {
+	return &apiFailure{status: http.StatusNotFound, name: "RESOURCE_NOT_FOUND", issue: "INVALID_RESOURCE_ID", message: "Specified resource ID does not exist: " + id}
+}
+
+func (f *apiFailure) write(w http.ResponseWriter) {
+	writeError(w, f.status, f.name, f.issue, f.message)
+}
+
+func decodeBody(r *http.Request, target any) *apiFailure {
+	err := json.NewDecoder(r.Body).Decode(target)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	return invalidRequest("MALFORMED_REQUEST_JSON", err.Error())
+}
+
+// =============================================================================
+// ORDERS
+// =============================================================================
+
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request, _ string) {
+	var request createOrderRequest
+	if failure := decodeBody(r, &request); failure != nil {
+		failure.write(w)
+		return
+	}
+	if failure := validateCreateOrder(request); failure != nil {
+		failure.write(w)
+		return
+	}
+	unit := request.PurchaseUnits[0]
+	if s.intercept(w, r, apiCall{operation: OperationCreateOrder, referenceID: unit.ReferenceID, amount: unit.Amount.Value}) {
+		return
+	}
+
+	created := &order{
+		id:          newID(""),
+		intent:      request.Intent,
+		status:      statusApproved,
+		referenceID: unit.ReferenceID,
+		amount:      unit.Amount,
+		createdAt:   s.now(),
+	}
+	s.mu.Lock()
+	s.orders[created.id] = created
+	response := created.response(nil)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func validateCreateOrder(request createOrderRequest) *apiFailure {
+	if request.Intent != IntentCapture && request.Intent != IntentAuthorize {
+		return invalidRequest("INVALID_PARAMETER_VALUE", "intent must be CAPTURE or AUTHORIZE")
+	}
+	if len(request.PurchaseUnits) != 1 {
+		return invalidRequest("INVALID_PARAMETER_VALUE", "the sandbox takes exactly one purchase unit")
+	}
+	if err := request.PurchaseUnits[0].Amount.validate(); err != nil {
+		return invalidRequest("INVALID_PARAMETER_VALUE", err.Error())
+	}
+	return nil
+}
+
+// findOrder returns the call to match scenarios against, or a 404.
+func (s *Server) findOrder(id string, operation Operation) (apiCall, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.orders[id]
+	if !ok {
+		return apiCall{}, resourceNotFound(id)
+	}
+	return apiCall{operation: operation, referenceID: found.referenceID, amount: found.amount.Value}, nil
+}
+
+func (s *Server) handleCaptureOrder(w http.ResponseWriter, r *http.Request, id string) {
+	call, failure := s.findOrder(id, OperationCaptureOrder)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	response, failure := s.captureOrder(id)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) captureOrder(id string) (orderResponse, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.orders[id]
+	if failure := checkOrderPayable(found, IntentCapture); failure != nil {
+		return orderResponse{}, failure
+	}
+	settled := &capture{
+		id:          newID("CAP"),
+		referenceID: found.referenceID,
+		status:      statusCompleted,
+		amount:      found.amount,
+		refunded:    zeroAmount(found.amount.CurrencyCode),
+		createdAt:   s.now(),
+	}
+	s.captures[settled.id] = settled
+	found.status = statusCompleted
+	return found.response(&paymentsResponse{Captures: []captureResponse{settled.response()}}), nil
+}
+
+func (s *Server) handleAuthorizeOrder(w http.ResponseWriter, r *http.Request, id string) {
+	call, failure := s.findOrder(id, OperationAuthorizeOrder)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	response, failure := s.authorizeOrder(id)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) authorizeOrder(id string) (orderResponse, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.orders[id]
+	if failure := checkOrderPayable(found, IntentAuthorize); failure != nil {
+		return orderResponse{}, failure
+	}
+	now := s.now()
+	reserved := &authorization{
+		id:          newID("AUTH"),
+		referenceID: found.referenceID,
+		status:      statusCreated,
+		amount:      found.amount,
+		createdAt:   now,
+		expiresAt:   now.Add(s.config.AuthorizationTTL),
+	}
+	s.authorizations[reserved.id] = reserved
+	found.status = statusCompleted
+	return found.response(&paymentsResponse{Authorizations: []authorizationResponse{reserved.response()}}), nil
+}
+
+var alreadyPaidIssues = map[string]string{
+	IntentCapture:   "ORDER_ALREADY_CAPTURED",
+	IntentAuthorize: "ORDER_ALREADY_AUTHORIZED",
+}
+
+func checkOrderPayable(found *order, intent string) *apiFailure {
+	if found.intent != intent {
+		return unprocessable("ACTION_DOES_NOT_MATCH_INTENT", "order intent is "+found.intent)
+	}
+	if found.status != statusApproved {
+		return unprocessable(alreadyPaidIssues[intent], "order "+found.id+" is "+found.status)
+	}
+	return nil
+}
+
+// =============================================================================
+// AUTHORIZATIONS
+// =============================================================================
+
+func (s *Server) findAuthorization(id string, operation Operation, requested *Amount) (apiCall, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.authorizations[id]
+	if !ok {
+		return apiCall{}, resourceNotFound(id)
+	}
+	return apiCall{operation: operation, referenceID: found.referenceID, amount: requestedValue(requested, found.amount)}, nil
+}
+
+func requestedValue(requested *Amount, fallback Amount) string {
+	if requested != nil {
+		return requested.Value
+	}
+	return fallback.Value
+}
+
+func (s *Server) handleCaptureAuthorization(w http.ResponseWriter, r *http.Request, id string) {
+	var request amountRequest
+	if failure := decodeBody(r, &request); failure != nil {
+		failure.write(w)
+		return
+	}
+	call, failure := s.findAuthorization(id, OperationCaptureAuthorization, request.Amount)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	response, failure := s.captureAuthorization(id, request.Amount)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// captureAuthorization settles the requested amount, all of it by default,
+// and releases the rest: every capture is a final capture.
+func (s *Server) captureAuthorization(id string, requested *Amount) (captureResponse, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.authorizations[id]
+	if failure := s.checkAuthorizationPending(found); failure != nil {
+		return captureResponse{}, failure
+	}
+	amount, failure := resolveAmount(requested, found.amount, "MAX_CAPTURE_AMOUNT_EXCEEDED")
+	if failure != nil {
+		return captureResponse{}, failure
+	}
+	settled := &capture{
+		id:          newID("CAP"),
+		referenceID: found.referenceID,
+		status:      statusCompleted,
+		amount:      amount,
+		refunded:    zeroAmount(amount.CurrencyCode),
+		createdAt:   s.now(),
+	}
+	s.captures[settled.id] = settled
+	found.status = statusCaptured
+	return settled.response(), nil
+}
+
+func (s *Server) handleVoidAuthorization(w http.ResponseWriter, r *http.Request, id string) {
+	call, failure := s.findAuthorization(id, OperationVoidAuthorization, nil)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	if failure := s.voidAuthorization(id); failure != nil {
+		failure.write(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) voidAuthorization(id string) *apiFailure {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.authorizations[id]
+	if failure := s.checkAuthorizationPending(found); failure != nil {
+		return failure
+	}
+	found.status = statusVoided
+	return nil
+}
+
+func (s *Server) checkAuthorizationPending(found *authorization) *apiFailure {
+	if found.status == statusCreated && !s.now().Before(found.expiresAt) {
+		found.status = statusExpired
+	}
+	switch found.status {
+	case statusCreated:
+		return nil
+	case statusExpired:
+		return unprocessable("AUTHORIZATION_EXPIRED", "authorization "+found.id+" expired at "+formatTime(found.expiresAt))
+	case statusVoided:
+		return unprocessable("AUTHORIZATION_VOIDED", "authorization "+found.id+" was voided")
+	default:
+		return unprocessable("AUTHORIZATION_ALREADY_CAPTURED", "authorization "+found.id+" was captured")
+	}
+}
+
+// =============================================================================
+// CAPTURES
+// =============================================================================
+
+func (s *Server) findCapture(id string, operation Operation, requested *Amount) (apiCall, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.captures[id]
+	if !ok {
+		return apiCall{}, resourceNotFound(id)
+	}
+	return apiCall{operation: operation, referenceID: found.referenceID, amount: requestedValue(requested, found.remaining())}, nil
+}
+
+func (s *Server) handleRefundCapture(w http.ResponseWriter, r *http.Request, id string) {
+	var request amountRequest
+	if failure := decodeBody(r, &request); failure != nil {
+		failure.write(w)
+		return
+	}
+	call, failure := s.findCapture(id, OperationRefundCapture, request.Amount)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	response, failure := s.refundCapture(id, request.Amount)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (s *Server) refundCapture(id string, requested *Amount) (refundResponse, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.captures[id]
+	switch found.status {
+	case statusVoided:
+		return refundResponse{}, unprocessable("CAPTURE_VOIDED", "capture "+id+" was voided")
+	case statusRefunded:
+		return refundResponse{}, unprocessable("CAPTURE_FULLY_REFUNDED", "capture "+id+" has been fully refunded")
+	}
+	amount, failure := resolveAmount(requested, found.remaining(), "REFUND_AMOUNT_EXCEEDED")
+	if failure != nil {
+		return refundResponse{}, failure
+	}
+	found.refunded = addAmounts(found.refunded, amount)
+	found.status = statusPartiallyRefunded
+	if found.remaining().isZero() {
+		found.status = statusRefunded
+	}
+	return refundResponse{ID: newID("REF"), Status: statusCompleted, Amount: amount, CreateTime: formatTime(s.now())}, nil
+}
+
+func (s *Server) handleVoidCapture(w http.ResponseWriter, r *http.Request, id string) {
+	call, failure := s.findCapture(id, OperationVoidCapture, nil)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	if s.intercept(w, r, call) {
+		return
+	}
+
+	response, failure := s.voidCapture(id)
+	if failure != nil {
+		failure.write(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// voidCapture reverses a capture in full before it settles. PayPal itself
+// only refunds captures; the sandbox offers this so card-style voids can be
+// exercised too.
+func (s *Server) voidCapture(id string) (captureResponse, *apiFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.captures[id]
+	if found.status == statusVoided {
+		return captureResponse{}, unprocessable("CAPTURE_VOIDED", "capture "+id+" was voided")
+	}
+	if !found.refunded.isZero() {
+		return captureResponse{}, unprocessable("VOID_NOT_ALLOWED", "capture "+id+" has refunds")
+	}
+	found.status = statusVoided
+	return found.response(), nil
+}
+
+// resolveAmount checks a requested amount against what is left, defaulting
+// to all of it.
+func resolveAmount(requested *Amount, available Amount, exceededIssue string) (Amount, *apiFailure) {
+	if requested == nil {
+		return available, nil
+	}
+	if err := requested.validate(); err != nil {
+		return Amount{}, invalidRequest("INVALID_PARAMETER_VALUE", err.Error())
+	}
+	if requested.CurrencyCode != available.CurrencyCode {
+		return Amount{}, unprocessable("CURRENCY_MISMATCH", "expected "+available.CurrencyCode+", got "+requested.CurrencyCode)
+	}
+	if compareAmounts(*requested, available) > 0 {
+		return Amount{}, unprocessable(exceededIssue, "requested "+requested.Value+", available "+available.Value)
+	}
+	return *requested, nil
+}
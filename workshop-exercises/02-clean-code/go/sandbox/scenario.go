@@ -0,0 +1,258 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// SCENARIOS
+// Scripted outcomes so tests can make the sandbox decline, stall or fail
+// =============================================================================
+
+var ErrInvalidScenario = errors.New("invalid sandbox scenario")
+
+type Outcome string
+
+const (
+	OutcomeApprove     Outcome = "approve"
+	OutcomeDecline     Outcome = "decline"
+	OutcomeServerError Outcome = "server_error"
+)
+
+type Operation string
+
+const (
+	OperationToken                Operation = "token"
+	OperationCreateOrder          Operation = "create_order"
+	OperationCaptureOrder         Operation = "capture_order"
+	OperationAuthorizeOrder       Operation = "authorize_order"
+	OperationCaptureAuthorization Operation = "capture_authorization"
+	OperationVoidAuthorization    Operation = "void_authorization"
+	OperationRefundCapture        Operation = "refund_capture"
+	OperationVoidCapture          Operation = "void_capture"
+)
+
+var knownOperations = map[Operation]bool{
+	OperationToken:                true,
+	OperationCreateOrder:          true,
+	OperationCaptureOrder:         true,
+	OperationAuthorizeOrder:       true,
+	OperationCaptureAuthorization: true,
+	OperationVoidAuthorization:    true,
+	OperationRefundCapture:        true,
+	OperationVoidCapture:          true,
+}
+
+const (
+	DefaultDeclineIssue = "INSTRUMENT_DECLINED"
+	defaultErrorStatus  = 500
+)
+
+// Scenario scripts the answer to the calls it matches. Empty match fields
+// (Operation, ReferenceID, Amount) match anything; ReferenceID is the
+// purchase unit's reference_id, also for calls on its captures and
+// authorizations. Delay stalls the answer, whatever the outcome. Times
+// limits how many calls the scenario answers; zero answers all of them.
+type Scenario struct {
+	Operation   Operation
+	ReferenceID string
+	Amount      string
+	Outcome     Outcome
+	// Issue is the PayPal issue code of a decline, INSTRUMENT_DECLINED by
+	// default. Status is the 5xx status of a server error, 500 by default.
+	Issue  string
+	Status int
+	Delay  time.Duration
+	Times  int
+}
+
+type scenarioJSON struct {
+	Operation   Operation `json:"operation,omitempty"`
+	ReferenceID string    `json:"referenceId,omitempty"`
+	Amount      string    `json:"amount,omitempty"`
+	Outcome     Outcome   `json:"outcome,omitempty"`
+	Issue       string    `json:"issue,omitempty"`
+	Status      int       `json:"status,omitempty"`
+	Delay       string    `json:"delay,omitempty"`
+	Times       int       `json:"times,omitempty"`
+}
+
+func (s Scenario) MarshalJSON() ([]byte, error) {
+	encoded := scenarioJSON{
+		Operation:   s.Operation,
+		ReferenceID: s.ReferenceID,
+		Amount:      s.Amount,
+		Outcome:     s.Outcome,
+		Issue:       s.Issue,
+		Status:      s.Status,
+		Times:       s.Times,
+	}
+	if s.Delay > 0 {
+		encoded.Delay = s.Delay.String()
+	}
+	return json.Marshal(encoded)
+}
+
+func (s *Scenario) UnmarshalJSON(data []byte) error {
+	var decoded scenarioJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var delay time.Duration
+	if decoded.Delay != "" {
+		parsed, err := time.ParseDuration(decoded.Delay)
+		if err != nil {
+			return fmt.Errorf("%w: delay %q: %v", ErrInvalidScenario, decoded.Delay, err)
+		}
+		delay = parsed
+	}
+	*s = Scenario{
+		Operation:   decoded.Operation,
+		ReferenceID: decoded.ReferenceID,
+		Amount:      decoded.Amount,
+		Outcome:     decoded.Outcome,
+		Issue:       decoded.Issue,
+		Status:      decoded.Status,
+		Delay:       delay,
+		Times:       decoded.Times,
+	}
+	return nil
+}
+
+// normalize fills in defaults and rejects scenarios the sandbox cannot play.
+func (s Scenario) normalize() (Scenario, error) {
+	if s.Operation != "" && !knownOperations[s.Operation] {
+		return Scenario{}, fmt.Errorf("%w: unknown operation %q", ErrInvalidScenario, s.Operation)
+	}
+	if s.Delay < 0 || s.Times < 0 {
+		return Scenario{}, fmt.Errorf("%w: delay and times must not be negative", ErrInvalidScenario)
+	}
+	switch s.Outcome {
+	case "":
+		s.Outcome = OutcomeApprove
+	case OutcomeApprove:
+	case OutcomeDecline:
+		if s.Issue == "" {
+			s.Issue = DefaultDeclineIssue
+		}
+	case OutcomeServerError:
+		if s.Status == 0 {
+			s.Status = defaultErrorStatus
+		}
+		if s.Status < 500 || s.Status > 599 {
+			return Scenario{}, fmt.Errorf("%w: server error status %d is not 5xx", ErrInvalidScenario, s.Status)
+		}
+	default:
+		return Scenario{}, fmt.Errorf("%w: unknown outcome %q", ErrInvalidScenario, s.Outcome)
+	}
+	return s, nil
+}
+
+func (s Scenario) matches(call apiCall) bool {
+	if s.Operation != "" && s.Operation != call.operation {
+		return false
+	}
+	if s.ReferenceID != "" && s.ReferenceID != call.referenceID {
+		return false
+	}
+	if s.Amount != "" && !sameValue(s.Amount, call.amount) {
+		return false
+	}
+	return true
+}
+
+func sameValue(scripted string, actual string) bool {
+	left, leftOK := Amount{Value: scripted}.parse()
+	right, rightOK := Amount{Value: actual}.parse()
+	return leftOK && rightOK && left.Cmp(right) == 0
+}
+
+func ParseScenarios(data []byte) ([]Scenario, error) {
+	var scenarios []Scenario
+	if err := json.Unmarshal(data, &scenarios); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+	}
+	for i, scenario := range scenarios {
+		normalized, err := scenario.normalize()
+		if err != nil {
+			return nil, fmt.Errorf("scenario %d: %w", i, err)
+		}
+		scenarios[i] = normalized
+	}
+	return scenarios, nil
+}
+
+func LoadScenarios(path string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading sandbox scenarios: %w", err)
+	}
+	return ParseScenarios(data)
+}
+
+// apiCall is what a scenario is matched against.
+type apiCall struct {
+	operation   Operation
+	referenceID string
+	amount      string
+}
+
+type scriptedScenario struct {
+	scenario  Scenario
+	remaining int
+}
+
+// script plays scenarios first come, first served.
+type script struct {
+	mu        sync.Mutex
+	scenarios []*scriptedScenario
+}
+
+func (s *script) add(scenarios []Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, scenario := range scenarios {
+		s.scenarios = append(s.scenarios, &scriptedScenario{scenario: scenario, remaining: scenario.Times})
+	}
+}
+
+func (s *script) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = nil
+}
+
+func (s *script) pending() []Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := make([]Scenario, 0, len(s.scenarios))
+	for _, scripted := range s.scenarios {
+		scenario := scripted.scenario
+		scenario.Times = scripted.remaining
+		pending = append(pending, scenario)
+	}
+	return pending
+}
+
+func (s *script) match(call apiCall) (Scenario, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, scripted := range s.scenarios {
+		if !scripted.scenario.matches(call) {
+			continue
+		}
+		if scripted.scenario.Times > 0 {
+			scripted.remaining--
+			if scripted.remaining == 0 {
+				s.scenarios = append(s.scenarios[:i], s.scenarios[i+1:]...)
+			}
+		}
+		return scripted.scenario, true
+	}
+	return Scenario{}, false
+}
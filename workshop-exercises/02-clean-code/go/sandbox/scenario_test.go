@@ -0,0 +1,108 @@
+package sandbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// SCENARIO TESTS
+// Testing: scenario.go
+// =============================================================================
+
+func TestScenario_ParseScenarios_FillsDefaults(t *testing.T) {
+	// Arrange
+	data := []byte(`[
+		{"operation": "capture_order", "outcome": "decline"},
+		{"outcome": "server_error", "delay": "1.5s"},
+		{"referenceId": "ord-7"}
+	]`)
+
+	// Act
+	scenarios, err := ParseScenarios(data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scenarios[0].Issue != DefaultDeclineIssue {
+		t.Errorf("Expected a default decline issue, got %q", scenarios[0].Issue)
+	}
+	if scenarios[1].Status != 500 || scenarios[1].Delay != 1500*time.Millisecond {
+		t.Errorf("Expected status 500 after 1.5s, got %d after %s", scenarios[1].Status, scenarios[1].Delay)
+	}
+	if scenarios[2].Outcome != OutcomeApprove {
+		t.Errorf("Expected an approval by default, got %s", scenarios[2].Outcome)
+	}
+}
+
+func TestScenario_ParseScenarios_InvalidScenario_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"unknown outcome", `[{"outcome": "explode"}]`},
+		{"unknown operation", `[{"operation": "teleport"}]`},
+		{"non 5xx status", `[{"outcome": "server_error", "status": 404}]`},
+		{"bad delay", `[{"delay": "soon"}]`},
+		{"negative times", `[{"times": -1}]`},
+		{"not an array", `{"outcome": "approve"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, err := ParseScenarios([]byte(tc.data))
+
+			// Assert
+			if !errors.Is(err, ErrInvalidScenario) {
+				t.Errorf("Expected ErrInvalidScenario, got %v", err)
+			}
+		})
+	}
+}
+
+func TestScenario_Script_FirstMatchWinsAndTimesRunOut(t *testing.T) {
+	// Arrange
+	var played script
+	played.add([]Scenario{
+		{ReferenceID: "ord-1", Outcome: OutcomeDecline, Times: 1},
+		{Amount: "10", Outcome: OutcomeServerError},
+	})
+	call := apiCall{operation: OperationCaptureOrder, referenceID: "ord-1", amount: "10.00"}
+
+	// Act
+	first, firstOK := played.match(call)
+	second, secondOK := played.match(call)
+	other, otherOK := played.match(apiCall{operation: OperationCaptureOrder, referenceID: "ord-2", amount: "11.00"})
+
+	// Assert
+	if !firstOK || first.Outcome != OutcomeDecline {
+		t.Errorf("Expected the decline to match first, got %+v", first)
+	}
+	if !secondOK || second.Outcome != OutcomeServerError {
+		t.Errorf("Expected the amount scenario once the decline ran out, got %+v", second)
+	}
+	if otherOK {
+		t.Errorf("Expected no scenario for another order and amount, got %+v", other)
+	}
+}
+
+func TestScenario_MarshalJSON_RoundTrip(t *testing.T) {
+	// Arrange
+	original := Scenario{Operation: OperationRefundCapture, Outcome: OutcomeServerError, Status: 502, Delay: 2 * time.Second, Times: 3}
+
+	// Act
+	data, err := original.MarshalJSON()
+	var decoded Scenario
+	decodeErr := decoded.UnmarshalJSON(data)
+
+	// Assert
+	if err != nil || decodeErr != nil {
+		t.Fatalf("Expected no errors, got %v / %v", err, decodeErr)
+	}
+	if decoded != original {
+		t.Errorf("Expected %+v, got %+v", original, decoded)
+	}
+}
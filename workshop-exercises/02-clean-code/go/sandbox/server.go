@@ -0,0 +1,412 @@
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// SANDBOX SERVER
+// Local stand-in for the PayPal REST API: OAuth tokens, orders and payments
+// =============================================================================
+
+// Config leaves zero fields at their DefaultConfig values.
+type Config struct {
+	ClientID         string
+	ClientSecret     string
+	TokenTTL         time.Duration
+	AuthorizationTTL time.Duration
+	Now              func() time.Time
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ClientID:         "sandbox-client",
+		ClientSecret:     "sandbox-secret",
+		TokenTTL:         9 * time.Hour,
+		AuthorizationTTL: 3 * 24 * time.Hour, // PayPal's honor period
+		Now:              time.Now,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if c.ClientID == "" {
+		c.ClientID = defaults.ClientID
+	}
+	if c.ClientSecret == "" {
+		c.ClientSecret = defaults.ClientSecret
+	}
+	if c.TokenTTL <= 0 {
+		c.TokenTTL = defaults.TokenTTL
+	}
+	if c.AuthorizationTTL <= 0 {
+		c.AuthorizationTTL = defaults.AuthorizationTTL
+	}
+	if c.Now == nil {
+		c.Now = defaults.Now
+	}
+	return c
+}
+
+// Server is an http.Handler serving the sandbox API. It approves every order
+// on creation (there is no buyer to send to an approval page) and keeps all
+// state in memory. Scenarios change how it answers; see Script.
+type Server struct {
+	config Config
+	script script
+
+	mu             sync.Mutex
+	tokens         map[string]time.Time
+	orders         map[string]*order
+	authorizations map[string]*authorization
+	captures       map[string]*capture
+	replies        map[string]reply
+}
+
+func NewServer(config Config) *Server {
+	return &Server{
+		config:         config.withDefaults(),
+		tokens:         make(map[string]time.Time),
+		orders:         make(map[string]*order),
+		authorizations: make(map[string]*authorization),
+		captures:       make(map[string]*capture),
+		replies:        make(map[string]reply),
+	}
+}
+
+// Script queues scenarios behind the ones already scripted.
+func (s *Server) Script(scenarios ...Scenario) error {
+	normalized := make([]Scenario, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		scenario, err := scenario.normalize()
+		if err != nil {
+			return err
+		}
+		normalized = append(normalized, scenario)
+	}
+	s.script.add(normalized)
+	return nil
+}
+
+func (s *Server) ResetScenarios() {
+	s.script.reset()
+}
+
+type route struct {
+	pattern []string
+	handler func(s *Server, w http.ResponseWriter, r *http.Request, id string)
+}
+
+var paymentRoutes = []route{
+	{[]string{"v2", "checkout", "orders"}, (*Server).handleCreateOrder},
+	{[]string{"v2", "checkout", "orders", "{id}", "capture"}, (*Server).handleCaptureOrder},
+	{[]string{"v2", "checkout", "orders", "{id}", "authorize"}, (*Server).handleAuthorizeOrder},
+	{[]string{"v2", "payments", "authorizations", "{id}", "capture"}, (*Server).handleCaptureAuthorization},
+	{[]string{"v2", "payments", "authorizations", "{id}", "void"}, (*Server).handleVoidAuthorization},
+	{[]string{"v2", "payments", "captures", "{id}", "refund"}, (*Server).handleRefundCapture},
+	{[]string{"v2", "payments", "captures", "{id}", "void"}, (*Server).handleVoidCapture},
+}
+
+func (r route) match(segments []string) (string, bool) {
+	if len(segments) != len(r.pattern) {
+		return "", false
+	}
+	id := ""
+	for i, part := range r.pattern {
+		if part == "{id}" {
+			id = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return "", false
+		}
+	}
+	return id, true
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "v1/oauth2/token":
+		s.routeToken(w, r)
+	case path == "sandbox/scenarios":
+		s.handleScenarios(w, r)
+	case strings.HasPrefix(path, "v2/"):
+		s.routePayments(w, r, strings.Split(path, "/"))
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "", "unknown endpoint "+r.URL.Path)
+	}
+}
+
+func (s *Server) routeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_SUPPORTED", "", r.Method+" is not supported")
+		return
+	}
+	s.handleToken(w, r)
+}
+
+func (s *Server) routePayments(w http.ResponseWriter, r *http.Request, segments []string) {
+	if !s.authenticated(r) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token", "missing, unknown or expired access token")
+		return
+	}
+	for _, candidate := range paymentRoutes {
+		id, ok := candidate.match(segments)
+		if !ok {
+			continue
+		}
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_SUPPORTED", "", r.Method+" is not supported")
+			return
+		}
+		s.handleOnce(w, r, func(w http.ResponseWriter) { candidate.handler(s, w, r, id) })
+		return
+	}
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "", "unknown endpoint "+r.URL.Path)
+}
+
+// =============================================================================
+// REQUEST IDS
+// A call repeated with the PayPal-Request-Id of one that succeeded gets the
+// first answer again instead of being carried out twice
+// =============================================================================
+
+type reply struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// recordingWriter keeps a copy of what the handler wrote.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   []byte
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(body []byte) (int, error) {
+	w.body = append(w.body, body...)
+	return w.ResponseWriter.Write(body)
+}
+
+// handleOnce only remembers successful answers, so a call that failed can
+// be tried again with the same request ID.
+func (s *Server) handleOnce(w http.ResponseWriter, r *http.Request, handle func(http.ResponseWriter)) {
+	requestID := r.Header.Get("PayPal-Request-Id")
+	if requestID == "" {
+		handle(w)
+		return
+	}
+	key := requestID + " " + r.URL.Path
+	s.mu.Lock()
+	previous, ok := s.replies[key]
+	s.mu.Unlock()
+	if ok {
+		for name, values := range previous.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(previous.status)
+		_, _ = w.Write(previous.body)
+		return
+	}
+
+	recorder := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+	handle(recorder)
+	if recorder.status >= 200 && recorder.status <= 299 {
+		s.mu.Lock()
+		s.replies[key] = reply{status: recorder.status, header: w.Header().Clone(), body: recorder.body}
+		s.mu.Unlock()
+	}
+}
+
+// =============================================================================
+// OAUTH
+// Client-credentials tokens, required as a Bearer header on every /v2 call
+// =============================================================================
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || !s.validClient(clientID, secret) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client Authentication failed")
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be client_credentials")
+		return
+	}
+	if s.intercept(w, r, apiCall{operation: OperationToken}) {
+		return
+	}
+
+	token := newID("A21AA")
+	s.mu.Lock()
+	s.tokens[token] = s.now().Add(s.config.TokenTTL)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.TokenTTL / time.Second),
+	})
+}
+
+func (s *Server) validClient(clientID string, secret string) bool {
+	idMatches := subtle.ConstantTimeCompare([]byte(clientID), []byte(s.config.ClientID)) == 1
+	secretMatches := subtle.ConstantTimeCompare([]byte(secret), []byte(s.config.ClientSecret)) == 1
+	return idMatches && secretMatches
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.tokens[token]
+	if !ok {
+		return false
+	}
+	if !s.now().Before(expiresAt) {
+		delete(s.tokens, token)
+		return false
+	}
+	return true
+}
+
+func (s *Server) now() time.Time {
+	return s.config.Now().UTC()
+}
+
+// =============================================================================
+// SCRIPTED OUTCOMES
+// =============================================================================
+
+// intercept plays the first scenario matching the call. It reports whether
+// the response has been written; an approval (after any delay) leaves the
+// call to carry on as normal.
+func (s *Server) intercept(w http.ResponseWriter, r *http.Request, call apiCall) bool {
+	scenario, ok := s.script.match(call)
+	if !ok {
+		return false
+	}
+	if err := wait(r.Context(), scenario.Delay); err != nil {
+		return true // the client gave up; nobody is listening
+	}
+	switch scenario.Outcome {
+	case OutcomeDecline:
+		writeError(w, http.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", scenario.Issue, "The requested action could not be performed (scripted decline).")
+		return true
+	case OutcomeServerError:
+		writeError(w, scenario.Status, "INTERNAL_SERVER_ERROR", "", "An internal server error occurred (scripted).")
+		return true
+	default:
+		return false
+	}
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.script.pending())
+	case http.MethodPost:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "", err.Error())
+			return
+		}
+		scenarios, err := ParseScenarios(data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "", err.Error())
+			return
+		}
+		s.script.add(scenarios)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.ResetScenarios()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_SUPPORTED", "", r.Method+" is not supported")
+	}
+}
+
+// =============================================================================
+// RESPONSES
+// =============================================================================
+
+type errorDetail struct {
+	Issue       string `json:"issue"`
+	Description string `json:"description,omitempty"`
+}
+
+type errorResponse struct {
+	Name    string        `json:"name"`
+	Message string        `json:"message"`
+	Details []errorDetail `json:"details,omitempty"`
+}
+
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func writeError(w http.ResponseWriter, status int, name string, issue string, message string) {
+	response := errorResponse{Name: name, Message: message}
+	if issue != "" {
+		response.Details = []errorDetail{{Issue: issue}}
+	}
+	writeJSON(w, status, response)
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string, description string) {
+	writeJSON(w, status, oauthErrorResponse{Error: code, ErrorDescription: description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newID(prefix string) string {
+	buffer := make([]byte, 8)
+	if _, err := rand.Read(buffer); err != nil {
+		panic(err)
+	}
+	return prefix + strings.ToUpper(hex.EncodeToString(buffer))
+}
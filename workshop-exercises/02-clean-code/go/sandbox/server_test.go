@@ -0,0 +1,457 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// SANDBOX SERVER TESTS
+// Testing: server.go, orders.go
+// =============================================================================
+
+type sandboxFixture struct {
+	t         *testing.T
+	server    *Server
+	http      *httptest.Server
+	token     string
+	requestID string
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newSandboxFixture(t *testing.T) *sandboxFixture {
+	t.Helper()
+	fixture := &sandboxFixture{t: t, now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	fixture.server = NewServer(Config{
+		TokenTTL:         time.Hour,
+		AuthorizationTTL: 72 * time.Hour,
+		Now:              fixture.clock,
+	})
+	fixture.http = httptest.NewServer(fixture.server)
+	t.Cleanup(fixture.http.Close)
+	return fixture
+}
+
+func (f *sandboxFixture) clock() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *sandboxFixture) advance(duration time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(duration)
+}
+
+func (f *sandboxFixture) requestToken(clientID string, secret string) *http.Response {
+	f.t.Helper()
+	form := url.Values{"grant_type": {"client_credentials"}}
+	request, _ := http.NewRequest(http.MethodPost, f.http.URL+"/v1/oauth2/token", strings.NewReader(form.Encode()))
+	request.SetBasicAuth(clientID, secret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		f.t.Fatalf("Expected token response, got %v", err)
+	}
+	return response
+}
+
+func (f *sandboxFixture) authenticate() {
+	f.t.Helper()
+	response := f.requestToken("sandbox-client", "sandbox-secret")
+	defer response.Body.Close()
+	var token tokenResponse
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		f.t.Fatalf("Expected an access token, got %v (status %d)", err, response.StatusCode)
+	}
+	f.token = token.AccessToken
+}
+
+func (f *sandboxFixture) post(path string, body string, decoded any) int {
+	f.t.Helper()
+	request, _ := http.NewRequest(http.MethodPost, f.http.URL+path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if f.token != "" {
+		request.Header.Set("Authorization", "Bearer "+f.token)
+	}
+	if f.requestID != "" {
+		request.Header.Set("PayPal-Request-Id", f.requestID)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		f.t.Fatalf("Expected a response from %s, got %v", path, err)
+	}
+	defer response.Body.Close()
+	if decoded != nil {
+		_ = json.NewDecoder(response.Body).Decode(decoded)
+	}
+	return response.StatusCode
+}
+
+func (f *sandboxFixture) createOrder(intent string, value string) orderResponse {
+	f.t.Helper()
+	var order orderResponse
+	status := f.post("/v2/checkout/orders", `{"intent":"`+intent+`","purchase_units":[{"reference_id":"ord-1","amount":{"currency_code":"USD","value":"`+value+`"}}]}`, &order)
+	if status != http.StatusCreated {
+		f.t.Fatalf("Expected 201 creating the order, got %d", status)
+	}
+	return order
+}
+
+func (f *sandboxFixture) capturedPayment(value string) captureResponse {
+	f.t.Helper()
+	order := f.createOrder(IntentCapture, value)
+	var captured orderResponse
+	if status := f.post("/v2/checkout/orders/"+order.ID+"/capture", "", &captured); status != http.StatusCreated {
+		f.t.Fatalf("Expected 201 capturing the order, got %d", status)
+	}
+	return captured.PurchaseUnits[0].Payments.Captures[0]
+}
+
+func (f *sandboxFixture) authorizedPayment(value string) authorizationResponse {
+	f.t.Helper()
+	order := f.createOrder(IntentAuthorize, value)
+	var authorized orderResponse
+	if status := f.post("/v2/checkout/orders/"+order.ID+"/authorize", "", &authorized); status != http.StatusCreated {
+		f.t.Fatalf("Expected 201 authorizing the order, got %d", status)
+	}
+	return authorized.PurchaseUnits[0].Payments.Authorizations[0]
+}
+
+func TestSandbox_Token_ValidClient_IssuesBearerToken(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+
+	// Act
+	response := fixture.requestToken("sandbox-client", "sandbox-secret")
+	defer response.Body.Close()
+	var token tokenResponse
+	_ = json.NewDecoder(response.Body).Decode(&token)
+
+	// Assert
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.StatusCode)
+	}
+	if token.TokenType != "Bearer" || token.AccessToken == "" {
+		t.Errorf("Expected a Bearer token, got %+v", token)
+	}
+	if token.ExpiresIn != 3600 {
+		t.Errorf("Expected the token to last 3600s, got %d", token.ExpiresIn)
+	}
+}
+
+func TestSandbox_Token_WrongSecret_ReturnsUnauthorized(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+
+	// Act
+	response := fixture.requestToken("sandbox-client", "wrong")
+	defer response.Body.Close()
+
+	// Assert
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", response.StatusCode)
+	}
+}
+
+func TestSandbox_Payments_MissingOrExpiredToken_ReturnsUnauthorized(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	body := `{"intent":"CAPTURE","purchase_units":[{"amount":{"currency_code":"USD","value":"10.00"}}]}`
+
+	// Act
+	withoutToken := fixture.post("/v2/checkout/orders", body, nil)
+	fixture.authenticate()
+	fixture.advance(time.Hour)
+	withExpiredToken := fixture.post("/v2/checkout/orders", body, nil)
+
+	// Assert
+	if withoutToken != http.StatusUnauthorized || withExpiredToken != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without and with an expired token, got %d and %d", withoutToken, withExpiredToken)
+	}
+}
+
+func TestSandbox_CaptureOrder_ApprovedOrder_CompletesCapture(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	order := fixture.createOrder(IntentCapture, "103.49")
+
+	// Act
+	var captured orderResponse
+	status := fixture.post("/v2/checkout/orders/"+order.ID+"/capture", "", &captured)
+	again := fixture.post("/v2/checkout/orders/"+order.ID+"/capture", "", nil)
+
+	// Assert
+	if status != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", status)
+	}
+	capture := captured.PurchaseUnits[0].Payments.Captures[0]
+	if capture.Status != statusCompleted || capture.Amount.Value != "103.49" {
+		t.Errorf("Expected a completed capture of 103.49, got %+v", capture)
+	}
+	if captured.PurchaseUnits[0].ReferenceID != "ord-1" {
+		t.Errorf("Expected reference ID ord-1, got %s", captured.PurchaseUnits[0].ReferenceID)
+	}
+	if again != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a second capture to be refused with 422, got %d", again)
+	}
+}
+
+func TestSandbox_CreateOrder_InvalidAmount_ReturnsBadRequest(t *testing.T) {
+	testCases := []string{"0.00", "-5.00", "1.005", "abc"}
+
+	for _, value := range testCases {
+		t.Run(value, func(t *testing.T) {
+			// Arrange
+			fixture := newSandboxFixture(t)
+			fixture.authenticate()
+
+			// Act
+			status := fixture.post("/v2/checkout/orders", `{"intent":"CAPTURE","purchase_units":[{"amount":{"currency_code":"USD","value":"`+value+`"}}]}`, nil)
+
+			// Assert
+			if status != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", status)
+			}
+		})
+	}
+}
+
+func TestSandbox_RefundCapture_PartialThenTooMuch_TracksRemaining(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	capture := fixture.capturedPayment("50.00")
+	path := "/v2/payments/captures/" + capture.ID + "/refund"
+
+	// Act
+	var refund refundResponse
+	partial := fixture.post(path, `{"amount":{"currency_code":"USD","value":"20.00"}}`, &refund)
+	var failure errorResponse
+	tooMuch := fixture.post(path, `{"amount":{"currency_code":"USD","value":"30.01"}}`, &failure)
+	rest := fixture.post(path, "", nil)
+	afterFull := fixture.post(path, "", nil)
+
+	// Assert
+	if partial != http.StatusCreated || refund.Amount.Value != "20.00" {
+		t.Errorf("Expected a 20.00 refund, got %d %+v", partial, refund)
+	}
+	if tooMuch != http.StatusUnprocessableEntity || failure.Details[0].Issue != "REFUND_AMOUNT_EXCEEDED" {
+		t.Errorf("Expected REFUND_AMOUNT_EXCEEDED, got %d %+v", tooMuch, failure)
+	}
+	if rest != http.StatusCreated {
+		t.Errorf("Expected the remaining 30.00 to be refundable, got %d", rest)
+	}
+	if afterFull != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a fully refunded capture to refuse refunds, got %d", afterFull)
+	}
+}
+
+func TestSandbox_RefundCapture_RepeatedRequestID_ReplaysFirstRefund(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	capture := fixture.capturedPayment("50.00")
+	path := "/v2/payments/captures/" + capture.ID + "/refund"
+	body := `{"amount":{"currency_code":"USD","value":"20.00"}}`
+
+	// Act
+	fixture.requestID = "refund-1"
+	var first, repeated, next refundResponse
+	firstStatus := fixture.post(path, body, &first)
+	repeatedStatus := fixture.post(path, body, &repeated)
+	fixture.requestID = "refund-2"
+	nextStatus := fixture.post(path, `{"amount":{"currency_code":"USD","value":"30.00"}}`, &next)
+
+	// Assert
+	if firstStatus != http.StatusCreated || repeatedStatus != http.StatusCreated || repeated.ID != first.ID {
+		t.Errorf("Expected the repeat to replay refund %s, got %d %+v", first.ID, repeatedStatus, repeated)
+	}
+	if nextStatus != http.StatusCreated || next.ID == first.ID {
+		t.Errorf("Expected the remaining 30.00 to be refundable once, got %d %+v", nextStatus, next)
+	}
+}
+
+func TestSandbox_VoidCapture_AfterRefund_IsNotAllowed(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	capture := fixture.capturedPayment("50.00")
+	fixture.post("/v2/payments/captures/"+capture.ID+"/refund", `{"amount":{"currency_code":"USD","value":"1.00"}}`, nil)
+
+	// Act
+	var failure errorResponse
+	status := fixture.post("/v2/payments/captures/"+capture.ID+"/void", "", &failure)
+
+	// Assert
+	if status != http.StatusUnprocessableEntity || failure.Details[0].Issue != "VOID_NOT_ALLOWED" {
+		t.Errorf("Expected VOID_NOT_ALLOWED, got %d %+v", status, failure)
+	}
+}
+
+func TestSandbox_CaptureAuthorization_PartialAmount_SettlesAndClosesAuthorization(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	authorization := fixture.authorizedPayment("100.00")
+	path := "/v2/payments/authorizations/" + authorization.ID + "/capture"
+
+	// Act
+	var capture captureResponse
+	status := fixture.post(path, `{"amount":{"currency_code":"USD","value":"60.00"}}`, &capture)
+	var failure errorResponse
+	again := fixture.post(path, "", &failure)
+
+	// Assert
+	if status != http.StatusCreated || capture.Amount.Value != "60.00" {
+		t.Errorf("Expected a 60.00 capture, got %d %+v", status, capture)
+	}
+	if again != http.StatusUnprocessableEntity || failure.Details[0].Issue != "AUTHORIZATION_ALREADY_CAPTURED" {
+		t.Errorf("Expected AUTHORIZATION_ALREADY_CAPTURED, got %d %+v", again, failure)
+	}
+}
+
+func TestSandbox_CaptureAuthorization_AfterHonorPeriod_ReturnsExpired(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	authorization := fixture.authorizedPayment("100.00")
+	fixture.advance(72 * time.Hour)
+	fixture.authenticate()
+
+	// Act
+	var failure errorResponse
+	status := fixture.post("/v2/payments/authorizations/"+authorization.ID+"/capture", "", &failure)
+
+	// Assert
+	if status != http.StatusUnprocessableEntity || failure.Details[0].Issue != "AUTHORIZATION_EXPIRED" {
+		t.Errorf("Expected AUTHORIZATION_EXPIRED, got %d %+v", status, failure)
+	}
+}
+
+func TestSandbox_VoidAuthorization_PendingAuthorization_ReturnsNoContent(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	authorization := fixture.authorizedPayment("100.00")
+
+	// Act
+	status := fixture.post("/v2/payments/authorizations/"+authorization.ID+"/void", "", nil)
+	capture := fixture.post("/v2/payments/authorizations/"+authorization.ID+"/capture", "", nil)
+
+	// Assert
+	if status != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", status)
+	}
+	if capture != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a voided authorization to refuse captures, got %d", capture)
+	}
+}
+
+func TestSandbox_UnknownResource_ReturnsNotFound(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+
+	// Act
+	var failure errorResponse
+	status := fixture.post("/v2/payments/captures/CAP-MISSING/refund", "", &failure)
+
+	// Assert
+	if status != http.StatusNotFound || failure.Name != "RESOURCE_NOT_FOUND" {
+		t.Errorf("Expected RESOURCE_NOT_FOUND, got %d %+v", status, failure)
+	}
+}
+
+func TestSandbox_ScriptedDecline_OnlyMatchingCallIsDeclined(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	if err := fixture.server.Script(Scenario{Operation: OperationCaptureOrder, Amount: "13.13", Outcome: OutcomeDecline, Times: 1}); err != nil {
+		t.Fatalf("Expected a valid scenario, got %v", err)
+	}
+	declinedOrder := fixture.createOrder(IntentCapture, "13.13")
+
+	// Act
+	var failure errorResponse
+	declined := fixture.post("/v2/checkout/orders/"+declinedOrder.ID+"/capture", "", &failure)
+	retried := fixture.post("/v2/checkout/orders/"+declinedOrder.ID+"/capture", "", nil)
+
+	// Assert
+	if declined != http.StatusUnprocessableEntity || failure.Details[0].Issue != DefaultDeclineIssue {
+		t.Errorf("Expected INSTRUMENT_DECLINED, got %d %+v", declined, failure)
+	}
+	if retried != http.StatusCreated {
+		t.Errorf("Expected the one-off scenario to be used up, got %d", retried)
+	}
+}
+
+func TestSandbox_ScriptedServerError_ReturnsScriptedStatus(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	_ = fixture.server.Script(Scenario{Operation: OperationCreateOrder, Outcome: OutcomeServerError, Status: http.StatusServiceUnavailable})
+
+	// Act
+	status := fixture.post("/v2/checkout/orders", `{"intent":"CAPTURE","purchase_units":[{"amount":{"currency_code":"USD","value":"10.00"}}]}`, nil)
+
+	// Assert
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", status)
+	}
+}
+
+func TestSandbox_ScriptedDelay_ApprovesAfterDelay(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+	fixture.authenticate()
+	_ = fixture.server.Script(Scenario{Operation: OperationCreateOrder, Delay: 50 * time.Millisecond})
+
+	// Act
+	start := time.Now()
+	status := fixture.post("/v2/checkout/orders", `{"intent":"CAPTURE","purchase_units":[{"amount":{"currency_code":"USD","value":"10.00"}}]}`, nil)
+
+	// Assert
+	if status != http.StatusCreated {
+		t.Errorf("Expected the delayed order to be created, got %d", status)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected the answer to take at least 50ms, took %s", elapsed)
+	}
+}
+
+func TestSandbox_ScenariosEndpoint_PostListDelete(t *testing.T) {
+	// Arrange
+	fixture := newSandboxFixture(t)
+
+	// Act
+	posted := fixture.post("/sandbox/scenarios", `[{"operation":"capture_order","outcome":"decline","issue":"INSUFFICIENT_FUNDS","times":2}]`, nil)
+	pending := fixture.server.script.pending()
+	request, _ := http.NewRequest(http.MethodDelete, fixture.http.URL+"/sandbox/scenarios", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Expected a response, got %v", err)
+	}
+	response.Body.Close()
+
+	// Assert
+	if posted != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", posted)
+	}
+	if len(pending) != 1 || pending[0].Issue != "INSUFFICIENT_FUNDS" || pending[0].Times != 2 {
+		t.Errorf("Expected the posted scenario to be pending, got %+v", pending)
+	}
+	if remaining := fixture.server.script.pending(); len(remaining) != 0 {
+		t.Errorf("Expected DELETE to clear scenarios, got %+v", remaining)
+	}
+}